@@ -17,6 +17,7 @@ var USGovWest = Region{
 	"https://autoscaling.us-gov-west-1.amazonaws.com",
 	ServiceInfo{"https://rds.us-gov-west-1.amazonaws.com", V2Signature},
 	"",
+	"https://streams.dynamodb.us-gov-west-1.amazonaws.com",
 }
 
 var USEast = Region{
@@ -36,6 +37,7 @@ var USEast = Region{
 	"https://autoscaling.us-east-1.amazonaws.com",
 	ServiceInfo{"https://rds.us-east-1.amazonaws.com", V2Signature},
 	"https://kinesis.us-east-1.amazonaws.com",
+	"https://streams.dynamodb.us-east-1.amazonaws.com",
 }
 
 var USWest = Region{
@@ -55,6 +57,7 @@ var USWest = Region{
 	"https://autoscaling.us-west-1.amazonaws.com",
 	ServiceInfo{"https://rds.us-west-1.amazonaws.com", V2Signature},
 	"",
+	"https://streams.dynamodb.us-west-1.amazonaws.com",
 }
 
 var USWest2 = Region{
@@ -74,6 +77,7 @@ var USWest2 = Region{
 	"https://autoscaling.us-west-2.amazonaws.com",
 	ServiceInfo{"https://rds.us-west-2.amazonaws.com", V2Signature},
 	"https://kinesis.us-west-2.amazonaws.com",
+	"https://streams.dynamodb.us-west-2.amazonaws.com",
 }
 
 var EUWest = Region{
@@ -93,6 +97,7 @@ var EUWest = Region{
 	"https://autoscaling.eu-west-1.amazonaws.com",
 	ServiceInfo{"https://rds.eu-west-1.amazonaws.com", V2Signature},
 	"https://kinesis.eu-west-1.amazonaws.com",
+	"https://streams.dynamodb.eu-west-1.amazonaws.com",
 }
 
 var APSoutheast = Region{
@@ -112,6 +117,7 @@ var APSoutheast = Region{
 	"https://autoscaling.ap-southeast-1.amazonaws.com",
 	ServiceInfo{"https://rds.ap-southeast-1.amazonaws.com", V2Signature},
 	"",
+	"https://streams.dynamodb.ap-southeast-1.amazonaws.com",
 }
 
 var APSoutheast2 = Region{
@@ -131,6 +137,7 @@ var APSoutheast2 = Region{
 	"https://autoscaling.ap-southeast-2.amazonaws.com",
 	ServiceInfo{"https://rds.ap-southeast-2.amazonaws.com", V2Signature},
 	"",
+	"https://streams.dynamodb.ap-southeast-2.amazonaws.com",
 }
 
 var APNortheast = Region{
@@ -150,6 +157,7 @@ var APNortheast = Region{
 	"https://autoscaling.ap-northeast-1.amazonaws.com",
 	ServiceInfo{"https://rds.ap-northeast-1.amazonaws.com", V2Signature},
 	"",
+	"https://streams.dynamodb.ap-northeast-1.amazonaws.com",
 }
 
 var SAEast = Region{
@@ -169,4 +177,5 @@ var SAEast = Region{
 	"https://autoscaling.sa-east-1.amazonaws.com",
 	ServiceInfo{"https://rds.sa-east-1.amazonaws.com", V2Signature},
 	"",
+	"https://streams.dynamodb.sa-east-1.amazonaws.com",
 }
@@ -0,0 +1,77 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// WaitResult is the outcome of a single poll of a resource by a Waiter.
+type WaitResult int
+
+const (
+	WaitRetry   WaitResult = iota // The resource is not in the desired state yet
+	WaitSuccess                   // The resource reached the desired state
+	WaitFailure                   // The resource can no longer reach the desired state
+)
+
+// WaitFunc polls a resource once. It returns the outcome of the poll
+// along with the observed state of the resource, which is reported in
+// the WaiterError returned by Wait when waiting fails. Returning an
+// error aborts the wait.
+type WaitFunc func() (result WaitResult, state string, err error)
+
+// Waiter polls a resource until it reaches a desired state. It is used
+// by the WaitUntil* functions of other goamz packages, which accept a
+// *Waiter to override their default delay and number of attempts.
+type Waiter struct {
+	Delay       time.Duration // Interval between attempts
+	MaxAttempts int           // Maximum number of attempts; zero means no limit
+}
+
+// WaiterError is returned by Wait when the resource reached a failure
+// state or the maximum number of attempts was exhausted.
+type WaiterError struct {
+	Name     string // Name of the waiter, e.g. "InstanceRunning"
+	State    string // Last state observed
+	Attempts int    // Number of attempts made
+	Timeout  bool   // Whether the waiter ran out of attempts
+}
+
+func (err *WaiterError) Error() string {
+	if err.Timeout {
+		return fmt.Sprintf("waiter %s: gave up after %d attempts (last state %q)", err.Name, err.Attempts, err.State)
+	}
+	return fmt.Sprintf("waiter %s: resource entered failure state %q", err.Name, err.State)
+}
+
+// Wait calls fn until it reports success or failure, sleeping for
+// w.Delay between calls. The name identifies the waiter in errors. Wait
+// returns ctx.Err() as soon as ctx is done.
+func (w Waiter) Wait(ctx context.Context, name string, fn WaitFunc) error {
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		result, state, err := fn()
+		if err != nil {
+			return err
+		}
+		switch result {
+		case WaitSuccess:
+			return nil
+		case WaitFailure:
+			return &WaiterError{Name: name, State: state, Attempts: attempt}
+		}
+		if w.MaxAttempts > 0 && attempt >= w.MaxAttempts {
+			return &WaiterError{Name: name, State: state, Attempts: attempt, Timeout: true}
+		}
+		timer := time.NewTimer(w.Delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
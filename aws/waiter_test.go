@@ -0,0 +1,76 @@
+package aws_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/crowdmob/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+func (S) TestWaiterSuccess(c *check.C) {
+	calls := 0
+	w := aws.Waiter{Delay: 1e6, MaxAttempts: 5}
+	err := w.Wait(context.Background(), "Test", func() (aws.WaitResult, string, error) {
+		calls++
+		if calls < 3 {
+			return aws.WaitRetry, "pending", nil
+		}
+		return aws.WaitSuccess, "running", nil
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(calls, check.Equals, 3)
+}
+
+func (S) TestWaiterFailure(c *check.C) {
+	w := aws.Waiter{Delay: 1e6, MaxAttempts: 5}
+	err := w.Wait(context.Background(), "Test", func() (aws.WaitResult, string, error) {
+		return aws.WaitFailure, "terminated", nil
+	})
+	c.Assert(err, check.DeepEquals, &aws.WaiterError{Name: "Test", State: "terminated", Attempts: 1})
+	c.Assert(err, check.ErrorMatches, `waiter Test: resource entered failure state "terminated"`)
+}
+
+func (S) TestWaiterMaxAttempts(c *check.C) {
+	calls := 0
+	w := aws.Waiter{Delay: 1e6, MaxAttempts: 3}
+	err := w.Wait(context.Background(), "Test", func() (aws.WaitResult, string, error) {
+		calls++
+		return aws.WaitRetry, "pending", nil
+	})
+	c.Assert(err, check.DeepEquals, &aws.WaiterError{Name: "Test", State: "pending", Attempts: 3, Timeout: true})
+	c.Assert(err, check.ErrorMatches, `waiter Test: gave up after 3 attempts \(last state "pending"\)`)
+	c.Assert(calls, check.Equals, 3)
+}
+
+func (S) TestWaiterError(c *check.C) {
+	w := aws.Waiter{Delay: 1e6, MaxAttempts: 3}
+	err := w.Wait(context.Background(), "Test", func() (aws.WaitResult, string, error) {
+		return aws.WaitRetry, "", errors.New("boom")
+	})
+	c.Assert(err, check.ErrorMatches, "boom")
+}
+
+func (S) TestWaiterContext(c *check.C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	w := aws.Waiter{Delay: time.Hour}
+	go func() {
+		time.Sleep(1e7)
+		cancel()
+	}()
+	err := w.Wait(ctx, "Test", func() (aws.WaitResult, string, error) {
+		calls++
+		return aws.WaitRetry, "pending", nil
+	})
+	c.Assert(err, check.Equals, context.Canceled)
+	c.Assert(calls, check.Equals, 1)
+
+	err = w.Wait(ctx, "Test", func() (aws.WaitResult, string, error) {
+		calls++
+		return aws.WaitSuccess, "running", nil
+	})
+	c.Assert(err, check.Equals, context.Canceled)
+	c.Assert(calls, check.Equals, 1)
+}
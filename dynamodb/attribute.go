@@ -13,6 +13,11 @@ const (
 	TYPE_NUMBER_SET = "NS"
 	TYPE_BINARY_SET = "BS"
 
+	TYPE_MAP     = "M"
+	TYPE_LIST    = "L"
+	TYPE_BOOLEAN = "BOOL"
+	TYPE_NULL    = "NULL"
+
 	COMPARISON_EQUAL                    = "EQ"
 	COMPARISON_NOT_EQUAL                = "NE"
 	COMPARISON_LESS_THAN_OR_EQUAL       = "LE"
@@ -39,11 +44,13 @@ type PrimaryKey struct {
 }
 
 type Attribute struct {
-	Type      string
-	Name      string
-	Value     string
-	SetValues []string
-	Exists    string // exists on dynamodb? Values: "true", "false", or ""
+	Type       string
+	Name       string
+	Value      string // "true" or "false" for BOOL attributes
+	SetValues  []string
+	MapValues  map[string]*Attribute // M attributes; each is named after its key
+	ListValues []*Attribute          // L attributes; the elements are unnamed
+	Exists     string                // exists on dynamodb? Values: "true", "false", or ""
 }
 
 type AttributeComparison struct {
@@ -140,6 +147,37 @@ func NewBinarySetAttribute(name string, values []string) *Attribute {
 	}
 }
 
+func NewMapAttribute(name string, values map[string]*Attribute) *Attribute {
+	return &Attribute{
+		Type:      TYPE_MAP,
+		Name:      name,
+		MapValues: values,
+	}
+}
+
+func NewListAttribute(name string, values []*Attribute) *Attribute {
+	return &Attribute{
+		Type:       TYPE_LIST,
+		Name:       name,
+		ListValues: values,
+	}
+}
+
+func NewBoolAttribute(name string, value bool) *Attribute {
+	return &Attribute{
+		Type:  TYPE_BOOLEAN,
+		Name:  name,
+		Value: strconv.FormatBool(value),
+	}
+}
+
+func NewNullAttribute(name string) *Attribute {
+	return &Attribute{
+		Type: TYPE_NULL,
+		Name: name,
+	}
+}
+
 func (a *Attribute) SetType() bool {
 	switch a.Type {
 	case TYPE_BINARY_SET, TYPE_NUMBER_SET, TYPE_STRING_SET:
@@ -148,6 +186,48 @@ func (a *Attribute) SetType() bool {
 	return false
 }
 
+// DocumentType reports whether the attribute is one of the document
+// types: M, L, BOOL or NULL.
+func (a *Attribute) DocumentType() bool {
+	switch a.Type {
+	case TYPE_MAP, TYPE_LIST, TYPE_BOOLEAN, TYPE_NULL:
+		return true
+	}
+	return false
+}
+
+// value returns the JSON value of the attribute, without its type.
+// Map and list attributes are converted recursively.
+func (a *Attribute) value() interface{} {
+	switch a.Type {
+	case TYPE_STRING_SET, TYPE_NUMBER_SET, TYPE_BINARY_SET:
+		return a.SetValues
+	case TYPE_MAP:
+		m := msi{}
+		for k, v := range a.MapValues {
+			m[k] = v.typedValue()
+		}
+		return m
+	case TYPE_LIST:
+		l := make([]interface{}, len(a.ListValues))
+		for i, v := range a.ListValues {
+			l[i] = v.typedValue()
+		}
+		return l
+	case TYPE_BOOLEAN:
+		return a.Value == "true"
+	case TYPE_NULL:
+		return true
+	}
+	return a.Value
+}
+
+// typedValue returns the attribute as a DynamoDB AttributeValue,
+// such as {"S": "foo"}.
+func (a *Attribute) typedValue() msi {
+	return msi{a.Type: a.value()}
+}
+
 func (a *Attribute) SetExists(exists bool) *Attribute {
 	if exists {
 		a.Exists = "true"
@@ -0,0 +1,393 @@
+package dynamodb
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	simplejson "github.com/bitly/go-simplejson"
+)
+
+const (
+	maxBatchWriteItems = 25
+	maxBatchGetKeys    = 100
+)
+
+// ErrUnprocessed is the error of batch entries that DynamoDB still
+// left unprocessed when the retry budget ran out.
+var ErrUnprocessed = errors.New("Item left unprocessed after all retries")
+
+// BatchOptions controls how ExecuteAll splits and retries batches.
+// The zero value, like a nil *BatchOptions, uses the defaults.
+type BatchOptions struct {
+	// MaxRetries is how many times a chunk is resubmitted after
+	// throttling errors or unprocessed entries. Defaults to 8.
+	MaxRetries int
+
+	// Concurrency is how many chunks run at the same time.
+	// Defaults to 4.
+	Concurrency int
+
+	// BaseDelay is the delay before the first resubmission of a
+	// chunk. It doubles with each further retry. Defaults to 50ms.
+	BaseDelay time.Duration
+}
+
+func (o *BatchOptions) withDefaults() BatchOptions {
+	var opts BatchOptions
+	if o != nil {
+		opts = *o
+	}
+	if opts.MaxRetries <= 0 {
+		opts.MaxRetries = 8
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	if opts.BaseDelay <= 0 {
+		opts.BaseDelay = 50 * time.Millisecond
+	}
+	return opts
+}
+
+// BatchWriteFailure is an entry of a BatchWriteItem that could not be
+// written: Err is ErrUnprocessed, or the error of the last request
+// that carried the entry.
+type BatchWriteFailure struct {
+	Table      *Table
+	Action     string // "Put" or "Delete"
+	Attributes []Attribute
+	Err        error
+}
+
+// BatchWriteError is returned by BatchWriteItem.ExecuteAll when some
+// entries could not be written. The other entries were written.
+type BatchWriteError struct {
+	Failures []BatchWriteFailure
+}
+
+func (e *BatchWriteError) Error() string {
+	return fmt.Sprintf("%d batch write items failed, first error: %v", len(e.Failures), e.Failures[0].Err)
+}
+
+// BatchGetFailure is a key of a BatchGetItem that could not be read.
+type BatchGetFailure struct {
+	Table *Table
+	Key   Key
+	Err   error
+}
+
+// BatchGetError is returned by BatchGetItem.ExecuteAll when some keys
+// could not be read. The items of the other keys are still returned.
+type BatchGetError struct {
+	Failures []BatchGetFailure
+}
+
+func (e *BatchGetError) Error() string {
+	return fmt.Sprintf("%d batch get keys failed, first error: %v", len(e.Failures), e.Failures[0].Err)
+}
+
+// writeRequest is a single entry of a BatchWriteItem.
+type writeRequest struct {
+	table  *Table
+	action string
+	attrs  []Attribute
+}
+
+// getRequest is a single key of a BatchGetItem.
+type getRequest struct {
+	table *Table
+	key   Key
+}
+
+// ExecuteAll writes all the items, however many there are. The items
+// are split into requests of at most 25 items which run concurrently,
+// and entries left unprocessed by DynamoDB are resubmitted with
+// exponential backoff. If some entries still fail, the error is a
+// *BatchWriteError listing them.
+func (batchWriteItem *BatchWriteItem) ExecuteAll(opts *BatchOptions) error {
+	o := opts.withDefaults()
+
+	// Iterate in a stable order, so that chunks are reproducible.
+	tables := make([]*Table, 0, len(batchWriteItem.ItemActions))
+	for t := range batchWriteItem.ItemActions {
+		tables = append(tables, t)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+
+	var requests []writeRequest
+	for _, t := range tables {
+		actions := batchWriteItem.ItemActions[t]
+		names := make([]string, 0, len(actions))
+		for action := range actions {
+			names = append(names, action)
+		}
+		sort.Strings(names)
+		for _, action := range names {
+			for _, attrs := range actions[action] {
+				requests = append(requests, writeRequest{t, action, attrs})
+			}
+		}
+	}
+
+	var chunks [][]writeRequest
+	for len(requests) > 0 {
+		n := len(requests)
+		if n > maxBatchWriteItems {
+			n = maxBatchWriteItems
+		}
+		chunks = append(chunks, requests[:n])
+		requests = requests[n:]
+	}
+
+	var mu sync.Mutex
+	var failures []BatchWriteFailure
+	runChunks(len(chunks), o.Concurrency, func(i int) {
+		failed := batchWriteItem.Server.writeChunk(chunks[i], o)
+		mu.Lock()
+		failures = append(failures, failed...)
+		mu.Unlock()
+	})
+
+	if len(failures) > 0 {
+		return &BatchWriteError{failures}
+	}
+	return nil
+}
+
+// writeChunk writes the requests, which must fit in one BatchWriteItem
+// request, retrying as allowed by o. It returns the requests that failed.
+func (s *Server) writeChunk(requests []writeRequest, o BatchOptions) []BatchWriteFailure {
+	for retry := 0; ; retry++ {
+		tables := map[string]*Table{}
+		itemActions := map[*Table]map[string][][]Attribute{}
+		for _, r := range requests {
+			tables[r.table.Name] = r.table
+			if itemActions[r.table] == nil {
+				itemActions[r.table] = map[string][][]Attribute{}
+			}
+			itemActions[r.table][r.action] = append(itemActions[r.table][r.action], r.attrs)
+		}
+		q := NewEmptyQuery()
+		q.AddWriteRequestItems(itemActions)
+
+		jsonResponse, err := s.queryServer(target("BatchWriteItem"), q)
+		if err == nil {
+			var unprocessed []writeRequest
+			if unprocessed, err = unprocessedItems(jsonResponse, tables); err == nil {
+				if len(unprocessed) == 0 {
+					return nil
+				}
+				requests, err = unprocessed, ErrUnprocessed
+			}
+		}
+		if (err != ErrUnprocessed && !retryable(err)) || retry >= o.MaxRetries {
+			failures := make([]BatchWriteFailure, len(requests))
+			for i, r := range requests {
+				failures[i] = BatchWriteFailure{r.table, r.action, r.attrs, err}
+			}
+			return failures
+		}
+		<-time.After(o.BaseDelay << uint(retry))
+	}
+}
+
+// unprocessedItems parses the UnprocessedItems of a BatchWriteItem
+// response.
+func unprocessedItems(jsonResponse []byte, tables map[string]*Table) ([]writeRequest, error) {
+	json, err := simplejson.NewJson(jsonResponse)
+	if err != nil {
+		return nil, err
+	}
+	unprocessed, err := json.Get("UnprocessedItems").Map()
+	if err != nil {
+		return nil, fmt.Errorf("Unexpected response %s", jsonResponse)
+	}
+
+	var requests []writeRequest
+	for name, entries := range unprocessed {
+		t := tables[name]
+		list, ok := entries.([]interface{})
+		if t == nil || !ok {
+			return nil, fmt.Errorf("Unexpected response %s", jsonResponse)
+		}
+		for _, entry := range list {
+			entry, _ := entry.(map[string]interface{})
+			r := writeRequest{table: t}
+			var item map[string]interface{}
+			if put, ok := entry["PutRequest"].(map[string]interface{}); ok {
+				r.action = "Put"
+				item, _ = put["Item"].(map[string]interface{})
+			} else if del, ok := entry["DeleteRequest"].(map[string]interface{}); ok {
+				r.action = "Delete"
+				item, _ = del["Key"].(map[string]interface{})
+			}
+			if item == nil {
+				return nil, fmt.Errorf("Unexpected response %s", jsonResponse)
+			}
+			r.attrs = attributeSlice(parseAttributes(item))
+			requests = append(requests, r)
+		}
+	}
+	return requests, nil
+}
+
+// attributeSlice returns the attributes of m sorted by name.
+func attributeSlice(m map[string]*Attribute) []Attribute {
+	attrs := make([]Attribute, 0, len(m))
+	for _, a := range m {
+		attrs = append(attrs, *a)
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Name < attrs[j].Name })
+	return attrs
+}
+
+// ExecuteAll reads all the items, however many keys there are. The keys
+// are split into requests of at most 100 keys which run concurrently,
+// and keys left unprocessed by DynamoDB are resubmitted with exponential
+// backoff. If some keys still fail, the items read are returned along
+// with a *BatchGetError listing the failed keys.
+func (batchGetItem *BatchGetItem) ExecuteAll(opts *BatchOptions) (map[string][]map[string]*Attribute, error) {
+	o := opts.withDefaults()
+
+	tables := make([]*Table, 0, len(batchGetItem.Keys))
+	for t := range batchGetItem.Keys {
+		tables = append(tables, t)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+
+	var requests []getRequest
+	for _, t := range tables {
+		for _, k := range batchGetItem.Keys[t] {
+			requests = append(requests, getRequest{t, k})
+		}
+	}
+
+	var chunks [][]getRequest
+	for len(requests) > 0 {
+		n := len(requests)
+		if n > maxBatchGetKeys {
+			n = maxBatchGetKeys
+		}
+		chunks = append(chunks, requests[:n])
+		requests = requests[n:]
+	}
+
+	var mu sync.Mutex
+	results := make(map[string][]map[string]*Attribute)
+	var failures []BatchGetFailure
+	runChunks(len(chunks), o.Concurrency, func(i int) {
+		items, failed := batchGetItem.Server.getChunk(chunks[i], o)
+		mu.Lock()
+		for table, tableItems := range items {
+			results[table] = append(results[table], tableItems...)
+		}
+		failures = append(failures, failed...)
+		mu.Unlock()
+	})
+
+	if len(failures) > 0 {
+		return results, &BatchGetError{failures}
+	}
+	return results, nil
+}
+
+// getChunk reads the keys, which must fit in one BatchGetItem request,
+// retrying as allowed by o. It returns the items read, by table name,
+// and the keys that failed.
+func (s *Server) getChunk(requests []getRequest, o BatchOptions) (map[string][]map[string]*Attribute, []BatchGetFailure) {
+	results := make(map[string][]map[string]*Attribute)
+	for retry := 0; ; retry++ {
+		tables := map[string]*Table{}
+		keys := map[*Table][]Key{}
+		for _, r := range requests {
+			tables[r.table.Name] = r.table
+			keys[r.table] = append(keys[r.table], r.key)
+		}
+		q := NewEmptyQuery()
+		q.AddGetRequestItems(keys)
+
+		jsonResponse, err := s.queryServer(target("BatchGetItem"), q)
+		if err == nil {
+			var unprocessed []getRequest
+			if unprocessed, err = parseBatchGetResponse(jsonResponse, tables, results); err == nil {
+				if len(unprocessed) == 0 {
+					return results, nil
+				}
+				requests, err = unprocessed, ErrUnprocessed
+			}
+		}
+		if (err != ErrUnprocessed && !retryable(err)) || retry >= o.MaxRetries {
+			failures := make([]BatchGetFailure, len(requests))
+			for i, r := range requests {
+				failures[i] = BatchGetFailure{r.table, r.key, err}
+			}
+			return results, failures
+		}
+		<-time.After(o.BaseDelay << uint(retry))
+	}
+}
+
+// parseBatchGetResponse adds the items of a BatchGetItem response to
+// results and returns its UnprocessedKeys.
+func parseBatchGetResponse(jsonResponse []byte, tables map[string]*Table, results map[string][]map[string]*Attribute) ([]getRequest, error) {
+	json, err := simplejson.NewJson(jsonResponse)
+	if err != nil {
+		return nil, err
+	}
+	responses, err := json.Get("Responses").Map()
+	if err != nil {
+		return nil, fmt.Errorf("Unexpected response %s", jsonResponse)
+	}
+	for name, entries := range responses {
+		list, ok := entries.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Unexpected response %s", jsonResponse)
+		}
+		for _, entry := range list {
+			item, ok := entry.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("Unexpected response %s", jsonResponse)
+			}
+			results[name] = append(results[name], parseAttributes(item))
+		}
+	}
+
+	var requests []getRequest
+	for name, unprocessed := range json.Get("UnprocessedKeys").MustMap() {
+		t := tables[name]
+		u, _ := unprocessed.(map[string]interface{})
+		keys, ok := u["Keys"].([]interface{})
+		if t == nil || !ok {
+			return nil, fmt.Errorf("Unexpected response %s", jsonResponse)
+		}
+		for _, k := range keys {
+			km, _ := k.(map[string]interface{})
+			key := parseKey(t, km)
+			if key == nil {
+				return nil, fmt.Errorf("Unexpected response %s", jsonResponse)
+			}
+			requests = append(requests, getRequest{t, *key})
+		}
+	}
+	return requests, nil
+}
+
+// runChunks calls f for each chunk index in [0, n), running
+// at most concurrency calls at the same time.
+func runChunks(n, concurrency int, f func(i int)) {
+	var wg sync.WaitGroup
+	sem := make(chan bool, concurrency)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- true
+		go func(i int) {
+			defer wg.Done()
+			f(i)
+			<-sem
+		}(i)
+	}
+	wg.Wait()
+}
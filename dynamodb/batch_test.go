@@ -0,0 +1,163 @@
+package dynamodb_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	simplejson "github.com/bitly/go-simplejson"
+	"github.com/crowdmob/goamz/aws"
+	"github.com/crowdmob/goamz/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+// BatchSuite checks the splitting and retrying done by ExecuteAll,
+// using an HTTP server that runs handle for every request.
+type BatchSuite struct {
+	server *dynamodb.Server
+	http   *httptest.Server
+	table  *dynamodb.Table
+
+	mu       sync.Mutex
+	requests []*simplejson.Json
+	handle   func(req *simplejson.Json, n int) (int, string)
+}
+
+var _ = check.Suite(&BatchSuite{})
+
+func (s *BatchSuite) SetUpSuite(c *check.C) {
+	s.http = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		json, _ := simplejson.NewJson(body)
+		s.mu.Lock()
+		s.requests = append(s.requests, json)
+		status, response := s.handle(json, len(s.requests))
+		s.mu.Unlock()
+		w.WriteHeader(status)
+		w.Write([]byte(response))
+	}))
+	auth := aws.Auth{AccessKey: "DUMMY_KEY", SecretKey: "DUMMY_SECRET"}
+	s.server = dynamodb.New(auth, aws.Region{DynamoDBEndpoint: s.http.URL})
+	s.table = s.server.NewTable("items", dynamodb.PrimaryKey{dynamodb.NewStringAttribute("id", ""), nil})
+}
+
+func (s *BatchSuite) SetUpTest(c *check.C) {
+	s.requests = nil
+}
+
+func (s *BatchSuite) TearDownSuite(c *check.C) {
+	s.http.Close()
+}
+
+var fastRetries = &dynamodb.BatchOptions{Concurrency: 1, BaseDelay: time.Millisecond, MaxRetries: 3}
+
+func putItems(n int) [][]dynamodb.Attribute {
+	var items [][]dynamodb.Attribute
+	for i := 0; i < n; i++ {
+		items = append(items, []dynamodb.Attribute{*dynamodb.NewStringAttribute("id", strconv.Itoa(i))})
+	}
+	return items
+}
+
+func (s *BatchSuite) TestWriteSplitsAndRetries(c *check.C) {
+	s.handle = func(req *simplejson.Json, n int) (int, string) {
+		switch n {
+		case 1:
+			return 400, `{"__type": "com.amazonaws.dynamodb.v20120810#ProvisionedThroughputExceededException", "message": "slow down"}`
+		case 2:
+			return 200, `{"UnprocessedItems": {"items": [{"PutRequest": {"Item": {"id": {"S": "7"}}}}]}}`
+		}
+		return 200, `{"UnprocessedItems": {}}`
+	}
+
+	batch := s.table.BatchWriteItems(map[string][][]dynamodb.Attribute{"Put": putItems(30)})
+	c.Assert(batch.ExecuteAll(fastRetries), check.IsNil)
+
+	c.Assert(s.requests, check.HasLen, 4)
+	sizes := []int{}
+	for _, req := range s.requests {
+		sizes = append(sizes, len(req.Get("RequestItems").Get("items").MustArray()))
+	}
+	c.Check(sizes, check.DeepEquals, []int{25, 25, 1, 5})
+	retried := s.requests[2].Get("RequestItems").Get("items").GetIndex(0).Get("PutRequest").Get("Item").Get("id").Get("S").MustString()
+	c.Check(retried, check.Equals, "7")
+}
+
+func (s *BatchSuite) TestWriteReportsFailures(c *check.C) {
+	s.handle = func(req *simplejson.Json, n int) (int, string) {
+		return 200, `{"UnprocessedItems": {"items": [{"PutRequest": {"Item": {"id": {"S": "1"}, "n": {"N": "2"}}}}]}}`
+	}
+
+	batch := s.table.BatchWriteItems(map[string][][]dynamodb.Attribute{"Put": putItems(2)})
+	err := batch.ExecuteAll(fastRetries)
+	c.Assert(err, check.FitsTypeOf, &dynamodb.BatchWriteError{})
+	failures := err.(*dynamodb.BatchWriteError).Failures
+	c.Assert(failures, check.HasLen, 1)
+	c.Check(failures[0].Table, check.Equals, s.table)
+	c.Check(failures[0].Action, check.Equals, "Put")
+	c.Check(failures[0].Attributes, check.DeepEquals, []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("id", "1"),
+		*dynamodb.NewNumericAttribute("n", "2"),
+	})
+	c.Check(failures[0].Err, check.Equals, dynamodb.ErrUnprocessed)
+	c.Check(s.requests, check.HasLen, 4)
+}
+
+func (s *BatchSuite) TestWriteDoesNotRetryValidationErrors(c *check.C) {
+	s.handle = func(req *simplejson.Json, n int) (int, string) {
+		return 400, `{"__type": "com.amazonaws.dynamodb.v20120810#ValidationException", "message": "bad key"}`
+	}
+
+	batch := s.table.BatchWriteItems(map[string][][]dynamodb.Attribute{
+		"Put":    putItems(2),
+		"Delete": putItems(1),
+	})
+	err := batch.ExecuteAll(fastRetries)
+	c.Assert(err, check.FitsTypeOf, &dynamodb.BatchWriteError{})
+	c.Check(err.(*dynamodb.BatchWriteError).Failures, check.HasLen, 3)
+	c.Check(err, check.ErrorMatches, "3 batch write items failed, first error: ValidationException: bad key")
+	c.Check(s.requests, check.HasLen, 1)
+}
+
+func (s *BatchSuite) TestGetSplitsAndRetries(c *check.C) {
+	s.handle = func(req *simplejson.Json, n int) (int, string) {
+		keys := req.Get("RequestItems").Get("items").Get("Keys").MustArray()
+		if len(keys) == 100 {
+			return 200, `{
+				"Responses": {"items": [{"id": {"S": "0"}}]},
+				"UnprocessedKeys": {"items": {"Keys": [{"id": {"S": "1"}}]}}
+			}`
+		}
+		return 200, `{"Responses": {"items": [{"id": {"S": "x"}}]}, "UnprocessedKeys": {}}`
+	}
+
+	var keys []dynamodb.Key
+	for i := 0; i < 120; i++ {
+		keys = append(keys, dynamodb.Key{HashKey: strconv.Itoa(i)})
+	}
+	results, err := s.table.BatchGetItems(keys).ExecuteAll(&dynamodb.BatchOptions{Concurrency: 2, BaseDelay: time.Millisecond})
+	c.Assert(err, check.IsNil)
+	c.Check(s.requests, check.HasLen, 3)
+	c.Check(results["items"], check.HasLen, 3)
+}
+
+func (s *BatchSuite) TestGetReportsFailures(c *check.C) {
+	s.handle = func(req *simplejson.Json, n int) (int, string) {
+		return 200, `{
+			"Responses": {"items": []},
+			"UnprocessedKeys": {"items": {"Keys": [{"id": {"S": "1"}}]}}
+		}`
+	}
+
+	keys := []dynamodb.Key{{HashKey: "1"}}
+	results, err := s.table.BatchGetItems(keys).ExecuteAll(fastRetries)
+	c.Assert(err, check.FitsTypeOf, &dynamodb.BatchGetError{})
+	c.Check(err.(*dynamodb.BatchGetError).Failures, check.DeepEquals, []dynamodb.BatchGetFailure{
+		{Table: s.table, Key: dynamodb.Key{HashKey: "1"}, Err: dynamodb.ErrUnprocessed},
+	})
+	c.Check(results["items"], check.HasLen, 0)
+	c.Check(s.requests, check.HasLen, 4)
+}
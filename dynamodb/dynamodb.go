@@ -49,6 +49,23 @@ func (e Error) Error() string {
 	return e.Code + ": " + e.Message
 }
 
+// ConditionalCheckFailedError is returned when DynamoDB rejects a
+// write with a ConditionalCheckFailedException, because its Expected
+// or ConditionExpression condition does not hold. With optimistic
+// locking, it means that the item was changed since it was read, and
+// that the read-modify-write may be retried. It holds the fields of
+// the Error that was returned before, Code included.
+type ConditionalCheckFailedError struct {
+	StatusCode int
+	Status     string
+	Code       string // "ConditionalCheckFailedException"
+	Message    string
+}
+
+func (e *ConditionalCheckFailedError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
 func buildError(r *http.Response, jsonBody []byte) error {
 
 	ddbError := Error{
@@ -63,6 +80,9 @@ func buildError(r *http.Response, jsonBody []byte) error {
 		return err
 	}
 	ddbError.Message = json.Get("message").MustString()
+	if ddbError.Message == "" {
+		ddbError.Message = json.Get("Message").MustString()
+	}
 
 	// Of the form: com.amazon.coral.validate#ValidationException
 	// We only want the last part
@@ -73,6 +93,24 @@ func buildError(r *http.Response, jsonBody []byte) error {
 	}
 	ddbError.Code = codeStr
 
+	if codeStr == "ConditionalCheckFailedException" {
+		return &ConditionalCheckFailedError{
+			StatusCode: ddbError.StatusCode,
+			Status:     ddbError.Status,
+			Code:       ddbError.Code,
+			Message:    ddbError.Message,
+		}
+	}
+	if codeStr == "TransactionCanceledException" {
+		return &TransactionCanceledError{
+			StatusCode: ddbError.StatusCode,
+			Status:     ddbError.Status,
+			Code:       ddbError.Code,
+			Message:    ddbError.Message,
+			Reasons:    cancellationReasons(json),
+		}
+	}
+
 	return &ddbError
 }
 
@@ -4,6 +4,7 @@ import (
 	"flag"
 	"github.com/crowdmob/goamz/aws"
 	"github.com/crowdmob/goamz/dynamodb"
+	"github.com/crowdmob/goamz/dynamodb/dynamodbtest"
 	"gopkg.in/check.v1"
 	"testing"
 	"time"
@@ -17,6 +18,10 @@ var local = flag.Bool("local", true, "Use DynamoDB local on 8080 instead of real
 var dynamodb_region aws.Region
 var dynamodb_auth aws.Auth
 
+// fake_server is the dynamodbtest server shared by the suites
+// when the tests do not run against dynamodb.
+var fake_server *dynamodbtest.Server
+
 type DynamoDBTest struct {
 	server            *dynamodb.Server
 	aws.Region        // Exports Region
@@ -131,7 +136,17 @@ func (s *DynamoDBTest) WaitUntilStatus(c *check.C, status string) {
 
 func setUpAuth(c *check.C) {
 	if !*amazon {
-		c.Skip("Test against amazon not enabled.")
+		if fake_server == nil {
+			srv, err := dynamodbtest.NewServer()
+			if err != nil {
+				c.Fatal(err)
+			}
+			fake_server = srv
+		}
+		c.Log("Using dynamodbtest server")
+		dynamodb_region = aws.Region{DynamoDBEndpoint: fake_server.URL()}
+		dynamodb_auth = aws.Auth{AccessKey: "DUMMY_KEY", SecretKey: "DUMMY_SECRET"}
+		return
 	}
 	if *local {
 		c.Log("Using local server")
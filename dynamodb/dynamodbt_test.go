@@ -0,0 +1,317 @@
+package dynamodb_test
+
+import (
+	"fmt"
+	"github.com/crowdmob/goamz/aws"
+	"github.com/crowdmob/goamz/dynamodb"
+	"github.com/crowdmob/goamz/dynamodb/dynamodbtest"
+	"gopkg.in/check.v1"
+	"sort"
+	"strconv"
+)
+
+// LocalServerSuite defines tests that run against the
+// dynamodbtest fake server only, covering the behavior
+// that the suites shared with dynamodb do not exercise.
+type LocalServerSuite struct {
+	srv   *dynamodbtest.Server
+	table *dynamodb.Table
+}
+
+var _ = check.Suite(&LocalServerSuite{})
+
+var local_table = dynamodb.TableDescriptionT{
+	TableName: "Events",
+	AttributeDefinitions: []dynamodb.AttributeDefinitionT{
+		{"UserId", "S"},
+		{"Time", "N"},
+		{"Kind", "S"},
+		{"Score", "N"},
+	},
+	KeySchema: []dynamodb.KeySchemaT{
+		{"UserId", "HASH"},
+		{"Time", "RANGE"},
+	},
+	LocalSecondaryIndexes: []dynamodb.LocalSecondaryIndexT{{
+		IndexName: "ByScore",
+		KeySchema: []dynamodb.KeySchemaT{
+			{"UserId", "HASH"},
+			{"Score", "RANGE"},
+		},
+		Projection: dynamodb.ProjectionT{ProjectionType: "ALL"},
+	}},
+	GlobalSecondaryIndexes: []dynamodb.GlobalSecondaryIndexT{{
+		IndexName: "ByKind",
+		KeySchema: []dynamodb.KeySchemaT{
+			{"Kind", "HASH"},
+			{"Time", "RANGE"},
+		},
+		Projection: dynamodb.ProjectionT{ProjectionType: "KEYS_ONLY"},
+		ProvisionedThroughput: dynamodb.ProvisionedThroughputT{
+			ReadCapacityUnits:  1,
+			WriteCapacityUnits: 1,
+		},
+	}},
+	ProvisionedThroughput: dynamodb.ProvisionedThroughputT{
+		ReadCapacityUnits:  1,
+		WriteCapacityUnits: 1,
+	},
+}
+
+func (s *LocalServerSuite) SetUpSuite(c *check.C) {
+	srv, err := dynamodbtest.NewServer()
+	c.Assert(err, check.IsNil)
+	s.srv = srv
+}
+
+func (s *LocalServerSuite) TearDownSuite(c *check.C) {
+	s.srv.Quit()
+}
+
+func (s *LocalServerSuite) SetUpTest(c *check.C) {
+	s.srv.Reset()
+	server := dynamodb.New(aws.Auth{AccessKey: "DUMMY_KEY", SecretKey: "DUMMY_SECRET"}, aws.Region{DynamoDBEndpoint: s.srv.URL()})
+	_, err := server.CreateTable(local_table)
+	c.Assert(err, check.IsNil)
+	pk, err := local_table.BuildPrimaryKey()
+	c.Assert(err, check.IsNil)
+	s.table = server.NewTable(local_table.TableName, pk)
+
+	// Users u0 to u3 have events at times 1 to 5, with
+	// scores decreasing in time and alternating kinds.
+	for u := 0; u < 4; u++ {
+		for t := 1; t <= 5; t++ {
+			kind := []string{"click", "view"}[t%2]
+			ok, err := s.table.PutItem(fmt.Sprintf("u%d", u), strconv.Itoa(t), []dynamodb.Attribute{
+				*dynamodb.NewStringAttribute("Kind", kind),
+				*dynamodb.NewNumericAttribute("Score", strconv.Itoa(10-t)),
+			})
+			c.Assert(ok, check.Equals, true)
+			c.Assert(err, check.IsNil)
+		}
+	}
+}
+
+func attrValues(items []map[string]*dynamodb.Attribute, name string) []string {
+	var values []string
+	for _, it := range items {
+		if a := it[name]; a != nil {
+			values = append(values, a.Value)
+		} else {
+			values = append(values, "")
+		}
+	}
+	return values
+}
+
+func (s *LocalServerSuite) TestDescribeTable(c *check.C) {
+	desc, err := s.table.DescribeTable()
+	c.Assert(err, check.IsNil)
+	c.Check(desc.TableStatus, check.Equals, "ACTIVE")
+	c.Check(desc.ItemCount, check.Equals, int64(20))
+	c.Assert(desc.GlobalSecondaryIndexes, check.HasLen, 1)
+	c.Check(desc.GlobalSecondaryIndexes[0].IndexName, check.Equals, "ByKind")
+	c.Assert(desc.LocalSecondaryIndexes, check.HasLen, 1)
+	c.Check(desc.LocalSecondaryIndexes[0].IndexName, check.Equals, "ByScore")
+
+	tables, err := s.table.Server.ListTables()
+	c.Assert(err, check.IsNil)
+	c.Check(tables, check.DeepEquals, []string{"Events"})
+
+	_, err = s.table.Server.CreateTable(local_table)
+	c.Assert(err, check.NotNil)
+	c.Check(err.(*dynamodb.Error).Code, check.Equals, "ResourceInUseException")
+}
+
+func (s *LocalServerSuite) TestQueryKeyConditions(c *check.C) {
+	items, err := s.table.Query([]dynamodb.AttributeComparison{
+		*dynamodb.NewEqualStringAttributeComparison("UserId", "u1"),
+		*dynamodb.NewNumericAttributeComparison("Time", dynamodb.COMPARISON_GREATER_THAN, 2),
+	})
+	c.Assert(err, check.IsNil)
+	c.Check(attrValues(items, "Time"), check.DeepEquals, []string{"3", "4", "5"})
+	c.Check(attrValues(items, "UserId"), check.DeepEquals, []string{"u1", "u1", "u1"})
+
+	q := dynamodb.NewQuery(s.table)
+	q.AddKeyConditions([]dynamodb.AttributeComparison{
+		*dynamodb.NewEqualStringAttributeComparison("UserId", "u1"),
+	})
+	q.AddScanIndexForward(false)
+	q.AddQueryFilter([]dynamodb.AttributeComparison{
+		*dynamodb.NewEqualStringAttributeComparison("Kind", "view"),
+	})
+	items, _, err = s.table.QueryTable(q)
+	c.Assert(err, check.IsNil)
+	c.Check(attrValues(items, "Time"), check.DeepEquals, []string{"5", "3", "1"})
+
+	count, err := s.table.CountQuery([]dynamodb.AttributeComparison{
+		*dynamodb.NewEqualStringAttributeComparison("UserId", "u2"),
+	})
+	c.Assert(err, check.IsNil)
+	c.Check(count, check.Equals, int64(5))
+}
+
+func (s *LocalServerSuite) TestQueryExpression(c *check.C) {
+	items, err := s.table.QueryExpression(&dynamodb.Expressions{
+		KeyCondition: dynamodb.And(
+			dynamodb.Equal(dynamodb.Path("UserId"), dynamodb.Value(dynamodb.NewStringAttribute("", "u0"))),
+			dynamodb.Between(dynamodb.Path("Time"),
+				dynamodb.Value(dynamodb.NewNumericAttribute("", "2")),
+				dynamodb.Value(dynamodb.NewNumericAttribute("", "4"))),
+		),
+		Filter:     dynamodb.GreaterThan(dynamodb.Path("Score"), dynamodb.Value(dynamodb.NewNumericAttribute("", "6"))),
+		Projection: []string{"Time"},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(items, check.HasLen, 2)
+	c.Check(attrValues(items, "Time"), check.DeepEquals, []string{"2", "3"})
+	c.Check(items[0]["Score"], check.IsNil)
+}
+
+func (s *LocalServerSuite) TestQueryMissingHashKey(c *check.C) {
+	_, err := s.table.Query([]dynamodb.AttributeComparison{
+		*dynamodb.NewNumericAttributeComparison("Time", dynamodb.COMPARISON_GREATER_THAN, 2),
+	})
+	c.Assert(err, check.NotNil)
+	c.Check(err.(*dynamodb.Error).Code, check.Equals, "ValidationException")
+	c.Check(err.(*dynamodb.Error).Message, check.Equals, "Query condition missed key schema element: UserId")
+}
+
+func (s *LocalServerSuite) TestQueryPagination(c *check.C) {
+	var times []string
+	var start *dynamodb.Key
+	for pages := 0; ; pages++ {
+		c.Assert(pages < 10, check.Equals, true)
+		q := dynamodb.NewQuery(s.table)
+		q.AddKeyConditions([]dynamodb.AttributeComparison{
+			*dynamodb.NewEqualStringAttributeComparison("UserId", "u3"),
+		})
+		q.AddLimit(2)
+		if start != nil {
+			q.AddExclusiveStartKey(s.table, start)
+		}
+		items, last, err := s.table.QueryTable(q)
+		c.Assert(err, check.IsNil)
+		times = append(times, attrValues(items, "Time")...)
+		if last == nil {
+			break
+		}
+		c.Check(last.HashKey, check.Equals, "u3")
+		start = last
+	}
+	c.Check(times, check.DeepEquals, []string{"1", "2", "3", "4", "5"})
+}
+
+func (s *LocalServerSuite) TestQueryOnIndex(c *check.C) {
+	// ByScore orders the events of a user by score,
+	// which is the reverse of their time order.
+	items, err := s.table.LimitedQueryOnIndex([]dynamodb.AttributeComparison{
+		*dynamodb.NewEqualStringAttributeComparison("UserId", "u0"),
+	}, "ByScore", 3)
+	c.Assert(err, check.IsNil)
+	c.Check(attrValues(items, "Time"), check.DeepEquals, []string{"5", "4", "3"})
+	c.Check(attrValues(items, "Kind"), check.DeepEquals, []string{"view", "click", "view"})
+
+	// ByKind only projects the keys.
+	items, err = s.table.QueryOnIndex([]dynamodb.AttributeComparison{
+		*dynamodb.NewEqualStringAttributeComparison("Kind", "click"),
+		*dynamodb.NewNumericAttributeComparison("Time", dynamodb.COMPARISON_EQUAL, 4),
+	}, "ByKind")
+	c.Assert(err, check.IsNil)
+	c.Check(attrValues(items, "UserId"), check.DeepEquals, []string{"u0", "u1", "u2", "u3"})
+	c.Check(attrValues(items, "Score"), check.DeepEquals, []string{"", "", "", ""})
+
+	_, err = s.table.QueryOnIndex([]dynamodb.AttributeComparison{
+		*dynamodb.NewEqualStringAttributeComparison("Kind", "click"),
+	}, "NoSuchIndex")
+	c.Assert(err, check.NotNil)
+	c.Check(err.(*dynamodb.Error).Code, check.Equals, "ValidationException")
+}
+
+func (s *LocalServerSuite) TestScanFilterAndPagination(c *check.C) {
+	items, err := s.table.Scan([]dynamodb.AttributeComparison{
+		*dynamodb.NewNumericAttributeComparison("Score", dynamodb.COMPARISON_GREATER_THAN_OR_EQUAL, 9),
+	})
+	c.Assert(err, check.IsNil)
+	c.Check(attrValues(items, "UserId"), check.DeepEquals, []string{"u0", "u1", "u2", "u3"})
+
+	var n int
+	var start *dynamodb.Key
+	for pages := 0; ; pages++ {
+		c.Assert(pages < 20, check.Equals, true)
+		items, last, err := s.table.ScanPartialLimit(nil, start, 3)
+		c.Assert(err, check.IsNil)
+		n += len(items)
+		if last == nil {
+			break
+		}
+		start = last
+	}
+	c.Check(n, check.Equals, 20)
+}
+
+func (s *LocalServerSuite) TestParallelScan(c *check.C) {
+	var keys []string
+	for segment := 0; segment < 3; segment++ {
+		items, err := s.table.ParallelScan(nil, segment, 3)
+		c.Assert(err, check.IsNil)
+		for _, it := range items {
+			keys = append(keys, it["UserId"].Value+"/"+it["Time"].Value)
+		}
+	}
+	c.Assert(keys, check.HasLen, 20)
+	sort.Strings(keys)
+	for i := 1; i < len(keys); i++ {
+		c.Check(keys[i], check.Not(check.Equals), keys[i-1])
+	}
+
+	_, err := s.table.ParallelScan(nil, 3, 3)
+	c.Assert(err, check.NotNil)
+	c.Check(err.(*dynamodb.Error).Code, check.Equals, "ValidationException")
+}
+
+func (s *LocalServerSuite) TestBatchWriteAndGet(c *check.C) {
+	_, err := s.table.BatchWriteItems(map[string][][]dynamodb.Attribute{
+		"Put": {{
+			*dynamodb.NewStringAttribute("UserId", "u9"),
+			*dynamodb.NewNumericAttribute("Time", "1"),
+			*dynamodb.NewStringAttribute("Kind", "view"),
+		}},
+		"Delete": {{
+			*dynamodb.NewStringAttribute("UserId", "u0"),
+			*dynamodb.NewNumericAttribute("Time", "1"),
+		}},
+	}).Execute()
+	c.Assert(err, check.IsNil)
+
+	results, err := s.table.BatchGetItems([]dynamodb.Key{
+		{HashKey: "u9", RangeKey: "1"},
+		{HashKey: "u0", RangeKey: "1"},
+		{HashKey: "u0", RangeKey: "2"},
+	}).Execute()
+	c.Assert(err, check.IsNil)
+	items := results["Events"]
+	c.Assert(items, check.HasLen, 2)
+	keys := []string{
+		items[0]["UserId"].Value + "/" + items[0]["Time"].Value,
+		items[1]["UserId"].Value + "/" + items[1]["Time"].Value,
+	}
+	sort.Strings(keys)
+	c.Check(keys, check.DeepEquals, []string{"u0/2", "u9/1"})
+
+	_, err = s.table.BatchGetItems([]dynamodb.Key{
+		{HashKey: "u0", RangeKey: "2"},
+		{HashKey: "u0", RangeKey: "2"},
+	}).Execute()
+	c.Assert(err, check.NotNil)
+	c.Check(err.(*dynamodb.Error).Code, check.Equals, "ValidationException")
+}
+
+func (s *LocalServerSuite) TestTableNotFound(c *check.C) {
+	pk, err := local_table.BuildPrimaryKey()
+	c.Assert(err, check.IsNil)
+	t := s.table.Server.NewTable("NoSuchTable", pk)
+	_, err = t.GetItem(&dynamodb.Key{HashKey: "u0", RangeKey: "1"})
+	c.Assert(err, check.NotNil)
+	c.Check(err.(*dynamodb.Error).Code, check.Equals, "ResourceNotFoundException")
+}
@@ -0,0 +1,110 @@
+package dynamodbtest
+
+// batchGetItem implements the DynamoDB BatchGetItem entry point.
+// All the keys are processed, so UnprocessedKeys is always empty.
+func (srv *Server) batchGetItem(r request) interface{} {
+	requests := r.object("RequestItems")
+	if len(requests) == 0 {
+		validationf("The requestItems parameter is required for BatchGetItem")
+	}
+
+	n := 0
+	responses := map[string]interface{}{}
+	for name := range requests {
+		t := srv.tableByName(name)
+		tr := requests.object(name)
+		proj := projection(tr, newExprContext(tr))
+		seen := make(map[string]bool)
+		items := []interface{}{}
+		for _, k := range tr.list("Keys") {
+			m, ok := k.(map[string]interface{})
+			if !ok {
+				validationf("Keys must be a list of objects")
+			}
+			key := toItem("Keys", m)
+			t.key.checkKey(key)
+			ek := t.key.encode(key)
+			if seen[ek] {
+				validationf("Provided list of item keys contains duplicates")
+			}
+			seen[ek] = true
+			n++
+
+			if it := t.items[ek]; it != nil {
+				if proj != nil {
+					it = proj(it)
+				}
+				items = append(items, copyItem(it))
+			}
+		}
+		responses[name] = items
+	}
+	if n > 100 {
+		validationf("Too many items requested for the BatchGetItem call")
+	}
+
+	return map[string]interface{}{
+		"Responses":       responses,
+		"UnprocessedKeys": map[string]interface{}{},
+	}
+}
+
+// batchWriteItem implements the DynamoDB BatchWriteItem entry point.
+// The requests are all checked before any is applied, and all of them
+// are processed, so UnprocessedItems is always empty.
+func (srv *Server) batchWriteItem(r request) interface{} {
+	requests := r.object("RequestItems")
+
+	type write struct {
+		t   *table
+		key string
+		it  item // nil for a DeleteRequest
+	}
+	var writes []write
+	for name := range requests {
+		t := srv.tableByName(name)
+		seen := make(map[string]bool)
+		for _, e := range requests.list(name) {
+			m, ok := e.(map[string]interface{})
+			if !ok {
+				validationf("RequestItems must hold lists of objects")
+			}
+			wr := request(m)
+			var w write
+			switch {
+			case wr.has("PutRequest") && !wr.has("DeleteRequest"):
+				it := wr.object("PutRequest").item("Item")
+				if it == nil {
+					validationf("One or more parameter values were invalid: Item is required")
+				}
+				t.checkItem(it)
+				w = write{t, t.key.encode(it), it}
+			case wr.has("DeleteRequest") && !wr.has("PutRequest"):
+				key := wr.object("DeleteRequest").item("Key")
+				t.key.checkKey(key)
+				w = write{t, t.key.encode(key), nil}
+			default:
+				validationf("Supplied AttributeValue has more than one datatypes set, must contain exactly one of the supported datatypes")
+			}
+			if seen[w.key] {
+				validationf("Provided list of item keys contains duplicates")
+			}
+			seen[w.key] = true
+			writes = append(writes, w)
+		}
+	}
+	if len(writes) == 0 || len(writes) > 25 {
+		validationf("The batch write request must hold between 1 and 25 requests")
+	}
+
+	for _, w := range writes {
+		if w.it == nil {
+			delete(w.t.items, w.key)
+		} else {
+			w.t.items[w.key] = copyItem(w.it)
+		}
+	}
+	return map[string]interface{}{
+		"UnprocessedItems": map[string]interface{}{},
+	}
+}
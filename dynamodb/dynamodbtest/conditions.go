@@ -0,0 +1,208 @@
+package dynamodbtest
+
+// condition reports whether an item, which is nil if
+// there is none, satisfies a condition.
+type condition func(it item) bool
+
+// comparison is a comparison of the legacy request parameters,
+// such as Expected, KeyConditions and ScanFilter.
+type comparison struct {
+	op   string
+	args []value
+}
+
+// comparisonArgs holds the number of arguments of each
+// comparison operator, -1 meaning one or more.
+var comparisonArgs = map[string]int{
+	"EQ":           1,
+	"NE":           1,
+	"LE":           1,
+	"LT":           1,
+	"GE":           1,
+	"GT":           1,
+	"NOT_NULL":     0,
+	"NULL":         0,
+	"CONTAINS":     1,
+	"NOT_CONTAINS": 1,
+	"BEGINS_WITH":  1,
+	"IN":           -1,
+	"BETWEEN":      2,
+}
+
+func newComparison(attr, op string, args []value) comparison {
+	n, ok := comparisonArgs[op]
+	if !ok {
+		validationf("Unknown ComparisonOperator %q for %s", op, attr)
+	}
+	if (n >= 0 && len(args) != n) || (n < 0 && len(args) == 0) {
+		validationf("One or more parameter values were invalid: Invalid number of argument(s) for the %s ComparisonOperator", op)
+	}
+	for _, a := range args {
+		switch op {
+		case "LE", "LT", "GE", "GT", "BETWEEN", "BEGINS_WITH":
+			if t := a.typ(); t != "S" && t != "N" && t != "B" {
+				validationf("One or more parameter values were invalid: ComparisonOperator %s is not valid for %s AttributeValue type", op, t)
+			}
+		}
+	}
+	return comparison{op, args}
+}
+
+// eval evaluates the comparison on v, which is nil if the
+// attribute does not exist.
+func (c comparison) eval(v value) bool {
+	switch c.op {
+	case "EQ":
+		return equal(v, c.args[0])
+	case "NE":
+		return !equal(v, c.args[0])
+	case "NOT_NULL":
+		return v != nil
+	case "NULL":
+		return v == nil
+	case "CONTAINS":
+		return contains(v, c.args[0])
+	case "NOT_CONTAINS":
+		return v != nil && !contains(v, c.args[0])
+	case "BEGINS_WITH":
+		return beginsWith(v, c.args[0])
+	case "IN":
+		for _, a := range c.args {
+			if equal(v, a) {
+				return true
+			}
+		}
+		return false
+	case "BETWEEN":
+		lo, ok1 := compare(v, c.args[0])
+		hi, ok2 := compare(v, c.args[1])
+		return ok1 && ok2 && lo >= 0 && hi <= 0
+	}
+	cmp, ok := compare(v, c.args[0])
+	if !ok {
+		return false
+	}
+	switch c.op {
+	case "LE":
+		return cmp <= 0
+	case "LT":
+		return cmp < 0
+	case "GE":
+		return cmp >= 0
+	}
+	return cmp > 0
+}
+
+// parseComparisons parses legacy conditions of the form
+// {"attr": {"ComparisonOperator": "EQ", "AttributeValueList": [...]}}.
+func parseComparisons(name string, conds request) map[string]comparison {
+	comparisons := make(map[string]comparison)
+	for attr := range conds {
+		c := conds.object(attr)
+		comparisons[attr] = newComparison(attr, c.string("ComparisonOperator"), parseValues(name, c.list("AttributeValueList")))
+	}
+	return comparisons
+}
+
+func parseValues(name string, list []interface{}) []value {
+	var values []value
+	for _, e := range list {
+		v, ok := toValue(e)
+		if !ok {
+			validationf("invalid attribute value in %s", name)
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+// legacyCondition returns the condition given by the legacy
+// parameter name, combined with the ConditionalOperator of r.
+// It returns nil if the parameter is not set.
+func legacyCondition(r request, name string) condition {
+	conds := r.object(name)
+	if conds == nil {
+		return nil
+	}
+	var cs []condition
+	if name == "Expected" {
+		cs = expectedConditions(conds)
+	} else {
+		for attr, c := range parseComparisons(name, conds) {
+			attr, c := attr, c
+			cs = append(cs, func(it item) bool {
+				return c.eval(it.get(attr))
+			})
+		}
+	}
+
+	or := false
+	switch op := r.string("ConditionalOperator"); op {
+	case "", "AND":
+	case "OR":
+		or = true
+	default:
+		validationf("Invalid ConditionalOperator %q", op)
+	}
+	return func(it item) bool {
+		for _, c := range cs {
+			if c(it) == or {
+				return or
+			}
+		}
+		return !or
+	}
+}
+
+// expectedConditions parses the Expected parameter, whose entries
+// either use the Value and Exists form or a ComparisonOperator.
+func expectedConditions(expected request) []condition {
+	var cs []condition
+	for attr := range expected {
+		attr := attr
+		e := expected.object(attr)
+		if e.has("ComparisonOperator") {
+			if e.has("Value") || e.has("Exists") {
+				validationf("One or more parameter values were invalid: Value or Exists cannot be used with ComparisonOperator for Attribute: %s", attr)
+			}
+			c := newComparison(attr, e.string("ComparisonOperator"), parseValues("Expected", e.list("AttributeValueList")))
+			cs = append(cs, func(it item) bool {
+				return c.eval(it.get(attr))
+			})
+			continue
+		}
+		exists := e.bool("Exists", true)
+		if !exists {
+			if e.has("Value") {
+				validationf("One or more parameter values were invalid: Value cannot be used when Exists is false for Attribute: %s", attr)
+			}
+			cs = append(cs, func(it item) bool {
+				return it.get(attr) == nil
+			})
+			continue
+		}
+		v, ok := toValue(e["Value"])
+		if !ok {
+			validationf("One or more parameter values were invalid: Value must be provided when Exists is true for Attribute: %s", attr)
+		}
+		cs = append(cs, func(it item) bool {
+			return equal(it.get(attr), v)
+		})
+	}
+	return cs
+}
+
+// checkCondition checks that the item, which is nil if it does not
+// exist, satisfies the Expected or ConditionExpression parameter.
+func checkCondition(r request, ctx *exprContext, it item) {
+	c := legacyCondition(r, "Expected")
+	if expr := r.string("ConditionExpression"); expr != "" {
+		if c != nil {
+			validationf("Can not use both expression and non-expression parameters in the same request: Non-expression parameters: {Expected} Expression parameters: {ConditionExpression}")
+		}
+		c = ctx.parseCondition(expr)
+	}
+	if c != nil && !c(it) {
+		fatalf(400, "ConditionalCheckFailedException", "The conditional request failed")
+	}
+}
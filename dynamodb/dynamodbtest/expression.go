@@ -0,0 +1,678 @@
+package dynamodbtest
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// exprContext holds the placeholders that the expressions
+// of a request may use.
+type exprContext struct {
+	names  map[string]string
+	values map[string]value
+}
+
+func newExprContext(r request) *exprContext {
+	ctx := &exprContext{
+		names:  make(map[string]string),
+		values: make(map[string]value),
+	}
+	names := r.object("ExpressionAttributeNames")
+	for k := range names {
+		ctx.names[k] = names.string(k)
+	}
+	values := r.object("ExpressionAttributeValues")
+	for k, v := range values {
+		val, ok := toValue(v)
+		if !ok {
+			validationf("ExpressionAttributeValues contains invalid value for key %s", k)
+		}
+		ctx.values[k] = val
+	}
+	return ctx
+}
+
+// operand returns a value computed from an item, or nil.
+type operand func(it item) value
+
+// pathElem is an element of a document path: either
+// an attribute name, or the index of a list element.
+type pathElem struct {
+	name  string
+	index int // -1 for names
+}
+
+// path is a document path, such as a.b[2].c.
+type path []pathElem
+
+func (p path) String() string {
+	var s string
+	for i, e := range p {
+		switch {
+		case e.index >= 0:
+			s += "[" + strconv.Itoa(e.index) + "]"
+		case i > 0:
+			s += "." + e.name
+		default:
+			s += e.name
+		}
+	}
+	return s
+}
+
+// get returns the value at p in the item, or nil if there is none.
+func (p path) get(it item) value {
+	v := it.get(p[0].name)
+	for _, e := range p[1:] {
+		switch {
+		case v == nil:
+			return nil
+		case e.index >= 0:
+			l := v.elems()
+			if v.typ() != "L" || e.index >= len(l) {
+				return nil
+			}
+			v = asValue(l[e.index])
+		default:
+			if v.typ() != "M" {
+				return nil
+			}
+			v = asValue(v.members()[e.name])
+		}
+	}
+	return v
+}
+
+// set sets the value at p in the item. The parent of the
+// element must exist; an index past the end of a list appends
+// to the list.
+func (p path) set(it item, v value) {
+	if len(p) == 1 {
+		it.set(p[0].name, v)
+		return
+	}
+	parent := p[:len(p)-1].get(it)
+	last := p[len(p)-1]
+	switch {
+	case parent == nil:
+	case last.index >= 0 && parent.typ() == "L":
+		l := parent.elems()
+		if last.index < len(l) {
+			l[last.index] = map[string]interface{}(v)
+		} else {
+			parent["L"] = append(l, map[string]interface{}(v))
+		}
+		return
+	case last.index < 0 && parent.typ() == "M":
+		parent.members()[last.name] = map[string]interface{}(v)
+		return
+	}
+	validationf("The document path provided in the update expression is invalid for update")
+}
+
+// remove removes the element at p from the item, if it exists.
+func (p path) remove(it item) {
+	if len(p) == 1 {
+		delete(it, p[0].name)
+		return
+	}
+	parent := p[:len(p)-1].get(it)
+	last := p[len(p)-1]
+	switch {
+	case parent == nil:
+	case last.index >= 0 && parent.typ() == "L":
+		if l := parent.elems(); last.index < len(l) {
+			parent["L"] = append(l[:last.index:last.index], l[last.index+1:]...)
+		}
+	case last.index < 0 && parent.typ() == "M":
+		delete(parent.members(), last.name)
+	}
+}
+
+// overlaps reports whether one of p and q is a prefix of the other.
+func (p path) overlaps(q path) bool {
+	for i := 0; i < len(p) && i < len(q); i++ {
+		if p[i] != q[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Tokens of expressions.
+const (
+	tokEOF = iota
+	tokIdent
+	tokName   // #name placeholder
+	tokValue  // :value placeholder
+	tokNumber // list index
+	tokPunct
+)
+
+type token struct {
+	kind int
+	text string
+}
+
+type parser struct {
+	ctx  *exprContext
+	expr string
+	toks []token
+	pos  int
+}
+
+func (ctx *exprContext) newParser(expr string) *parser {
+	p := &parser{ctx: ctx, expr: expr}
+	for i := 0; i < len(expr); {
+		c := rune(expr[i])
+		start := i
+		kind := tokPunct
+		switch {
+		case unicode.IsSpace(c):
+			i++
+			continue
+		case c == '#' || c == ':':
+			kind = tokName
+			if c == ':' {
+				kind = tokValue
+			}
+			i = scanIdent(expr, i+1)
+			if i == start+1 {
+				p.fail()
+			}
+		case c == '_' || unicode.IsLetter(c):
+			kind = tokIdent
+			i = scanIdent(expr, i)
+		case unicode.IsDigit(c):
+			kind = tokNumber
+			for i++; i < len(expr) && unicode.IsDigit(rune(expr[i])); i++ {
+			}
+		case strings.HasPrefix(expr[i:], "<>") || strings.HasPrefix(expr[i:], "<=") || strings.HasPrefix(expr[i:], ">="):
+			i += 2
+		case strings.ContainsRune("()[],.=<>+-", c):
+			i++
+		default:
+			p.fail()
+		}
+		p.toks = append(p.toks, token{kind, expr[start:i]})
+	}
+	p.toks = append(p.toks, token{kind: tokEOF})
+	return p
+}
+
+// scanIdent returns the end of the identifier starting at i in s.
+func scanIdent(s string, i int) int {
+	for i < len(s) && (s[i] == '_' || unicode.IsLetter(rune(s[i])) || unicode.IsDigit(rune(s[i]))) {
+		i++
+	}
+	return i
+}
+
+func (p *parser) fail() {
+	validationf("Invalid expression: Syntax error; expression: %q", p.expr)
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the punctuation
+// or the case insensitive keyword s.
+func (p *parser) accept(s string) bool {
+	t := p.peek()
+	if (t.kind == tokPunct && t.text == s) || (t.kind == tokIdent && strings.EqualFold(t.text, s)) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(s string) {
+	if !p.accept(s) {
+		p.fail()
+	}
+}
+
+// isCall reports whether the next tokens are a call of the function name.
+func (p *parser) isCall(name string) bool {
+	t := p.peek()
+	if t.kind == tokEOF {
+		return false
+	}
+	n := p.toks[p.pos+1]
+	return t.kind == tokIdent && t.text == name && n.kind == tokPunct && n.text == "("
+}
+
+func (p *parser) end() {
+	if p.peek().kind != tokEOF {
+		p.fail()
+	}
+}
+
+func (p *parser) path() path {
+	var pa path
+	for {
+		t := p.next()
+		switch t.kind {
+		case tokIdent:
+			pa = append(pa, pathElem{t.text, -1})
+		case tokName:
+			name, ok := p.ctx.names[t.text]
+			if !ok {
+				validationf("Value provided in ExpressionAttributeNames unused in expressions or undefined: keys: {%s}", t.text)
+			}
+			pa = append(pa, pathElem{name, -1})
+		default:
+			p.fail()
+		}
+		for p.accept("[") {
+			t := p.next()
+			if t.kind != tokNumber {
+				p.fail()
+			}
+			n, err := strconv.Atoi(t.text)
+			if err != nil {
+				p.fail()
+			}
+			p.expect("]")
+			pa = append(pa, pathElem{index: n})
+		}
+		if !p.accept(".") {
+			return pa
+		}
+	}
+}
+
+func (p *parser) value() value {
+	t := p.next()
+	if t.kind != tokValue {
+		p.fail()
+	}
+	v, ok := p.ctx.values[t.text]
+	if !ok {
+		validationf("An expression attribute value used in expression is not defined; attribute value: %s", t.text)
+	}
+	return v
+}
+
+// operand parses an operand of a condition: a path,
+// a value placeholder or a call of size.
+func (p *parser) operand() operand {
+	if p.isCall("size") {
+		p.next()
+		p.next()
+		pa := p.path()
+		p.expect(")")
+		return func(it item) value {
+			if v := pa.get(it); v != nil {
+				return size(v)
+			}
+			return nil
+		}
+	}
+	if p.peek().kind == tokValue {
+		v := p.value()
+		return func(item) value { return v }
+	}
+	pa := p.path()
+	return pa.get
+}
+
+// parseCondition parses a condition or filter expression.
+func (ctx *exprContext) parseCondition(expr string) condition {
+	p := ctx.newParser(expr)
+	c := p.or()
+	p.end()
+	return c
+}
+
+func (p *parser) or() condition {
+	c := p.and()
+	for p.accept("OR") {
+		a, b := c, p.and()
+		c = func(it item) bool { return a(it) || b(it) }
+	}
+	return c
+}
+
+func (p *parser) and() condition {
+	c := p.not()
+	for p.accept("AND") {
+		a, b := c, p.not()
+		c = func(it item) bool { return a(it) && b(it) }
+	}
+	return c
+}
+
+func (p *parser) not() condition {
+	if p.accept("NOT") {
+		c := p.not()
+		return func(it item) bool { return !c(it) }
+	}
+	return p.primary()
+}
+
+var comparators = map[string]string{
+	"=":  "EQ",
+	"<>": "NE",
+	"<":  "LT",
+	"<=": "LE",
+	">":  "GT",
+	">=": "GE",
+}
+
+func (p *parser) primary() condition {
+	if p.accept("(") {
+		c := p.or()
+		p.expect(")")
+		return c
+	}
+	for _, f := range []string{"attribute_exists", "attribute_not_exists", "attribute_type", "begins_with", "contains"} {
+		if p.isCall(f) {
+			return p.function(f)
+		}
+	}
+
+	a := p.operand()
+	if p.accept("BETWEEN") {
+		lo := p.operand()
+		p.expect("AND")
+		hi := p.operand()
+		return func(it item) bool {
+			v := a(it)
+			c1, ok1 := compare(v, lo(it))
+			c2, ok2 := compare(v, hi(it))
+			return ok1 && ok2 && c1 >= 0 && c2 <= 0
+		}
+	}
+	if p.accept("IN") {
+		p.expect("(")
+		var list []operand
+		for {
+			list = append(list, p.operand())
+			if !p.accept(",") {
+				break
+			}
+		}
+		p.expect(")")
+		return func(it item) bool {
+			v := a(it)
+			for _, o := range list {
+				if equal(v, o(it)) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	t := p.next()
+	op, ok := comparators[t.text]
+	if t.kind != tokPunct || !ok {
+		p.fail()
+	}
+	b := p.operand()
+	return func(it item) bool {
+		x, y := a(it), b(it)
+		switch op {
+		case "EQ":
+			return equal(x, y)
+		case "NE":
+			return !equal(x, y)
+		}
+		return y != nil && comparison{op, []value{y}}.eval(x)
+	}
+}
+
+func (p *parser) function(name string) condition {
+	p.next()
+	p.next()
+	pa := p.path()
+	var arg operand
+	if name != "attribute_exists" && name != "attribute_not_exists" {
+		p.expect(",")
+		arg = p.operand()
+	}
+	p.expect(")")
+
+	switch name {
+	case "attribute_exists":
+		return func(it item) bool { return pa.get(it) != nil }
+	case "attribute_not_exists":
+		return func(it item) bool { return pa.get(it) == nil }
+	case "attribute_type":
+		return func(it item) bool {
+			v, t := pa.get(it), arg(it)
+			return v != nil && t != nil && t.typ() == "S" && v.typ() == t.str()
+		}
+	case "begins_with":
+		return func(it item) bool { return beginsWith(pa.get(it), arg(it)) }
+	}
+	return func(it item) bool { return contains(pa.get(it), arg(it)) }
+}
+
+// parseProjection parses a projection expression and returns
+// a function returning the projected attributes of an item.
+func (ctx *exprContext) parseProjection(expr string) func(it item) item {
+	p := ctx.newParser(expr)
+	var paths []path
+	for {
+		paths = append(paths, p.path())
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.end()
+	return func(it item) item {
+		out := item{}
+		for _, pa := range paths {
+			if v := pa.get(it); v != nil {
+				project(out, pa, copyValue(v))
+			}
+		}
+		return out
+	}
+}
+
+// project sets v at p in out, creating the maps and lists holding it.
+// List elements are appended, so that the projection of a[3] and a[5]
+// is a list of two elements, as DynamoDB does.
+func project(out map[string]interface{}, p path, v value) {
+	name := p[0].name
+	if len(p) == 1 {
+		out[name] = map[string]interface{}(v)
+		return
+	}
+	parent := asValue(out[name])
+	if parent == nil {
+		parent = container(p[1])
+		out[name] = map[string]interface{}(parent)
+	}
+	projectElem(parent, p[1:], v)
+}
+
+// projectElem sets v at p in the map or list parent.
+func projectElem(parent value, p path, v value) {
+	if p[0].index < 0 {
+		project(parent.members(), p, v)
+		return
+	}
+	elem := v
+	if len(p) > 1 {
+		elem = container(p[1])
+		projectElem(elem, p[1:], v)
+	}
+	parent["L"] = append(parent.elems(), map[string]interface{}(elem))
+}
+
+// container returns an empty value that can hold the path element e.
+func container(e pathElem) value {
+	if e.index >= 0 {
+		return value{"L": []interface{}{}}
+	}
+	return value{"M": map[string]interface{}{}}
+}
+
+// updateAction is an action of an update expression, applied
+// to an item after all the actions have been parsed.
+type updateAction struct {
+	clause string // SET, REMOVE, ADD or DELETE
+	path   path
+	value  operand
+}
+
+// parseUpdate parses an update expression and returns a function
+// applying it to an item. The values are computed from the item as
+// it was before the update.
+func (ctx *exprContext) parseUpdate(expr string) func(it item) []path {
+	p := ctx.newParser(expr)
+	var actions []updateAction
+	seen := map[string]bool{}
+	for p.peek().kind != tokEOF {
+		t := p.next()
+		clause := strings.ToUpper(t.text)
+		if t.kind != tokIdent || seen[clause] {
+			p.fail()
+		}
+		seen[clause] = true
+		for {
+			a := updateAction{clause: clause, path: p.path()}
+			switch clause {
+			case "SET":
+				p.expect("=")
+				a.value = p.setValue()
+			case "REMOVE":
+			case "ADD", "DELETE":
+				if len(a.path) > 1 {
+					validationf("Invalid UpdateExpression: The %s action only supports top-level attributes", clause)
+				}
+				v := p.value()
+				a.value = func(item) value { return v }
+			default:
+				p.fail()
+			}
+			for _, b := range actions {
+				if a.path.overlaps(b.path) {
+					validationf("Invalid UpdateExpression: Two document paths overlap with each other; must remove or rewrite one of these paths; path one: [%s], path two: [%s]", b.path, a.path)
+				}
+			}
+			actions = append(actions, a)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+	if len(actions) == 0 {
+		p.fail()
+	}
+
+	return func(it item) []path {
+		old := copyItem(it)
+		values := make([]value, len(actions))
+		for i, a := range actions {
+			if a.value != nil {
+				values[i] = a.value(old)
+			}
+		}
+		var updated []path
+		var removed []path
+		for i, a := range actions {
+			switch a.clause {
+			case "SET":
+				a.path.set(it, values[i])
+			case "REMOVE":
+				removed = append(removed, a.path)
+			case "ADD":
+				v := values[i]
+				if t := v.typ(); t != "N" && !isSetType(t) {
+					validationf("Invalid UpdateExpression: Incorrect operand type for operator or function; operator: ADD, operand type: %s", t)
+				}
+				a.path.set(it, add(a.path.get(it), v))
+			case "DELETE":
+				a.path.set(it, removeElements(a.path.get(it), values[i]))
+			}
+			updated = append(updated, a.path)
+		}
+		// Remove list elements from the last one, so that
+		// the indexes of the others stay valid.
+		sort.SliceStable(removed, func(i, j int) bool {
+			return removed[i][len(removed[i])-1].index > removed[j][len(removed[j])-1].index
+		})
+		for _, pa := range removed {
+			pa.remove(it)
+		}
+		return updated
+	}
+}
+
+// setValue parses the value of a SET action.
+func (p *parser) setValue() operand {
+	a := p.setOperand()
+	if p.accept("+") {
+		b := p.setOperand()
+		return func(it item) value {
+			x, y := a(it), b(it)
+			if x == nil || y == nil || x.typ() != "N" || y.typ() != "N" {
+				validationf("An operand in the update expression has an incorrect data type")
+			}
+			return add(x, y)
+		}
+	}
+	if p.accept("-") {
+		b := p.setOperand()
+		return func(it item) value { return subtract(a(it), b(it)) }
+	}
+	return a
+}
+
+func (p *parser) setOperand() operand {
+	switch {
+	case p.isCall("if_not_exists"):
+		p.next()
+		p.next()
+		pa := p.path()
+		p.expect(",")
+		def := p.setOperand()
+		p.expect(")")
+		return func(it item) value {
+			if v := pa.get(it); v != nil {
+				return v
+			}
+			return def(it)
+		}
+	case p.isCall("list_append"):
+		p.next()
+		p.next()
+		a := p.setOperand()
+		p.expect(",")
+		b := p.setOperand()
+		p.expect(")")
+		return func(it item) value {
+			x, y := a(it), b(it)
+			if x == nil || y == nil || x.typ() != "L" || y.typ() != "L" {
+				validationf("An operand in the update expression has an incorrect data type")
+			}
+			return value{"L": append(append([]interface{}{}, x.elems()...), y.elems()...)}
+		}
+	case p.peek().kind == tokValue:
+		v := p.value()
+		return func(item) value { return v }
+	}
+	pa := p.path()
+	return func(it item) value {
+		v := pa.get(it)
+		if v == nil {
+			validationf("The provided expression refers to an attribute that does not exist in the item")
+		}
+		return v
+	}
+}
@@ -0,0 +1,211 @@
+package dynamodbtest
+
+// checkItem checks that the item has the key attributes of the
+// table, and that its index key attributes have the right types.
+func (t *table) checkItem(it item) {
+	t.key.checkItem(it)
+	for _, ix := range t.indexes {
+		for _, name := range ix.key.names() {
+			if v := it.get(name); v != nil && v.typ() != ix.key.types[name] {
+				validationf("One or more parameter values were invalid: Type mismatch for Index Key %s Expected: %s Actual: %s IndexName: %s", name, ix.key.types[name], v.typ(), ix.name)
+			}
+		}
+	}
+}
+
+// projection returns the function selecting the attributes
+// requested by the AttributesToGet or ProjectionExpression
+// parameter of r. It returns nil if neither is set.
+func projection(r request, ctx *exprContext) func(it item) item {
+	if expr := r.string("ProjectionExpression"); expr != "" {
+		if r.has("AttributesToGet") {
+			validationf("Can not use both expression and non-expression parameters in the same request: Non-expression parameters: {AttributesToGet} Expression parameters: {ProjectionExpression}")
+		}
+		return ctx.parseProjection(expr)
+	}
+	names := r.strings("AttributesToGet")
+	if len(names) == 0 {
+		return nil
+	}
+	return func(it item) item {
+		out := item{}
+		for _, name := range names {
+			out.set(name, copyValue(it.get(name)))
+		}
+		return out
+	}
+}
+
+// writeResponse returns the response to a write request, holding
+// the attributes asked for by its ReturnValues parameter.
+func writeResponse(r request, old, new item, updated []path) interface{} {
+	resp := map[string]interface{}{}
+	var attrs item
+	switch rv := r.string("ReturnValues"); rv {
+	case "", "NONE":
+	case "ALL_OLD":
+		attrs = old
+	case "ALL_NEW":
+		if r.has("Item") || new == nil {
+			validationf("ReturnValues can only be NONE or ALL_OLD")
+		}
+		attrs = new
+	case "UPDATED_OLD", "UPDATED_NEW":
+		if updated == nil {
+			validationf("ReturnValues can only be NONE or ALL_OLD")
+		}
+		src := old
+		if rv == "UPDATED_NEW" {
+			src = new
+		}
+		attrs = item{}
+		for _, p := range updated {
+			if v := p.get(src); v != nil {
+				project(attrs, p, copyValue(v))
+			}
+		}
+	default:
+		validationf("Invalid ReturnValues %q", rv)
+	}
+	if len(attrs) > 0 {
+		resp["Attributes"] = copyItem(attrs)
+	}
+	return resp
+}
+
+// putItem implements the DynamoDB PutItem entry point.
+func (srv *Server) putItem(r request) interface{} {
+	t := srv.table(r)
+	it := r.item("Item")
+	if it == nil {
+		validationf("One or more parameter values were invalid: Item is required")
+	}
+	t.checkItem(it)
+
+	k := t.key.encode(it)
+	old := t.items[k]
+	checkCondition(r, newExprContext(r), old)
+	t.items[k] = copyItem(it)
+	return writeResponse(r, old, nil, nil)
+}
+
+// getItem implements the DynamoDB GetItem entry point.
+func (srv *Server) getItem(r request) interface{} {
+	t := srv.table(r)
+	key := r.item("Key")
+	t.key.checkKey(key)
+	proj := projection(r, newExprContext(r))
+
+	resp := map[string]interface{}{}
+	if it := t.items[t.key.encode(key)]; it != nil {
+		if proj != nil {
+			it = proj(it)
+		}
+		resp["Item"] = copyItem(it)
+	}
+	return resp
+}
+
+// deleteItem implements the DynamoDB DeleteItem entry point.
+func (srv *Server) deleteItem(r request) interface{} {
+	t := srv.table(r)
+	key := r.item("Key")
+	t.key.checkKey(key)
+
+	k := t.key.encode(key)
+	old := t.items[k]
+	checkCondition(r, newExprContext(r), old)
+	delete(t.items, k)
+	return writeResponse(r, old, nil, nil)
+}
+
+// updateItem implements the DynamoDB UpdateItem entry point. As
+// DynamoDB does, it creates the item if it does not exist.
+func (srv *Server) updateItem(r request) interface{} {
+	t := srv.table(r)
+	key := r.item("Key")
+	t.key.checkKey(key)
+	ctx := newExprContext(r)
+
+	var update func(it item) []path
+	if expr := r.string("UpdateExpression"); expr != "" {
+		if r.has("AttributeUpdates") {
+			validationf("Can not use both expression and non-expression parameters in the same request: Non-expression parameters: {AttributeUpdates} Expression parameters: {UpdateExpression}")
+		}
+		update = ctx.parseUpdate(expr)
+	} else {
+		update = attributeUpdates(r.object("AttributeUpdates"))
+	}
+
+	k := t.key.encode(key)
+	old := t.items[k]
+	checkCondition(r, ctx, old)
+
+	it := copyItem(old)
+	if it == nil {
+		it = copyItem(key)
+	}
+	updated := update(it)
+	for _, name := range t.key.names() {
+		if !equal(it.get(name), key.get(name)) {
+			validationf("One or more parameter values were invalid: Cannot update attribute %s. This attribute is part of the key", name)
+		}
+	}
+	t.checkItem(it)
+	t.items[k] = it
+	if updated == nil {
+		updated = []path{}
+	}
+	return writeResponse(r, old, it, updated)
+}
+
+// attributeUpdates parses the legacy AttributeUpdates parameter
+// and returns a function applying the updates to an item.
+func attributeUpdates(updates request) func(it item) []path {
+	type update struct {
+		name   string
+		action string
+		value  value
+	}
+	var us []update
+	for name := range updates {
+		u := updates.object(name)
+		action := u.string("Action")
+		if action == "" {
+			action = "PUT"
+		}
+		v, ok := toValue(u["Value"])
+		if u.has("Value") && !ok {
+			validationf("invalid attribute value for %s in AttributeUpdates", name)
+		}
+		switch {
+		case action == "PUT" && v == nil:
+			validationf("One or more parameter values were invalid: Only DELETE action is allowed when no attribute value is specified")
+		case action == "ADD" && (v == nil || (v.typ() != "N" && !isSetType(v.typ()))):
+			validationf("One or more parameter values were invalid: ADD action is not supported for the type %s", v.typ())
+		case action == "DELETE" && v != nil && !isSetType(v.typ()):
+			validationf("One or more parameter values were invalid: DELETE action with value is not supported for the type %s", v.typ())
+		case action != "PUT" && action != "ADD" && action != "DELETE":
+			validationf("Invalid Action %q in AttributeUpdates", action)
+		}
+		us = append(us, update{name, action, v})
+	}
+
+	return func(it item) []path {
+		var updated []path
+		for _, u := range us {
+			switch {
+			case u.action == "PUT":
+				it.set(u.name, copyValue(u.value))
+			case u.action == "ADD":
+				it.set(u.name, add(it.get(u.name), u.value))
+			case u.value == nil:
+				it.set(u.name, nil)
+			default:
+				it.set(u.name, removeElements(it.get(u.name), u.value))
+			}
+			updated = append(updated, path{{u.name, -1}})
+		}
+		return updated
+	}
+}
@@ -0,0 +1,347 @@
+package dynamodbtest
+
+import (
+	"hash/fnv"
+	"sort"
+)
+
+// keyOperators holds the comparison operators allowed
+// on range keys in key conditions.
+var keyOperators = map[string]bool{
+	"EQ":          true,
+	"LE":          true,
+	"LT":          true,
+	"GE":          true,
+	"GT":          true,
+	"BEGINS_WITH": true,
+	"BETWEEN":     true,
+}
+
+// index returns the index named by the IndexName parameter, or nil
+// if there is none, and the key schema that orders the items read.
+func (t *table) index(r request) (*index, keySchema) {
+	name := r.string("IndexName")
+	if name == "" {
+		return nil, t.key
+	}
+	ix := t.indexes[name]
+	if ix == nil {
+		validationf("The table does not have the specified index: %s", name)
+	}
+	if ix.global && r.bool("ConsistentRead", false) {
+		validationf("Consistent reads are not supported on global secondary indexes")
+	}
+	return ix, ix.key
+}
+
+// keyConditions returns the KeyConditions or KeyConditionExpression
+// of a query, checking them against the key schema ks.
+func keyConditions(r request, ctx *exprContext, ks keySchema) map[string]comparison {
+	var conds map[string]comparison
+	if expr := r.string("KeyConditionExpression"); expr != "" {
+		if r.has("KeyConditions") {
+			validationf("Can not use both expression and non-expression parameters in the same request: Non-expression parameters: {KeyConditions} Expression parameters: {KeyConditionExpression}")
+		}
+		conds = ctx.parseKeyCondition(expr)
+	} else {
+		conds = parseComparisons("KeyConditions", r.object("KeyConditions"))
+	}
+
+	if c, ok := conds[ks.hash]; !ok || c.op != "EQ" {
+		validationf("Query condition missed key schema element: %s", ks.hash)
+	}
+	for name, c := range conds {
+		switch {
+		case name == ks.hash:
+		case name != ks.rng:
+			validationf("Query condition missed key schema element: %s", name)
+		case !keyOperators[c.op]:
+			validationf("Query key condition not supported")
+		}
+		for _, a := range c.args {
+			if a.typ() != ks.types[name] {
+				validationf("One or more parameter values were invalid: Condition parameter type does not match schema type")
+			}
+		}
+	}
+	return conds
+}
+
+// parseKeyCondition parses a key condition expression, which is
+// made of the comparisons of the key attributes joined by AND.
+func (ctx *exprContext) parseKeyCondition(expr string) map[string]comparison {
+	p := ctx.newParser(expr)
+	conds := make(map[string]comparison)
+	for {
+		p.keyComparison(conds)
+		if !p.accept("AND") {
+			break
+		}
+	}
+	p.end()
+	return conds
+}
+
+func (p *parser) keyComparison(conds map[string]comparison) {
+	if p.accept("(") {
+		p.keyComparison(conds)
+		p.expect(")")
+		return
+	}
+
+	var pa path
+	var c comparison
+	switch {
+	case p.isCall("begins_with"):
+		p.next()
+		p.next()
+		pa = p.path()
+		p.expect(",")
+		c = comparison{"BEGINS_WITH", []value{p.value()}}
+		p.expect(")")
+	default:
+		pa = p.path()
+		if p.accept("BETWEEN") {
+			lo := p.value()
+			p.expect("AND")
+			c = comparison{"BETWEEN", []value{lo, p.value()}}
+			break
+		}
+		t := p.next()
+		op, ok := comparators[t.text]
+		if t.kind != tokPunct || !ok || op == "NE" {
+			p.fail()
+		}
+		c = comparison{op, []value{p.value()}}
+	}
+
+	if len(pa) != 1 {
+		validationf("Invalid KeyConditionExpression: Key conditions can only be on top-level key attributes")
+	}
+	if _, ok := conds[pa[0].name]; ok {
+		validationf("Invalid KeyConditionExpression: KeyConditionExpressions must only contain one condition per key")
+	}
+	conds[pa[0].name] = c
+}
+
+// filter returns the QueryFilter, ScanFilter or FilterExpression
+// of a request, or nil if there is none.
+func filter(r request, ctx *exprContext, name string) condition {
+	c := legacyCondition(r, name)
+	if expr := r.string("FilterExpression"); expr != "" {
+		if c != nil {
+			validationf("Can not use both expression and non-expression parameters in the same request: Non-expression parameters: {%s} Expression parameters: {FilterExpression}", name)
+		}
+		c = ctx.parseCondition(expr)
+	}
+	return c
+}
+
+// order compares the items a and b in the order in which they are
+// read from the index ix, or from the table if ix is nil: by hash key,
+// then by range key, then by table key for the items of an index.
+func (t *table) order(ix *index, a, b item) int {
+	names := t.key.names()
+	if ix != nil {
+		names = append(ix.key.names(), names...)
+	}
+	for _, name := range names {
+		x, y := a.get(name), b.get(name)
+		switch {
+		case x == nil && y == nil:
+			continue
+		case x == nil:
+			return -1
+		case y == nil:
+			return 1
+		}
+		if c, _ := compare(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// query implements the DynamoDB Query entry point.
+func (srv *Server) query(r request) interface{} {
+	t := srv.table(r)
+	ix, ks := t.index(r)
+	ctx := newExprContext(r)
+	conds := keyConditions(r, ctx, ks)
+	f := filter(r, ctx, "QueryFilter")
+
+	var items []item
+	for _, it := range t.items {
+		if !ks.hasKey(it) {
+			continue
+		}
+		match := true
+		for name, c := range conds {
+			match = match && c.eval(it.get(name))
+		}
+		if match {
+			items = append(items, it)
+		}
+	}
+
+	forward := r.bool("ScanIndexForward", true)
+	sort.Slice(items, func(i, j int) bool {
+		c := t.order(ix, items[i], items[j])
+		return (forward && c < 0) || (!forward && c > 0)
+	})
+	if !forward {
+		return t.page(r, ctx, ix, items, f, -1)
+	}
+	return t.page(r, ctx, ix, items, f, 1)
+}
+
+// scan implements the DynamoDB Scan entry point. Items are read
+// in key order; the items of a segment of a parallel scan are
+// those whose hash key falls into the segment.
+func (srv *Server) scan(r request) interface{} {
+	t := srv.table(r)
+	ix, ks := t.index(r)
+	ctx := newExprContext(r)
+	f := filter(r, ctx, "ScanFilter")
+
+	segment, total := r.int("Segment"), r.int("TotalSegments")
+	if r.has("Segment") != r.has("TotalSegments") {
+		validationf("The Segment parameter is required but was not present in the request when parameter TotalSegments is present")
+	}
+	if r.has("TotalSegments") && (total < 1 || total > 1000000 || segment < 0 || segment >= total) {
+		validationf("The Segment parameter must be less than the TotalSegments parameter, which must be between 1 and 1000000")
+	}
+
+	var items []item
+	for _, it := range t.items {
+		if !ks.hasKey(it) {
+			continue
+		}
+		if total > 0 {
+			h := fnv.New32a()
+			h.Write([]byte(keySchema{hash: ks.hash}.encode(it)))
+			if int(h.Sum32()%uint32(total)) != segment {
+				continue
+			}
+		}
+		items = append(items, it)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return t.order(ix, items[i], items[j]) < 0
+	})
+	return t.page(r, ctx, ix, items, f, 1)
+}
+
+// page returns the response to a query or scan reading items, in
+// their order dir (1 or -1), from the ExclusiveStartKey parameter
+// and up to the Limit parameter.
+func (t *table) page(r request, ctx *exprContext, ix *index, items []item, f condition, dir int) interface{} {
+	if start := r.item("ExclusiveStartKey"); start != nil {
+		for _, name := range t.key.names() {
+			if start.get(name) == nil {
+				validationf("The provided starting key is invalid: The provided key element does not match the schema")
+			}
+		}
+		// The dynamodb package only sends the table key, so
+		// take the index key attributes from the item if it
+		// still exists.
+		if it := t.items[t.key.encode(start)]; it != nil && ix != nil {
+			start = copyItem(start)
+			for _, name := range ix.key.names() {
+				if start.get(name) == nil {
+					start.set(name, it.get(name))
+				}
+			}
+		}
+		i := 0
+		for i < len(items) && dir*t.order(ix, items[i], start) <= 0 {
+			i++
+		}
+		items = items[i:]
+	}
+
+	limit := r.int("Limit")
+	if r.has("Limit") && limit < 1 {
+		validationf("Limit must be greater than or equal to 1")
+	}
+	resp := map[string]interface{}{}
+	if limit > 0 && len(items) >= limit {
+		// Like DynamoDB, return a LastEvaluatedKey whenever
+		// the limit is reached, even if no item is left.
+		items = items[:limit]
+		last := items[limit-1]
+		key := t.key.keyOf(last)
+		if ix != nil {
+			for _, name := range ix.key.names() {
+				key.set(name, copyValue(last.get(name)))
+			}
+		}
+		resp["LastEvaluatedKey"] = key
+	}
+
+	proj := projection(r, ctx)
+	sel := r.string("Select")
+	switch sel {
+	case "", "ALL_ATTRIBUTES", "ALL_PROJECTED_ATTRIBUTES", "COUNT":
+		if proj != nil && sel != "" {
+			validationf("Cannot specify the AttributesToGet or ProjectionExpression when choosing to get %s", sel)
+		}
+	case "SPECIFIC_ATTRIBUTES":
+		if proj == nil {
+			validationf("SPECIFIC_ATTRIBUTES requires AttributesToGet or ProjectionExpression")
+		}
+	default:
+		validationf("Invalid Select %q", sel)
+	}
+	if sel == "ALL_ATTRIBUTES" && ix != nil && ix.global && ix.projection.ProjectionType != "ALL" {
+		validationf("One or more parameter values were invalid: Select type ALL_ATTRIBUTES is not supported for global secondary index %s because its projection type is not ALL", ix.name)
+	}
+
+	var out []interface{}
+	for _, it := range items {
+		if f != nil && !f(it) {
+			continue
+		}
+		if ix != nil && sel != "ALL_ATTRIBUTES" {
+			it = t.project(ix, it)
+		}
+		if proj != nil {
+			it = proj(it)
+		}
+		out = append(out, copyItem(it))
+	}
+	resp["Count"] = len(out)
+	resp["ScannedCount"] = len(items)
+	if rc := r.string("ReturnConsumedCapacity"); rc == "TOTAL" || rc == "INDEXES" {
+		resp["ConsumedCapacity"] = map[string]interface{}{
+			"TableName":     t.desc.TableName,
+			"CapacityUnits": readCapacity(items, r.bool("ConsistentRead", false)),
+		}
+	}
+	if sel != "COUNT" {
+		if out == nil {
+			out = []interface{}{}
+		}
+		resp["Items"] = out
+	}
+	return resp
+}
+
+// readCapacity returns the capacity units consumed by reading items:
+// one unit per started 4KB for consistent reads, half as much for
+// eventually consistent reads.
+func readCapacity(items []item, consistent bool) float64 {
+	var size int64
+	for _, it := range items {
+		size += itemSize(it)
+	}
+	units := float64((size + 4095) / 4096)
+	if units == 0 {
+		units = 1
+	}
+	if !consistent {
+		units /= 2
+	}
+	return units
+}
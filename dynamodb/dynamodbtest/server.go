@@ -0,0 +1,263 @@
+// The dynamodbtest package implements a fake DynamoDB provider
+// keeping its tables in memory, so that code using the dynamodb
+// package can be tested without DynamoDB Local or an AWS account.
+package dynamodbtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/crowdmob/goamz/dynamodb"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Server implements a DynamoDB simulator for use in testing.
+type Server struct {
+	url      string
+	listener net.Listener
+	mu       sync.Mutex
+	tables   map[string]*table // name -> table
+}
+
+var actions = map[string]func(*Server, request) interface{}{
+	"CreateTable":        (*Server).createTable,
+	"DescribeTable":      (*Server).describeTable,
+	"ListTables":         (*Server).listTables,
+	"DeleteTable":        (*Server).deleteTable,
+	"UpdateTable":        (*Server).updateTable,
+	"UpdateTimeToLive":   (*Server).updateTimeToLive,
+	"DescribeTimeToLive": (*Server).describeTimeToLive,
+	"PutItem":            (*Server).putItem,
+	"GetItem":            (*Server).getItem,
+	"UpdateItem":         (*Server).updateItem,
+	"DeleteItem":         (*Server).deleteItem,
+	"Query":              (*Server).query,
+	"Scan":               (*Server).scan,
+	"BatchGetItem":       (*Server).batchGetItem,
+	"BatchWriteItem":     (*Server).batchWriteItem,
+}
+
+// NewServer returns a new server with no tables.
+func NewServer() (*Server, error) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return nil, fmt.Errorf("cannot listen on localhost: %v", err)
+	}
+	srv := &Server{
+		listener: l,
+		url:      "http://" + l.Addr().String(),
+		tables:   make(map[string]*table),
+	}
+	go http.Serve(l, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		srv.serveHTTP(w, req)
+	}))
+	return srv, nil
+}
+
+// Quit closes down the server.
+func (srv *Server) Quit() {
+	srv.listener.Close()
+}
+
+// URL returns the URL of the server.
+func (srv *Server) URL() string {
+	return srv.url
+}
+
+// Reset deletes all the tables of the server.
+func (srv *Server) Reset() {
+	srv.mu.Lock()
+	srv.tables = make(map[string]*table)
+	srv.mu.Unlock()
+}
+
+// serveHTTP serves the DynamoDB JSON protocol.
+func (srv *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	// Methods on Server that deal with parsing the request may
+	// fail. To save on error handling code, we allow these methods
+	// to call fatalf, which will panic with a *dynamodb.Error which
+	// will be caught here and returned to the client as a properly
+	// formed DynamoDB error.
+	defer func() {
+		switch err := recover().(type) {
+		case *dynamodb.Error:
+			writeError(w, err)
+		case nil:
+		default:
+			panic(err)
+		}
+	}()
+
+	target := req.Header.Get("X-Amz-Target")
+	if !strings.HasPrefix(target, "DynamoDB_20120810.") {
+		fatalf(400, "UnknownOperationException", "unknown target %q", target)
+	}
+	f := actions[strings.TrimPrefix(target, "DynamoDB_20120810.")]
+	if f == nil {
+		fatalf(400, "UnknownOperationException", "unsupported operation %q", target)
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		fatalf(400, "SerializationException", "cannot read request: %v", err)
+	}
+	r := request{}
+	if err := json.Unmarshal(body, &r); err != nil {
+		fatalf(400, "SerializationException", "cannot parse request: %v", err)
+	}
+
+	srv.mu.Lock()
+	response := func() interface{} {
+		defer srv.mu.Unlock()
+		return f(srv, r)
+	}()
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		panic(fmt.Errorf("error marshalling %#v: %v", response, err))
+	}
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	w.Write(data)
+}
+
+// writeError writes an appropriate error response.
+func writeError(w http.ResponseWriter, err *dynamodb.Error) {
+	data, _ := json.Marshal(map[string]string{
+		"__type":  "com.amazonaws.dynamodb.v20120810#" + err.Code,
+		"message": err.Message,
+	})
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	w.WriteHeader(err.StatusCode)
+	w.Write(data)
+}
+
+func fatalf(statusCode int, code string, f string, a ...interface{}) {
+	panic(&dynamodb.Error{
+		StatusCode: statusCode,
+		Code:       code,
+		Message:    fmt.Sprintf(f, a...),
+	})
+}
+
+func validationf(f string, a ...interface{}) {
+	fatalf(400, "ValidationException", f, a...)
+}
+
+// request holds the parameters of a request. Its accessors
+// call fatalf when a parameter has the wrong type.
+type request map[string]interface{}
+
+func (r request) has(name string) bool {
+	_, ok := r[name]
+	return ok
+}
+
+func (r request) string(name string) string {
+	switch v := r[name].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	validationf("%s must be a string", name)
+	panic("unreachable")
+}
+
+// bool returns a boolean parameter. The dynamodb package sends
+// some of them as the strings "true" and "false".
+func (r request) bool(name string, def bool) bool {
+	switch v := r[name].(type) {
+	case nil:
+		return def
+	case bool:
+		return v
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	validationf("%s must be a boolean", name)
+	panic("unreachable")
+}
+
+func (r request) int(name string) int {
+	switch v := r[name].(type) {
+	case nil:
+		return 0
+	case float64:
+		if v == float64(int(v)) {
+			return int(v)
+		}
+	}
+	validationf("%s must be an integer", name)
+	panic("unreachable")
+}
+
+func (r request) object(name string) request {
+	switch v := r[name].(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		return request(v)
+	}
+	validationf("%s must be an object", name)
+	panic("unreachable")
+}
+
+func (r request) list(name string) []interface{} {
+	switch v := r[name].(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return v
+	}
+	validationf("%s must be a list", name)
+	panic("unreachable")
+}
+
+func (r request) strings(name string) []string {
+	var s []string
+	for _, v := range r.list(name) {
+		str, ok := v.(string)
+		if !ok {
+			validationf("%s must be a list of strings", name)
+		}
+		s = append(s, str)
+	}
+	return s
+}
+
+// item returns a parameter holding attribute values, such as Item or Key.
+func (r request) item(name string) item {
+	v := r.object(name)
+	if v == nil {
+		return nil
+	}
+	return toItem(name, v)
+}
+
+// decodeTo decodes the whole request into v, which must be a
+// pointer to the type of the dynamodb package describing it.
+func (r request) decodeTo(v interface{}) {
+	data, _ := json.Marshal(r)
+	if err := json.Unmarshal(data, v); err != nil {
+		validationf("invalid request: %v", err)
+	}
+}
+
+// table returns the table named by the TableName parameter.
+func (srv *Server) table(r request) *table {
+	return srv.tableByName(r.string("TableName"))
+}
+
+func (srv *Server) tableByName(name string) *table {
+	t := srv.tables[name]
+	if t == nil {
+		fatalf(400, "ResourceNotFoundException", "Requested resource not found: Table: %s not found", name)
+	}
+	return t
+}
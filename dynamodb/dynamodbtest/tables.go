@@ -0,0 +1,533 @@
+package dynamodbtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/crowdmob/goamz/dynamodb"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// keySchema holds the names of the key attributes of a table or index.
+type keySchema struct {
+	hash  string
+	rng   string // "" if there is no range key
+	types map[string]string
+}
+
+// index holds a simulated secondary index.
+type index struct {
+	name       string
+	global     bool
+	key        keySchema
+	projection dynamodb.ProjectionT
+	throughput dynamodb.ProvisionedThroughputT
+}
+
+// table holds a simulated DynamoDB table.
+type table struct {
+	desc    dynamodb.TableDescriptionT
+	key     keySchema
+	items   map[string]item // primary key -> item
+	indexes map[string]*index
+	ttl     dynamodb.TimeToLiveDescriptionT
+}
+
+var tableNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]{3,255}$`)
+
+// newKeySchema checks the key schema ks against the attribute types.
+func newKeySchema(ks []dynamodb.KeySchemaT, types map[string]string) keySchema {
+	var k keySchema
+	for i, e := range ks {
+		switch {
+		case e.KeyType == "HASH" && i == 0:
+			k.hash = e.AttributeName
+		case e.KeyType == "RANGE" && i == 1:
+			k.rng = e.AttributeName
+		default:
+			validationf("Invalid KeySchema: the first element must be a HASH key and the second a RANGE key")
+		}
+	}
+	if k.hash == "" || len(ks) > 2 {
+		validationf("Invalid KeySchema: it must have a HASH key and at most one RANGE key")
+	}
+	k.types = make(map[string]string)
+	for _, name := range k.names() {
+		t := types[name]
+		if t == "" {
+			validationf("One or more parameter values were invalid: Some index key attributes are not defined in AttributeDefinitions. Keys: [%s]", name)
+		}
+		k.types[name] = t
+	}
+	return k
+}
+
+func (k keySchema) names() []string {
+	if k.rng == "" {
+		return []string{k.hash}
+	}
+	return []string{k.hash, k.rng}
+}
+
+func (k keySchema) schema() []dynamodb.KeySchemaT {
+	ks := []dynamodb.KeySchemaT{{AttributeName: k.hash, KeyType: "HASH"}}
+	if k.rng != "" {
+		ks = append(ks, dynamodb.KeySchemaT{AttributeName: k.rng, KeyType: "RANGE"})
+	}
+	return ks
+}
+
+// attributeTypes returns the types of the attributes in defs.
+func attributeTypes(defs []dynamodb.AttributeDefinitionT) map[string]string {
+	types := make(map[string]string)
+	for _, d := range defs {
+		if d.Type != "S" && d.Type != "N" && d.Type != "B" {
+			validationf("Invalid AttributeType %q for %s", d.Type, d.Name)
+		}
+		if types[d.Name] != "" {
+			validationf("Duplicate AttributeName %s in AttributeDefinitions", d.Name)
+		}
+		types[d.Name] = d.Type
+	}
+	return types
+}
+
+// encode returns a string identifying the key of it, which holds
+// the key attributes of k.
+func (k keySchema) encode(it item) string {
+	var parts []string
+	for _, name := range k.names() {
+		v := it.get(name)
+		parts = append(parts, setKey(v.typ()+"S", v.str()))
+	}
+	data, _ := json.Marshal(parts)
+	return string(data)
+}
+
+// checkItem checks that it holds the key attributes of k
+// with the right types.
+func (k keySchema) checkItem(it item) {
+	for _, name := range k.names() {
+		v := it.get(name)
+		if v == nil {
+			validationf("One or more parameter values were invalid: Missing the key %s in the item", name)
+		}
+		if v.typ() != k.types[name] {
+			validationf("One or more parameter values were invalid: Type mismatch for key %s expected: %s actual: %s", name, k.types[name], v.typ())
+		}
+		if v.str() == "" {
+			validationf("One or more parameter values are not valid. The AttributeValue for a key attribute cannot contain an empty string value. Key: %s", name)
+		}
+	}
+}
+
+// checkKey checks that key holds exactly the key attributes of k.
+func (k keySchema) checkKey(key item) {
+	if key == nil {
+		validationf("The provided key element does not match the schema")
+	}
+	for _, name := range k.names() {
+		if v := key.get(name); v == nil || v.typ() != k.types[name] {
+			validationf("The provided key element does not match the schema")
+		}
+	}
+	if len(key) != len(k.names()) {
+		validationf("The provided key element does not match the schema")
+	}
+}
+
+// keyOf returns the key attributes of it.
+func (k keySchema) keyOf(it item) item {
+	key := item{}
+	for _, name := range k.names() {
+		key.set(name, copyValue(it.get(name)))
+	}
+	return key
+}
+
+// hasKey reports whether it holds the key attributes of k, as
+// required for the item to be in an index.
+func (k keySchema) hasKey(it item) bool {
+	for _, name := range k.names() {
+		if v := it.get(name); v == nil || v.typ() != k.types[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// createTable implements the DynamoDB CreateTable entry point.
+func (srv *Server) createTable(r request) interface{} {
+	var desc dynamodb.TableDescriptionT
+	r.decodeTo(&desc)
+	if !tableNamePattern.MatchString(desc.TableName) {
+		validationf("Invalid table name %q", desc.TableName)
+	}
+	if srv.tables[desc.TableName] != nil {
+		fatalf(400, "ResourceInUseException", "Table already exists: %s", desc.TableName)
+	}
+
+	types := attributeTypes(desc.AttributeDefinitions)
+	t := &table{
+		key:     newKeySchema(desc.KeySchema, types),
+		items:   make(map[string]item),
+		indexes: make(map[string]*index),
+		ttl:     dynamodb.TimeToLiveDescriptionT{TimeToLiveStatus: dynamodb.TTL_STATUS_DISABLED},
+	}
+	used := make(map[string]bool)
+	for _, name := range t.key.names() {
+		used[name] = true
+	}
+
+	billingMode := r.string("BillingMode")
+	switch billingMode {
+	case "", dynamodb.BILLING_MODE_PROVISIONED:
+		checkThroughput(desc.ProvisionedThroughput)
+	case dynamodb.BILLING_MODE_PAY_PER_REQUEST:
+		if r.has("ProvisionedThroughput") {
+			validationf("One or more parameter values were invalid: Neither ReadCapacityUnits nor WriteCapacityUnits can be specified when BillingMode is PAY_PER_REQUEST")
+		}
+		desc.BillingModeSummary = &dynamodb.BillingModeSummaryT{BillingMode: billingMode}
+	default:
+		validationf("Invalid BillingMode %q", billingMode)
+	}
+
+	for _, lsi := range desc.LocalSecondaryIndexes {
+		ix := t.newIndex(lsi.IndexName, false, lsi.KeySchema, lsi.Projection, types)
+		if ix.key.hash != t.key.hash || ix.key.rng == "" {
+			validationf("One or more parameter values were invalid: Index KeySchema does not have the same leading hash key as table KeySchema for index: %s", lsi.IndexName)
+		}
+		for _, name := range ix.key.names() {
+			used[name] = true
+		}
+	}
+	for _, gsi := range desc.GlobalSecondaryIndexes {
+		ix := t.newIndex(gsi.IndexName, true, gsi.KeySchema, gsi.Projection, types)
+		if billingMode != dynamodb.BILLING_MODE_PAY_PER_REQUEST {
+			checkThroughput(gsi.ProvisionedThroughput)
+			ix.throughput = gsi.ProvisionedThroughput
+		}
+		for _, name := range ix.key.names() {
+			used[name] = true
+		}
+	}
+	if len(used) != len(types) {
+		validationf("One or more parameter values were invalid: Number of attributes in KeySchema does not exactly match number of attributes defined in AttributeDefinitions")
+	}
+
+	t.desc = dynamodb.TableDescriptionT{
+		AttributeDefinitions: desc.AttributeDefinitions,
+		BillingModeSummary:   desc.BillingModeSummary,
+		CreationDateTime:     float64(time.Now().Unix()),
+		KeySchema:            t.key.schema(),
+		TableName:            desc.TableName,
+		TableStatus:          "ACTIVE",
+	}
+	if billingMode != dynamodb.BILLING_MODE_PAY_PER_REQUEST {
+		t.desc.ProvisionedThroughput.ReadCapacityUnits = desc.ProvisionedThroughput.ReadCapacityUnits
+		t.desc.ProvisionedThroughput.WriteCapacityUnits = desc.ProvisionedThroughput.WriteCapacityUnits
+	}
+	t.setStream(desc.StreamSpecification)
+
+	srv.tables[t.desc.TableName] = t
+	return map[string]interface{}{"TableDescription": t.description()}
+}
+
+func checkThroughput(pt dynamodb.ProvisionedThroughputT) {
+	if pt.ReadCapacityUnits < 1 || pt.WriteCapacityUnits < 1 {
+		validationf("One or more parameter values were invalid: ReadCapacityUnits and WriteCapacityUnits must both be specified and greater than 0")
+	}
+}
+
+// newIndex adds a secondary index to the table.
+func (t *table) newIndex(name string, global bool, ks []dynamodb.KeySchemaT, projection dynamodb.ProjectionT, types map[string]string) *index {
+	if !tableNamePattern.MatchString(name) {
+		validationf("Invalid index name %q", name)
+	}
+	if t.indexes[name] != nil {
+		validationf("One or more parameter values were invalid: Duplicate index name: %s", name)
+	}
+	switch projection.ProjectionType {
+	case "ALL", "KEYS_ONLY":
+		if len(projection.NonKeyAttributes) > 0 {
+			validationf("One or more parameter values were invalid: ProjectionType is %s, but NonKeyAttributes is specified", projection.ProjectionType)
+		}
+	case "INCLUDE":
+	default:
+		validationf("One or more parameter values were invalid: Unknown ProjectionType: %q", projection.ProjectionType)
+	}
+	ix := &index{
+		name:       name,
+		global:     global,
+		key:        newKeySchema(ks, types),
+		projection: projection,
+	}
+	t.indexes[name] = ix
+	return ix
+}
+
+// setStream enables or disables the stream of the table.
+func (t *table) setStream(spec *dynamodb.StreamSpecificationT) {
+	if spec == nil {
+		return
+	}
+	if !spec.StreamEnabled {
+		t.desc.StreamSpecification = nil
+		return
+	}
+	switch spec.StreamViewType {
+	case "KEYS_ONLY", "NEW_IMAGE", "OLD_IMAGE", "NEW_AND_OLD_IMAGES":
+	default:
+		validationf("Invalid StreamViewType %q", spec.StreamViewType)
+	}
+	s := *spec
+	t.desc.StreamSpecification = &s
+	t.desc.LatestStreamLabel = time.Now().UTC().Format("2006-01-02T15:04:05.000")
+	t.desc.LatestStreamArn = fmt.Sprintf("arn:aws:dynamodb:ddblocal:000000000000:table/%s/stream/%s", t.desc.TableName, t.desc.LatestStreamLabel)
+}
+
+// description returns the description of the table,
+// with up to date sizes and indexes.
+func (t *table) description() dynamodb.TableDescriptionT {
+	desc := t.desc
+	desc.ItemCount = int64(len(t.items))
+	desc.TableSizeBytes = 0
+	for _, it := range t.items {
+		desc.TableSizeBytes += itemSize(it)
+	}
+	desc.LocalSecondaryIndexes = nil
+	desc.GlobalSecondaryIndexes = nil
+
+	names := make([]string, 0, len(t.indexes))
+	for name := range t.indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ix := t.indexes[name]
+		var count, size int64
+		for _, it := range t.items {
+			if ix.key.hasKey(it) {
+				count++
+				size += itemSize(t.project(ix, it))
+			}
+		}
+		if ix.global {
+			desc.GlobalSecondaryIndexes = append(desc.GlobalSecondaryIndexes, dynamodb.GlobalSecondaryIndexT{
+				IndexName:             ix.name,
+				IndexSizeBytes:        size,
+				IndexStatus:           "ACTIVE",
+				ItemCount:             count,
+				KeySchema:             ix.key.schema(),
+				Projection:            ix.projection,
+				ProvisionedThroughput: ix.throughput,
+			})
+		} else {
+			desc.LocalSecondaryIndexes = append(desc.LocalSecondaryIndexes, dynamodb.LocalSecondaryIndexT{
+				IndexName:      ix.name,
+				IndexSizeBytes: size,
+				ItemCount:      count,
+				KeySchema:      ix.key.schema(),
+				Projection:     ix.projection,
+			})
+		}
+	}
+	return desc
+}
+
+// itemSize approximates the size of an item as DynamoDB counts it.
+func itemSize(it item) int64 {
+	data, _ := json.Marshal(it)
+	return int64(len(data))
+}
+
+// project returns the attributes of it that are projected into ix.
+func (t *table) project(ix *index, it item) item {
+	if ix.projection.ProjectionType == "ALL" {
+		return it
+	}
+	p := t.key.keyOf(it)
+	for _, name := range ix.key.names() {
+		p.set(name, it.get(name))
+	}
+	for _, name := range ix.projection.NonKeyAttributes {
+		p.set(name, it.get(name))
+	}
+	return p
+}
+
+// describeTable implements the DynamoDB DescribeTable entry point.
+func (srv *Server) describeTable(r request) interface{} {
+	return map[string]interface{}{"Table": srv.table(r).description()}
+}
+
+// listTables implements the DynamoDB ListTables entry point.
+func (srv *Server) listTables(r request) interface{} {
+	names := make([]string, 0, len(srv.tables))
+	for name := range srv.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	start := r.string("ExclusiveStartTableName")
+	i := sort.SearchStrings(names, start)
+	if i < len(names) && names[i] == start {
+		i++
+	}
+	names = names[i:]
+
+	resp := map[string]interface{}{}
+	if limit := r.int("Limit"); limit > 0 && limit < len(names) {
+		names = names[:limit]
+		resp["LastEvaluatedTableName"] = names[limit-1]
+	}
+	resp["TableNames"] = names
+	return resp
+}
+
+// deleteTable implements the DynamoDB DeleteTable entry point.
+func (srv *Server) deleteTable(r request) interface{} {
+	t := srv.table(r)
+	delete(srv.tables, t.desc.TableName)
+	desc := t.description()
+	desc.TableStatus = "DELETING"
+	return map[string]interface{}{"TableDescription": desc}
+}
+
+// updateTable implements the DynamoDB UpdateTable entry point.
+// The changes are made at once, so that the table stays ACTIVE.
+func (srv *Server) updateTable(r request) interface{} {
+	t := srv.table(r)
+
+	var update dynamodb.UpdateTableT
+	r.decodeTo(&update)
+	types := attributeTypes(append(append([]dynamodb.AttributeDefinitionT{}, t.desc.AttributeDefinitions...), newDefinitions(t, update.AttributeDefinitions)...))
+
+	billingMode := dynamodb.BILLING_MODE_PROVISIONED
+	if t.desc.BillingModeSummary != nil {
+		billingMode = t.desc.BillingModeSummary.BillingMode
+	}
+	switch update.BillingMode {
+	case "", billingMode:
+	case dynamodb.BILLING_MODE_PROVISIONED:
+		if update.ProvisionedThroughput == nil {
+			validationf("One or more parameter values were invalid: ProvisionedThroughput must be specified when BillingMode is PROVISIONED")
+		}
+		billingMode = update.BillingMode
+	case dynamodb.BILLING_MODE_PAY_PER_REQUEST:
+		billingMode = update.BillingMode
+	default:
+		validationf("Invalid BillingMode %q", update.BillingMode)
+	}
+	if update.ProvisionedThroughput != nil {
+		if billingMode == dynamodb.BILLING_MODE_PAY_PER_REQUEST {
+			validationf("One or more parameter values were invalid: Neither ReadCapacityUnits nor WriteCapacityUnits can be specified when BillingMode is PAY_PER_REQUEST")
+		}
+		checkThroughput(*update.ProvisionedThroughput)
+	}
+
+	for _, u := range update.GlobalSecondaryIndexUpdates {
+		switch {
+		case u.Create != nil:
+			ix := t.newIndex(u.Create.IndexName, true, u.Create.KeySchema, u.Create.Projection, types)
+			if billingMode == dynamodb.BILLING_MODE_PROVISIONED {
+				checkThroughput(u.Create.ProvisionedThroughput)
+				ix.throughput = u.Create.ProvisionedThroughput
+			}
+		case u.Update != nil:
+			ix := t.indexes[u.Update.IndexName]
+			if ix == nil || !ix.global {
+				fatalf(400, "ResourceNotFoundException", "Requested resource not found: Index: %s not found", u.Update.IndexName)
+			}
+			checkThroughput(u.Update.ProvisionedThroughput)
+			ix.throughput = u.Update.ProvisionedThroughput
+		case u.Delete != nil:
+			ix := t.indexes[u.Delete.IndexName]
+			if ix == nil || !ix.global {
+				fatalf(400, "ResourceNotFoundException", "Requested resource not found: Index: %s not found", u.Delete.IndexName)
+			}
+			delete(t.indexes, ix.name)
+		}
+	}
+	t.desc.AttributeDefinitions = append(t.desc.AttributeDefinitions, newDefinitions(t, update.AttributeDefinitions)...)
+
+	if billingMode == dynamodb.BILLING_MODE_PAY_PER_REQUEST {
+		if t.desc.BillingModeSummary == nil || t.desc.BillingModeSummary.BillingMode != billingMode {
+			t.desc.BillingModeSummary = &dynamodb.BillingModeSummaryT{
+				BillingMode:                       billingMode,
+				LastUpdateToPayPerRequestDateTime: float64(time.Now().Unix()),
+			}
+		}
+		t.desc.ProvisionedThroughput = dynamodb.ProvisionedThroughputT{}
+		for _, ix := range t.indexes {
+			ix.throughput = dynamodb.ProvisionedThroughputT{}
+		}
+	} else if t.desc.BillingModeSummary != nil {
+		t.desc.BillingModeSummary.BillingMode = billingMode
+	}
+	if update.ProvisionedThroughput != nil {
+		pt := &t.desc.ProvisionedThroughput
+		now := float64(time.Now().Unix())
+		if update.ProvisionedThroughput.ReadCapacityUnits < pt.ReadCapacityUnits || update.ProvisionedThroughput.WriteCapacityUnits < pt.WriteCapacityUnits {
+			pt.LastDecreaseDateTime = now
+			pt.NumberOfDecreasesToday++
+		} else {
+			pt.LastIncreaseDateTime = now
+		}
+		pt.ReadCapacityUnits = update.ProvisionedThroughput.ReadCapacityUnits
+		pt.WriteCapacityUnits = update.ProvisionedThroughput.WriteCapacityUnits
+	}
+	t.setStream(update.StreamSpecification)
+
+	return map[string]interface{}{"TableDescription": t.description()}
+}
+
+// newDefinitions returns the attribute definitions of defs
+// that the table does not have yet.
+func newDefinitions(t *table, defs []dynamodb.AttributeDefinitionT) []dynamodb.AttributeDefinitionT {
+	var added []dynamodb.AttributeDefinitionT
+	for _, d := range defs {
+		found := false
+		for _, e := range t.desc.AttributeDefinitions {
+			if e.Name == d.Name {
+				if e.Type != d.Type {
+					validationf("Cannot change the type of attribute %s", d.Name)
+				}
+				found = true
+			}
+		}
+		if !found {
+			added = append(added, d)
+		}
+	}
+	return added
+}
+
+// updateTimeToLive implements the DynamoDB UpdateTimeToLive entry
+// point. Items are not expired by the server.
+func (srv *Server) updateTimeToLive(r request) interface{} {
+	t := srv.table(r)
+	spec := r.object("TimeToLiveSpecification")
+	if spec == nil || spec.string("AttributeName") == "" {
+		validationf("TimeToLiveSpecification is required")
+	}
+	enabled := spec.bool("Enabled", false)
+	if enabled == (t.ttl.TimeToLiveStatus == dynamodb.TTL_STATUS_ENABLED) {
+		validationf("TimeToLive is already %s", map[bool]string{true: "enabled", false: "disabled"}[enabled])
+	}
+	if enabled {
+		t.ttl = dynamodb.TimeToLiveDescriptionT{
+			AttributeName:    spec.string("AttributeName"),
+			TimeToLiveStatus: dynamodb.TTL_STATUS_ENABLED,
+		}
+	} else {
+		t.ttl = dynamodb.TimeToLiveDescriptionT{TimeToLiveStatus: dynamodb.TTL_STATUS_DISABLED}
+	}
+	return map[string]interface{}{"TimeToLiveSpecification": spec}
+}
+
+// describeTimeToLive implements the DynamoDB DescribeTimeToLive entry point.
+func (srv *Server) describeTimeToLive(r request) interface{} {
+	return map[string]interface{}{"TimeToLiveDescription": srv.table(r).ttl}
+}
@@ -0,0 +1,416 @@
+package dynamodbtest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+// A value is an attribute value in the form used by the DynamoDB
+// protocol, holding its type as only key, such as {"S": "foo"}
+// or {"M": {"a": {"N": "1"}}}.
+type value map[string]interface{}
+
+// An item maps attribute names to values. It has the same form
+// as the contents of an M value.
+type item map[string]interface{}
+
+// toItem checks that v holds attribute values, and returns it as an item.
+func toItem(name string, v map[string]interface{}) item {
+	for attr, av := range v {
+		if _, ok := toValue(av); !ok {
+			validationf("invalid attribute value for %s in %s", attr, name)
+		}
+	}
+	return item(v)
+}
+
+// toValue checks that v is a valid attribute value.
+func toValue(v interface{}) (value, bool) {
+	m, ok := v.(map[string]interface{})
+	if !ok || len(m) != 1 {
+		return nil, false
+	}
+	val := value(m)
+	switch t, x := val.typ(), val.data(); t {
+	case "S", "B":
+		_, ok = x.(string)
+		if ok && t == "B" {
+			_, err := base64.StdEncoding.DecodeString(x.(string))
+			ok = err == nil
+		}
+	case "N":
+		s, isString := x.(string)
+		ok = isString && parseNumber(s) != nil
+	case "SS", "NS", "BS":
+		l, isList := x.([]interface{})
+		ok = isList && len(l) > 0
+		seen := map[string]bool{}
+		for _, e := range l {
+			s, isString := e.(string)
+			if !isString || (t == "NS" && parseNumber(s) == nil) {
+				return nil, false
+			}
+			k := setKey(t, s)
+			if seen[k] {
+				return nil, false
+			}
+			seen[k] = true
+		}
+	case "BOOL":
+		_, ok = x.(bool)
+	case "NULL":
+		ok = x == true
+	case "M":
+		var items map[string]interface{}
+		items, ok = x.(map[string]interface{})
+		for _, e := range items {
+			if _, ok = toValue(e); !ok {
+				break
+			}
+		}
+	case "L":
+		var elems []interface{}
+		elems, ok = x.([]interface{})
+		for _, e := range elems {
+			if _, ok = toValue(e); !ok {
+				break
+			}
+		}
+	default:
+		ok = false
+	}
+	return val, ok
+}
+
+func (v value) typ() string {
+	for t := range v {
+		return t
+	}
+	return ""
+}
+
+func (v value) data() interface{} {
+	for _, x := range v {
+		return x
+	}
+	return nil
+}
+
+func (v value) str() string {
+	s, _ := v.data().(string)
+	return s
+}
+
+func (v value) elems() []interface{} {
+	l, _ := v.data().([]interface{})
+	return l
+}
+
+func (v value) members() map[string]interface{} {
+	m, _ := v.data().(map[string]interface{})
+	return m
+}
+
+func isSetType(t string) bool {
+	return t == "SS" || t == "NS" || t == "BS"
+}
+
+func parseNumber(s string) *big.Rat {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return nil
+	}
+	return r
+}
+
+// formatNumber formats r as DynamoDB does, without exponent
+// or trailing zeros.
+func formatNumber(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	for prec := 1; ; prec++ {
+		s := r.FloatString(prec)
+		if parseNumber(s).Cmp(r) == 0 {
+			return s
+		}
+	}
+}
+
+// setKey returns a string identifying the element s of a set of type
+// t, so that numbers equal in value are the same element.
+func setKey(t, s string) string {
+	if t == "NS" {
+		if n := parseNumber(s); n != nil {
+			return n.RatString()
+		}
+	}
+	return s
+}
+
+// compare compares two scalar values of the same type. ok is false
+// if the values are not both strings, numbers or binaries of the
+// same type.
+func compare(a, b value) (c int, ok bool) {
+	if a == nil || b == nil || a.typ() != b.typ() {
+		return 0, false
+	}
+	switch a.typ() {
+	case "S":
+		return strings.Compare(a.str(), b.str()), true
+	case "N":
+		x, y := parseNumber(a.str()), parseNumber(b.str())
+		if x == nil || y == nil {
+			return 0, false
+		}
+		return x.Cmp(y), true
+	case "B":
+		x, _ := base64.StdEncoding.DecodeString(a.str())
+		y, _ := base64.StdEncoding.DecodeString(b.str())
+		return bytes.Compare(x, y), true
+	}
+	return 0, false
+}
+
+// equal reports whether a and b are equal values. Sets are
+// equal if they have the same elements in any order.
+func equal(a, b value) bool {
+	if a == nil || b == nil || a.typ() != b.typ() {
+		return false
+	}
+	t := a.typ()
+	switch {
+	case t == "S" || t == "N" || t == "B":
+		c, ok := compare(a, b)
+		return ok && c == 0
+	case isSetType(t):
+		x, y := a.elems(), b.elems()
+		if len(x) != len(y) {
+			return false
+		}
+		for _, e := range x {
+			if !setContains(b, e.(string)) {
+				return false
+			}
+		}
+		return true
+	case t == "M":
+		x, y := a.members(), b.members()
+		if len(x) != len(y) {
+			return false
+		}
+		for k, e := range x {
+			f, ok := y[k]
+			if !ok || !equal(asValue(e), asValue(f)) {
+				return false
+			}
+		}
+		return true
+	case t == "L":
+		x, y := a.elems(), b.elems()
+		if len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(asValue(x[i]), asValue(y[i])) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// asValue returns the value held by an attribute of an item or an
+// element of an M or L value, or nil if there is none.
+func asValue(v interface{}) value {
+	switch v := v.(type) {
+	case value:
+		return v
+	case map[string]interface{}:
+		return value(v)
+	}
+	return nil
+}
+
+func setContains(set value, s string) bool {
+	t := set.typ()
+	k := setKey(t, s)
+	for _, e := range set.elems() {
+		if setKey(t, e.(string)) == k {
+			return true
+		}
+	}
+	return false
+}
+
+// contains implements the CONTAINS comparison and the contains
+// function: a is a string containing the string b, or a set or
+// list holding b.
+func contains(a, b value) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	switch t := a.typ(); {
+	case t == "S" && b.typ() == "S":
+		return strings.Contains(a.str(), b.str())
+	case t == "B" && b.typ() == "B":
+		x, _ := base64.StdEncoding.DecodeString(a.str())
+		y, _ := base64.StdEncoding.DecodeString(b.str())
+		return bytes.Contains(x, y)
+	case isSetType(t) && t == b.typ()+"S":
+		return setContains(a, b.str())
+	case t == "L":
+		for _, e := range a.elems() {
+			if equal(asValue(e), b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// beginsWith implements the BEGINS_WITH comparison and the
+// begins_with function, on strings and binaries.
+func beginsWith(a, b value) bool {
+	if a == nil || b == nil || a.typ() != b.typ() {
+		return false
+	}
+	switch a.typ() {
+	case "S":
+		return strings.HasPrefix(a.str(), b.str())
+	case "B":
+		x, _ := base64.StdEncoding.DecodeString(a.str())
+		y, _ := base64.StdEncoding.DecodeString(b.str())
+		return bytes.HasPrefix(x, y)
+	}
+	return false
+}
+
+// size implements the size function, returning nil for
+// values that have no size.
+func size(v value) value {
+	var n int
+	switch t := v.typ(); {
+	case t == "S":
+		n = len(v.str())
+	case t == "B":
+		b, _ := base64.StdEncoding.DecodeString(v.str())
+		n = len(b)
+	case isSetType(t) || t == "L":
+		n = len(v.elems())
+	case t == "M":
+		n = len(v.members())
+	default:
+		return nil
+	}
+	return value{"N": formatNumber(big.NewRat(int64(n), 1))}
+}
+
+// add adds b to a, which are either numbers or sets of the same type.
+func add(a, b value) value {
+	if a == nil {
+		return copyValue(b)
+	}
+	t := a.typ()
+	if t != b.typ() {
+		validationf("An operand in the update expression has an incorrect data type")
+	}
+	switch {
+	case t == "N":
+		return value{"N": formatNumber(new(big.Rat).Add(parseNumber(a.str()), parseNumber(b.str())))}
+	case isSetType(t):
+		elems := append([]interface{}{}, a.elems()...)
+		for _, e := range b.elems() {
+			if !setContains(a, e.(string)) {
+				elems = append(elems, e)
+			}
+		}
+		return value{t: elems}
+	}
+	validationf("An operand in the update expression has an incorrect data type")
+	panic("unreachable")
+}
+
+// subtract subtracts the number b from the number a.
+func subtract(a, b value) value {
+	if a == nil || b == nil || a.typ() != "N" || b.typ() != "N" {
+		validationf("An operand in the update expression has an incorrect data type")
+	}
+	return value{"N": formatNumber(new(big.Rat).Sub(parseNumber(a.str()), parseNumber(b.str())))}
+}
+
+// removeElements removes the elements of the set b from the set a.
+// It returns nil if no element is left.
+func removeElements(a, b value) value {
+	if a == nil {
+		return nil
+	}
+	t := a.typ()
+	if t != b.typ() || !isSetType(t) {
+		validationf("An operand in the update expression has an incorrect data type")
+	}
+	var elems []interface{}
+	for _, e := range a.elems() {
+		if !setContains(b, e.(string)) {
+			elems = append(elems, e)
+		}
+	}
+	if len(elems) == 0 {
+		return nil
+	}
+	return value{t: elems}
+}
+
+// get returns the value of the attribute name, or nil if there is none.
+func (it item) get(name string) value {
+	return asValue(it[name])
+}
+
+// set sets the value of the attribute name, removing it if v is nil.
+// Values are always stored as plain maps so that they can be
+// read back like the values decoded from requests.
+func (it item) set(name string, v value) {
+	if v == nil {
+		delete(it, name)
+		return
+	}
+	it[name] = map[string]interface{}(v)
+}
+
+func copyValue(v value) value {
+	if v == nil {
+		return nil
+	}
+	return value(copyData(map[string]interface{}(v)).(map[string]interface{}))
+}
+
+func copyItem(it item) item {
+	if it == nil {
+		return nil
+	}
+	return item(copyData(map[string]interface{}(it)).(map[string]interface{}))
+}
+
+func copyData(x interface{}) interface{} {
+	switch x := x.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(x))
+		for k, v := range x {
+			m[k] = copyData(v)
+		}
+		return m
+	case value:
+		return copyData(map[string]interface{}(x))
+	case []interface{}:
+		l := make([]interface{}, len(x))
+		for i, v := range x {
+			l[i] = copyData(v)
+		}
+		return l
+	}
+	return x
+}
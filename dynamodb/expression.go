@@ -0,0 +1,367 @@
+package dynamodb
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Expressions holds the expressions of a single request. All of them
+// share one set of ExpressionAttributeNames and ExpressionAttributeValues
+// placeholders, which are generated when the expressions are added to
+// a Query with AddExpressions. Nil fields are left out of the request.
+//
+// KeyCondition is used by Query, Filter by Query and Scan, Condition by
+// PutItem, UpdateItem and DeleteItem, and Update by UpdateItem only.
+type Expressions struct {
+	KeyCondition Condition
+	Filter       Condition
+	Condition    Condition
+	Projection   []string
+	Update       *Update
+}
+
+// Condition is a condition expression, built with functions such as
+// Equal, And and AttributeExists.
+type Condition interface {
+	buildCondition(b *exprBuilder) string
+}
+
+// Operand is an operand of a condition or of a SET action, built with
+// Path, Value, Size, IfNotExists, ListAppend, Plus or Minus.
+type Operand interface {
+	buildOperand(b *exprBuilder) string
+}
+
+type condition func(b *exprBuilder) string
+
+func (c condition) buildCondition(b *exprBuilder) string { return c(b) }
+
+type operand func(b *exprBuilder) string
+
+func (o operand) buildOperand(b *exprBuilder) string { return o(b) }
+
+// exprBuilder generates the placeholders of the expressions
+// of one request. The first error encountered is kept in err.
+type exprBuilder struct {
+	names  map[string]string // attribute name -> placeholder
+	values msi               // placeholder -> typed value
+	err    error
+}
+
+func newExprBuilder() *exprBuilder {
+	return &exprBuilder{
+		names:  map[string]string{},
+		values: msi{},
+	}
+}
+
+func (b *exprBuilder) name(name string) string {
+	if p, ok := b.names[name]; ok {
+		return p
+	}
+	p := "#n" + strconv.Itoa(len(b.names))
+	b.names[name] = p
+	return p
+}
+
+// path returns path with every attribute name replaced by a
+// placeholder. Paths are of the form "a.b[1].c"; list indexes
+// are kept as they are.
+func (b *exprBuilder) path(path string) string {
+	var out []string
+	for _, elem := range strings.Split(path, ".") {
+		name := elem
+		index := ""
+		if i := strings.Index(elem, "["); i >= 0 {
+			name, index = elem[:i], elem[i:]
+		}
+		if name == "" || !validIndexes(index) {
+			b.fail(fmt.Errorf("invalid document path %q", path))
+			return ""
+		}
+		out = append(out, b.name(name)+index)
+	}
+	return strings.Join(out, ".")
+}
+
+// validIndexes reports whether s is a sequence of list
+// indexes such as "[0][12]".
+func validIndexes(s string) bool {
+	for s != "" {
+		end := strings.Index(s, "]")
+		if s[0] != '[' || end < 2 {
+			return false
+		}
+		if _, err := strconv.ParseUint(s[1:end], 10, 32); err != nil {
+			return false
+		}
+		s = s[end+1:]
+	}
+	return true
+}
+
+func (b *exprBuilder) value(a *Attribute) string {
+	if a == nil {
+		b.fail(errors.New("nil expression value"))
+		return ""
+	}
+	p := ":v" + strconv.Itoa(len(b.values))
+	b.values[p] = a.typedValue()
+	return p
+}
+
+func (b *exprBuilder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+func (b *exprBuilder) operands(operands []Operand) []string {
+	out := make([]string, len(operands))
+	for i, o := range operands {
+		out[i] = o.buildOperand(b)
+	}
+	return out
+}
+
+// Path returns an operand referring to the attribute at the given
+// document path, such as "Name", "Address.City" or "Tags[0]". Attribute
+// names are always replaced with placeholders, so reserved words need
+// no escaping, but names containing "." or "[" cannot be expressed.
+func Path(path string) Operand {
+	return operand(func(b *exprBuilder) string { return b.path(path) })
+}
+
+// Value returns an operand with the value of a. The name of a is ignored.
+func Value(a *Attribute) Operand {
+	return operand(func(b *exprBuilder) string { return b.value(a) })
+}
+
+// Size returns an operand with the size of the attribute at path.
+func Size(path string) Operand {
+	return operand(func(b *exprBuilder) string { return "size(" + b.path(path) + ")" })
+}
+
+// IfNotExists returns an operand that evaluates to the attribute at
+// path if it exists, and to value otherwise. It is only valid in SET
+// actions.
+func IfNotExists(path string, value Operand) Operand {
+	return operand(func(b *exprBuilder) string {
+		return "if_not_exists(" + b.path(path) + ", " + value.buildOperand(b) + ")"
+	})
+}
+
+// ListAppend returns an operand with list b appended to list a.
+// It is only valid in SET actions.
+func ListAppend(a, b Operand) Operand {
+	return operand(func(eb *exprBuilder) string {
+		return "list_append(" + a.buildOperand(eb) + ", " + b.buildOperand(eb) + ")"
+	})
+}
+
+// Plus returns the operand a + b. It is only valid in SET actions.
+func Plus(a, b Operand) Operand {
+	return operand(func(eb *exprBuilder) string { return a.buildOperand(eb) + " + " + b.buildOperand(eb) })
+}
+
+// Minus returns the operand a - b. It is only valid in SET actions.
+func Minus(a, b Operand) Operand {
+	return operand(func(eb *exprBuilder) string { return a.buildOperand(eb) + " - " + b.buildOperand(eb) })
+}
+
+func comparison(op string, a, b Operand) Condition {
+	return condition(func(eb *exprBuilder) string { return a.buildOperand(eb) + " " + op + " " + b.buildOperand(eb) })
+}
+
+func Equal(a, b Operand) Condition              { return comparison("=", a, b) }
+func NotEqual(a, b Operand) Condition           { return comparison("<>", a, b) }
+func LessThan(a, b Operand) Condition           { return comparison("<", a, b) }
+func LessThanOrEqual(a, b Operand) Condition    { return comparison("<=", a, b) }
+func GreaterThan(a, b Operand) Condition        { return comparison(">", a, b) }
+func GreaterThanOrEqual(a, b Operand) Condition { return comparison(">=", a, b) }
+
+// Between is true when lower <= a <= upper.
+func Between(a, lower, upper Operand) Condition {
+	return condition(func(b *exprBuilder) string {
+		return a.buildOperand(b) + " BETWEEN " + lower.buildOperand(b) + " AND " + upper.buildOperand(b)
+	})
+}
+
+// In is true when a is equal to any of the values.
+func In(a Operand, values ...Operand) Condition {
+	return condition(func(b *exprBuilder) string {
+		if len(values) == 0 {
+			b.fail(errors.New("IN needs at least one value"))
+		}
+		return a.buildOperand(b) + " IN (" + strings.Join(b.operands(values), ", ") + ")"
+	})
+}
+
+func logical(op string, conditions []Condition) Condition {
+	return condition(func(b *exprBuilder) string {
+		if len(conditions) == 0 {
+			b.fail(fmt.Errorf("%s needs at least one condition", op))
+		}
+		parts := make([]string, len(conditions))
+		for i, c := range conditions {
+			parts[i] = "(" + c.buildCondition(b) + ")"
+		}
+		return strings.Join(parts, " "+op+" ")
+	})
+}
+
+// And is true when all the conditions are true.
+func And(conditions ...Condition) Condition { return logical("AND", conditions) }
+
+// Or is true when any of the conditions is true.
+func Or(conditions ...Condition) Condition { return logical("OR", conditions) }
+
+// Not is true when c is false.
+func Not(c Condition) Condition {
+	return condition(func(b *exprBuilder) string { return "NOT (" + c.buildCondition(b) + ")" })
+}
+
+// AttributeExists is true when the item has an attribute at path.
+func AttributeExists(path string) Condition {
+	return condition(func(b *exprBuilder) string { return "attribute_exists(" + b.path(path) + ")" })
+}
+
+// AttributeNotExists is true when the item has no attribute at path.
+func AttributeNotExists(path string) Condition {
+	return condition(func(b *exprBuilder) string { return "attribute_not_exists(" + b.path(path) + ")" })
+}
+
+// AttributeType is true when the attribute at path has the given
+// type, such as TYPE_STRING or TYPE_MAP.
+func AttributeType(path, attributeType string) Condition {
+	return condition(func(b *exprBuilder) string {
+		return "attribute_type(" + b.path(path) + ", " + b.value(NewStringAttribute("", attributeType)) + ")"
+	})
+}
+
+// BeginsWith is true when the attribute at path starts with prefix.
+func BeginsWith(path string, prefix Operand) Condition {
+	return condition(func(b *exprBuilder) string {
+		return "begins_with(" + b.path(path) + ", " + prefix.buildOperand(b) + ")"
+	})
+}
+
+// Contains is true when the string attribute at path contains the
+// substring a, or when the set or list at path contains the element a.
+func Contains(path string, a Operand) Condition {
+	return condition(func(b *exprBuilder) string {
+		return "contains(" + b.path(path) + ", " + a.buildOperand(b) + ")"
+	})
+}
+
+// Update is an update expression. Its methods add actions to the
+// expression and return it, so that calls can be chained:
+//
+//	u := new(dynamodb.Update).
+//		Set("Views", dynamodb.Plus(dynamodb.Path("Views"), dynamodb.Value(one))).
+//		Remove("Draft")
+type Update struct {
+	set, remove, add, del []Operand
+}
+
+// Set sets the attribute at path to value.
+func (u *Update) Set(path string, value Operand) *Update {
+	u.set = append(u.set, operand(func(b *exprBuilder) string {
+		return b.path(path) + " = " + value.buildOperand(b)
+	}))
+	return u
+}
+
+// SetIfNotExists sets the attribute at path to value,
+// unless the attribute already exists.
+func (u *Update) SetIfNotExists(path string, value Operand) *Update {
+	return u.Set(path, IfNotExists(path, value))
+}
+
+// Remove removes the attribute at path.
+func (u *Update) Remove(path string) *Update {
+	u.remove = append(u.remove, operand(func(b *exprBuilder) string { return b.path(path) }))
+	return u
+}
+
+// Add adds value to the number, or the elements of value to the set,
+// at path. The attribute is created if it does not exist.
+func (u *Update) Add(path string, value *Attribute) *Update {
+	u.add = append(u.add, operand(func(b *exprBuilder) string {
+		return b.path(path) + " " + b.value(value)
+	}))
+	return u
+}
+
+// Delete removes the elements of the set value from the set at path.
+func (u *Update) Delete(path string, value *Attribute) *Update {
+	u.del = append(u.del, operand(func(b *exprBuilder) string {
+		return b.path(path) + " " + b.value(value)
+	}))
+	return u
+}
+
+func (u *Update) build(b *exprBuilder) string {
+	var clauses []string
+	for _, c := range []struct {
+		keyword string
+		actions []Operand
+	}{
+		{"SET", u.set},
+		{"REMOVE", u.remove},
+		{"ADD", u.add},
+		{"DELETE", u.del},
+	} {
+		if len(c.actions) > 0 {
+			clauses = append(clauses, c.keyword+" "+strings.Join(b.operands(c.actions), ", "))
+		}
+	}
+	if len(clauses) == 0 {
+		b.fail(errors.New("empty update expression"))
+	}
+	return strings.Join(clauses, " ")
+}
+
+// AddExpressions adds the expressions in e to the query, along with the
+// ExpressionAttributeNames and ExpressionAttributeValues they refer to.
+// Expressions cannot be combined with the legacy parameters such as
+// KeyConditions, QueryFilter, Expected or AttributeUpdates.
+func (q *Query) AddExpressions(e *Expressions) error {
+	b := newExprBuilder()
+	if e.KeyCondition != nil {
+		q.buffer["KeyConditionExpression"] = e.KeyCondition.buildCondition(b)
+	}
+	if e.Filter != nil {
+		q.buffer["FilterExpression"] = e.Filter.buildCondition(b)
+	}
+	if e.Condition != nil {
+		q.buffer["ConditionExpression"] = e.Condition.buildCondition(b)
+	}
+	if len(e.Projection) > 0 {
+		paths := make([]string, len(e.Projection))
+		for i, p := range e.Projection {
+			paths[i] = b.path(p)
+		}
+		q.buffer["ProjectionExpression"] = strings.Join(paths, ", ")
+	}
+	if e.Update != nil {
+		q.buffer["UpdateExpression"] = e.Update.build(b)
+	}
+	if b.err != nil {
+		return b.err
+	}
+
+	if len(b.names) > 0 {
+		names := msi{}
+		for name, p := range b.names {
+			names[p] = name
+		}
+		q.buffer["ExpressionAttributeNames"] = names
+	}
+	if len(b.values) > 0 {
+		q.buffer["ExpressionAttributeValues"] = b.values
+	}
+	return nil
+}
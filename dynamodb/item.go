@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strconv"
 	"time"
 )
 
@@ -188,6 +189,29 @@ func (t *Table) putItem(hashKey, rangeKey string, attributes, expected []Attribu
 		q.AddExpected(expected)
 	}
 
+	return t.putItemQuery(q)
+}
+
+// PutItemExpression puts an item, if the Condition of e, if any, holds.
+func (t *Table) PutItemExpression(hashKey, rangeKey string, attributes []Attribute, e *Expressions) (bool, error) {
+	if len(attributes) == 0 {
+		return false, errors.New("At least one attribute is required.")
+	}
+
+	q := NewQuery(t)
+
+	keys := t.Key.Clone(hashKey, rangeKey)
+	attributes = append(attributes, keys...)
+
+	q.AddItem(attributes)
+	if err := q.AddExpressions(e); err != nil {
+		return false, err
+	}
+
+	return t.putItemQuery(q)
+}
+
+func (t *Table) putItemQuery(q *Query) (bool, error) {
 	var jsonResponse []byte
 	var err error
 	// based on:
@@ -202,11 +226,7 @@ func (t *Table) putItem(hashKey, rangeKey string, attributes, expected []Attribu
 		retry := false
 		if err != nil {
 			log.Printf("Error requesting from Amazon, request was: %#v\n response is:%#v\n and error is: %#v\n", q, string(jsonResponse), err)
-			if err, ok := err.(*Error); ok {
-				retry = (err.StatusCode == 500) ||
-					(err.Code == "ThrottlingException") ||
-					(err.Code == "ProvisionedThroughputExceededException")
-			}
+			retry = retryable(err)
 		}
 
 		if !retry {
@@ -230,6 +250,17 @@ func (t *Table) putItem(hashKey, rangeKey string, attributes, expected []Attribu
 	return true, nil
 }
 
+// retryable reports whether a request that failed with err
+// may succeed if it is sent again later.
+func retryable(err error) bool {
+	if err, ok := err.(*Error); ok {
+		return (err.StatusCode == 500) ||
+			(err.Code == "ThrottlingException") ||
+			(err.Code == "ProvisionedThroughputExceededException")
+	}
+	return false
+}
+
 func (t *Table) deleteItem(key *Key, expected []Attribute) (bool, error) {
 	q := NewQuery(t)
 	q.AddKey(t, key)
@@ -238,18 +269,18 @@ func (t *Table) deleteItem(key *Key, expected []Attribute) (bool, error) {
 		q.AddExpected(expected)
 	}
 
-	jsonResponse, err := t.Server.queryServer(target("DeleteItem"), q)
-
-	if err != nil {
-		return false, err
-	}
+	return t.itemQuery(target("DeleteItem"), q)
+}
 
-	_, err = simplejson.NewJson(jsonResponse)
-	if err != nil {
+// DeleteItemExpression deletes an item, if the Condition of e, if any, holds.
+func (t *Table) DeleteItemExpression(key *Key, e *Expressions) (bool, error) {
+	q := NewQuery(t)
+	q.AddKey(t, key)
+	if err := q.AddExpressions(e); err != nil {
 		return false, err
 	}
 
-	return true, nil
+	return t.itemQuery(target("DeleteItem"), q)
 }
 
 func (t *Table) DeleteItem(key *Key) (bool, error) {
@@ -284,6 +315,63 @@ func (t *Table) ConditionalDeleteAttributes(key *Key, attributes, expected []Att
 	return t.modifyAttributes(key, attributes, expected, "DELETE")
 }
 
+// versionCheck returns the Expected attribute checking that the numeric
+// attribute name of an item holds the version current, and the attribute
+// holding the next version. Version 0 is that of an item that does not
+// exist yet, so it checks that the hash key hashName does not exist.
+func versionCheck(hashName, name string, current int64) (expected, next Attribute) {
+	next = *NewNumericAttribute(name, strconv.FormatInt(current+1, 10))
+	if current == 0 {
+		return Attribute{Name: hashName, Exists: "false"}, next
+	}
+	return *NewNumericAttribute(name, strconv.FormatInt(current, 10)), next
+}
+
+// withAttribute returns a copy of attributes in which a replaces the
+// attribute with the same name, or to which it is appended.
+func withAttribute(attributes []Attribute, a Attribute) []Attribute {
+	out := make([]Attribute, 0, len(attributes)+1)
+	for _, b := range attributes {
+		if b.Name != a.Name {
+			out = append(out, b)
+		}
+	}
+	return append(out, a)
+}
+
+// VersionedPutItem puts an item using its numeric attribute versionName
+// for optimistic locking: the item is only put if the item in the table
+// has the version current, or if there is no such item and current is 0.
+// The item is put with the version current+1, which is returned. If the
+// item has another version, the error is a *ConditionalCheckFailedError.
+func (t *Table) VersionedPutItem(hashKey, rangeKey string, attributes []Attribute, versionName string, current int64) (int64, error) {
+	expected, next := versionCheck(t.Key.KeyAttribute.Name, versionName, current)
+	attributes = withAttribute(attributes, next)
+	if _, err := t.putItem(hashKey, rangeKey, attributes, []Attribute{expected}); err != nil {
+		return current, err
+	}
+	return current + 1, nil
+}
+
+// VersionedUpdateAttributes is like UpdateAttributes, with the same
+// optimistic locking as VersionedPutItem. The version is incremented
+// by the update itself.
+func (t *Table) VersionedUpdateAttributes(key *Key, attributes []Attribute, versionName string, current int64) (int64, error) {
+	expected, next := versionCheck(t.Key.KeyAttribute.Name, versionName, current)
+	attributes = withAttribute(attributes, next)
+	if _, err := t.modifyAttributes(key, attributes, []Attribute{expected}, "PUT"); err != nil {
+		return current, err
+	}
+	return current + 1, nil
+}
+
+// VersionedDeleteItem deletes an item, if it has the version current
+// in its numeric attribute versionName, as VersionedPutItem checks.
+func (t *Table) VersionedDeleteItem(key *Key, versionName string, current int64) (bool, error) {
+	expected, _ := versionCheck(t.Key.KeyAttribute.Name, versionName, current)
+	return t.deleteItem(key, []Attribute{expected})
+}
+
 func (t *Table) modifyAttributes(key *Key, attributes, expected []Attribute, action string) (bool, error) {
 
 	if len(attributes) == 0 {
@@ -298,7 +386,27 @@ func (t *Table) modifyAttributes(key *Key, attributes, expected []Attribute, act
 		q.AddExpected(expected)
 	}
 
-	jsonResponse, err := t.Server.queryServer(target("UpdateItem"), q)
+	return t.itemQuery(target("UpdateItem"), q)
+}
+
+// UpdateItemExpression applies the Update of e to an item, if the
+// Condition of e, if any, holds.
+func (t *Table) UpdateItemExpression(key *Key, e *Expressions) (bool, error) {
+	if e.Update == nil {
+		return false, errors.New("An update expression is required.")
+	}
+
+	q := NewQuery(t)
+	q.AddKey(t, key)
+	if err := q.AddExpressions(e); err != nil {
+		return false, err
+	}
+
+	return t.itemQuery(target("UpdateItem"), q)
+}
+
+func (t *Table) itemQuery(target string, q *Query) (bool, error) {
+	jsonResponse, err := t.Server.queryServer(target, q)
 
 	if err != nil {
 		return false, err
@@ -312,65 +420,21 @@ func (t *Table) modifyAttributes(key *Key, attributes, expected []Attribute, act
 	return true, nil
 }
 
+// ParseAttributes converts an item in the DynamoDB JSON format, such as
+// {"Name": {"S": "foo"}}, decoded with encoding/json, to attributes.
+// It is meant for packages that receive items outside of this one,
+// such as DynamoDB Streams records.
+func ParseAttributes(item map[string]interface{}) map[string]*Attribute {
+	return parseAttributes(item)
+}
+
 func parseAttributes(s map[string]interface{}) map[string]*Attribute {
 	results := map[string]*Attribute{}
 
 	for key, value := range s {
 		if v, ok := value.(map[string]interface{}); ok {
-			if val, ok := v[TYPE_STRING].(string); ok {
-				results[key] = &Attribute{
-					Type:  TYPE_STRING,
-					Name:  key,
-					Value: val,
-				}
-			} else if val, ok := v[TYPE_NUMBER].(string); ok {
-				results[key] = &Attribute{
-					Type:  TYPE_NUMBER,
-					Name:  key,
-					Value: val,
-				}
-			} else if val, ok := v[TYPE_BINARY].(string); ok {
-				results[key] = &Attribute{
-					Type:  TYPE_BINARY,
-					Name:  key,
-					Value: val,
-				}
-			} else if vals, ok := v[TYPE_STRING_SET].([]interface{}); ok {
-				arry := make([]string, len(vals))
-				for i, ivalue := range vals {
-					if val, ok := ivalue.(string); ok {
-						arry[i] = val
-					}
-				}
-				results[key] = &Attribute{
-					Type:      TYPE_STRING_SET,
-					Name:      key,
-					SetValues: arry,
-				}
-			} else if vals, ok := v[TYPE_NUMBER_SET].([]interface{}); ok {
-				arry := make([]string, len(vals))
-				for i, ivalue := range vals {
-					if val, ok := ivalue.(string); ok {
-						arry[i] = val
-					}
-				}
-				results[key] = &Attribute{
-					Type:      TYPE_NUMBER_SET,
-					Name:      key,
-					SetValues: arry,
-				}
-			} else if vals, ok := v[TYPE_BINARY_SET].([]interface{}); ok {
-				arry := make([]string, len(vals))
-				for i, ivalue := range vals {
-					if val, ok := ivalue.(string); ok {
-						arry[i] = val
-					}
-				}
-				results[key] = &Attribute{
-					Type:      TYPE_BINARY_SET,
-					Name:      key,
-					SetValues: arry,
-				}
+			if a := parseAttribute(key, v); a != nil {
+				results[key] = a
 			}
 		} else {
 			log.Printf("type assertion to map[string] interface{} failed for : %s\n ", value)
@@ -380,3 +444,82 @@ func parseAttributes(s map[string]interface{}) map[string]*Attribute {
 
 	return results
 }
+
+// parseAttribute converts a single DynamoDB AttributeValue, such as
+// {"S": "foo"}, to an Attribute with the given name. Map and list
+// values are converted recursively. It returns nil if the type of the
+// value is unknown.
+func parseAttribute(name string, v map[string]interface{}) *Attribute {
+	if val, ok := v[TYPE_STRING].(string); ok {
+		return &Attribute{
+			Type:  TYPE_STRING,
+			Name:  name,
+			Value: val,
+		}
+	} else if val, ok := v[TYPE_NUMBER].(string); ok {
+		return &Attribute{
+			Type:  TYPE_NUMBER,
+			Name:  name,
+			Value: val,
+		}
+	} else if val, ok := v[TYPE_BINARY].(string); ok {
+		return &Attribute{
+			Type:  TYPE_BINARY,
+			Name:  name,
+			Value: val,
+		}
+	} else if vals, ok := v[TYPE_STRING_SET].([]interface{}); ok {
+		return &Attribute{
+			Type:      TYPE_STRING_SET,
+			Name:      name,
+			SetValues: parseSetValues(vals),
+		}
+	} else if vals, ok := v[TYPE_NUMBER_SET].([]interface{}); ok {
+		return &Attribute{
+			Type:      TYPE_NUMBER_SET,
+			Name:      name,
+			SetValues: parseSetValues(vals),
+		}
+	} else if vals, ok := v[TYPE_BINARY_SET].([]interface{}); ok {
+		return &Attribute{
+			Type:      TYPE_BINARY_SET,
+			Name:      name,
+			SetValues: parseSetValues(vals),
+		}
+	} else if vals, ok := v[TYPE_MAP].(map[string]interface{}); ok {
+		return &Attribute{
+			Type:      TYPE_MAP,
+			Name:      name,
+			MapValues: parseAttributes(vals),
+		}
+	} else if vals, ok := v[TYPE_LIST].([]interface{}); ok {
+		list := make([]*Attribute, 0, len(vals))
+		for _, ivalue := range vals {
+			if val, ok := ivalue.(map[string]interface{}); ok {
+				if a := parseAttribute("", val); a != nil {
+					list = append(list, a)
+				}
+			}
+		}
+		return &Attribute{
+			Type:       TYPE_LIST,
+			Name:       name,
+			ListValues: list,
+		}
+	} else if val, ok := v[TYPE_BOOLEAN].(bool); ok {
+		return NewBoolAttribute(name, val)
+	} else if _, ok := v[TYPE_NULL]; ok {
+		return NewNullAttribute(name)
+	}
+	return nil
+}
+
+func parseSetValues(vals []interface{}) []string {
+	arry := make([]string, len(vals))
+	for i, ivalue := range vals {
+		if val, ok := ivalue.(string); ok {
+			arry[i] = val
+		}
+	}
+	return arry
+}
@@ -127,6 +127,13 @@ func (s *ItemSuite) TestConditionalPutUpdateDeleteItem(c *check.C) {
 			c.Errorf("Expect condition does not meet.")
 		} else {
 			c.Check(err.Error(), check.Matches, "ConditionalCheckFailedException.*")
+			// The error code and status are still there for the
+			// callers that checked them on a *dynamodb.Error.
+			ccErr, _ := err.(*dynamodb.ConditionalCheckFailedError)
+			c.Assert(ccErr, check.NotNil)
+			c.Check(ccErr.Code, check.Equals, "ConditionalCheckFailedException")
+			c.Check(ccErr.StatusCode, check.Equals, 400)
+			c.Check(ccErr.Status, check.Equals, "400 Bad Request")
 		}
 
 		// Update attributes with condition failed
@@ -413,3 +420,97 @@ func (s *ItemSuite) TestUpdateItemWithSet(c *check.C) {
 		}
 	}
 }
+
+func (s *ItemSuite) TestExpressionPutUpdateDeleteItem(c *check.C) {
+	if s.WithRange {
+		// No rangekey test required
+		return
+	}
+
+	attrs := []dynamodb.Attribute{
+		*dynamodb.NewNumericAttribute("Count", "1"),
+	}
+	pk := &dynamodb.Key{HashKey: "NewHashKeyVal"}
+	notExists := &dynamodb.Expressions{Condition: dynamodb.AttributeNotExists("TestHashKey")}
+
+	if ok, err := s.table.PutItemExpression("NewHashKeyVal", "", attrs, notExists); !ok {
+		c.Fatal(err)
+	}
+	if ok, err := s.table.PutItemExpression("NewHashKeyVal", "", attrs, notExists); ok {
+		c.Errorf("Expect condition does not meet.")
+	} else {
+		c.Check(err.Error(), check.Matches, "ConditionalCheckFailedException.*")
+	}
+
+	update := &dynamodb.Expressions{
+		Update:    new(dynamodb.Update).Set("Count", dynamodb.Plus(dynamodb.Path("Count"), dynamodb.Value(dynamodb.NewNumericAttribute("", "2")))),
+		Condition: dynamodb.LessThan(dynamodb.Path("Count"), dynamodb.Value(dynamodb.NewNumericAttribute("", "2"))),
+	}
+	if ok, err := s.table.UpdateItemExpression(pk, update); !ok {
+		c.Errorf("Expect condition met. %s", err)
+	}
+	if ok, err := s.table.UpdateItemExpression(pk, update); ok {
+		c.Errorf("Expect condition does not meet.")
+	} else {
+		c.Check(err.Error(), check.Matches, "ConditionalCheckFailedException.*")
+	}
+
+	item, err := s.table.GetItem(pk)
+	if err != nil {
+		c.Fatal(err)
+	}
+	c.Check(item["Count"], check.DeepEquals, dynamodb.NewNumericAttribute("Count", "3"))
+
+	del := &dynamodb.Expressions{
+		Condition: dynamodb.Equal(dynamodb.Path("Count"), dynamodb.Value(dynamodb.NewNumericAttribute("", "3"))),
+	}
+	if ok, err := s.table.DeleteItemExpression(pk, del); !ok {
+		c.Errorf("Expect condition met. %s", err)
+	}
+}
+
+func (s *ItemSuite) TestVersionedPutUpdateDeleteItem(c *check.C) {
+	attrs := []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("Attr1", "Attr1Val"),
+	}
+
+	var rk string
+	if s.WithRange {
+		rk = "1"
+	}
+	pk := &dynamodb.Key{HashKey: "NewHashKeyVal", RangeKey: rk}
+
+	// Put the first version
+	version, err := s.table.VersionedPutItem("NewHashKeyVal", rk, attrs, "Version", 0)
+	c.Assert(err, check.IsNil)
+	c.Check(version, check.Equals, int64(1))
+
+	// Putting it again from version 0 fails
+	_, err = s.table.VersionedPutItem("NewHashKeyVal", rk, attrs, "Version", 0)
+	c.Check(err, check.FitsTypeOf, &dynamodb.ConditionalCheckFailedError{})
+
+	// Update from the current version
+	update := []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("Attr1", "Attr1NewVal"),
+	}
+	version, err = s.table.VersionedUpdateAttributes(pk, update, "Version", version)
+	c.Assert(err, check.IsNil)
+	c.Check(version, check.Equals, int64(2))
+
+	item, err := s.table.GetItem(pk)
+	c.Assert(err, check.IsNil)
+	c.Check(item["Attr1"], check.DeepEquals, dynamodb.NewStringAttribute("Attr1", "Attr1NewVal"))
+	c.Check(item["Version"], check.DeepEquals, dynamodb.NewNumericAttribute("Version", "2"))
+
+	// Stale versions are rejected
+	_, err = s.table.VersionedUpdateAttributes(pk, update, "Version", 1)
+	c.Check(err, check.FitsTypeOf, &dynamodb.ConditionalCheckFailedError{})
+	_, err = s.table.VersionedDeleteItem(pk, "Version", 1)
+	c.Check(err, check.FitsTypeOf, &dynamodb.ConditionalCheckFailedError{})
+
+	ok, err := s.table.VersionedDeleteItem(pk, "Version", 2)
+	c.Assert(err, check.IsNil)
+	c.Check(ok, check.Equals, true)
+	_, err = s.table.GetItem(pk)
+	c.Check(err, check.Equals, dynamodb.ErrNotFound)
+}
@@ -0,0 +1,227 @@
+package dynamodb
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	simplejson "github.com/bitly/go-simplejson"
+)
+
+// Iterator reads the items of a query or a scan one page at a time,
+// following LastEvaluatedKey, so that only one page of items is held
+// in memory at once:
+//
+//	it := table.ScanIterator(q, 0)
+//	for it.Next() {
+//		for _, item := range it.Items() {
+//			...
+//		}
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator struct {
+	table     *Table
+	target    string
+	query     *Query
+	limit     int64 // total number of items to read, 0 for all
+	pageLimit int64 // Limit of the query, 0 if none
+
+	items            []map[string]*Attribute
+	count            int64
+	scannedCount     int64
+	consumedCapacity float64
+	done             bool
+	err              error
+}
+
+// QueryIterator returns an iterator over the items of the query q.
+// If limit is not 0, the iterator stops after reading limit items;
+// the Limit of q, if any, only bounds the size of each page.
+func (t *Table) QueryIterator(q *Query, limit int64) *Iterator {
+	return newIterator(t, target("Query"), q, limit)
+}
+
+// ScanIterator is like QueryIterator for the scan q, which may be
+// nil to scan the whole table.
+func (t *Table) ScanIterator(q *Query, limit int64) *Iterator {
+	if q == nil {
+		q = NewQuery(t)
+	}
+	return newIterator(t, target("Scan"), q, limit)
+}
+
+func newIterator(t *Table, target string, q *Query, limit int64) *Iterator {
+	// Work on a copy of q, so that it can be run again.
+	page := &Query{msi{}}
+	for k, v := range q.buffer {
+		page.buffer[k] = v
+	}
+	page.AddReturnConsumedCapacity("TOTAL")
+
+	it := &Iterator{table: t, target: target, query: page, limit: limit}
+	if l, ok := q.buffer["Limit"].(int64); ok {
+		it.pageLimit = l
+	}
+	return it
+}
+
+// Next reads the next page of items, skipping empty pages, and
+// reports whether there is one. It returns false at the end of the
+// items, or when the request for a page fails.
+func (it *Iterator) Next() bool {
+	it.items = nil
+	for !it.done && it.err == nil {
+		if it.limit > 0 {
+			remaining := it.limit - it.count
+			if remaining <= 0 {
+				it.done = true
+				break
+			}
+			if it.pageLimit == 0 || it.pageLimit > remaining {
+				it.query.AddLimit(remaining)
+			}
+		}
+		it.err = it.readPage()
+		if len(it.items) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (it *Iterator) readPage() error {
+	jsonResponse, err := it.table.Server.queryServer(it.target, it.query)
+	if err != nil {
+		return err
+	}
+	json, err := simplejson.NewJson(jsonResponse)
+	if err != nil {
+		return err
+	}
+
+	count, err := json.Get("Count").Int64()
+	if err != nil {
+		message := fmt.Sprintf("Unexpected response %s", jsonResponse)
+		return errors.New(message)
+	}
+	var items []map[string]*Attribute
+	for _, entry := range json.Get("Items").MustArray() {
+		item, ok := entry.(map[string]interface{})
+		if !ok {
+			message := fmt.Sprintf("Unexpected response %s", jsonResponse)
+			return errors.New(message)
+		}
+		items = append(items, parseAttributes(item))
+	}
+	it.items = items
+	it.count += count
+	it.scannedCount += json.Get("ScannedCount").MustInt64()
+	it.consumedCapacity += json.Get("ConsumedCapacity").Get("CapacityUnits").MustFloat64()
+
+	// The last evaluated key is sent back as is, as it holds
+	// the index key attributes when querying an index.
+	if lastKey, ok := json.CheckGet("LastEvaluatedKey"); ok {
+		it.query.buffer["ExclusiveStartKey"] = lastKey.Interface()
+	} else {
+		it.done = true
+	}
+	return nil
+}
+
+// Items returns the items of the page read by the last call to Next.
+func (it *Iterator) Items() []map[string]*Attribute {
+	return it.items
+}
+
+// Err returns the error that stopped the iterator, if any.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Count returns the number of items read so far. With a query
+// selecting COUNT, it is the number of matching items.
+func (it *Iterator) Count() int64 {
+	return it.count
+}
+
+// ScannedCount returns the number of items evaluated so far,
+// before the filter of the query or scan was applied.
+func (it *Iterator) ScannedCount() int64 {
+	return it.scannedCount
+}
+
+// ConsumedCapacity returns the number of capacity units
+// consumed so far by the requests for the pages.
+func (it *Iterator) ConsumedCapacity() float64 {
+	return it.consumedCapacity
+}
+
+// ScanPage is a page of items read by one segment of a parallel
+// scan, with the number of items it evaluated and the capacity it
+// consumed. If the segment failed, Err is set and Items is empty.
+type ScanPage struct {
+	Segment          int
+	Items            []map[string]*Attribute
+	ScannedCount     int64
+	ConsumedCapacity float64
+	Err              error
+}
+
+// ParallelScanPages scans the table with totalSegments segments, all
+// of them running concurrently, and sends their pages on the returned
+// channel, which is closed when every segment is done. The scan q,
+// which may be nil, gives the filter and the page size of every
+// segment. The scan stops early when stop is closed.
+func (t *Table) ParallelScanPages(q *Query, totalSegments int, stop <-chan struct{}) <-chan ScanPage {
+	if q == nil {
+		q = NewQuery(t)
+	}
+	pages := make(chan ScanPage)
+	var wg sync.WaitGroup
+	for segment := 0; segment < totalSegments; segment++ {
+		it := t.ScanIterator(q, 0)
+		it.query.AddParallelScanConfiguration(segment, totalSegments)
+		wg.Add(1)
+		go func(segment int) {
+			defer wg.Done()
+			var scanned int64
+			var capacity float64
+			send := func(page ScanPage) bool {
+				// Check stop first, as select picks any ready case.
+				select {
+				case <-stop:
+					return false
+				default:
+				}
+				select {
+				case pages <- page:
+					return true
+				case <-stop:
+					return false
+				}
+			}
+			for it.Next() {
+				page := ScanPage{
+					Segment:          segment,
+					Items:            it.Items(),
+					ScannedCount:     it.ScannedCount() - scanned,
+					ConsumedCapacity: it.ConsumedCapacity() - capacity,
+				}
+				scanned, capacity = it.ScannedCount(), it.ConsumedCapacity()
+				if !send(page) {
+					return
+				}
+			}
+			if err := it.Err(); err != nil {
+				send(ScanPage{Segment: segment, Err: err})
+			}
+		}(segment)
+	}
+	go func() {
+		wg.Wait()
+		close(pages)
+	}()
+	return pages
+}
@@ -0,0 +1,133 @@
+package dynamodb_test
+
+import (
+	"github.com/crowdmob/goamz/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+// The iterator tests run against the items of LocalServerSuite.
+
+func (s *LocalServerSuite) TestQueryIteratorPages(c *check.C) {
+	q := dynamodb.NewQuery(s.table)
+	q.AddKeyConditions([]dynamodb.AttributeComparison{
+		*dynamodb.NewEqualStringAttributeComparison("UserId", "u1"),
+	})
+	q.AddLimit(2)
+
+	it := s.table.QueryIterator(q, 0)
+	var sizes []int
+	var times []string
+	for it.Next() {
+		sizes = append(sizes, len(it.Items()))
+		times = append(times, attrValues(it.Items(), "Time")...)
+	}
+	c.Assert(it.Err(), check.IsNil)
+	c.Check(sizes, check.DeepEquals, []int{2, 2, 1})
+	c.Check(times, check.DeepEquals, []string{"1", "2", "3", "4", "5"})
+	c.Check(it.Count(), check.Equals, int64(5))
+	c.Check(it.ScannedCount(), check.Equals, int64(5))
+	c.Check(it.ConsumedCapacity() > 0, check.Equals, true)
+
+	// The query is left unchanged, and can be run again.
+	items, err := dynamodb.RunQuery(q, s.table)
+	c.Assert(err, check.IsNil)
+	c.Check(items, check.HasLen, 2)
+}
+
+func (s *LocalServerSuite) TestScanIteratorLimit(c *check.C) {
+	q := dynamodb.NewQuery(s.table)
+	q.AddLimit(3)
+	it := s.table.ScanIterator(q, 7)
+	var sizes []int
+	for it.Next() {
+		sizes = append(sizes, len(it.Items()))
+	}
+	c.Assert(it.Err(), check.IsNil)
+	c.Check(sizes, check.DeepEquals, []int{3, 3, 1})
+	c.Check(it.Count(), check.Equals, int64(7))
+
+	// With a filter, the limit applies to the items returned.
+	q = dynamodb.NewQuery(s.table)
+	q.AddScanFilter([]dynamodb.AttributeComparison{
+		*dynamodb.NewEqualStringAttributeComparison("Kind", "view"),
+	})
+	it = s.table.ScanIterator(q, 5)
+	var kinds []string
+	for it.Next() {
+		kinds = append(kinds, attrValues(it.Items(), "Kind")...)
+	}
+	c.Assert(it.Err(), check.IsNil)
+	c.Check(kinds, check.DeepEquals, []string{"view", "view", "view", "view", "view"})
+	c.Check(it.ScannedCount() > 5, check.Equals, true)
+}
+
+func (s *LocalServerSuite) TestQueryIteratorCount(c *check.C) {
+	q := dynamodb.NewQuery(s.table)
+	q.AddKeyConditions([]dynamodb.AttributeComparison{
+		*dynamodb.NewEqualStringAttributeComparison("UserId", "u2"),
+	})
+	q.AddSelect("COUNT")
+	it := s.table.QueryIterator(q, 0)
+	c.Check(it.Next(), check.Equals, false)
+	c.Assert(it.Err(), check.IsNil)
+	c.Check(it.Count(), check.Equals, int64(5))
+}
+
+func (s *LocalServerSuite) TestIteratorError(c *check.C) {
+	pk, err := local_table.BuildPrimaryKey()
+	c.Assert(err, check.IsNil)
+	t := s.table.Server.NewTable("NoSuchTable", pk)
+	it := t.ScanIterator(nil, 0)
+	c.Check(it.Next(), check.Equals, false)
+	c.Check(it.Err(), check.ErrorMatches, "ResourceNotFoundException: .*")
+	c.Check(it.Next(), check.Equals, false)
+
+	var errs int
+	for page := range t.ParallelScanPages(nil, 3, nil) {
+		c.Check(page.Err, check.ErrorMatches, "ResourceNotFoundException: .*")
+		errs++
+	}
+	c.Check(errs, check.Equals, 3)
+}
+
+func (s *LocalServerSuite) TestParallelScanPages(c *check.C) {
+	q := dynamodb.NewQuery(s.table)
+	q.AddLimit(2)
+	seen := make(map[string]bool)
+	segments := make(map[int]bool)
+	var scanned int64
+	for page := range s.table.ParallelScanPages(q, 4, nil) {
+		c.Assert(page.Err, check.IsNil)
+		c.Check(len(page.Items) <= 2, check.Equals, true)
+		segments[page.Segment] = true
+		scanned += page.ScannedCount
+		for _, item := range page.Items {
+			key := item["UserId"].Value + "/" + item["Time"].Value
+			c.Check(seen[key], check.Equals, false)
+			seen[key] = true
+		}
+	}
+	c.Check(seen, check.HasLen, 20)
+	c.Check(scanned, check.Equals, int64(20))
+	for segment := range segments {
+		c.Check(segment >= 0 && segment < 4, check.Equals, true)
+	}
+}
+
+func (s *LocalServerSuite) TestParallelScanPagesStop(c *check.C) {
+	q := dynamodb.NewQuery(s.table)
+	q.AddLimit(1)
+	stop := make(chan struct{})
+	pages := s.table.ParallelScanPages(q, 2, stop)
+	page := <-pages
+	c.Assert(page.Err, check.IsNil)
+	close(stop)
+
+	// The channel is closed once the segments see the stop, each
+	// of them sending at most the page it was sending then.
+	n := 1
+	for range pages {
+		n++
+	}
+	c.Check(n <= 3, check.Equals, true)
+}
@@ -0,0 +1,377 @@
+package dynamodb
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Mapper saves and loads the items of a table as values of a struct
+// type. The fields are converted as by MarshalAttributes and
+// UnmarshalAttributes, and the options of their dynamodb tags give
+// their roles in the table:
+//
+//	type Event struct {
+//		UserId  string `dynamodb:"user_id,hash"`
+//		Time    int64  `dynamodb:"time,range,range=ByKind"`
+//		Kind    string `dynamodb:"kind,hash=ByKind"`
+//		Score   int    `dynamodb:"score,omitempty"`
+//		Version int64  `dynamodb:"version,version"`
+//	}
+//
+// The "hash" and "range" options mark the primary key of the table,
+// and the "hash=Index" and "range=Index" options the key of a
+// secondary index. A field with the "version" option, which must be
+// an integer, is used for optimistic locking as by VersionedPutItem:
+// Save only writes an item whose version in the table is the one in
+// the struct, and increments it.
+type Mapper struct {
+	Table          *Table
+	ConsistentRead bool // whether Load uses consistent reads
+
+	typ     reflect.Type
+	hash    *field
+	rng     *field
+	version *field
+	indexes map[string]*mapperIndex
+}
+
+type mapperIndex struct {
+	hash *field
+	rng  *field
+}
+
+// NewMapper returns a mapper for the items of t, whose struct type
+// is the type of model, a struct or a pointer to a struct.
+func NewMapper(t *Table, model interface{}) (*Mapper, error) {
+	typ := reflect.TypeOf(model)
+	if typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("The model of a mapper must be a struct, not %v", typ)
+	}
+
+	m := &Mapper{Table: t, typ: typ, indexes: make(map[string]*mapperIndex)}
+	fields := cachedTypeFields(typ)
+	for i := range fields {
+		f := &fields[i]
+		if err := m.setRoles(f); err != nil {
+			return nil, err
+		}
+	}
+
+	if m.hash == nil {
+		return nil, fmt.Errorf("%v has no field with the hash option", typ)
+	}
+	for name, ix := range m.indexes {
+		if ix.hash == nil {
+			return nil, fmt.Errorf("%v has no hash key for the index %s", typ, name)
+		}
+	}
+	if k := t.Key; k.KeyAttribute != nil {
+		if err := m.checkKeyAttribute(m.hash, k.KeyAttribute, "hash"); err != nil {
+			return nil, err
+		}
+		if k.HasRange() != (m.rng != nil) {
+			return nil, fmt.Errorf("The range key of %v does not match the key of the table %s", typ, t.Name)
+		}
+		if k.HasRange() {
+			if err := m.checkKeyAttribute(m.rng, k.RangeAttribute, "range"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
+
+// setRoles records the roles of the field f.
+func (m *Mapper) setRoles(f *field) error {
+	set := func(role **field, what string) error {
+		if *role != nil {
+			return fmt.Errorf("%v has more than one %s", m.typ, what)
+		}
+		if what == "version" {
+			switch m.fieldType(f).Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			default:
+				return fmt.Errorf("The version field %s of %v must be an integer", f.name, m.typ)
+			}
+		} else if _, err := m.keyType(f); err != nil {
+			return err
+		}
+		*role = f
+		return nil
+	}
+	index := func(name string) *mapperIndex {
+		ix := m.indexes[name]
+		if ix == nil {
+			ix = &mapperIndex{}
+			m.indexes[name] = ix
+		}
+		return ix
+	}
+
+	if f.hashKey {
+		if err := set(&m.hash, "hash key"); err != nil {
+			return err
+		}
+	}
+	if f.rangeKey {
+		if err := set(&m.rng, "range key"); err != nil {
+			return err
+		}
+	}
+	if f.version {
+		if err := set(&m.version, "version"); err != nil {
+			return err
+		}
+	}
+	for _, name := range f.indexHashes {
+		if err := set(&index(name).hash, "hash key for the index "+name); err != nil {
+			return err
+		}
+	}
+	for _, name := range f.indexRanges {
+		if err := set(&index(name).rng, "range key for the index "+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldType returns the declared type of the field f.
+func (m *Mapper) fieldType(f *field) reflect.Type {
+	return m.typ.FieldByIndex(f.index).Type
+}
+
+// keyType returns the type of the key attribute stored from f.
+func (m *Mapper) keyType(f *field) (string, error) {
+	switch m.fieldType(f).Kind() {
+	case reflect.String:
+		return TYPE_STRING, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return TYPE_NUMBER, nil
+	}
+	return "", fmt.Errorf("The key field %s of %v must be a string or a number", f.name, m.typ)
+}
+
+func (m *Mapper) checkKeyAttribute(f *field, a *Attribute, role string) error {
+	typ, _ := m.keyType(f)
+	if f.name != a.Name || typ != a.Type {
+		return fmt.Errorf("The %s key %s (%s) of %v does not match the key %s (%s) of the table %s", role, f.name, typ, m.typ, a.Name, a.Type, m.Table.Name)
+	}
+	return nil
+}
+
+// value returns the struct pointed to by v.
+func (m *Mapper) value(v interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Type() != m.typ {
+		return reflect.Value{}, fmt.Errorf("Expected a non-nil *%v, got %T", m.typ, v)
+	}
+	return rv.Elem(), nil
+}
+
+// key returns the primary key of the item stored from attributes.
+func (m *Mapper) key(attributes []Attribute) (*Key, error) {
+	key := &Key{}
+	hash := findAttribute(attributes, m.hash.name)
+	if hash == nil {
+		return nil, fmt.Errorf("The hash key %s of the %v is empty", m.hash.name, m.typ)
+	}
+	key.HashKey = hash.Value
+	if m.rng != nil {
+		rng := findAttribute(attributes, m.rng.name)
+		if rng == nil {
+			return nil, fmt.Errorf("The range key %s of the %v is empty", m.rng.name, m.typ)
+		}
+		key.RangeKey = rng.Value
+	}
+	return key, nil
+}
+
+func findAttribute(attributes []Attribute, name string) *Attribute {
+	for i := range attributes {
+		if attributes[i].Name == name {
+			return &attributes[i]
+		}
+	}
+	return nil
+}
+
+// versionOf returns the version held by the struct rv.
+func (m *Mapper) versionOf(rv reflect.Value) int64 {
+	fv := fieldByIndex(rv, m.version.index)
+	switch {
+	case !fv.IsValid():
+		return 0
+	case fv.Kind() >= reflect.Uint && fv.Kind() <= reflect.Uint64:
+		return int64(fv.Uint())
+	}
+	return fv.Int()
+}
+
+// Save puts the struct pointed to by v in the table, replacing any
+// item with the same key. If the struct has a version field, the item
+// is only put if its version in the table is the one of the struct,
+// or if there is no such item and the version of the struct is 0; the
+// version is then incremented, in the table and in the struct. If the
+// versions differ, the error is a *ConditionalCheckFailedError.
+func (m *Mapper) Save(v interface{}) error {
+	rv, err := m.value(v)
+	if err != nil {
+		return err
+	}
+	attributes, err := MarshalAttributes(v)
+	if err != nil {
+		return err
+	}
+	if _, err := m.key(attributes); err != nil {
+		return err
+	}
+
+	q := NewQuery(m.Table)
+	var next int64
+	if m.version != nil {
+		current := m.versionOf(rv)
+		expected, a := versionCheck(m.hash.name, m.version.name, current)
+		attributes = withAttribute(attributes, a)
+		q.AddExpected([]Attribute{expected})
+		next = current + 1
+	}
+	q.AddItem(attributes)
+
+	if _, err := m.Table.putItemQuery(q); err != nil {
+		return err
+	}
+	if m.version != nil {
+		fv := fieldByIndex(rv, m.version.index)
+		if fv.Kind() >= reflect.Uint && fv.Kind() <= reflect.Uint64 {
+			fv.SetUint(uint64(next))
+		} else {
+			fv.SetInt(next)
+		}
+	}
+	return nil
+}
+
+// Load sets the struct pointed to by v, whose key fields must be set,
+// from the item with the same key in the table. It returns ErrNotFound
+// if there is no such item.
+func (m *Mapper) Load(v interface{}) error {
+	rv, err := m.value(v)
+	if err != nil {
+		return err
+	}
+	attributes, err := MarshalAttributes(v)
+	if err != nil {
+		return err
+	}
+	key, err := m.key(attributes)
+	if err != nil {
+		return err
+	}
+
+	item, err := m.Table.GetItemConsistent(key, m.ConsistentRead)
+	if err != nil {
+		return err
+	}
+	rv.Set(reflect.Zero(m.typ))
+	return UnmarshalAttributes(&item, v)
+}
+
+// Delete deletes the item with the key of the struct pointed to by v.
+// If the struct has a non-zero version, the item is only deleted if
+// its version in the table is the same.
+func (m *Mapper) Delete(v interface{}) error {
+	rv, err := m.value(v)
+	if err != nil {
+		return err
+	}
+	attributes, err := MarshalAttributes(v)
+	if err != nil {
+		return err
+	}
+	key, err := m.key(attributes)
+	if err != nil {
+		return err
+	}
+
+	if m.version != nil && m.versionOf(rv) != 0 {
+		_, err = m.Table.VersionedDeleteItem(key, m.version.name, m.versionOf(rv))
+		return err
+	}
+	_, err = m.Table.DeleteItem(key)
+	return err
+}
+
+// KeyQuery returns a query for the items whose hash key is hashKey,
+// in the table if index is "", or in the named secondary index.
+func (m *Mapper) KeyQuery(index string, hashKey string) (*Query, error) {
+	f := m.hash
+	if index != "" {
+		ix := m.indexes[index]
+		if ix == nil {
+			return nil, fmt.Errorf("%v has no key for the index %s", m.typ, index)
+		}
+		f = ix.hash
+	}
+	typ, _ := m.keyType(f)
+
+	q := NewQuery(m.Table)
+	q.AddKeyConditions([]AttributeComparison{{
+		f.name,
+		COMPARISON_EQUAL,
+		[]Attribute{{Type: typ, Name: f.name, Value: hashKey}},
+	}})
+	if index != "" {
+		q.AddIndex(index)
+	}
+	return q, nil
+}
+
+// QueryInto runs the query q, following LastEvaluatedKey until all
+// the items are read, and sets the slice pointed to by out, whose
+// elements are structs or pointers to structs, to the items. The
+// Limit of q, if any, only bounds the size of each page.
+func (m *Mapper) QueryInto(q *Query, out interface{}) error {
+	return m.readInto(m.Table.QueryIterator(q, 0), out)
+}
+
+// ScanInto is like QueryInto for the scan q, which may be nil
+// to scan the whole table.
+func (m *Mapper) ScanInto(q *Query, out interface{}) error {
+	return m.readInto(m.Table.ScanIterator(q, 0), out)
+}
+
+func (m *Mapper) readInto(it *Iterator, out interface{}) error {
+	sv := reflect.ValueOf(out)
+	if sv.Kind() != reflect.Ptr || sv.IsNil() || sv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("Expected a non-nil pointer to a slice, got %T", out)
+	}
+	sv = sv.Elem()
+	elemType := sv.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if (isPtr && elemType.Elem() != m.typ) || (!isPtr && elemType != m.typ) {
+		return fmt.Errorf("Expected a slice of %v or *%v, got %T", m.typ, m.typ, out)
+	}
+
+	results := reflect.MakeSlice(sv.Type(), 0, 0)
+	for it.Next() {
+		for _, item := range it.Items() {
+			ev := reflect.New(m.typ)
+			if err := UnmarshalAttributes(&item, ev.Interface()); err != nil {
+				return err
+			}
+			if !isPtr {
+				ev = ev.Elem()
+			}
+			results = reflect.Append(results, ev)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	sv.Set(results)
+	return nil
+}
@@ -0,0 +1,165 @@
+package dynamodb_test
+
+import (
+	"fmt"
+	"github.com/crowdmob/goamz/aws"
+	"github.com/crowdmob/goamz/dynamodb"
+	"github.com/crowdmob/goamz/dynamodb/dynamodbtest"
+	"gopkg.in/check.v1"
+)
+
+type MapperSuite struct {
+	srv    *dynamodbtest.Server
+	table  *dynamodb.Table
+	mapper *dynamodb.Mapper
+}
+
+var _ = check.Suite(&MapperSuite{})
+
+type mapperEvent struct {
+	UserId  string `dynamodb:"UserId,hash"`
+	Time    int64  `dynamodb:"Time,range,range=ByKind"`
+	Kind    string `dynamodb:"Kind,hash=ByKind"`
+	Score   int    `dynamodb:"Score,omitempty"`
+	Tags    []string
+	Version int64  `dynamodb:"Version,version"`
+	Ignored string `dynamodb:"-"`
+}
+
+func (s *MapperSuite) SetUpSuite(c *check.C) {
+	srv, err := dynamodbtest.NewServer()
+	c.Assert(err, check.IsNil)
+	s.srv = srv
+}
+
+func (s *MapperSuite) TearDownSuite(c *check.C) {
+	s.srv.Quit()
+}
+
+func (s *MapperSuite) SetUpTest(c *check.C) {
+	s.srv.Reset()
+	server := dynamodb.New(aws.Auth{AccessKey: "DUMMY_KEY", SecretKey: "DUMMY_SECRET"}, aws.Region{DynamoDBEndpoint: s.srv.URL()})
+	_, err := server.CreateTable(local_table)
+	c.Assert(err, check.IsNil)
+	pk, err := local_table.BuildPrimaryKey()
+	c.Assert(err, check.IsNil)
+	s.table = server.NewTable(local_table.TableName, pk)
+	s.mapper, err = dynamodb.NewMapper(s.table, mapperEvent{})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *MapperSuite) TestSaveLoadDelete(c *check.C) {
+	e := &mapperEvent{UserId: "u0", Time: 1, Kind: "click", Tags: []string{"a", "b"}, Ignored: "x"}
+	c.Assert(s.mapper.Save(e), check.IsNil)
+	c.Check(e.Version, check.Equals, int64(1))
+
+	item, err := s.table.GetItem(&dynamodb.Key{HashKey: "u0", RangeKey: "1"})
+	c.Assert(err, check.IsNil)
+	c.Check(item["Version"].Value, check.Equals, "1")
+	c.Check(item["Score"], check.IsNil)
+	c.Check(item["Ignored"], check.IsNil)
+
+	loaded := &mapperEvent{UserId: "u0", Time: 1, Score: 3}
+	c.Assert(s.mapper.Load(loaded), check.IsNil)
+	c.Check(loaded, check.DeepEquals, &mapperEvent{UserId: "u0", Time: 1, Kind: "click", Tags: []string{"a", "b"}, Version: 1})
+
+	c.Assert(s.mapper.Delete(loaded), check.IsNil)
+	c.Check(s.mapper.Load(loaded), check.Equals, dynamodb.ErrNotFound)
+}
+
+func (s *MapperSuite) TestSaveVersion(c *check.C) {
+	e := &mapperEvent{UserId: "u0", Time: 1, Kind: "click"}
+	c.Assert(s.mapper.Save(e), check.IsNil)
+
+	// A second writer starting from version 0 loses.
+	other := &mapperEvent{UserId: "u0", Time: 1, Kind: "view"}
+	err := s.mapper.Save(other)
+	c.Check(err, check.FitsTypeOf, &dynamodb.ConditionalCheckFailedError{})
+	c.Check(other.Version, check.Equals, int64(0))
+
+	e.Score = 5
+	c.Assert(s.mapper.Save(e), check.IsNil)
+	c.Check(e.Version, check.Equals, int64(2))
+
+	// Version 0 is that of a new item, which an item without a
+	// version is not.
+	attrs := []dynamodb.Attribute{*dynamodb.NewStringAttribute("Kind", "view")}
+	_, err = s.table.PutItem("u1", "1", attrs)
+	c.Assert(err, check.IsNil)
+	err = s.mapper.Save(&mapperEvent{UserId: "u1", Time: 1, Kind: "click"})
+	c.Check(err, check.FitsTypeOf, &dynamodb.ConditionalCheckFailedError{})
+
+	// Deleting with a stale version fails too.
+	stale := *e
+	stale.Version = 1
+	err = s.mapper.Delete(&stale)
+	c.Check(err, check.FitsTypeOf, &dynamodb.ConditionalCheckFailedError{})
+	c.Assert(s.mapper.Delete(e), check.IsNil)
+}
+
+func (s *MapperSuite) TestQueryAndScanInto(c *check.C) {
+	for u := 0; u < 3; u++ {
+		for t := 1; t <= 4; t++ {
+			e := &mapperEvent{UserId: fmt.Sprintf("u%d", u), Time: int64(t), Kind: []string{"click", "view"}[t%2]}
+			c.Assert(s.mapper.Save(e), check.IsNil)
+		}
+	}
+
+	q, err := s.mapper.KeyQuery("", "u1")
+	c.Assert(err, check.IsNil)
+	q.AddLimit(3)
+	var events []mapperEvent
+	c.Assert(s.mapper.QueryInto(q, &events), check.IsNil)
+	c.Assert(events, check.HasLen, 4)
+	for i, e := range events {
+		c.Check(e.UserId, check.Equals, "u1")
+		c.Check(e.Time, check.Equals, int64(i+1))
+		c.Check(e.Version, check.Equals, int64(1))
+	}
+
+	// The pages of an index query start from keys holding
+	// the index key attributes.
+	q, err = s.mapper.KeyQuery("ByKind", "view")
+	c.Assert(err, check.IsNil)
+	q.AddLimit(1)
+	var views []*mapperEvent
+	c.Assert(s.mapper.QueryInto(q, &views), check.IsNil)
+	c.Assert(views, check.HasLen, 6)
+	for _, e := range views {
+		c.Check(e.Kind, check.Equals, "view")
+	}
+
+	var all []mapperEvent
+	c.Assert(s.mapper.ScanInto(nil, &all), check.IsNil)
+	c.Check(all, check.HasLen, 12)
+
+	_, err = s.mapper.KeyQuery("NoSuchIndex", "view")
+	c.Check(err, check.ErrorMatches, ".* has no key for the index NoSuchIndex")
+	c.Check(s.mapper.ScanInto(nil, &[]string{}), check.ErrorMatches, "Expected a slice of .*")
+}
+
+func (s *MapperSuite) TestNewMapperErrors(c *check.C) {
+	type noHash struct {
+		Id string
+	}
+	_, err := dynamodb.NewMapper(s.table, noHash{})
+	c.Check(err, check.ErrorMatches, ".* has no field with the hash option")
+
+	type wrongKey struct {
+		Id   string `dynamodb:",hash"`
+		Time int64  `dynamodb:",range"`
+	}
+	_, err = dynamodb.NewMapper(s.table, &wrongKey{})
+	c.Check(err, check.ErrorMatches, "The hash key Id .* does not match the key UserId .*")
+
+	type badVersion struct {
+		UserId  string `dynamodb:",hash"`
+		Time    int64  `dynamodb:",range"`
+		Version string `dynamodb:",version"`
+	}
+	_, err = dynamodb.NewMapper(s.table, badVersion{})
+	c.Check(err, check.ErrorMatches, "The version field Version .* must be an integer")
+
+	_, err = dynamodb.NewMapper(s.table, "not a struct")
+	c.Check(err, check.ErrorMatches, "The model of a mapper must be a struct.*")
+}
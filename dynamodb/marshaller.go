@@ -1,6 +1,7 @@
 package dynamodb
 
 import (
+	"encoding"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -13,6 +14,20 @@ import (
 	"unicode"
 )
 
+// MarshalAttributes converts the fields of the struct pointed to by m
+// to attributes. Nested structs, maps and slices are stored as JSON
+// strings, unless the field's json tag has the "document" option, as in
+//
+//	Address Address `json:"address,document"`
+//
+// in which case they are stored as M and L attributes, recursively.
+// Inside documents, booleans are stored as BOOL attributes and nil
+// pointers as NULL attributes.
+//
+// A dynamodb tag takes precedence over the json tag of a field. Fields
+// whose dynamodb tag has the "omitempty" option are omitted when they
+// hold a zero value; the option is ignored in json tags. Empty strings,
+// slices and maps are always omitted, as DynamoDB does not accept them.
 func MarshalAttributes(m interface{}) ([]Attribute, error) {
 	v := reflect.ValueOf(m).Elem()
 
@@ -20,10 +35,18 @@ func MarshalAttributes(m interface{}) ([]Attribute, error) {
 	builder.buffer = []Attribute{}
 	for _, f := range cachedTypeFields(v.Type()) { // loop on each field
 		fv := fieldByIndex(v, f.index)
-		if !fv.IsValid() || isEmptyValueToOmit(fv) {
+		if !fv.IsValid() || isEmptyValueToOmit(fv) || (f.omitEmpty && isEmptyValue(fv)) {
 			continue
 		}
 
+		if f.document {
+			a, err := documentAttribute(f.name, fv)
+			if err != nil {
+				return builder.buffer, err
+			}
+			builder.Push(a)
+			continue
+		}
 		err := builder.reflectToDynamoDBAttribute(f.name, fv)
 		if err != nil {
 			return builder.buffer, err
@@ -33,6 +56,9 @@ func MarshalAttributes(m interface{}) ([]Attribute, error) {
 	return builder.buffer, nil
 }
 
+// UnmarshalAttributes sets the fields of the struct pointed to by m from
+// attributes. M, L, BOOL and NULL attributes are accepted for any field,
+// whether or not it has the "document" option.
 func UnmarshalAttributes(attributesRef *map[string]*Attribute, m interface{}) error {
 	rv := reflect.ValueOf(m)
 	if rv.Kind() != reflect.Ptr || rv.IsNil() {
@@ -48,7 +74,12 @@ func UnmarshalAttributes(attributesRef *map[string]*Attribute, m interface{}) er
 		if correlatedAttribute == nil {
 			continue
 		}
-		err := unmarshallAttribute(correlatedAttribute, fv)
+		var err error
+		if f.document {
+			err = unmarshalDocument(correlatedAttribute, fv)
+		} else {
+			err = unmarshallAttribute(correlatedAttribute, fv)
+		}
 		if err != nil {
 			return err
 		}
@@ -66,6 +97,10 @@ func (builder *attributeBuilder) Push(attribute *Attribute) {
 }
 
 func unmarshallAttribute(a *Attribute, v reflect.Value) error {
+	if a.DocumentType() {
+		return unmarshalDocument(a, v)
+	}
+
 	switch v.Kind() {
 	case reflect.Bool:
 		n, err := strconv.ParseInt(a.Value, 10, 64)
@@ -192,6 +227,259 @@ func unmarshallAttribute(a *Attribute, v reflect.Value) error {
 	return nil
 }
 
+var textUnmarshalerType = reflect.TypeOf(new(encoding.TextUnmarshaler)).Elem()
+
+// unmarshalDocument sets v from a, which may be part of a document.
+// Values that are not documents are handled by unmarshallAttribute.
+func unmarshalDocument(a *Attribute, v reflect.Value) error {
+	if a.Type == TYPE_NULL {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		x, err := a.genericValue()
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(x))
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return unmarshalDocument(a, v.Elem())
+	}
+	if a.Type == TYPE_STRING && reflect.PtrTo(v.Type()).Implements(textUnmarshalerType) && v.CanAddr() {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(a.Value))
+	}
+
+	switch a.Type {
+	case TYPE_BOOLEAN:
+		if v.Kind() != reflect.Bool {
+			return fmt.Errorf("UnmarshalTypeError (bool) cannot unmarshal into %#v", v.Type())
+		}
+		v.SetBool(a.Value == "true")
+
+	case TYPE_MAP:
+		switch v.Kind() {
+		case reflect.Struct:
+			for _, f := range cachedTypeFields(v.Type()) {
+				fa := a.MapValues[f.name]
+				if fa == nil {
+					continue
+				}
+				fv := fieldByIndex(v, f.index)
+				if !fv.IsValid() {
+					continue
+				}
+				if err := unmarshalDocument(fa, fv); err != nil {
+					return err
+				}
+			}
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return fmt.Errorf("UnmarshalTypeError (map) cannot unmarshal into %#v", v.Type())
+			}
+			m := reflect.MakeMap(v.Type())
+			for k, ea := range a.MapValues {
+				ev := reflect.New(v.Type().Elem()).Elem()
+				if err := unmarshalDocument(ea, ev); err != nil {
+					return err
+				}
+				m.SetMapIndex(reflect.ValueOf(k).Convert(v.Type().Key()), ev)
+			}
+			v.Set(m)
+		default:
+			return fmt.Errorf("UnmarshalTypeError (map) cannot unmarshal into %#v", v.Type())
+		}
+
+	case TYPE_LIST:
+		switch v.Kind() {
+		case reflect.Slice:
+			l := reflect.MakeSlice(v.Type(), len(a.ListValues), len(a.ListValues))
+			for i, ea := range a.ListValues {
+				if err := unmarshalDocument(ea, l.Index(i)); err != nil {
+					return err
+				}
+			}
+			v.Set(l)
+		case reflect.Array:
+			if len(a.ListValues) > v.Len() {
+				return fmt.Errorf("UnmarshalTypeError (list) %d elements do not fit in %#v", len(a.ListValues), v.Type())
+			}
+			for i, ea := range a.ListValues {
+				if err := unmarshalDocument(ea, v.Index(i)); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("UnmarshalTypeError (list) cannot unmarshal into %#v", v.Type())
+		}
+
+	case TYPE_BINARY:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := base64.StdEncoding.DecodeString(a.Value)
+			if err != nil {
+				return fmt.Errorf("UnmarshalTypeError (byte) %#v: %#v", a.Value, err)
+			}
+			v.SetBytes(b)
+			break
+		}
+		return unmarshallAttribute(a, v)
+
+	case TYPE_STRING:
+		if v.Kind() == reflect.String {
+			v.SetString(a.Value)
+			break
+		}
+		return unmarshallAttribute(a, v)
+
+	default:
+		return unmarshallAttribute(a, v)
+	}
+
+	return nil
+}
+
+// genericValue returns the value of a as the types that encoding/json
+// uses for interface{} values: map[string]interface{}, []interface{},
+// float64, string, bool and nil. Sets become []string, and binary
+// values []byte.
+func (a *Attribute) genericValue() (interface{}, error) {
+	switch a.Type {
+	case TYPE_MAP:
+		m := make(map[string]interface{}, len(a.MapValues))
+		for k, ea := range a.MapValues {
+			x, err := ea.genericValue()
+			if err != nil {
+				return nil, err
+			}
+			m[k] = x
+		}
+		return m, nil
+	case TYPE_LIST:
+		l := make([]interface{}, len(a.ListValues))
+		for i, ea := range a.ListValues {
+			x, err := ea.genericValue()
+			if err != nil {
+				return nil, err
+			}
+			l[i] = x
+		}
+		return l, nil
+	case TYPE_BOOLEAN:
+		return a.Value == "true", nil
+	case TYPE_NULL:
+		return nil, nil
+	case TYPE_NUMBER:
+		n, err := strconv.ParseFloat(a.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("UnmarshalTypeError (number) %#v: %#v", a.Value, err)
+		}
+		return n, nil
+	case TYPE_BINARY:
+		b, err := base64.StdEncoding.DecodeString(a.Value)
+		if err != nil {
+			return nil, fmt.Errorf("UnmarshalTypeError (byte) %#v: %#v", a.Value, err)
+		}
+		return b, nil
+	case TYPE_STRING_SET, TYPE_NUMBER_SET, TYPE_BINARY_SET:
+		return a.SetValues, nil
+	}
+	return a.Value, nil
+}
+
+var textMarshalerType = reflect.TypeOf(new(encoding.TextMarshaler)).Elem()
+
+// documentAttribute converts v to an attribute that may be part of a
+// document: structs and maps become M attributes, slices and arrays L
+// attributes, booleans BOOL attributes, and nil values NULL attributes.
+// Values implementing encoding.TextMarshaler, such as time.Time, are
+// stored as strings.
+func documentAttribute(name string, v reflect.Value) (*Attribute, error) {
+	if v.Type().Implements(textMarshalerType) && !((v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil()) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return NewStringAttribute(name, string(text)), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return NewBoolAttribute(name, v.Bool()), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32, reflect.Float64:
+		rv, err := numericReflectedValueString(v)
+		if err != nil {
+			return nil, err
+		}
+		return NewNumericAttribute(name, rv), nil
+
+	case reflect.String:
+		return NewStringAttribute(name, v.String()), nil
+
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return NewNullAttribute(name), nil
+		}
+		return documentAttribute(name, v.Elem())
+
+	case reflect.Struct:
+		values := map[string]*Attribute{}
+		for _, f := range cachedTypeFields(v.Type()) {
+			fv := fieldByIndex(v, f.index)
+			if !fv.IsValid() || isEmptyValueToOmit(fv) {
+				continue
+			}
+			a, err := documentAttribute(f.name, fv)
+			if err != nil {
+				return nil, err
+			}
+			values[f.name] = a
+		}
+		return NewMapAttribute(name, values), nil
+
+	case reflect.Map:
+		if v.IsNil() {
+			return NewNullAttribute(name), nil
+		}
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("UnsupportedTypeError %#v", v.Type())
+		}
+		values := map[string]*Attribute{}
+		for _, k := range v.MapKeys() {
+			a, err := documentAttribute(k.String(), v.MapIndex(k))
+			if err != nil {
+				return nil, err
+			}
+			values[k.String()] = a
+		}
+		return NewMapAttribute(name, values), nil
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return NewNullAttribute(name), nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return NewBinaryAttribute(name, base64.StdEncoding.EncodeToString(v.Bytes())), nil
+		}
+		fallthrough
+	case reflect.Array:
+		values := make([]*Attribute, v.Len())
+		for i := range values {
+			a, err := documentAttribute("", v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			values[i] = a
+		}
+		return NewListAttribute(name, values), nil
+	}
+	return nil, fmt.Errorf("UnsupportedTypeError %#v", v.Type())
+}
+
 // reflectValueQuoted writes the value in v to the output.
 // If quoted is true, the serialization is wrapped in a JSON string.
 func (e *attributeBuilder) reflectToDynamoDBAttribute(name string, v reflect.Value) error {
@@ -345,6 +633,14 @@ type field struct {
 	typ       reflect.Type
 	omitEmpty bool
 	quoted    bool
+	document  bool // stored as M and L attributes rather than as JSON
+
+	// Roles given by the dynamodb tag, used by Mapper.
+	hashKey     bool
+	rangeKey    bool
+	version     bool
+	indexHashes []string // names of the indexes it is the hash key of
+	indexRanges []string // names of the indexes it is the range key of
 }
 
 // byName sorts field by name, breaking ties with depth,
@@ -433,8 +729,20 @@ func (o tagOptions) Contains(optionName string) bool {
 	return false
 }
 
-// parseTag splits a struct field's json tag into its name and
-// comma-separated options.
+// Values returns the values of the options of the form name=value,
+// which may be repeated.
+func (o tagOptions) Values(name string) []string {
+	var values []string
+	for _, s := range strings.Split(string(o), ",") {
+		if strings.HasPrefix(s, name+"=") {
+			values = append(values, s[len(name)+1:])
+		}
+	}
+	return values
+}
+
+// parseTag splits a struct field's dynamodb or json tag into its
+// name and comma-separated options.
 func parseTag(tag string) (string, tagOptions) {
 	if idx := strings.Index(tag, ","); idx != -1 {
 		return tag[:idx], tagOptions(tag[idx+1:])
@@ -477,6 +785,10 @@ func typeFields(t reflect.Type) []field {
 					continue
 				}
 				tag := sf.Tag.Get("json")
+				dtag, dynamo := sf.Tag.Lookup("dynamodb")
+				if dynamo {
+					tag = dtag
+				}
 				if tag == "-" {
 					continue
 				}
@@ -500,8 +812,25 @@ func typeFields(t reflect.Type) []field {
 					if name == "" {
 						name = sf.Name
 					}
-					fields = append(fields, field{name, tagged, index, ft,
-						opts.Contains("omitempty"), opts.Contains("string")})
+					nf := field{
+						name:     name,
+						tag:      tagged,
+						index:    index,
+						typ:      ft,
+						quoted:   opts.Contains("string"),
+						document: opts.Contains("document"),
+					}
+					// The other options only apply in dynamodb tags:
+					// omitempty was never honoured in json tags.
+					if dynamo {
+						nf.omitEmpty = opts.Contains("omitempty")
+						nf.hashKey = opts.Contains("hash")
+						nf.rangeKey = opts.Contains("range")
+						nf.version = opts.Contains("version")
+						nf.indexHashes = opts.Values("hash")
+						nf.indexRanges = opts.Values("range")
+					}
+					fields = append(fields, nf)
 					if count[f.typ] > 1 {
 						// If there were multiple instances, add a second,
 						// so that the annihilation code will see a duplicate.
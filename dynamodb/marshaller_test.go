@@ -252,6 +252,65 @@ func (s *MarshallerSuite) TestMarshalZeroValues(c *check.C) {
 	c.Check(attrs, check.DeepEquals, expected)
 }
 
+func (s *MarshallerSuite) TestMarshalDynamoDBTags(c *check.C) {
+	type tagged struct {
+		Id      string `json:"json_id" dynamodb:"id,hash"`
+		Count   int    `dynamodb:"count,omitempty"`
+		Total   int    `json:"total"`
+		Skipped string `json:"skipped" dynamodb:"-"`
+	}
+	attrs, err := dynamodb.MarshalAttributes(&tagged{Id: "a", Skipped: "x"})
+	c.Assert(err, check.IsNil)
+	c.Check(attrs, check.DeepEquals, []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("id", "a"),
+		*dynamodb.NewNumericAttribute("total", "0"),
+	})
+}
+
+func (s *MarshallerSuite) TestMarshalJSONOmitEmpty(c *check.C) {
+	// The omitempty option of json tags is ignored, as it always was.
+	type legacy struct {
+		Id    string `json:"id"`
+		Count int    `json:"count,omitempty"`
+	}
+	attrs, err := dynamodb.MarshalAttributes(&legacy{Id: "a"})
+	c.Assert(err, check.IsNil)
+	c.Check(attrs, check.DeepEquals, []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("id", "a"),
+		*dynamodb.NewNumericAttribute("count", "0"),
+	})
+}
+
+type EmbeddedName struct {
+	Name string
+}
+
+type EmbeddedX struct {
+	EmbeddedName
+}
+
+type EmbeddedY struct {
+	EmbeddedName
+}
+
+func (s *MarshallerSuite) TestMarshalDuplicateEmbedded(c *check.C) {
+	// Name is ambiguous at the same depth, so it is dropped as it would
+	// be by encoding/json.
+	type outer struct {
+		EmbeddedX
+		EmbeddedY
+		Id string
+	}
+	obj := &outer{Id: "a"}
+	obj.EmbeddedX.Name = "x"
+	obj.EmbeddedY.Name = "y"
+	attrs, err := dynamodb.MarshalAttributes(obj)
+	c.Assert(err, check.IsNil)
+	c.Check(attrs, check.DeepEquals, []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("Id", "a"),
+	})
+}
+
 func (s *MarshallerSuite) TestMarshalEmptySets(c *check.C) {
 	testObj := testObjectWithEmptySets()
 	attrs, err := dynamodb.MarshalAttributes(testObj)
@@ -280,3 +339,98 @@ func (s *MarshallerSuite) TestUnmarshalEmptySets(c *check.C) {
 	expected := testObjectWithNilSets()
 	c.Check(testObj, check.DeepEquals, expected)
 }
+
+type TestDocumentStruct struct {
+	Name    string
+	Sub     TestSubStruct          `json:",document"`
+	Counts  map[string]int         `json:",document"`
+	Subs    []TestSubStruct        `json:",document"`
+	Enabled bool                   `json:",document"`
+	Missing *TestSubStruct         `json:",document"` // omitted when nil
+	Created time.Time              `json:",document"`
+	Extra   map[string]interface{} `json:",document"`
+}
+
+func testObjectDocument() *TestDocumentStruct {
+	return &TestDocumentStruct{
+		Name:    "doc",
+		Sub:     TestSubStruct{SubBool: true, SubInt: 2, SubString: "subtest", SubStringArray: []string{"sub1", "sub2"}},
+		Counts:  map[string]int{"a": 1},
+		Subs:    []TestSubStruct{{SubInt: 3}},
+		Enabled: true,
+		Created: time.Date(2003, 3, 3, 17, 3, 0, 0, time.UTC),
+		Extra:   map[string]interface{}{"flag": false, "none": nil, "list": []interface{}{"x", 1.5}},
+	}
+}
+
+func testAttrsDocument() []dynamodb.Attribute {
+	return []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("Name", "doc"),
+		*dynamodb.NewMapAttribute("Sub", map[string]*dynamodb.Attribute{
+			"SubBool":   dynamodb.NewBoolAttribute("SubBool", true),
+			"SubInt":    dynamodb.NewNumericAttribute("SubInt", "2"),
+			"SubString": dynamodb.NewStringAttribute("SubString", "subtest"),
+			"SubStringArray": dynamodb.NewListAttribute("SubStringArray", []*dynamodb.Attribute{
+				dynamodb.NewStringAttribute("", "sub1"),
+				dynamodb.NewStringAttribute("", "sub2"),
+			}),
+		}),
+		*dynamodb.NewMapAttribute("Counts", map[string]*dynamodb.Attribute{
+			"a": dynamodb.NewNumericAttribute("a", "1"),
+		}),
+		*dynamodb.NewListAttribute("Subs", []*dynamodb.Attribute{
+			dynamodb.NewMapAttribute("", map[string]*dynamodb.Attribute{
+				"SubBool": dynamodb.NewBoolAttribute("SubBool", false),
+				"SubInt":  dynamodb.NewNumericAttribute("SubInt", "3"),
+			}),
+		}),
+		*dynamodb.NewBoolAttribute("Enabled", true),
+		*dynamodb.NewStringAttribute("Created", "2003-03-03T17:03:00Z"),
+		*dynamodb.NewMapAttribute("Extra", map[string]*dynamodb.Attribute{
+			"flag": dynamodb.NewBoolAttribute("flag", false),
+			"none": dynamodb.NewNullAttribute("none"),
+			"list": dynamodb.NewListAttribute("list", []*dynamodb.Attribute{
+				dynamodb.NewStringAttribute("", "x"),
+				dynamodb.NewNumericAttribute("", "1.5"),
+			}),
+		}),
+	}
+}
+
+func (s *MarshallerSuite) TestMarshalDocument(c *check.C) {
+	testObj := testObjectDocument()
+	attrs, err := dynamodb.MarshalAttributes(testObj)
+	if err != nil {
+		c.Errorf("Error from dynamodb.MarshalAttributes: %#v", err)
+	}
+
+	expected := testAttrsDocument()
+	c.Check(attrs, check.DeepEquals, expected)
+}
+
+func (s *MarshallerSuite) TestUnmarshalDocument(c *check.C) {
+	testObj := &TestDocumentStruct{}
+
+	attrMap := map[string]*dynamodb.Attribute{}
+	attrs := testAttrsDocument()
+	for i, _ := range attrs {
+		attrMap[attrs[i].Name] = &attrs[i]
+	}
+
+	err := dynamodb.UnmarshalAttributes(&attrMap, testObj)
+	if err != nil {
+		c.Fatalf("Error from dynamodb.UnmarshalAttributes: %#v (Built: %#v)", err, testObj)
+	}
+
+	expected := testObjectDocument()
+	c.Check(testObj, check.DeepEquals, expected)
+
+	attrMap["Missing"] = dynamodb.NewNullAttribute("Missing")
+	attrMap["Subs"] = dynamodb.NewNullAttribute("Subs")
+	err = dynamodb.UnmarshalAttributes(&attrMap, testObj)
+	if err != nil {
+		c.Fatalf("Error from dynamodb.UnmarshalAttributes: %#v (Built: %#v)", err, testObj)
+	}
+	c.Check(testObj.Missing, check.IsNil)
+	c.Check(testObj.Subs, check.IsNil)
+}
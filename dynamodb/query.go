@@ -35,6 +35,16 @@ func (t *Table) LimitedQueryOnIndex(attributeComparisons []AttributeComparison,
 	return RunQuery(q, t)
 }
 
+// QueryExpression runs a query built from the KeyCondition, Filter
+// and Projection of e.
+func (t *Table) QueryExpression(e *Expressions) ([]map[string]*Attribute, error) {
+	q := NewQuery(t)
+	if err := q.AddExpressions(e); err != nil {
+		return nil, err
+	}
+	return RunQuery(q, t)
+}
+
 func (t *Table) CountQuery(attributeComparisons []AttributeComparison) (int64, error) {
 	q := NewQuery(t)
 	q.AddKeyConditions(attributeComparisons)
@@ -2,6 +2,7 @@ package dynamodb
 
 import (
 	"encoding/json"
+	"errors"
 	"sort"
 )
 
@@ -121,9 +122,13 @@ func (q *Query) AddCreateRequestTable(description TableDescriptionT) {
 	b["AttributeDefinitions"] = attDefs
 	b["KeySchema"] = description.KeySchema
 	b["TableName"] = description.TableName
-	b["ProvisionedThroughput"] = msi{
-		"ReadCapacityUnits":  int(description.ProvisionedThroughput.ReadCapacityUnits),
-		"WriteCapacityUnits": int(description.ProvisionedThroughput.WriteCapacityUnits),
+	payPerRequest := false
+	if description.BillingModeSummary != nil && description.BillingModeSummary.BillingMode != "" {
+		b["BillingMode"] = description.BillingModeSummary.BillingMode
+		payPerRequest = description.BillingModeSummary.BillingMode == BILLING_MODE_PAY_PER_REQUEST
+	}
+	if !payPerRequest {
+		b["ProvisionedThroughput"] = provisionedThroughput(description.ProvisionedThroughput)
 	}
 
 	localSecondaryIndexes := []interface{}{}
@@ -143,20 +148,101 @@ func (q *Query) AddCreateRequestTable(description TableDescriptionT) {
 	globalSecondaryIndexes := []interface{}{}
 
 	for _, ind := range description.GlobalSecondaryIndexes {
-		globalSecondaryIndexes = append(globalSecondaryIndexes, msi{
+		index := msi{
 			"IndexName":  ind.IndexName,
 			"KeySchema":  ind.KeySchema,
 			"Projection": ind.Projection,
-			"ProvisionedThroughput": msi{
-				"ReadCapacityUnits":  int(ind.ProvisionedThroughput.ReadCapacityUnits),
-				"WriteCapacityUnits": int(ind.ProvisionedThroughput.WriteCapacityUnits),
-			},
-		})
+		}
+		if !payPerRequest {
+			index["ProvisionedThroughput"] = provisionedThroughput(ind.ProvisionedThroughput)
+		}
+		globalSecondaryIndexes = append(globalSecondaryIndexes, index)
 	}
 
 	if len(globalSecondaryIndexes) > 0 {
 		b["GlobalSecondaryIndexes"] = globalSecondaryIndexes
 	}
+
+	if description.StreamSpecification != nil {
+		b["StreamSpecification"] = description.StreamSpecification
+	}
+}
+
+func (q *Query) AddUpdateRequestTable(update UpdateTableT) error {
+	b := q.buffer
+	b["TableName"] = update.TableName
+
+	if len(update.AttributeDefinitions) > 0 {
+		attDefs := []interface{}{}
+		for _, attr := range update.AttributeDefinitions {
+			attDefs = append(attDefs, msi{
+				"AttributeName": attr.Name,
+				"AttributeType": attr.Type,
+			})
+		}
+		b["AttributeDefinitions"] = attDefs
+	}
+
+	if update.BillingMode != "" {
+		b["BillingMode"] = update.BillingMode
+	}
+
+	if update.ProvisionedThroughput != nil {
+		b["ProvisionedThroughput"] = provisionedThroughput(*update.ProvisionedThroughput)
+	}
+
+	indexUpdates := []interface{}{}
+
+	for _, u := range update.GlobalSecondaryIndexUpdates {
+		switch {
+		case u.Create != nil && u.Update == nil && u.Delete == nil:
+			index := msi{
+				"IndexName":  u.Create.IndexName,
+				"KeySchema":  u.Create.KeySchema,
+				"Projection": u.Create.Projection,
+			}
+			// Indexes of PAY_PER_REQUEST tables have no throughput.
+			pt := u.Create.ProvisionedThroughput
+			if pt.ReadCapacityUnits != 0 || pt.WriteCapacityUnits != 0 {
+				index["ProvisionedThroughput"] = provisionedThroughput(pt)
+			}
+			indexUpdates = append(indexUpdates, msi{"Create": index})
+		case u.Update != nil && u.Create == nil && u.Delete == nil:
+			indexUpdates = append(indexUpdates, msi{"Update": msi{
+				"IndexName":             u.Update.IndexName,
+				"ProvisionedThroughput": provisionedThroughput(u.Update.ProvisionedThroughput),
+			}})
+		case u.Delete != nil && u.Create == nil && u.Update == nil:
+			indexUpdates = append(indexUpdates, msi{"Delete": msi{
+				"IndexName": u.Delete.IndexName,
+			}})
+		default:
+			return errors.New("A global secondary index update must set exactly one of Create, Update and Delete.")
+		}
+	}
+
+	if len(indexUpdates) > 0 {
+		b["GlobalSecondaryIndexUpdates"] = indexUpdates
+	}
+
+	if update.StreamSpecification != nil {
+		b["StreamSpecification"] = update.StreamSpecification
+	}
+	return nil
+}
+
+func (q *Query) AddTimeToLiveSpecification(attributeName string, enabled bool) {
+	q.buffer["TimeToLiveSpecification"] = msi{
+		"AttributeName": attributeName,
+		"Enabled":       enabled,
+	}
+}
+
+func provisionedThroughput(pt ProvisionedThroughputT) msi {
+	return msi{
+		"ReadCapacityUnits":  int(pt.ReadCapacityUnits),
+		"WriteCapacityUnits": int(pt.WriteCapacityUnits),
+	}
 }
 
 func (q *Query) AddDeleteRequestTable(description TableDescriptionT) {
@@ -179,6 +265,12 @@ func (q *Query) AddSelect(value string) {
 	q.buffer["Select"] = value
 }
 
+// AddReturnConsumedCapacity asks for the capacity consumed by the
+// request: value is "TOTAL", "INDEXES" or "NONE".
+func (q *Query) AddReturnConsumedCapacity(value string) {
+	q.buffer["ReturnConsumedCapacity"] = value
+}
+
 func (q *Query) AddIndex(value string) {
 	q.buffer["IndexName"] = value
 }
@@ -211,7 +303,7 @@ func buildComparisons(comparisons []AttributeComparison) msi {
 	for _, c := range comparisons {
 		avlist := []interface{}{}
 		for _, attributeValue := range c.AttributeValueList {
-			avlist = append(avlist, msi{attributeValue.Type: attributeValue.Value})
+			avlist = append(avlist, attributeValue.typedValue())
 		}
 		out[c.AttributeName] = msi{
 			"AttributeValueList": avlist,
@@ -231,9 +323,7 @@ func (q *Query) AddUpdates(attributes []Attribute, action string) {
 	updates := msi{}
 	for _, a := range attributes {
 		au := msi{
-			"Value": msi{
-				a.Type: map[bool]interface{}{true: a.SetValues, false: a.Value}[a.SetType()],
-			},
+			"Value":  a.typedValue(),
 			"Action": action,
 		}
 		// Delete 'Value' from AttributeUpdates if Type is not Set
@@ -255,7 +345,7 @@ func (q *Query) AddExpected(attributes []Attribute) {
 		}
 		// If set Exists to false, we must remove Value
 		if value["Exists"] != "false" {
-			value["Value"] = msi{a.Type: map[bool]interface{}{true: a.SetValues, false: a.Value}[a.SetType()]}
+			value["Value"] = a.typedValue()
 		}
 		expected[a.Name] = value
 	}
@@ -265,8 +355,7 @@ func (q *Query) AddExpected(attributes []Attribute) {
 func attributeList(attributes []Attribute) msi {
 	b := msi{}
 	for _, a := range attributes {
-		//UGH!!  (I miss the query operator)
-		b[a.Name] = msi{a.Type: map[bool]interface{}{true: a.SetValues, false: a.Value}[a.SetType()]}
+		b[a.Name] = a.typedValue()
 	}
 	return b
 }
@@ -427,3 +427,345 @@ func (s *QueryBuilderSuite) TestAddQueryFilterConditions(c *check.C) {
 	}
 	c.Check(queryJson, check.DeepEquals, expectedJson)
 }
+
+func (s *QueryBuilderSuite) TestAddItemDocumentQuery(c *check.C) {
+	primary := dynamodb.NewStringAttribute("domain", "")
+	key := dynamodb.PrimaryKey{primary, nil}
+	table := s.server.NewTable("sites", key)
+
+	q := dynamodb.NewQuery(table)
+	attrs := []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("domain", "test"),
+		*dynamodb.NewMapAttribute("owner", map[string]*dynamodb.Attribute{
+			"name":   dynamodb.NewStringAttribute("name", "bob"),
+			"admins": dynamodb.NewListAttribute("admins", []*dynamodb.Attribute{dynamodb.NewBoolAttribute("", true), dynamodb.NewNullAttribute("")}),
+		}),
+		*dynamodb.NewBoolAttribute("public", false),
+		*dynamodb.NewNullAttribute("note"),
+	}
+	q.AddItem(attrs)
+
+	queryJson, err := simplejson.NewJson([]byte(q.String()))
+	if err != nil {
+		c.Fatal(err)
+	}
+
+	expectedJson, err := simplejson.NewJson([]byte(`
+	{
+		"Item": {
+			"domain": {"S": "test"},
+			"owner": {
+				"M": {
+					"name": {"S": "bob"},
+					"admins": {"L": [{"BOOL": true}, {"NULL": true}]}
+				}
+			},
+			"public": {"BOOL": false},
+			"note": {"NULL": true}
+		},
+		"TableName": "sites"
+	}
+	`))
+	if err != nil {
+		c.Fatal(err)
+	}
+	c.Check(queryJson, check.DeepEquals, expectedJson)
+}
+
+func (s *QueryBuilderSuite) TestAddUpdatesDocumentQuery(c *check.C) {
+	primary := dynamodb.NewStringAttribute("domain", "")
+	key := dynamodb.PrimaryKey{primary, nil}
+	table := s.server.NewTable("sites", key)
+
+	q := dynamodb.NewQuery(table)
+	q.AddKey(table, &dynamodb.Key{HashKey: "test"})
+	q.AddUpdates([]dynamodb.Attribute{
+		*dynamodb.NewListAttribute("tags", []*dynamodb.Attribute{dynamodb.NewStringAttribute("", "a")}),
+	}, "PUT")
+	q.AddExpected([]dynamodb.Attribute{
+		*dynamodb.NewBoolAttribute("public", true).SetExists(true),
+	})
+
+	queryJson, err := simplejson.NewJson([]byte(q.String()))
+	if err != nil {
+		c.Fatal(err)
+	}
+
+	expectedJson, err := simplejson.NewJson([]byte(`
+	{
+		"AttributeUpdates": {
+			"tags": {
+				"Action": "PUT",
+				"Value": {"L": [{"S": "a"}]}
+			}
+		},
+		"Expected": {
+			"public": {
+				"Exists": "true",
+				"Value": {"BOOL": true}
+			}
+		},
+		"Key": {
+			"domain": {"S": "test"}
+		},
+		"TableName": "sites"
+	}
+	`))
+	if err != nil {
+		c.Fatal(err)
+	}
+	c.Check(queryJson, check.DeepEquals, expectedJson)
+}
+
+func (s *QueryBuilderSuite) TestAddExpressionsQuery(c *check.C) {
+	primary := dynamodb.NewStringAttribute("domain", "")
+	key := dynamodb.PrimaryKey{primary, nil}
+	table := s.server.NewTable("sites", key)
+
+	q := dynamodb.NewQuery(table)
+	err := q.AddExpressions(&dynamodb.Expressions{
+		KeyCondition: dynamodb.Equal(dynamodb.Path("domain"), dynamodb.Value(dynamodb.NewStringAttribute("", "example.com"))),
+		Filter: dynamodb.And(
+			dynamodb.Between(dynamodb.Path("stats.visits"), dynamodb.Value(dynamodb.NewNumericAttribute("", "1")), dynamodb.Value(dynamodb.NewNumericAttribute("", "10"))),
+			dynamodb.Not(dynamodb.BeginsWith("path[0]", dynamodb.Value(dynamodb.NewStringAttribute("", "/admin")))),
+			dynamodb.Or(dynamodb.AttributeNotExists("name"), dynamodb.In(dynamodb.Size("name"), dynamodb.Value(dynamodb.NewNumericAttribute("", "3")))),
+		),
+		Projection: []string{"domain", "stats.visits", "path[0]"},
+	})
+	c.Assert(err, check.IsNil)
+
+	queryJson, err := simplejson.NewJson([]byte(q.String()))
+	if err != nil {
+		c.Fatal(err)
+	}
+
+	expectedJson, err := simplejson.NewJson([]byte(`
+	{
+		"KeyConditionExpression": "#n0 = :v0",
+		"FilterExpression": "(#n1.#n2 BETWEEN :v1 AND :v2) AND (NOT (begins_with(#n3[0], :v3))) AND ((attribute_not_exists(#n4)) OR (size(#n4) IN (:v4)))",
+		"ProjectionExpression": "#n0, #n1.#n2, #n3[0]",
+		"ExpressionAttributeNames": {
+			"#n0": "domain",
+			"#n1": "stats",
+			"#n2": "visits",
+			"#n3": "path",
+			"#n4": "name"
+		},
+		"ExpressionAttributeValues": {
+			":v0": {"S": "example.com"},
+			":v1": {"N": "1"},
+			":v2": {"N": "10"},
+			":v3": {"S": "/admin"},
+			":v4": {"N": "3"}
+		},
+		"TableName": "sites"
+	}
+	`))
+	if err != nil {
+		c.Fatal(err)
+	}
+	c.Check(queryJson, check.DeepEquals, expectedJson)
+}
+
+func (s *QueryBuilderSuite) TestAddUpdateExpressionQuery(c *check.C) {
+	primary := dynamodb.NewStringAttribute("domain", "")
+	key := dynamodb.PrimaryKey{primary, nil}
+	table := s.server.NewTable("sites", key)
+
+	one := dynamodb.NewNumericAttribute("", "1")
+	update := new(dynamodb.Update).
+		Set("visits", dynamodb.Plus(dynamodb.Path("visits"), dynamodb.Value(one))).
+		SetIfNotExists("created", dynamodb.Value(dynamodb.NewStringAttribute("", "today"))).
+		Set("log", dynamodb.ListAppend(dynamodb.Path("log"), dynamodb.Value(dynamodb.NewListAttribute("", []*dynamodb.Attribute{dynamodb.NewStringAttribute("", "hit")})))).
+		Remove("draft").
+		Add("tags", dynamodb.NewStringSetAttribute("", []string{"new"})).
+		Delete("tags", dynamodb.NewStringSetAttribute("", []string{"old"}))
+
+	q := dynamodb.NewQuery(table)
+	q.AddKey(table, &dynamodb.Key{HashKey: "test"})
+	err := q.AddExpressions(&dynamodb.Expressions{
+		Update:    update,
+		Condition: dynamodb.AttributeExists("domain"),
+	})
+	c.Assert(err, check.IsNil)
+
+	queryJson, err := simplejson.NewJson([]byte(q.String()))
+	if err != nil {
+		c.Fatal(err)
+	}
+
+	expectedJson, err := simplejson.NewJson([]byte(`
+	{
+		"ConditionExpression": "attribute_exists(#n0)",
+		"UpdateExpression": "SET #n1 = #n1 + :v0, #n2 = if_not_exists(#n2, :v1), #n3 = list_append(#n3, :v2) REMOVE #n4 ADD #n5 :v3 DELETE #n5 :v4",
+		"ExpressionAttributeNames": {
+			"#n0": "domain",
+			"#n1": "visits",
+			"#n2": "created",
+			"#n3": "log",
+			"#n4": "draft",
+			"#n5": "tags"
+		},
+		"ExpressionAttributeValues": {
+			":v0": {"N": "1"},
+			":v1": {"S": "today"},
+			":v2": {"L": [{"S": "hit"}]},
+			":v3": {"SS": ["new"]},
+			":v4": {"SS": ["old"]}
+		},
+		"Key": {
+			"domain": {"S": "test"}
+		},
+		"TableName": "sites"
+	}
+	`))
+	if err != nil {
+		c.Fatal(err)
+	}
+	c.Check(queryJson, check.DeepEquals, expectedJson)
+}
+
+func (s *QueryBuilderSuite) TestAddExpressionsErrors(c *check.C) {
+	table := s.server.NewTable("sites", dynamodb.PrimaryKey{dynamodb.NewStringAttribute("domain", ""), nil})
+
+	for _, e := range []*dynamodb.Expressions{
+		{Projection: []string{"a..b"}},
+		{Projection: []string{"a[x]"}},
+		{Filter: dynamodb.AttributeExists("a[1")},
+		{Filter: dynamodb.And()},
+		{Filter: dynamodb.Equal(dynamodb.Path("a"), dynamodb.Value(nil))},
+		{Update: new(dynamodb.Update)},
+	} {
+		q := dynamodb.NewQuery(table)
+		c.Check(q.AddExpressions(e), check.NotNil)
+	}
+}
+
+func (s *QueryBuilderSuite) TestAddCreateRequestTablePayPerRequest(c *check.C) {
+	q := dynamodb.NewEmptyQuery()
+	q.AddCreateRequestTable(dynamodb.TableDescriptionT{
+		TableName:            "sites",
+		AttributeDefinitions: []dynamodb.AttributeDefinitionT{{"domain", "S"}, {"owner", "S"}},
+		KeySchema:            []dynamodb.KeySchemaT{{"domain", "HASH"}},
+		BillingModeSummary:   &dynamodb.BillingModeSummaryT{BillingMode: dynamodb.BILLING_MODE_PAY_PER_REQUEST},
+		GlobalSecondaryIndexes: []dynamodb.GlobalSecondaryIndexT{{
+			IndexName:  "OwnerIndex",
+			KeySchema:  []dynamodb.KeySchemaT{{"owner", "HASH"}},
+			Projection: dynamodb.ProjectionT{ProjectionType: "KEYS_ONLY"},
+		}},
+	})
+
+	queryJson, err := simplejson.NewJson([]byte(q.String()))
+	if err != nil {
+		c.Fatal(err)
+	}
+
+	expectedJson, err := simplejson.NewJson([]byte(`
+	{
+		"AttributeDefinitions": [
+			{"AttributeName": "domain", "AttributeType": "S"},
+			{"AttributeName": "owner", "AttributeType": "S"}
+		],
+		"BillingMode": "PAY_PER_REQUEST",
+		"GlobalSecondaryIndexes": [{
+			"IndexName": "OwnerIndex",
+			"KeySchema": [{"AttributeName": "owner", "KeyType": "HASH"}],
+			"Projection": {"ProjectionType": "KEYS_ONLY", "NonKeyAttributes": null}
+		}],
+		"KeySchema": [{"AttributeName": "domain", "KeyType": "HASH"}],
+		"TableName": "sites"
+	}
+	`))
+	if err != nil {
+		c.Fatal(err)
+	}
+	c.Check(queryJson, check.DeepEquals, expectedJson)
+}
+
+func (s *QueryBuilderSuite) TestAddUpdateRequestTable(c *check.C) {
+	q := dynamodb.NewEmptyQuery()
+	err := q.AddUpdateRequestTable(dynamodb.UpdateTableT{
+		TableName:             "sites",
+		AttributeDefinitions:  []dynamodb.AttributeDefinitionT{{"owner", "S"}},
+		BillingMode:           dynamodb.BILLING_MODE_PROVISIONED,
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughputT{ReadCapacityUnits: 5, WriteCapacityUnits: 2},
+		GlobalSecondaryIndexUpdates: []dynamodb.GlobalSecondaryIndexUpdateT{
+			{Create: &dynamodb.GlobalSecondaryIndexT{
+				IndexName:             "OwnerIndex",
+				KeySchema:             []dynamodb.KeySchemaT{{"owner", "HASH"}},
+				Projection:            dynamodb.ProjectionT{ProjectionType: "ALL"},
+				ProvisionedThroughput: dynamodb.ProvisionedThroughputT{ReadCapacityUnits: 1, WriteCapacityUnits: 1},
+			}},
+			{Update: &dynamodb.GlobalSecondaryIndexT{
+				IndexName:             "DateIndex",
+				ProvisionedThroughput: dynamodb.ProvisionedThroughputT{ReadCapacityUnits: 3, WriteCapacityUnits: 3},
+			}},
+			{Delete: &dynamodb.GlobalSecondaryIndexT{IndexName: "OldIndex"}},
+		},
+		StreamSpecification: &dynamodb.StreamSpecificationT{StreamEnabled: true, StreamViewType: "NEW_IMAGE"},
+	})
+	c.Assert(err, check.IsNil)
+
+	queryJson, err := simplejson.NewJson([]byte(q.String()))
+	if err != nil {
+		c.Fatal(err)
+	}
+
+	expectedJson, err := simplejson.NewJson([]byte(`
+	{
+		"AttributeDefinitions": [{"AttributeName": "owner", "AttributeType": "S"}],
+		"BillingMode": "PROVISIONED",
+		"GlobalSecondaryIndexUpdates": [
+			{"Create": {
+				"IndexName": "OwnerIndex",
+				"KeySchema": [{"AttributeName": "owner", "KeyType": "HASH"}],
+				"Projection": {"ProjectionType": "ALL", "NonKeyAttributes": null},
+				"ProvisionedThroughput": {"ReadCapacityUnits": 1, "WriteCapacityUnits": 1}
+			}},
+			{"Update": {
+				"IndexName": "DateIndex",
+				"ProvisionedThroughput": {"ReadCapacityUnits": 3, "WriteCapacityUnits": 3}
+			}},
+			{"Delete": {"IndexName": "OldIndex"}}
+		],
+		"ProvisionedThroughput": {"ReadCapacityUnits": 5, "WriteCapacityUnits": 2},
+		"StreamSpecification": {"StreamEnabled": true, "StreamViewType": "NEW_IMAGE"},
+		"TableName": "sites"
+	}
+	`))
+	if err != nil {
+		c.Fatal(err)
+	}
+	c.Check(queryJson, check.DeepEquals, expectedJson)
+
+	q = dynamodb.NewEmptyQuery()
+	err = q.AddUpdateRequestTable(dynamodb.UpdateTableT{
+		TableName: "sites",
+		GlobalSecondaryIndexUpdates: []dynamodb.GlobalSecondaryIndexUpdateT{
+			{Update: &dynamodb.GlobalSecondaryIndexT{}, Delete: &dynamodb.GlobalSecondaryIndexT{}},
+		},
+	})
+	c.Check(err, check.NotNil)
+}
+
+func (s *QueryBuilderSuite) TestAddTimeToLiveSpecification(c *check.C) {
+	table := s.server.NewTable("sessions", dynamodb.PrimaryKey{dynamodb.NewStringAttribute("id", ""), nil})
+	q := dynamodb.NewQuery(table)
+	q.AddTimeToLiveSpecification("expires", true)
+
+	queryJson, err := simplejson.NewJson([]byte(q.String()))
+	if err != nil {
+		c.Fatal(err)
+	}
+
+	expectedJson, err := simplejson.NewJson([]byte(`
+	{
+		"TableName": "sessions",
+		"TimeToLiveSpecification": {"AttributeName": "expires", "Enabled": true}
+	}
+	`))
+	if err != nil {
+		c.Fatal(err)
+	}
+	c.Check(queryJson, check.DeepEquals, expectedJson)
+}
@@ -81,6 +81,15 @@ func (t *Table) Scan(attributeComparisons []AttributeComparison) ([]map[string]*
 	return t.FetchResults(q)
 }
 
+// ScanExpression scans the table using the Filter and Projection of e.
+func (t *Table) ScanExpression(e *Expressions) ([]map[string]*Attribute, error) {
+	q := NewQuery(t)
+	if err := q.AddExpressions(e); err != nil {
+		return nil, err
+	}
+	return t.FetchResults(q)
+}
+
 func (t *Table) ParallelScan(attributeComparisons []AttributeComparison, segment int, totalSegments int) ([]map[string]*Attribute, error) {
 	q := NewQuery(t)
 	q.AddScanFilter(attributeComparisons)
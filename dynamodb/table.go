@@ -7,6 +7,16 @@ import (
 	simplejson "github.com/bitly/go-simplejson"
 )
 
+const (
+	BILLING_MODE_PROVISIONED     = "PROVISIONED"
+	BILLING_MODE_PAY_PER_REQUEST = "PAY_PER_REQUEST"
+
+	TTL_STATUS_ENABLING  = "ENABLING"
+	TTL_STATUS_DISABLING = "DISABLING"
+	TTL_STATUS_ENABLED   = "ENABLED"
+	TTL_STATUS_DISABLED  = "DISABLED"
+)
+
 type Table struct {
 	Server *Server
 	Name   string
@@ -31,12 +41,24 @@ type ProjectionT struct {
 type GlobalSecondaryIndexT struct {
 	IndexName             string
 	IndexSizeBytes        int64
+	IndexStatus           string
+	Backfilling           bool
 	ItemCount             int64
 	KeySchema             []KeySchemaT
 	Projection            ProjectionT
 	ProvisionedThroughput ProvisionedThroughputT
 }
 
+// GlobalSecondaryIndexUpdateT is one change to the global secondary
+// indexes of a table; exactly one of Create, Update and Delete must be
+// set. Update only uses the IndexName and ProvisionedThroughput of the
+// index, and Delete only its IndexName.
+type GlobalSecondaryIndexUpdateT struct {
+	Create *GlobalSecondaryIndexT
+	Update *GlobalSecondaryIndexT
+	Delete *GlobalSecondaryIndexT
+}
+
 type LocalSecondaryIndexT struct {
 	IndexName      string
 	IndexSizeBytes int64
@@ -46,28 +68,78 @@ type LocalSecondaryIndexT struct {
 }
 
 type ProvisionedThroughputT struct {
+	LastDecreaseDateTime   float64
+	LastIncreaseDateTime   float64
 	NumberOfDecreasesToday int64
 	ReadCapacityUnits      int64
 	WriteCapacityUnits     int64
 }
 
+// BillingModeSummaryT is the billing mode of a table, either
+// BILLING_MODE_PROVISIONED or BILLING_MODE_PAY_PER_REQUEST. A table
+// created without one uses provisioned throughput.
+type BillingModeSummaryT struct {
+	BillingMode                       string
+	LastUpdateToPayPerRequestDateTime float64
+}
+
+// StreamSpecificationT enables or disables DynamoDB Streams on a table.
+// StreamViewType is one of KEYS_ONLY, NEW_IMAGE, OLD_IMAGE or
+// NEW_AND_OLD_IMAGES.
+type StreamSpecificationT struct {
+	StreamEnabled  bool
+	StreamViewType string `json:",omitempty"`
+}
+
 type TableDescriptionT struct {
 	AttributeDefinitions   []AttributeDefinitionT
+	BillingModeSummary     *BillingModeSummaryT
 	CreationDateTime       float64
 	ItemCount              int64
 	KeySchema              []KeySchemaT
 	LocalSecondaryIndexes  []LocalSecondaryIndexT
 	GlobalSecondaryIndexes []GlobalSecondaryIndexT
 	ProvisionedThroughput  ProvisionedThroughputT
+	StreamSpecification    *StreamSpecificationT
+	LatestStreamArn        string
+	LatestStreamLabel      string
 	TableName              string
 	TableSizeBytes         int64
 	TableStatus            string
 }
 
+// UpdateTableT describes the changes made by UpdateTable. Fields left
+// at their zero value are not changed. AttributeDefinitions must define
+// the key attributes of the indexes being created.
+type UpdateTableT struct {
+	TableName                   string
+	AttributeDefinitions        []AttributeDefinitionT
+	BillingMode                 string
+	ProvisionedThroughput       *ProvisionedThroughputT
+	GlobalSecondaryIndexUpdates []GlobalSecondaryIndexUpdateT
+	StreamSpecification         *StreamSpecificationT
+}
+
+// TimeToLiveDescriptionT is the time to live setting of a table. Items
+// expire once the time, in seconds since the epoch, held by their
+// AttributeName number attribute has passed.
+type TimeToLiveDescriptionT struct {
+	AttributeName    string
+	TimeToLiveStatus string
+}
+
 type describeTableResponse struct {
 	Table TableDescriptionT
 }
 
+type updateTableResponse struct {
+	TableDescription TableDescriptionT
+}
+
+type describeTimeToLiveResponse struct {
+	TimeToLiveDescription TimeToLiveDescriptionT
+}
+
 func findAttributeDefinitionByName(ads []AttributeDefinitionT, name string) *AttributeDefinitionT {
 	for _, a := range ads {
 		if a.Name == name {
@@ -209,6 +281,71 @@ func (s *Server) DescribeTable(name string) (*TableDescriptionT, error) {
 	return &r.Table, nil
 }
 
+func (t *Table) UpdateTable(update UpdateTableT) (*TableDescriptionT, error) {
+	update.TableName = t.Name
+	return t.Server.UpdateTable(update)
+}
+
+// UpdateTable changes the throughput, billing mode, global secondary
+// indexes or stream of a table, and returns its new description. The
+// table is UPDATING until the changes are done.
+func (s *Server) UpdateTable(update UpdateTableT) (*TableDescriptionT, error) {
+	q := NewEmptyQuery()
+	if err := q.AddUpdateRequestTable(update); err != nil {
+		return nil, err
+	}
+
+	jsonResponse, err := s.queryServer(target("UpdateTable"), q)
+	if err != nil {
+		return nil, err
+	}
+
+	var r updateTableResponse
+	err = json.Unmarshal(jsonResponse, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &r.TableDescription, nil
+}
+
+func (t *Table) UpdateTimeToLive(attributeName string, enabled bool) error {
+	return t.Server.UpdateTimeToLive(t.Name, attributeName, enabled)
+}
+
+// UpdateTimeToLive enables or disables the expiry of the items of a
+// table by the time held in their attributeName attribute.
+func (s *Server) UpdateTimeToLive(tableName, attributeName string, enabled bool) error {
+	q := NewEmptyQuery()
+	q.addTableByName(tableName)
+	q.AddTimeToLiveSpecification(attributeName, enabled)
+
+	_, err := s.queryServer(target("UpdateTimeToLive"), q)
+	return err
+}
+
+func (t *Table) DescribeTimeToLive() (*TimeToLiveDescriptionT, error) {
+	return t.Server.DescribeTimeToLive(t.Name)
+}
+
+func (s *Server) DescribeTimeToLive(tableName string) (*TimeToLiveDescriptionT, error) {
+	q := NewEmptyQuery()
+	q.addTableByName(tableName)
+
+	jsonResponse, err := s.queryServer(target("DescribeTimeToLive"), q)
+	if err != nil {
+		return nil, err
+	}
+
+	var r describeTimeToLiveResponse
+	err = json.Unmarshal(jsonResponse, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &r.TimeToLiveDescription, nil
+}
+
 func keyParam(k *PrimaryKey, hashKey string, rangeKey string) string {
 	value := fmt.Sprintf("{\"HashKeyElement\":{%s}", keyValue(k.KeyAttribute.Type, hashKey))
 
@@ -0,0 +1,218 @@
+package dynamodb
+
+import (
+	"errors"
+	"fmt"
+
+	simplejson "github.com/bitly/go-simplejson"
+)
+
+// TransactWriteItem is one action of a TransactWriteItems request. It
+// is created with the TransactPut, TransactUpdate, TransactDelete and
+// TransactConditionCheck methods of Table.
+type TransactWriteItem struct {
+	action      string // Put, Update, Delete or ConditionCheck
+	table       *Table
+	key         *Key
+	item        []Attribute
+	expressions *Expressions
+
+	// ReturnValuesOnConditionCheckFailure may be set to "ALL_OLD" to
+	// get the item in the cancellation reason when the condition of
+	// this action fails.
+	ReturnValuesOnConditionCheckFailure string
+}
+
+// TransactPut returns an action that puts an item, if the Condition
+// of e holds. e may be nil.
+func (t *Table) TransactPut(hashKey, rangeKey string, attributes []Attribute, e *Expressions) *TransactWriteItem {
+	item := append(append([]Attribute(nil), attributes...), t.Key.Clone(hashKey, rangeKey)...)
+	return &TransactWriteItem{action: "Put", table: t, item: item, expressions: e}
+}
+
+// TransactUpdate returns an action that applies the Update of e to
+// an item, if the Condition of e holds.
+func (t *Table) TransactUpdate(key *Key, e *Expressions) *TransactWriteItem {
+	return &TransactWriteItem{action: "Update", table: t, key: key, expressions: e}
+}
+
+// TransactDelete returns an action that deletes an item, if the
+// Condition of e holds. e may be nil.
+func (t *Table) TransactDelete(key *Key, e *Expressions) *TransactWriteItem {
+	return &TransactWriteItem{action: "Delete", table: t, key: key, expressions: e}
+}
+
+// TransactConditionCheck returns an action that only checks that the
+// Condition of e holds for an item, without changing it.
+func (t *Table) TransactConditionCheck(key *Key, e *Expressions) *TransactWriteItem {
+	return &TransactWriteItem{action: "ConditionCheck", table: t, key: key, expressions: e}
+}
+
+// TransactGetItem is one item of a TransactGetItems request,
+// created with Table.TransactGet.
+type TransactGetItem struct {
+	table      *Table
+	key        *Key
+	projection []string
+}
+
+// TransactGet returns a request for the item with the given key.
+// If projection is not empty, only those attributes are returned.
+func (t *Table) TransactGet(key *Key, projection []string) *TransactGetItem {
+	return &TransactGetItem{table: t, key: key, projection: projection}
+}
+
+// CancellationReason tells why one action of a canceled
+// transaction failed.
+type CancellationReason struct {
+	Code    string // "None" when the action was not at fault
+	Message string
+	Item    map[string]*Attribute // with ReturnValuesOnConditionCheckFailure only
+}
+
+// TransactionCanceledError is returned when DynamoDB cancels a
+// transaction with a TransactionCanceledException. It holds the fields
+// of Error, and Reasons, with one reason per action in the order of the
+// actions of the request.
+type TransactionCanceledError struct {
+	StatusCode int
+	Status     string
+	Code       string // "TransactionCanceledException"
+	Message    string
+	Reasons    []CancellationReason
+}
+
+func (e *TransactionCanceledError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// AddTransactWriteItems adds the actions of a TransactWriteItems
+// request to the query. Each action has its own expression placeholders.
+func (q *Query) AddTransactWriteItems(items []*TransactWriteItem) error {
+	out := []interface{}{}
+	for _, item := range items {
+		action := NewEmptyQuery()
+		action.addTable(item.table)
+		if item.action == "Put" {
+			action.AddItem(item.item)
+		} else {
+			action.AddKey(item.table, item.key)
+		}
+		if item.action == "Update" && (item.expressions == nil || item.expressions.Update == nil) {
+			return errors.New("An update expression is required.")
+		}
+		if item.action == "ConditionCheck" && (item.expressions == nil || item.expressions.Condition == nil) {
+			return errors.New("A condition expression is required.")
+		}
+		if item.expressions != nil {
+			if err := action.AddExpressions(item.expressions); err != nil {
+				return err
+			}
+		}
+		if item.ReturnValuesOnConditionCheckFailure != "" {
+			action.buffer["ReturnValuesOnConditionCheckFailure"] = item.ReturnValuesOnConditionCheckFailure
+		}
+		out = append(out, msi{item.action: action.buffer})
+	}
+	q.buffer["TransactItems"] = out
+	return nil
+}
+
+// AddTransactGetItems adds the items of a TransactGetItems
+// request to the query.
+func (q *Query) AddTransactGetItems(items []*TransactGetItem) error {
+	out := []interface{}{}
+	for _, item := range items {
+		get := NewEmptyQuery()
+		get.addTable(item.table)
+		get.AddKey(item.table, item.key)
+		if err := get.AddExpressions(&Expressions{Projection: item.projection}); err != nil {
+			return err
+		}
+		out = append(out, msi{"Get": get.buffer})
+	}
+	q.buffer["TransactItems"] = out
+	return nil
+}
+
+// TransactWriteItems runs the actions atomically: either all of them
+// succeed or none does. If a condition fails, the error is a
+// *TransactionCanceledError. A non-empty clientRequestToken makes the
+// request idempotent: retrying it with the same token within ten
+// minutes has no further effect.
+func (s *Server) TransactWriteItems(items []*TransactWriteItem, clientRequestToken string) error {
+	if len(items) == 0 {
+		return errors.New("At least one item is required.")
+	}
+
+	q := NewEmptyQuery()
+	if err := q.AddTransactWriteItems(items); err != nil {
+		return err
+	}
+	if clientRequestToken != "" {
+		q.buffer["ClientRequestToken"] = clientRequestToken
+	}
+
+	jsonResponse, err := s.queryServer(target("TransactWriteItems"), q)
+	if err != nil {
+		return err
+	}
+
+	_, err = simplejson.NewJson(jsonResponse)
+	return err
+}
+
+// TransactGetItems reads the items atomically. The results are in the
+// order of the requests, with nil for items that do not exist.
+func (s *Server) TransactGetItems(items []*TransactGetItem) ([]map[string]*Attribute, error) {
+	if len(items) == 0 {
+		return nil, errors.New("At least one item is required.")
+	}
+
+	q := NewEmptyQuery()
+	if err := q.AddTransactGetItems(items); err != nil {
+		return nil, err
+	}
+
+	jsonResponse, err := s.queryServer(target("TransactGetItems"), q)
+	if err != nil {
+		return nil, err
+	}
+
+	json, err := simplejson.NewJson(jsonResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	responses, err := json.Get("Responses").Array()
+	if err != nil || len(responses) != len(items) {
+		message := fmt.Sprintf("Unexpected response %s", jsonResponse)
+		return nil, errors.New(message)
+	}
+
+	results := make([]map[string]*Attribute, len(items))
+	for i := range results {
+		if item, err := json.Get("Responses").GetIndex(i).Get("Item").Map(); err == nil {
+			results[i] = parseAttributes(item)
+		}
+	}
+	return results, nil
+}
+
+// cancellationReasons parses the CancellationReasons of a
+// TransactionCanceledException.
+func cancellationReasons(json *simplejson.Json) []CancellationReason {
+	var reasons []CancellationReason
+	for i := range json.Get("CancellationReasons").MustArray() {
+		r := json.Get("CancellationReasons").GetIndex(i)
+		reason := CancellationReason{
+			Code:    r.Get("Code").MustString(),
+			Message: r.Get("Message").MustString(),
+		}
+		if item, err := r.Get("Item").Map(); err == nil {
+			reason.Item = parseAttributes(item)
+		}
+		reasons = append(reasons, reason)
+	}
+	return reasons
+}
@@ -0,0 +1,187 @@
+package dynamodb_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	simplejson "github.com/bitly/go-simplejson"
+	"github.com/crowdmob/goamz/aws"
+	"github.com/crowdmob/goamz/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+// TransactionSuite checks the requests sent by the transaction calls
+// and the parsing of their responses, using a canned HTTP server.
+type TransactionSuite struct {
+	server   *dynamodb.Server
+	http     *httptest.Server
+	table    *dynamodb.Table
+	target   string
+	body     []byte
+	status   int
+	response string
+}
+
+var _ = check.Suite(&TransactionSuite{})
+
+func (s *TransactionSuite) SetUpSuite(c *check.C) {
+	s.http = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		s.target = req.Header.Get("X-Amz-Target")
+		s.body, _ = ioutil.ReadAll(req.Body)
+		w.WriteHeader(s.status)
+		w.Write([]byte(s.response))
+	}))
+	auth := aws.Auth{AccessKey: "DUMMY_KEY", SecretKey: "DUMMY_SECRET"}
+	s.server = dynamodb.New(auth, aws.Region{DynamoDBEndpoint: s.http.URL})
+	s.table = s.server.NewTable("accounts", dynamodb.PrimaryKey{dynamodb.NewStringAttribute("id", ""), nil})
+}
+
+func (s *TransactionSuite) TearDownSuite(c *check.C) {
+	s.http.Close()
+}
+
+func (s *TransactionSuite) respond(status int, response string) {
+	s.status = status
+	s.response = response
+}
+
+func (s *TransactionSuite) checkBody(c *check.C, expected string) {
+	bodyJson, err := simplejson.NewJson(s.body)
+	c.Assert(err, check.IsNil)
+	expectedJson, err := simplejson.NewJson([]byte(expected))
+	c.Assert(err, check.IsNil)
+	c.Check(bodyJson, check.DeepEquals, expectedJson)
+}
+
+func (s *TransactionSuite) TestTransactWriteItems(c *check.C) {
+	s.respond(200, `{}`)
+
+	ten := dynamodb.Value(dynamodb.NewNumericAttribute("", "10"))
+	debit := s.table.TransactUpdate(&dynamodb.Key{HashKey: "alice"}, &dynamodb.Expressions{
+		Update:    new(dynamodb.Update).Set("balance", dynamodb.Minus(dynamodb.Path("balance"), ten)),
+		Condition: dynamodb.GreaterThanOrEqual(dynamodb.Path("balance"), ten),
+	})
+	debit.ReturnValuesOnConditionCheckFailure = "ALL_OLD"
+	err := s.server.TransactWriteItems([]*dynamodb.TransactWriteItem{
+		debit,
+		s.table.TransactPut("log-1", "", []dynamodb.Attribute{*dynamodb.NewNumericAttribute("amount", "10")}, &dynamodb.Expressions{
+			Condition: dynamodb.AttributeNotExists("id"),
+		}),
+		s.table.TransactDelete(&dynamodb.Key{HashKey: "pending-1"}, nil),
+		s.table.TransactConditionCheck(&dynamodb.Key{HashKey: "bob"}, &dynamodb.Expressions{
+			Condition: dynamodb.AttributeExists("id"),
+		}),
+	}, "token-1")
+	c.Assert(err, check.IsNil)
+	c.Check(s.target, check.Equals, "DynamoDB_20120810.TransactWriteItems")
+	s.checkBody(c, `
+	{
+		"ClientRequestToken": "token-1",
+		"TransactItems": [
+			{"Update": {
+				"TableName": "accounts",
+				"Key": {"id": {"S": "alice"}},
+				"UpdateExpression": "SET #n0 = #n0 - :v1",
+				"ConditionExpression": "#n0 >= :v0",
+				"ExpressionAttributeNames": {"#n0": "balance"},
+				"ExpressionAttributeValues": {":v0": {"N": "10"}, ":v1": {"N": "10"}},
+				"ReturnValuesOnConditionCheckFailure": "ALL_OLD"
+			}},
+			{"Put": {
+				"TableName": "accounts",
+				"Item": {"amount": {"N": "10"}, "id": {"S": "log-1"}},
+				"ConditionExpression": "attribute_not_exists(#n0)",
+				"ExpressionAttributeNames": {"#n0": "id"}
+			}},
+			{"Delete": {
+				"TableName": "accounts",
+				"Key": {"id": {"S": "pending-1"}}
+			}},
+			{"ConditionCheck": {
+				"TableName": "accounts",
+				"Key": {"id": {"S": "bob"}},
+				"ConditionExpression": "attribute_exists(#n0)",
+				"ExpressionAttributeNames": {"#n0": "id"}
+			}}
+		]
+	}`)
+}
+
+func (s *TransactionSuite) TestTransactWriteItemsCanceled(c *check.C) {
+	s.respond(400, `{
+		"__type": "com.amazonaws.dynamodb.v20120810#TransactionCanceledException",
+		"Message": "Transaction cancelled, please refer cancellation reasons for specific reasons [ConditionalCheckFailed, None]",
+		"CancellationReasons": [
+			{"Code": "ConditionalCheckFailed", "Message": "The conditional request failed", "Item": {"id": {"S": "alice"}, "balance": {"N": "5"}}},
+			{"Code": "None"}
+		]
+	}`)
+
+	err := s.server.TransactWriteItems([]*dynamodb.TransactWriteItem{
+		s.table.TransactConditionCheck(&dynamodb.Key{HashKey: "alice"}, &dynamodb.Expressions{
+			Condition: dynamodb.AttributeExists("id"),
+		}),
+		s.table.TransactDelete(&dynamodb.Key{HashKey: "bob"}, nil),
+	}, "")
+	c.Assert(err, check.FitsTypeOf, &dynamodb.TransactionCanceledError{})
+	tcErr := err.(*dynamodb.TransactionCanceledError)
+	c.Check(tcErr.StatusCode, check.Equals, 400)
+	c.Check(tcErr.Code, check.Equals, "TransactionCanceledException")
+	c.Check(err.Error(), check.Matches, "TransactionCanceledException: Transaction cancelled.*")
+	c.Check(tcErr.Reasons, check.DeepEquals, []dynamodb.CancellationReason{{
+		Code:    "ConditionalCheckFailed",
+		Message: "The conditional request failed",
+		Item: map[string]*dynamodb.Attribute{
+			"id":      dynamodb.NewStringAttribute("id", "alice"),
+			"balance": dynamodb.NewNumericAttribute("balance", "5"),
+		},
+	}, {
+		Code: "None",
+	}})
+}
+
+func (s *TransactionSuite) TestTransactWriteItemsValidation(c *check.C) {
+	err := s.server.TransactWriteItems(nil, "")
+	c.Check(err, check.ErrorMatches, "At least one item is required.")
+
+	err = s.server.TransactWriteItems([]*dynamodb.TransactWriteItem{
+		s.table.TransactUpdate(&dynamodb.Key{HashKey: "alice"}, nil),
+	}, "")
+	c.Check(err, check.ErrorMatches, "An update expression is required.")
+
+	err = s.server.TransactWriteItems([]*dynamodb.TransactWriteItem{
+		s.table.TransactConditionCheck(&dynamodb.Key{HashKey: "alice"}, &dynamodb.Expressions{}),
+	}, "")
+	c.Check(err, check.ErrorMatches, "A condition expression is required.")
+}
+
+func (s *TransactionSuite) TestTransactGetItems(c *check.C) {
+	s.respond(200, `{"Responses": [{"Item": {"id": {"S": "alice"}, "balance": {"N": "5"}}}, {}]}`)
+
+	items, err := s.server.TransactGetItems([]*dynamodb.TransactGetItem{
+		s.table.TransactGet(&dynamodb.Key{HashKey: "alice"}, []string{"id", "balance"}),
+		s.table.TransactGet(&dynamodb.Key{HashKey: "nobody"}, nil),
+	})
+	c.Assert(err, check.IsNil)
+	c.Check(s.target, check.Equals, "DynamoDB_20120810.TransactGetItems")
+	s.checkBody(c, `
+	{
+		"TransactItems": [
+			{"Get": {
+				"TableName": "accounts",
+				"Key": {"id": {"S": "alice"}},
+				"ProjectionExpression": "#n0, #n1",
+				"ExpressionAttributeNames": {"#n0": "id", "#n1": "balance"}
+			}},
+			{"Get": {
+				"TableName": "accounts",
+				"Key": {"id": {"S": "nobody"}}
+			}}
+		]
+	}`)
+	c.Check(items, check.DeepEquals, []map[string]*dynamodb.Attribute{{
+		"id":      dynamodb.NewStringAttribute("id", "alice"),
+		"balance": dynamodb.NewNumericAttribute("balance", "5"),
+	}, nil})
+}
@@ -0,0 +1,171 @@
+// Package dynamodbstreams reads the change records of DynamoDB tables
+// that have DynamoDB Streams enabled.
+package dynamodbstreams
+
+import (
+	"encoding/json"
+	"github.com/crowdmob/goamz/aws"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// New creates a new DynamoDB Streams object.
+func New(auth aws.Auth, region aws.Region) *Streams {
+	return &Streams{auth, region}
+}
+
+// This operation returns the streams of the account, or of the given
+// table if tableName is not empty. Pass the LastEvaluatedStreamArn of
+// a response as exclusiveStartStreamArn to get the next page.
+func (s *Streams) ListStreams(tableName string, limit int, exclusiveStartStreamArn string) (resp *ListStreamsResponse, err error) {
+	query := NewEmptyQuery()
+	if tableName != "" {
+		query.AddTableName(tableName)
+	}
+	if limit > 0 {
+		query.AddLimit(limit)
+	}
+	if exclusiveStartStreamArn != "" {
+		query.AddExclusiveStartStreamArn(exclusiveStartStreamArn)
+	}
+
+	body, err := s.query(target("ListStreams"), query)
+	if err != nil {
+		return nil, err
+	}
+
+	lsr := &ListStreamsResponse{}
+	err = json.Unmarshal(body, lsr)
+	return lsr, err
+}
+
+// This operation returns the status, view type and shards of a stream.
+// Pass the LastEvaluatedShardId of a description as exclusiveStartShardId
+// to get the next page of shards.
+func (s *Streams) DescribeStream(streamArn string, limit int, exclusiveStartShardId string) (resp *StreamDescription, err error) {
+	query := NewQueryWithStream(streamArn)
+	if limit > 0 {
+		query.AddLimit(limit)
+	}
+	if exclusiveStartShardId != "" {
+		query.AddExclusiveStartShardId(exclusiveStartShardId)
+	}
+
+	body, err := s.query(target("DescribeStream"), query)
+	if err != nil {
+		return nil, err
+	}
+
+	dsr := &DescribeStreamResponse{}
+	err = json.Unmarshal(body, dsr)
+	return &dsr.StreamDescription, err
+}
+
+// This operation returns a shard iterator, the position in the shard from
+// which GetRecords reads. sequenceNumber is only used with the
+// AT_SEQUENCE_NUMBER and AFTER_SEQUENCE_NUMBER iterator types.
+func (s *Streams) GetShardIterator(streamArn, shardId string, iteratorType ShardIteratorType, sequenceNumber string) (resp *GetShardIteratorResponse, err error) {
+	query := NewQueryWithStream(streamArn)
+	query.AddShardId(shardId)
+	query.AddShardIteratorType(iteratorType)
+	if sequenceNumber != "" {
+		query.AddSequenceNumber(sequenceNumber)
+	}
+
+	body, err := s.query(target("GetShardIterator"), query)
+	if err != nil {
+		return nil, err
+	}
+
+	gsr := &GetShardIteratorResponse{}
+	err = json.Unmarshal(body, gsr)
+	return gsr, err
+}
+
+// This operation returns the stream records from a shard, starting at the
+// position of shardIterator, and the iterator to use for the next call.
+func (s *Streams) GetRecords(shardIterator string, limit int) (resp *GetRecordsResponse, err error) {
+	query := NewEmptyQuery()
+	query.AddShardIterator(shardIterator)
+	if limit > 0 {
+		query.AddLimit(limit)
+	}
+
+	body, err := s.query(target("GetRecords"), query)
+	if err != nil {
+		return nil, err
+	}
+
+	grr := &GetRecordsResponse{}
+	err = json.Unmarshal(body, grr)
+	return grr, err
+}
+
+func (s *Streams) query(target string, query *Query) ([]byte, error) {
+	data := strings.NewReader(query.String())
+	hreq, err := http.NewRequest("POST", s.Region.DynamoDBStreamsEndpoint+"/", data)
+	if err != nil {
+		return nil, err
+	}
+
+	hreq.Header.Set("Content-Type", "application/x-amz-json-1.0")
+	hreq.Header.Set("X-Amz-Date", time.Now().UTC().Format(aws.ISO8601BasicFormat))
+	hreq.Header.Set("X-Amz-Target", target)
+
+	token := s.Auth.Token()
+	if token != "" {
+		hreq.Header.Set("X-Amz-Security-Token", token)
+	}
+
+	signer := aws.NewV4Signer(s.Auth, "dynamodb", s.Region)
+	signer.Sign(hreq)
+
+	resp, err := http.DefaultClient.Do(hreq)
+	if err != nil {
+		log.Printf("dynamodbstreams: Error calling Amazon\n: %v", err)
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		log.Printf("dynamodbstreams: Could not read response body\n")
+		return nil, err
+	}
+
+	// "A response code of 200 indicates the operation was successful."
+	if resp.StatusCode != 200 {
+		return nil, buildError(resp, body)
+	}
+
+	return body, nil
+}
+
+func buildError(r *http.Response, jsonBody []byte) error {
+	streamsError := &Error{
+		StatusCode: r.StatusCode,
+		Status:     r.Status,
+	}
+
+	err := json.Unmarshal(jsonBody, streamsError)
+	if err != nil {
+		log.Printf("dynamodbstreams: Failed to parse body as JSON")
+		return err
+	}
+
+	// Of the form: com.amazonaws.dynamodb.v20120810#ExpiredIteratorException
+	// We only want the last part
+	if i := strings.Index(streamsError.Code, "#"); i >= 0 {
+		streamsError.Code = streamsError.Code[i+1:]
+	}
+
+	return streamsError
+}
+
+func target(name string) string {
+	return "DynamoDBStreams_20120810." + name
+}
@@ -0,0 +1,242 @@
+package dynamodbstreams_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/crowdmob/goamz/aws"
+	"github.com/crowdmob/goamz/dynamodb"
+	"github.com/crowdmob/goamz/dynamodbstreams"
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) {
+	check.TestingT(t)
+}
+
+// StreamsSuite runs the client against an HTTP server that answers
+// every request with handle.
+type StreamsSuite struct {
+	streams *dynamodbstreams.Streams
+	http    *httptest.Server
+
+	mu       sync.Mutex
+	requests []string
+	handle   func(target string, req map[string]interface{}) (int, string)
+}
+
+var _ = check.Suite(&StreamsSuite{})
+
+func (s *StreamsSuite) SetUpSuite(c *check.C) {
+	s.http = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		var params map[string]interface{}
+		json.Unmarshal(body, &params)
+		target := strings.TrimPrefix(req.Header.Get("X-Amz-Target"), "DynamoDBStreams_20120810.")
+		s.mu.Lock()
+		s.requests = append(s.requests, target)
+		status, response := s.handle(target, params)
+		s.mu.Unlock()
+		w.WriteHeader(status)
+		w.Write([]byte(response))
+	}))
+	auth := aws.Auth{AccessKey: "DUMMY_KEY", SecretKey: "DUMMY_SECRET"}
+	s.streams = dynamodbstreams.New(auth, aws.Region{Name: "us-east-1", DynamoDBStreamsEndpoint: s.http.URL})
+}
+
+func (s *StreamsSuite) SetUpTest(c *check.C) {
+	s.requests = nil
+}
+
+func (s *StreamsSuite) TearDownSuite(c *check.C) {
+	s.http.Close()
+}
+
+func (s *StreamsSuite) TestGetRecords(c *check.C) {
+	s.handle = func(target string, req map[string]interface{}) (int, string) {
+		c.Check(target, check.Equals, "GetRecords")
+		c.Check(req["ShardIterator"], check.Equals, "it")
+		return 200, `{
+			"NextShardIterator": "next",
+			"Records": [{
+				"awsRegion": "us-east-1",
+				"eventID": "e1",
+				"eventName": "MODIFY",
+				"eventSource": "aws:dynamodb",
+				"eventVersion": "1.1",
+				"dynamodb": {
+					"Keys": {"id": {"S": "a"}},
+					"NewImage": {"id": {"S": "a"}, "n": {"N": "2"}, "m": {"M": {"x": {"S": "y"}}}},
+					"OldImage": {"id": {"S": "a"}, "n": {"N": "1"}},
+					"SequenceNumber": "100",
+					"SizeBytes": 26,
+					"StreamViewType": "NEW_AND_OLD_IMAGES"
+				}
+			}]
+		}`
+	}
+
+	resp, err := s.streams.GetRecords("it", 0)
+	c.Assert(err, check.IsNil)
+	c.Check(resp.NextShardIterator, check.Equals, "next")
+	c.Assert(resp.Records, check.HasLen, 1)
+	r := resp.Records[0]
+	c.Check(r.EventName, check.Equals, "MODIFY")
+	c.Check(r.Dynamodb.SequenceNumber, check.Equals, "100")
+	c.Check(r.Dynamodb.StreamViewType, check.Equals, dynamodbstreams.StreamViewNewAndOldImages)
+	c.Check(r.Dynamodb.Keys, check.DeepEquals, map[string]*dynamodb.Attribute{
+		"id": dynamodb.NewStringAttribute("id", "a"),
+	})
+	c.Check(r.Dynamodb.NewImage["n"], check.DeepEquals, dynamodb.NewNumericAttribute("n", "2"))
+	c.Check(r.Dynamodb.NewImage["m"].MapValues["x"].Value, check.Equals, "y")
+	c.Check(r.Dynamodb.OldImage["n"].Value, check.Equals, "1")
+}
+
+func (s *StreamsSuite) TestError(c *check.C) {
+	s.handle = func(target string, req map[string]interface{}) (int, string) {
+		return 400, `{"__type": "com.amazonaws.dynamodb.v20120810#ExpiredIteratorException", "message": "expired"}`
+	}
+
+	_, err := s.streams.GetRecords("it", 0)
+	c.Assert(err, check.FitsTypeOf, &dynamodbstreams.Error{})
+	c.Check(err.(*dynamodbstreams.Error).Code, check.Equals, "ExpiredIteratorException")
+	c.Check(err.(*dynamodbstreams.Error).Message, check.Equals, "expired")
+}
+
+// fakeStream answers the requests of a Processor for a stream made of
+// shards, whose records are served from records, keyed by iterator.
+// GetShardIterator returns iterators of the form shard/type/sequence.
+type fakeStream struct {
+	shards  string
+	records map[string]string
+}
+
+func (f *fakeStream) handle(target string, req map[string]interface{}) (int, string) {
+	switch target {
+	case "DescribeStream":
+		return 200, `{"StreamDescription": {"StreamArn": "arn", "Shards": ` + f.shards + `}}`
+	case "GetShardIterator":
+		seq, _ := req["SequenceNumber"].(string)
+		it := req["ShardId"].(string) + "/" + req["ShardIteratorType"].(string) + "/" + seq
+		return 200, `{"ShardIterator": "` + it + `"}`
+	case "GetRecords":
+		if resp, ok := f.records[req["ShardIterator"].(string)]; ok {
+			return 200, resp
+		}
+		return 400, `{"__type": "com.amazonaws.dynamodb.v20120810#ExpiredIteratorException", "message": "expired"}`
+	}
+	return 400, `{"__type": "UnknownOperationException"}`
+}
+
+func record(seq string) string {
+	return `{"eventName": "INSERT", "dynamodb": {"Keys": {"id": {"S": "` + seq + `"}}, "SequenceNumber": "` + seq + `"}}`
+}
+
+var lineage = &fakeStream{
+	shards: `[
+		{"ShardId": "child", "ParentShardId": "parent"},
+		{"ShardId": "parent", "SequenceNumberRange": {"StartingSequenceNumber": "1", "EndingSequenceNumber": "2"}}
+	]`,
+	records: map[string]string{
+		"parent/TRIM_HORIZON/":           `{"NextShardIterator": "parent-1", "Records": [` + record("1") + `]}`,
+		"parent-1":                       `{"NextShardIterator": "parent-2", "Records": [` + record("2") + `]}`,
+		"parent-2":                       `{"Records": []}`,
+		"parent/AFTER_SEQUENCE_NUMBER/1": `{"NextShardIterator": "parent-2", "Records": [` + record("2") + `]}`,
+		"child/TRIM_HORIZON/":            `{"Records": [` + record("3") + `]}`,
+	},
+}
+
+// run runs a processor on the stream until the record with
+// sequence number last has been handled, and returns the
+// sequence numbers of the records handled.
+func (s *StreamsSuite) run(c *check.C, p *dynamodbstreams.Processor, last string) []string {
+	var mu sync.Mutex
+	var handled []string
+	stop := make(chan struct{})
+	p.Streams = s.streams
+	p.StreamArn = "arn"
+	p.PollInterval = time.Millisecond
+	p.DescribeInterval = time.Millisecond
+	p.Handler = func(shardId string, records []dynamodbstreams.Record) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, r := range records {
+			c.Check(r.Dynamodb.Keys["id"].Value, check.Equals, r.Dynamodb.SequenceNumber)
+			handled = append(handled, r.Dynamodb.SequenceNumber)
+			if r.Dynamodb.SequenceNumber == last {
+				close(stop)
+			}
+		}
+		return nil
+	}
+	c.Assert(p.Run(stop), check.IsNil)
+	return handled
+}
+
+func (s *StreamsSuite) TestProcessorFollowsLineage(c *check.C) {
+	s.handle = lineage.handle
+	store := dynamodbstreams.NewMemoryCheckpointStore()
+
+	handled := s.run(c, &dynamodbstreams.Processor{Store: store}, "3")
+	c.Check(handled, check.DeepEquals, []string{"1", "2", "3"})
+
+	checkpoint, _ := store.Checkpoint("arn", "parent")
+	c.Check(checkpoint, check.Equals, dynamodbstreams.ShardEnd)
+}
+
+func (s *StreamsSuite) TestProcessorResumesFromCheckpoint(c *check.C) {
+	s.handle = lineage.handle
+	store := dynamodbstreams.NewMemoryCheckpointStore()
+	store.SetCheckpoint("arn", "parent", "1")
+
+	handled := s.run(c, &dynamodbstreams.Processor{Store: store}, "3")
+	c.Check(handled, check.DeepEquals, []string{"2", "3"})
+}
+
+func (s *StreamsSuite) TestProcessorSkipsFinishedShards(c *check.C) {
+	s.handle = lineage.handle
+	store := dynamodbstreams.NewMemoryCheckpointStore()
+	store.SetCheckpoint("arn", "parent", dynamodbstreams.ShardEnd)
+
+	handled := s.run(c, &dynamodbstreams.Processor{Store: store}, "3")
+	c.Check(handled, check.DeepEquals, []string{"3"})
+}
+
+func (s *StreamsSuite) TestProcessorRenewsExpiredIterators(c *check.C) {
+	expired := true
+	s.handle = func(target string, req map[string]interface{}) (int, string) {
+		if target == "GetRecords" && req["ShardIterator"] == "parent-1" && expired {
+			expired = false
+			return 400, `{"__type": "com.amazonaws.dynamodb.v20120810#ExpiredIteratorException", "message": "expired"}`
+		}
+		return lineage.handle(target, req)
+	}
+	store := dynamodbstreams.NewMemoryCheckpointStore()
+
+	handled := s.run(c, &dynamodbstreams.Processor{Store: store}, "3")
+	c.Check(handled, check.DeepEquals, []string{"1", "2", "3"})
+}
+
+func (s *StreamsSuite) TestProcessorReturnsHandlerErrors(c *check.C) {
+	s.handle = lineage.handle
+	store := dynamodbstreams.NewMemoryCheckpointStore()
+	p := &dynamodbstreams.Processor{
+		Streams:   s.streams,
+		StreamArn: "arn",
+		Store:     store,
+		Handler: func(shardId string, records []dynamodbstreams.Record) error {
+			return errors.New("handler failed")
+		},
+	}
+
+	c.Check(p.Run(make(chan struct{})), check.ErrorMatches, "handler failed")
+	checkpoint, _ := store.Checkpoint("arn", "parent")
+	c.Check(checkpoint, check.Equals, "")
+}
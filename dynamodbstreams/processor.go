@@ -0,0 +1,242 @@
+package dynamodbstreams
+
+import (
+	"sync"
+	"time"
+)
+
+// ShardEnd is the checkpoint of a shard whose records
+// have all been processed.
+const ShardEnd = "SHARD_END"
+
+// CheckpointStore keeps how far a Processor got in each shard, so that
+// processing resumes where it stopped. A checkpoint is the sequence
+// number of the last record processed in the shard, or ShardEnd.
+type CheckpointStore interface {
+	// Checkpoint returns the checkpoint of the shard,
+	// or "" if none was set.
+	Checkpoint(streamArn, shardId string) (string, error)
+	SetCheckpoint(streamArn, shardId, checkpoint string) error
+}
+
+// MemoryCheckpointStore is a CheckpointStore that keeps checkpoints in
+// memory only. It is safe for concurrent use.
+type MemoryCheckpointStore struct {
+	mu          sync.Mutex
+	checkpoints map[string]string
+}
+
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{checkpoints: make(map[string]string)}
+}
+
+func (m *MemoryCheckpointStore) Checkpoint(streamArn, shardId string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.checkpoints[streamArn+"/"+shardId], nil
+}
+
+func (m *MemoryCheckpointStore) SetCheckpoint(streamArn, shardId, checkpoint string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checkpoints[streamArn+"/"+shardId] = checkpoint
+	return nil
+}
+
+// Processor hands all the records of a stream to Handler, shard by shard.
+// The records of a shard are handled in order, and a shard is only
+// started once its parent shard, if still in the stream, is finished,
+// so that all the changes to an item are seen in order. Shards that
+// are not related may be handled concurrently.
+//
+// Progress is saved in Store after every successful call to Handler,
+// so records are handled at least once: those of a failed call are
+// handled again when the processor is restarted.
+type Processor struct {
+	Streams   *Streams
+	StreamArn string
+	Store     CheckpointStore
+	Handler   func(shardId string, records []Record) error
+
+	// IteratorType is where shards without a checkpoint are started.
+	// Defaults to TRIM_HORIZON.
+	IteratorType ShardIteratorType
+
+	// Limit is the maximum number of records of each GetRecords call.
+	Limit int
+
+	// PollInterval is the delay before reading an open shard again
+	// after it returned no records. Defaults to one second.
+	PollInterval time.Duration
+
+	// DescribeInterval is the delay between looks for new shards.
+	// Defaults to ten seconds.
+	DescribeInterval time.Duration
+}
+
+type shardResult struct {
+	shardId string
+	err     error
+}
+
+// Run processes the stream until stop is closed, in which case it
+// returns nil, or until Handler, the Store or a request fails, in
+// which case it returns that error. Either way, it waits for the
+// shards being processed to stop before returning.
+func (p *Processor) Run(stop <-chan struct{}) error {
+	describeInterval := p.DescribeInterval
+	if describeInterval <= 0 {
+		describeInterval = 10 * time.Second
+	}
+
+	quit := make(chan struct{})
+	results := make(chan shardResult)
+	running := make(map[string]bool)
+	finish := func(err error) error {
+		close(quit)
+		for len(running) > 0 {
+			r := <-results
+			delete(running, r.shardId)
+		}
+		return err
+	}
+
+	for {
+		shards, err := p.shards()
+		if err != nil {
+			return finish(err)
+		}
+		ids := make(map[string]bool)
+		for _, shard := range shards {
+			ids[shard.ShardId] = true
+		}
+		for _, shard := range shards {
+			if running[shard.ShardId] {
+				continue
+			}
+			ready, err := p.ready(shard, ids)
+			if err != nil {
+				return finish(err)
+			}
+			if !ready {
+				continue
+			}
+			running[shard.ShardId] = true
+			go func(shardId string) {
+				results <- shardResult{shardId, p.processShard(shardId, quit)}
+			}(shard.ShardId)
+		}
+
+		select {
+		case <-stop:
+			return finish(nil)
+		case r := <-results:
+			delete(running, r.shardId)
+			if r.err != nil {
+				return finish(r.err)
+			}
+		case <-time.After(describeInterval):
+		}
+	}
+}
+
+// shards returns all the shards of the stream.
+func (p *Processor) shards() ([]Shard, error) {
+	var shards []Shard
+	start := ""
+	for {
+		desc, err := p.Streams.DescribeStream(p.StreamArn, 0, start)
+		if err != nil {
+			return nil, err
+		}
+		shards = append(shards, desc.Shards...)
+		if desc.LastEvaluatedShardId == "" {
+			return shards, nil
+		}
+		start = desc.LastEvaluatedShardId
+	}
+}
+
+// ready reports whether shard is unfinished and can be started: its
+// parent must be finished or gone from the stream, whose shard ids are
+// in ids.
+func (p *Processor) ready(shard Shard, ids map[string]bool) (bool, error) {
+	checkpoint, err := p.Store.Checkpoint(p.StreamArn, shard.ShardId)
+	if err != nil || checkpoint == ShardEnd {
+		return false, err
+	}
+	if shard.ParentShardId == "" || !ids[shard.ParentShardId] {
+		return true, nil
+	}
+	checkpoint, err = p.Store.Checkpoint(p.StreamArn, shard.ParentShardId)
+	return checkpoint == ShardEnd, err
+}
+
+// iterator returns an iterator positioned after the checkpoint of
+// the shard, or at IteratorType if there is none.
+func (p *Processor) iterator(shardId string) (string, error) {
+	checkpoint, err := p.Store.Checkpoint(p.StreamArn, shardId)
+	if err != nil {
+		return "", err
+	}
+	iteratorType := p.IteratorType
+	if checkpoint != "" {
+		iteratorType = ShardIteratorAfterSequenceNumber
+	} else if iteratorType == "" {
+		iteratorType = ShardIteratorTrimHorizon
+	}
+	resp, err := p.Streams.GetShardIterator(p.StreamArn, shardId, iteratorType, checkpoint)
+	if err != nil {
+		return "", err
+	}
+	return resp.ShardIterator, nil
+}
+
+// processShard handles the records of the shard until its end, or
+// until quit is closed.
+func (p *Processor) processShard(shardId string, quit <-chan struct{}) error {
+	pollInterval := p.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+
+	iterator, err := p.iterator(shardId)
+	if err != nil {
+		return err
+	}
+	for iterator != "" {
+		select {
+		case <-quit:
+			return nil
+		default:
+		}
+
+		resp, err := p.Streams.GetRecords(iterator, p.Limit)
+		if e, ok := err.(*Error); ok && e.Code == "ExpiredIteratorException" {
+			if iterator, err = p.iterator(shardId); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if n := len(resp.Records); n > 0 {
+			if err := p.Handler(shardId, resp.Records); err != nil {
+				return err
+			}
+			if err := p.Store.SetCheckpoint(p.StreamArn, shardId, resp.Records[n-1].Dynamodb.SequenceNumber); err != nil {
+				return err
+			}
+		} else if resp.NextShardIterator != "" {
+			select {
+			case <-quit:
+				return nil
+			case <-time.After(pollInterval):
+			}
+		}
+		iterator = resp.NextShardIterator
+	}
+	return p.Store.SetCheckpoint(p.StreamArn, shardId, ShardEnd)
+}
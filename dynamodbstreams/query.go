@@ -0,0 +1,64 @@
+package dynamodbstreams
+
+import (
+	"encoding/json"
+)
+
+type msi map[string]interface{}
+type Query struct {
+	buffer msi
+}
+
+func NewEmptyQuery() *Query {
+	return &Query{msi{}}
+}
+
+func NewQueryWithStream(streamArn string) *Query {
+	q := &Query{msi{}}
+	q.AddStreamArn(streamArn)
+	return q
+}
+
+func (q *Query) AddStreamArn(streamArn string) {
+	q.buffer["StreamArn"] = streamArn
+}
+
+func (q *Query) AddTableName(name string) {
+	q.buffer["TableName"] = name
+}
+
+func (q *Query) AddLimit(limit int) {
+	q.buffer["Limit"] = limit
+}
+
+func (q *Query) AddExclusiveStartStreamArn(streamArn string) {
+	q.buffer["ExclusiveStartStreamArn"] = streamArn
+}
+
+func (q *Query) AddExclusiveStartShardId(shardId string) {
+	q.buffer["ExclusiveStartShardId"] = shardId
+}
+
+func (q *Query) AddShardId(id string) {
+	q.buffer["ShardId"] = id
+}
+
+func (q *Query) AddShardIteratorType(t ShardIteratorType) {
+	q.buffer["ShardIteratorType"] = t
+}
+
+func (q *Query) AddSequenceNumber(sequenceNumber string) {
+	q.buffer["SequenceNumber"] = sequenceNumber
+}
+
+func (q *Query) AddShardIterator(iterator string) {
+	q.buffer["ShardIterator"] = iterator
+}
+
+func (q *Query) String() string {
+	bytes, err := json.Marshal(q.buffer)
+	if err != nil {
+		panic(err)
+	}
+	return string(bytes)
+}
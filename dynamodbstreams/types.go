@@ -0,0 +1,167 @@
+package dynamodbstreams
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/crowdmob/goamz/aws"
+	"github.com/crowdmob/goamz/dynamodb"
+)
+
+type ShardIteratorType string
+type StreamViewType string
+
+const (
+	// Start reading exactly from the position denoted by a specific sequence number.
+	ShardIteratorAtSequenceNumber ShardIteratorType = "AT_SEQUENCE_NUMBER"
+
+	// Start reading right after the position denoted by a specific sequence number.
+	ShardIteratorAfterSequenceNumber ShardIteratorType = "AFTER_SEQUENCE_NUMBER"
+
+	// Start reading at the oldest record in the shard, which is
+	// at most 24 hours old.
+	ShardIteratorTrimHorizon ShardIteratorType = "TRIM_HORIZON"
+
+	// Start reading just after the most recent record in the shard.
+	ShardIteratorLatest ShardIteratorType = "LATEST"
+
+	// Only the key attributes of the modified item are written to the stream.
+	StreamViewKeysOnly StreamViewType = "KEYS_ONLY"
+
+	// The entire item, as it appears after it was modified, is written to the stream.
+	StreamViewNewImage StreamViewType = "NEW_IMAGE"
+
+	// The entire item, as it appeared before it was modified, is written to the stream.
+	StreamViewOldImage StreamViewType = "OLD_IMAGE"
+
+	// Both the new and the old images of the item are written to the stream.
+	StreamViewNewAndOldImages StreamViewType = "NEW_AND_OLD_IMAGES"
+)
+
+// Main DynamoDB Streams object
+type Streams struct {
+	aws.Auth
+	aws.Region
+}
+
+// A stream of a table, as returned by ListStreams.
+type Stream struct {
+	StreamArn   string
+	StreamLabel string
+	TableName   string
+}
+
+// The range of possible sequence numbers for the shard. EndingSequenceNumber
+// is empty while the shard is still open.
+type SequenceNumberRange struct {
+	EndingSequenceNumber   string
+	StartingSequenceNumber string
+}
+
+// A uniquely identified group of stream records within a stream.
+type Shard struct {
+	ParentShardId       string
+	SequenceNumberRange SequenceNumberRange
+	ShardId             string
+}
+
+// Description of a stream.
+type StreamDescription struct {
+	CreationRequestDateTime float64
+	KeySchema               []dynamodb.KeySchemaT
+	LastEvaluatedShardId    string
+	Shards                  []Shard
+	StreamArn               string
+	StreamLabel             string
+	StreamStatus            string
+	StreamViewType          StreamViewType
+	TableName               string
+}
+
+// A change to an item of the table. EventName is INSERT, MODIFY or REMOVE.
+type Record struct {
+	AwsRegion    string `json:"awsRegion"`
+	Dynamodb     StreamRecord
+	EventID      string `json:"eventID"`
+	EventName    string `json:"eventName"`
+	EventSource  string `json:"eventSource"`
+	EventVersion string `json:"eventVersion"`
+}
+
+// The item change carried by a Record. Which of the images are set
+// depends on the StreamViewType of the stream.
+type StreamRecord struct {
+	ApproximateCreationDateTime float64
+	Keys                        map[string]*dynamodb.Attribute
+	NewImage                    map[string]*dynamodb.Attribute
+	OldImage                    map[string]*dynamodb.Attribute
+	SequenceNumber              string
+	SizeBytes                   int64
+	StreamViewType              StreamViewType
+}
+
+// UnmarshalJSON decodes the images of the record into attributes.
+func (r *StreamRecord) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		ApproximateCreationDateTime float64
+		Keys                        map[string]interface{}
+		NewImage                    map[string]interface{}
+		OldImage                    map[string]interface{}
+		SequenceNumber              string
+		SizeBytes                   int64
+		StreamViewType              StreamViewType
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*r = StreamRecord{
+		ApproximateCreationDateTime: raw.ApproximateCreationDateTime,
+		SequenceNumber:              raw.SequenceNumber,
+		SizeBytes:                   raw.SizeBytes,
+		StreamViewType:              raw.StreamViewType,
+	}
+	if raw.Keys != nil {
+		r.Keys = dynamodb.ParseAttributes(raw.Keys)
+	}
+	if raw.NewImage != nil {
+		r.NewImage = dynamodb.ParseAttributes(raw.NewImage)
+	}
+	if raw.OldImage != nil {
+		r.OldImage = dynamodb.ParseAttributes(raw.OldImage)
+	}
+	return nil
+}
+
+// Represents the output of a ListStreams operation.
+type ListStreamsResponse struct {
+	LastEvaluatedStreamArn string
+	Streams                []Stream
+}
+
+// Represents the output of a DescribeStream operation.
+type DescribeStreamResponse struct {
+	StreamDescription StreamDescription
+}
+
+// Represents the output of a GetShardIterator operation.
+type GetShardIteratorResponse struct {
+	ShardIterator string
+}
+
+// Represents the output of a GetRecords operation. NextShardIterator
+// is empty once the end of a closed shard has been reached.
+type GetRecordsResponse struct {
+	NextShardIterator string
+	Records           []Record
+}
+
+// Error represents an error in an operation with DynamoDB Streams (following goamz/kinesis)
+type Error struct {
+	StatusCode int // HTTP status code (200, 403, ...)
+	Status     string
+	Code       string `json:"__type"`
+	Message    string `json:"message"`
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("[HTTP %d] %s : %s", e.StatusCode, e.Code, e.Message)
+}
@@ -83,6 +83,35 @@ func (f *Filter) addParams(params map[string]string) {
 	}
 }
 
+// PageOptions holds the pagination parameters of a Describe call that
+// supports them. Pass the NextToken of a response to get the following
+// page; an empty NextToken in a response means there are no more pages.
+type PageOptions struct {
+	MaxResults int    // Maximum number of results per page; zero means the EC2 default
+	NextToken  string // Token of the page to return; empty for the first page
+}
+
+func (p *PageOptions) addParams(params map[string]string) {
+	if p != nil {
+		if p.MaxResults != 0 {
+			params["MaxResults"] = strconv.Itoa(p.MaxResults)
+		}
+		if p.NextToken != "" {
+			params["NextToken"] = p.NextToken
+		}
+	}
+}
+
+// next returns the options for the page following the one that
+// returned nextToken.
+func (p *PageOptions) next(nextToken string) *PageOptions {
+	next := &PageOptions{NextToken: nextToken}
+	if p != nil {
+		next.MaxResults = p.MaxResults
+	}
+	return next
+}
+
 // ----------------------------------------------------------------------------
 // Request dispatching logic.
 
@@ -119,7 +148,7 @@ type xmlErrors struct {
 var timeNow = time.Now
 
 func (ec2 *EC2) query(params map[string]string, resp interface{}) error {
-	params["Version"] = "2014-02-01"
+	params["Version"] = "2016-11-15"
 	params["Timestamp"] = timeNow().In(time.UTC).Format(time.RFC3339)
 	endpoint, err := url.Parse(ec2.Region.EC2Endpoint)
 	if err != nil {
@@ -218,6 +247,7 @@ type RunInstancesOptions struct {
 	BlockDeviceMappings   []BlockDeviceMapping
 	EbsOptimized          bool
 	NetworkInterfaces     []NetworkInterface
+	LaunchTemplate        *LaunchTemplateSpecification // Other options override the template values
 }
 
 // NetworkInterface is for creating and attaching to ec2 instances on launch
@@ -347,7 +377,9 @@ type InstanceNetworkInterface struct {
 // InstanceNetworkInterfaceAttachment describes a network interface attachment to an instance
 // See http://goo.gl/0ql0Cg for more details
 type InstanceNetworkInterfaceAttachment struct {
-	AttachmentID        string `xml:"attachmentID"`        // The ID of the network interface attachment.
+	AttachmentID        string `xml:"attachmentId"`        // The ID of the network interface attachment.
+	InstanceId          string `xml:"instanceId"`          // The ID of the instance.
+	InstanceOwnerId     string `xml:"instanceOwnerId"`     // The ID of the AWS account that owns the instance.
 	DeviceIndex         int32  `xml:"deviceIndex"`         // The index of the device on the instance for the network interface attachment.
 	Status              string `xml:"status"`              // Valid values: attaching | attached | detaching | detached
 	AttachTime          string `xml:"attachTime"`          // Time attached, as a Datetime
@@ -382,13 +414,16 @@ type IamInstanceProfile struct {
 // RunInstances starts new instances in EC2.
 // If options.MinCount and options.MaxCount are both zero, a single instance
 // will be started; otherwise if options.MaxCount is zero, options.MinCount
-// will be used insteead.
+// will be used insteead. ImageId and InstanceType may be left empty when
+// options.LaunchTemplate provides them.
 //
 // See http://goo.gl/Mcm3b for more details.
 func (ec2 *EC2) RunInstances(options *RunInstancesOptions) (resp *RunInstancesResp, err error) {
 	params := makeParams("RunInstances")
-	params["ImageId"] = options.ImageId
-	params["InstanceType"] = options.InstanceType
+	options.addLaunchParams(params, "")
+	if options.LaunchTemplate != nil {
+		options.LaunchTemplate.addParams(params, "LaunchTemplate.")
+	}
 	var min, max int
 	if options.MinCount == 0 && options.MaxCount == 0 {
 		min = 1
@@ -402,135 +437,155 @@ func (ec2 *EC2) RunInstances(options *RunInstancesOptions) (resp *RunInstancesRe
 	}
 	params["MinCount"] = strconv.Itoa(min)
 	params["MaxCount"] = strconv.Itoa(max)
+
+	token, err := clientToken()
+	if err != nil {
+		return nil, err
+	}
+	params["ClientToken"] = token
+
+	if options.DisableAPITermination {
+		params["DisableApiTermination"] = "true"
+	}
+	if options.ShutdownBehavior != "" {
+		params["InstanceInitiatedShutdownBehavior"] = options.ShutdownBehavior
+	}
+	if options.PrivateIPAddress != "" {
+		params["PrivateIpAddress"] = options.PrivateIPAddress
+	}
+
+	resp = &RunInstancesResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// addLaunchParams adds the parameters describing the instances to launch,
+// each name prefixed with prefix. These are shared by RunInstances and the
+// launch specification of other requests.
+func (options *RunInstancesOptions) addLaunchParams(params map[string]string, prefix string) {
+	if options.ImageId != "" {
+		params[prefix+"ImageId"] = options.ImageId
+	}
+	if options.InstanceType != "" {
+		params[prefix+"InstanceType"] = options.InstanceType
+	}
 	i, j := 1, 1
 	for _, g := range options.SecurityGroups {
 		if g.Id != "" {
-			params["SecurityGroupId."+strconv.Itoa(i)] = g.Id
+			params[prefix+"SecurityGroupId."+strconv.Itoa(i)] = g.Id
 			i++
 		} else {
-			params["SecurityGroup."+strconv.Itoa(j)] = g.Name
+			params[prefix+"SecurityGroup."+strconv.Itoa(j)] = g.Name
 			j++
 		}
 	}
-
-	for i, d := range options.BlockDeviceMappings {
-		if d.DeviceName != "" {
-			params["BlockDeviceMapping."+strconv.Itoa(i)+".DeviceName"] = d.DeviceName
-		}
-		if d.VirtualName != "" {
-			params["BlockDeviceMapping."+strconv.Itoa(i)+".VirtualName"] = d.VirtualName
-		}
-		if d.SnapshotId != "" {
-			params["BlockDeviceMapping."+strconv.Itoa(i)+".Ebs.SnapshotId"] = d.SnapshotId
-		}
-		if d.VolumeType != "" {
-			params["BlockDeviceMapping."+strconv.Itoa(i)+".Ebs.VolumeType"] = d.VolumeType
-		}
-		if d.VolumeSize != 0 {
-			params["BlockDeviceMapping."+strconv.Itoa(i)+".Ebs.VolumeSize"] = strconv.FormatInt(d.VolumeSize, 10)
-		}
-		if d.DeleteOnTermination {
-			params["BlockDeviceMapping."+strconv.Itoa(i)+".Ebs.DeleteOnTermination"] = "true"
-		}
-		if d.IOPS != 0 {
-			params["BlockDeviceMapping."+strconv.Itoa(i)+".Ebs.Iops"] = strconv.FormatInt(d.IOPS, 10)
-		}
-	}
-
-	token, err := clientToken()
-	if err != nil {
-		return nil, err
-	}
-	params["ClientToken"] = token
+	addBlockDeviceParams(params, prefix+"BlockDeviceMapping", options.BlockDeviceMappings)
 
 	if options.KeyName != "" {
-		params["KeyName"] = options.KeyName
+		params[prefix+"KeyName"] = options.KeyName
 	}
 	if options.KernelId != "" {
-		params["KernelId"] = options.KernelId
+		params[prefix+"KernelId"] = options.KernelId
 	}
 	if options.RamdiskId != "" {
-		params["RamdiskId"] = options.RamdiskId
+		params[prefix+"RamdiskId"] = options.RamdiskId
 	}
 	if options.UserData != nil {
 		userData := make([]byte, b64.EncodedLen(len(options.UserData)))
 		b64.Encode(userData, options.UserData)
-		params["UserData"] = string(userData)
+		params[prefix+"UserData"] = string(userData)
 	}
 	if options.AvailabilityZone != "" {
-		params["Placement.AvailabilityZone"] = options.AvailabilityZone
+		params[prefix+"Placement.AvailabilityZone"] = options.AvailabilityZone
 	}
 	if options.PlacementGroupName != "" {
-		params["Placement.GroupName"] = options.PlacementGroupName
+		params[prefix+"Placement.GroupName"] = options.PlacementGroupName
 	}
 	if options.Tenancy != "" {
-		params["Placement.Tenancy"] = options.Tenancy
+		params[prefix+"Placement.Tenancy"] = options.Tenancy
 	}
 	if options.Monitoring {
-		params["Monitoring.Enabled"] = "true"
+		params[prefix+"Monitoring.Enabled"] = "true"
 	}
 	if options.SubnetId != "" {
-		params["SubnetId"] = options.SubnetId
-	}
-	if options.DisableAPITermination {
-		params["DisableApiTermination"] = "true"
-	}
-	if options.ShutdownBehavior != "" {
-		params["InstanceInitiatedShutdownBehavior"] = options.ShutdownBehavior
-	}
-	if options.PrivateIPAddress != "" {
-		params["PrivateIpAddress"] = options.PrivateIPAddress
+		params[prefix+"SubnetId"] = options.SubnetId
 	}
 	if options.IamInstanceProfile.ARN != "" {
-		params["IamInstanceProfile.Arn"] = options.IamInstanceProfile.ARN
+		params[prefix+"IamInstanceProfile.Arn"] = options.IamInstanceProfile.ARN
 	}
 	if options.IamInstanceProfile.Name != "" {
-		params["IamInstanceProfile.Name"] = options.IamInstanceProfile.Name
+		params[prefix+"IamInstanceProfile.Name"] = options.IamInstanceProfile.Name
 	}
 	if options.EbsOptimized {
-		params["EbsOptimized"] = "true"
+		params[prefix+"EbsOptimized"] = "true"
 	}
+	addNetworkInterfaceParams(params, prefix+"NetworkInterface", options.NetworkInterfaces)
+}
 
-	if options.NetworkInterfaces != nil {
-		for i, ni := range options.NetworkInterfaces {
-			prefix := fmt.Sprintf("NetworkInterface.%d.", i+1)
-			params[prefix+"DeviceIndex"] = strconv.Itoa(i)
-			if ni.SubnetId != "" {
-				params[prefix+"SubnetId"] = ni.SubnetId
-			}
-			if ni.Description != "" {
-				params[prefix+"Description"] = ni.Description
-			}
-			if ni.AssociatePublicIpAddress {
-				params[prefix+"AssociatePublicIpAddress"] = "true"
-			}
-			if ni.PrivateIpAddress != "" {
-				params[prefix+"PrivateIpAddress"] = ni.PrivateIpAddress
-			}
-			if ni.SecurityGroups != nil {
-				for secId, g := range ni.SecurityGroups {
-					params[prefix+"SecurityGroupId."+strconv.Itoa(secId+1)] = g.Id
-				}
-			}
-			if ni.DeleteOnTermination {
-				params[prefix+"DeleteOnTermination"] = "true"
-			}
-			if ni.PrivateIpAddresses != nil {
-				for pId, addy := range ni.PrivateIpAddresses {
-					params[prefix+"PrivateIpAddresses."+strconv.Itoa(pId+1)+".PrivateIpAddress"] = addy.PrivateIPAddress
-					if addy.Primary {
-						params[prefix+"PrivateIpAddresses."+strconv.Itoa(pId+1)+".Primary"] = "true"
-					}
-				}
-			}
+// addBlockDeviceParams adds the parameters for a list of block device
+// mappings, numbered from 1 after label.
+func addBlockDeviceParams(params map[string]string, label string, mappings []BlockDeviceMapping) {
+	for i, d := range mappings {
+		prefix := label + "." + strconv.Itoa(i+1) + "."
+		if d.DeviceName != "" {
+			params[prefix+"DeviceName"] = d.DeviceName
+		}
+		if d.VirtualName != "" {
+			params[prefix+"VirtualName"] = d.VirtualName
+		}
+		if d.SnapshotId != "" {
+			params[prefix+"Ebs.SnapshotId"] = d.SnapshotId
+		}
+		if d.VolumeType != "" {
+			params[prefix+"Ebs.VolumeType"] = d.VolumeType
+		}
+		if d.VolumeSize != 0 {
+			params[prefix+"Ebs.VolumeSize"] = strconv.FormatInt(d.VolumeSize, 10)
+		}
+		if d.DeleteOnTermination {
+			params[prefix+"Ebs.DeleteOnTermination"] = "true"
+		}
+		if d.IOPS != 0 {
+			params[prefix+"Ebs.Iops"] = strconv.FormatInt(d.IOPS, 10)
 		}
 	}
-	resp = &RunInstancesResp{}
-	err = ec2.query(params, resp)
-	if err != nil {
-		return nil, err
+}
+
+// addNetworkInterfaceParams adds the parameters for a list of network
+// interfaces to attach at launch, numbered from 1 after label. The
+// device index of each interface is its position in the list.
+func addNetworkInterfaceParams(params map[string]string, label string, interfaces []NetworkInterface) {
+	for i, ni := range interfaces {
+		prefix := fmt.Sprintf("%s.%d.", label, i+1)
+		params[prefix+"DeviceIndex"] = strconv.Itoa(i)
+		if ni.SubnetId != "" {
+			params[prefix+"SubnetId"] = ni.SubnetId
+		}
+		if ni.Description != "" {
+			params[prefix+"Description"] = ni.Description
+		}
+		if ni.AssociatePublicIpAddress {
+			params[prefix+"AssociatePublicIpAddress"] = "true"
+		}
+		if ni.PrivateIpAddress != "" {
+			params[prefix+"PrivateIpAddress"] = ni.PrivateIpAddress
+		}
+		for secId, g := range ni.SecurityGroups {
+			params[prefix+"SecurityGroupId."+strconv.Itoa(secId+1)] = g.Id
+		}
+		if ni.DeleteOnTermination {
+			params[prefix+"DeleteOnTermination"] = "true"
+		}
+		for pId, addy := range ni.PrivateIpAddresses {
+			params[prefix+"PrivateIpAddresses."+strconv.Itoa(pId+1)+".PrivateIpAddress"] = addy.PrivateIPAddress
+			if addy.Primary {
+				params[prefix+"PrivateIpAddresses."+strconv.Itoa(pId+1)+".Primary"] = "true"
+			}
+		}
 	}
-	return
 }
 
 func clientToken() (string, error) {
@@ -613,7 +668,9 @@ type Address struct {
 
 // DescribeAddresses returns details about one or more
 // Elastic IP Addresses. Returned addresses can be
-// filtered by Public IP, Allocation ID or multiple filters
+// filtered by Public IP, Allocation ID or multiple filters.
+// EC2 doesn't paginate this call, so all matching addresses are
+// always returned.
 //
 // See http://goo.gl/zW7J4p for more details.
 func (ec2 *EC2) DescribeAddresses(publicIps []string, allocationIds []string, filter *Filter) (resp *DescribeAddressesResp, err error) {
@@ -774,6 +831,7 @@ func (ec2 *EC2) DiassociateAddress(publicIp, associationId string) (resp *Diasso
 type DescribeInstancesResp struct {
 	RequestId    string        `xml:"requestId"`
 	Reservations []Reservation `xml:"reservationSet>item"`
+	NextToken    string        `xml:"nextToken"` // Set when more results are available
 }
 
 // Reservation represents details about a reservation in EC2.
@@ -793,9 +851,19 @@ type Reservation struct {
 //
 // See http://goo.gl/4No7c for more details.
 func (ec2 *EC2) DescribeInstances(instIds []string, filter *Filter) (resp *DescribeInstancesResp, err error) {
+	return ec2.DescribeInstancesPage(instIds, filter, nil)
+}
+
+// DescribeInstancesPage is like DescribeInstances, but returns only the
+// page of results selected by page. EC2 doesn't allow paginating results
+// when instance ids are given.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeInstances.html for more details.
+func (ec2 *EC2) DescribeInstancesPage(instIds []string, filter *Filter, page *PageOptions) (resp *DescribeInstancesResp, err error) {
 	params := makeParams("DescribeInstances")
 	addParamsList(params, "InstanceId", instIds)
 	filter.addParams(params)
+	page.addParams(params)
 	resp = &DescribeInstancesResp{}
 	err = ec2.query(params, resp)
 	if err != nil {
@@ -814,6 +882,22 @@ func (ec2 *EC2) DescribeInstances(instIds []string, filter *Filter) (resp *Descr
 	return
 }
 
+// DescribeInstancesPages calls fn with every page of the instances
+// matching instIds and filter, until fn returns false or there are no
+// more pages. The page parameter selects the first page and the page size.
+func (ec2 *EC2) DescribeInstancesPages(instIds []string, filter *Filter, page *PageOptions, fn func(page *DescribeInstancesResp) bool) error {
+	for {
+		resp, err := ec2.DescribeInstancesPage(instIds, filter, page)
+		if err != nil {
+			return err
+		}
+		if !fn(resp) || resp.NextToken == "" {
+			return nil
+		}
+		page = page.next(resp.NextToken)
+	}
+}
+
 // ----------------------------------------------------------------------------
 // Image and snapshot management functions and types.
 
@@ -823,6 +907,7 @@ func (ec2 *EC2) DescribeInstances(instIds []string, filter *Filter) (resp *Descr
 type ImagesResp struct {
 	RequestId string  `xml:"requestId"`
 	Images    []Image `xml:"imagesSet>item"`
+	NextToken string  `xml:"nextToken"` // Set when more results are available
 }
 
 // BlockDeviceMapping represents the association of a block device with an image.
@@ -877,11 +962,21 @@ type Image struct {
 //
 // See http://goo.gl/SRBhW for more details.
 func (ec2 *EC2) Images(ids []string, filter *Filter) (resp *ImagesResp, err error) {
+	return ec2.ImagesPage(ids, filter, nil)
+}
+
+// ImagesPage is like Images, but returns only the page of results
+// selected by page. EC2 doesn't allow paginating results when image ids
+// are given.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeImages.html for more details.
+func (ec2 *EC2) ImagesPage(ids []string, filter *Filter, page *PageOptions) (resp *ImagesResp, err error) {
 	params := makeParams("DescribeImages")
 	for i, id := range ids {
 		params["ImageId."+strconv.Itoa(i+1)] = id
 	}
 	filter.addParams(params)
+	page.addParams(params)
 
 	resp = &ImagesResp{}
 	err = ec2.query(params, resp)
@@ -891,6 +986,22 @@ func (ec2 *EC2) Images(ids []string, filter *Filter) (resp *ImagesResp, err erro
 	return
 }
 
+// ImagesPages calls fn with every page of the images matching ids and
+// filter, until fn returns false or there are no more pages. The page
+// parameter selects the first page and the page size.
+func (ec2 *EC2) ImagesPages(ids []string, filter *Filter, page *PageOptions, fn func(page *ImagesResp) bool) error {
+	for {
+		resp, err := ec2.ImagesPage(ids, filter, page)
+		if err != nil {
+			return err
+		}
+		if !fn(resp) || resp.NextToken == "" {
+			return nil
+		}
+		page = page.next(resp.NextToken)
+	}
+}
+
 type CreateImageResp struct {
 	RequestId string `xml:"requestId"`
 	ImageId   string `xml:"imageId"`
@@ -988,6 +1099,7 @@ func (ec2 *EC2) DeleteSnapshots(ssid string) (resp *SimpleResp, err error) {
 type SnapshotsResp struct {
 	RequestId string     `xml:"requestId"`
 	Snapshots []Snapshot `xml:"snapshotSet>item"`
+	NextToken string     `xml:"nextToken"` // Set when more results are available
 }
 
 // Snapshot represents details about a volume snapshot.
@@ -1011,11 +1123,21 @@ type Snapshot struct {
 //
 // See http://goo.gl/ogJL4 for more details.
 func (ec2 *EC2) Snapshots(ids []string, filter *Filter) (resp *SnapshotsResp, err error) {
+	return ec2.SnapshotsPage(ids, filter, nil)
+}
+
+// SnapshotsPage is like Snapshots, but returns only the page of results
+// selected by page. EC2 doesn't allow paginating results when snapshot
+// ids are given.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeSnapshots.html for more details.
+func (ec2 *EC2) SnapshotsPage(ids []string, filter *Filter, page *PageOptions) (resp *SnapshotsResp, err error) {
 	params := makeParams("DescribeSnapshots")
 	for i, id := range ids {
 		params["SnapshotId."+strconv.Itoa(i+1)] = id
 	}
 	filter.addParams(params)
+	page.addParams(params)
 
 	resp = &SnapshotsResp{}
 	err = ec2.query(params, resp)
@@ -1025,6 +1147,22 @@ func (ec2 *EC2) Snapshots(ids []string, filter *Filter) (resp *SnapshotsResp, er
 	return
 }
 
+// SnapshotsPages calls fn with every page of the snapshots matching ids
+// and filter, until fn returns false or there are no more pages. The
+// page parameter selects the first page and the page size.
+func (ec2 *EC2) SnapshotsPages(ids []string, filter *Filter, page *PageOptions, fn func(page *SnapshotsResp) bool) error {
+	for {
+		resp, err := ec2.SnapshotsPage(ids, filter, page)
+		if err != nil {
+			return err
+		}
+		if !fn(resp) || resp.NextToken == "" {
+			return nil
+		}
+		page = page.next(resp.NextToken)
+	}
+}
+
 // DeregisterImage
 //
 type DeregisterImageResponse struct {
@@ -1342,6 +1480,7 @@ type DescribedTag struct {
 type DescribeTagsResp struct {
 	RequestId string         `xml:"requestId"`
 	Tags      []DescribedTag `xml:"tagSet>item"`
+	NextToken string         `xml:"nextToken"` // Set when more results are available
 }
 
 // DescribeTags returns tags about one or more EC2 Resources. Returned tags can
@@ -1349,8 +1488,17 @@ type DescribeTagsResp struct {
 //
 // See http://goo.gl/hgJjO7 for more details.
 func (ec2 *EC2) DescribeTags(filter *Filter) (resp *DescribeTagsResp, err error) {
+	return ec2.DescribeTagsPage(filter, nil)
+}
+
+// DescribeTagsPage is like DescribeTags, but returns only the page of
+// results selected by page.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeTags.html for more details.
+func (ec2 *EC2) DescribeTagsPage(filter *Filter, page *PageOptions) (resp *DescribeTagsResp, err error) {
 	params := makeParams("DescribeTags")
 	filter.addParams(params)
+	page.addParams(params)
 	resp = &DescribeTagsResp{}
 	err = ec2.query(params, resp)
 	if err != nil {
@@ -1359,6 +1507,22 @@ func (ec2 *EC2) DescribeTags(filter *Filter) (resp *DescribeTagsResp, err error)
 	return
 }
 
+// DescribeTagsPages calls fn with every page of the tags matching filter,
+// until fn returns false or there are no more pages. The page parameter
+// selects the first page and the page size.
+func (ec2 *EC2) DescribeTagsPages(filter *Filter, page *PageOptions, fn func(page *DescribeTagsResp) bool) error {
+	for {
+		resp, err := ec2.DescribeTagsPage(filter, page)
+		if err != nil {
+			return err
+		}
+		if !fn(resp) || resp.NextToken == "" {
+			return nil
+		}
+		page = page.next(resp.NextToken)
+	}
+}
+
 // Response to a StartInstances request.
 //
 // See http://goo.gl/awKeF for more details.
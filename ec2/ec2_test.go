@@ -92,6 +92,10 @@ func (s *S) TestRunInstancesExample(c *check.C) {
 		DisableAPITermination: true,
 		ShutdownBehavior:      "terminate",
 		PrivateIPAddress:      "10.0.0.25",
+		BlockDeviceMappings: []ec2.BlockDeviceMapping{
+			{DeviceName: "/dev/sdb", VirtualName: "ephemeral0"},
+			{DeviceName: "/dev/sdc", SnapshotId: "snap-a08912c9", VolumeType: "io1", VolumeSize: 100, IOPS: 1000, DeleteOnTermination: true},
+		},
 	}
 	resp, err := s.ec2.RunInstances(&options)
 
@@ -116,6 +120,16 @@ func (s *S) TestRunInstancesExample(c *check.C) {
 	c.Assert(req.Form["DisableApiTermination"], check.DeepEquals, []string{"true"})
 	c.Assert(req.Form["InstanceInitiatedShutdownBehavior"], check.DeepEquals, []string{"terminate"})
 	c.Assert(req.Form["PrivateIpAddress"], check.DeepEquals, []string{"10.0.0.25"})
+	c.Assert(req.Form["BlockDeviceMapping.0.DeviceName"], check.IsNil)
+	c.Assert(req.Form["BlockDeviceMapping.1.DeviceName"], check.DeepEquals, []string{"/dev/sdb"})
+	c.Assert(req.Form["BlockDeviceMapping.1.VirtualName"], check.DeepEquals, []string{"ephemeral0"})
+	c.Assert(req.Form["BlockDeviceMapping.1.Ebs.VolumeSize"], check.IsNil)
+	c.Assert(req.Form["BlockDeviceMapping.2.DeviceName"], check.DeepEquals, []string{"/dev/sdc"})
+	c.Assert(req.Form["BlockDeviceMapping.2.Ebs.SnapshotId"], check.DeepEquals, []string{"snap-a08912c9"})
+	c.Assert(req.Form["BlockDeviceMapping.2.Ebs.VolumeType"], check.DeepEquals, []string{"io1"})
+	c.Assert(req.Form["BlockDeviceMapping.2.Ebs.VolumeSize"], check.DeepEquals, []string{"100"})
+	c.Assert(req.Form["BlockDeviceMapping.2.Ebs.Iops"], check.DeepEquals, []string{"1000"})
+	c.Assert(req.Form["BlockDeviceMapping.2.Ebs.DeleteOnTermination"], check.DeepEquals, []string{"true"})
 
 	c.Assert(err, check.IsNil)
 	c.Assert(resp.RequestId, check.Equals, "59dbff89-35bd-4eac-99ed-be587EXAMPLE")
@@ -256,6 +270,40 @@ func (s *S) TestDescribeInstancesExample2(c *check.C) {
 	c.Assert(r0t1.Value, check.Equals, "Production")
 }
 
+func (s *S) TestDescribeInstancesExample3(c *check.C) {
+	testServer.Response(200, nil, DescribeInstancesExample3)
+
+	resp, err := s.ec2.DescribeInstances(nil, nil)
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.Reservations, check.HasLen, 1)
+	c.Assert(resp.Reservations[0].Instances, check.HasLen, 1)
+
+	i := resp.Reservations[0].Instances[0]
+	c.Assert(i.InstanceId, check.Equals, "i-1234567890abcdef0")
+	c.Assert(i.State, check.Equals, ec2.InstanceState{Code: 16, Name: "running"})
+	c.Assert(i.AvailabilityZone, check.Equals, "eu-west-1c")
+	c.Assert(i.Tenancy, check.Equals, "default")
+	c.Assert(i.VpcId, check.Equals, "vpc-11112222")
+	c.Assert(i.SubnetId, check.Equals, "subnet-56f5f633")
+	c.Assert(i.IPAddress, check.Equals, "54.194.252.215")
+	c.Assert(i.SecurityGroups, check.DeepEquals, []ec2.SecurityGroup{{Id: "sg-e4076980", Name: "SecurityGroup1"}})
+	c.Assert(i.BlockDevices, check.HasLen, 1)
+	c.Assert(i.BlockDevices[0].EBS.VolumeId, check.Equals, "vol-1234567890abcdef0")
+	c.Assert(i.Tags, check.DeepEquals, []ec2.Tag{{Key: "Name", Value: "Server_1"}})
+	c.Assert(i.SourceDestCheck, check.Equals, true)
+
+	c.Assert(i.NetworkInterfaces, check.HasLen, 1)
+	ni := i.NetworkInterfaces[0]
+	c.Assert(ni.Id, check.Equals, "eni-551ba033")
+	c.Assert(ni.Attachment.AttachmentID, check.Equals, "eni-attach-39697adc")
+	c.Assert(ni.Attachment.DeviceIndex, check.Equals, int32(0))
+	c.Assert(ni.Attachment.Status, check.Equals, "attached")
+	c.Assert(ni.Association.PublicIP, check.Equals, "54.194.252.215")
+	c.Assert(ni.PrivateIPAddresses, check.HasLen, 1)
+	c.Assert(ni.PrivateIPAddresses[0].PrivateIPAddress, check.Equals, "192.168.1.88")
+}
+
 func (s *S) TestDescribeAddressesPublicIPExample(c *check.C) {
 	testServer.Response(200, nil, DescribeAddressesExample)
 
@@ -570,12 +618,13 @@ func (s *S) TestDescribeSubnetsExample(c *check.C) {
 func (s *S) TestCreateSecurityGroupExample(c *check.C) {
 	testServer.Response(200, nil, CreateSecurityGroupExample)
 
-	resp, err := s.ec2.CreateSecurityGroup("websrv", "Web Servers")
+	resp, err := s.ec2.CreateSecurityGroup("websrv", "Web Servers", "")
 
 	req := testServer.WaitRequest()
 	c.Assert(req.Form["Action"], check.DeepEquals, []string{"CreateSecurityGroup"})
 	c.Assert(req.Form["GroupName"], check.DeepEquals, []string{"websrv"})
 	c.Assert(req.Form["GroupDescription"], check.DeepEquals, []string{"Web Servers"})
+	c.Assert(req.Form["VpcId"], check.IsNil)
 
 	c.Assert(err, check.IsNil)
 	c.Assert(resp.RequestId, check.Equals, "59dbff89-35bd-4eac-99ed-be587EXAMPLE")
@@ -624,6 +673,30 @@ func (s *S) TestDescribeSecurityGroupsExample(c *check.C) {
 	c.Assert(g1ipp.SourceIPs, check.IsNil)
 }
 
+func (s *S) TestDescribeSecurityGroupsExample2(c *check.C) {
+	testServer.Response(200, nil, DescribeSecurityGroupsExample2)
+
+	resp, err := s.ec2.SecurityGroups(nil, nil)
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.Groups, check.HasLen, 1)
+
+	g0 := resp.Groups[0]
+	c.Assert(g0.Id, check.Equals, "sg-1a2b3c4d")
+	c.Assert(g0.VpcId, check.Equals, "vpc-614cc409")
+	c.Assert(g0.IPPerms, check.DeepEquals, []ec2.IPPerm{{
+		Protocol:     "tcp",
+		FromPort:     80,
+		ToPort:       80,
+		SourceIPs:    []string{"203.0.113.0/24"},
+		SourceGroups: []ec2.UserSecurityGroup{{Id: "sg-2a2b3c4d", OwnerId: "123456789012"}},
+	}})
+	c.Assert(g0.IPPermsEgress, check.DeepEquals, []ec2.IPPerm{{
+		Protocol:  "-1",
+		SourceIPs: []string{"0.0.0.0/0"},
+	}})
+}
+
 func (s *S) TestDescribeSecurityGroups(c *check.C) {
 	testServer.Response(200, nil, SecurityGroupsVPCExample)
 
@@ -971,7 +1044,7 @@ func (s *S) TestSignatureWithEndpointPath(c *check.C) {
 	c.Assert(err, check.IsNil)
 
 	req := testServer.WaitRequest()
-	c.Assert(req.Form["Signature"], check.DeepEquals, []string{"VVoC6Y6xfES+KvZo+789thP8+tye4F6fOKBiKmXk4S4="})
+	c.Assert(req.Form["Signature"], check.DeepEquals, []string{"GyOtOywjGvfuycOC9UGskdnqTN8Oe5DuovtKzv2mhgo="})
 }
 
 func (s *S) TestDescribeReservedInstancesiExample(c *check.C) {
@@ -1004,3 +1077,120 @@ func (s *S) TestDeregisterImage(c *check.C) {
 	c.Assert(resp.Response, check.Equals, true)
 
 }
+
+func (s *S) TestDescribeInstancesPage(c *check.C) {
+	testServer.Response(200, nil, DescribeInstancesFirstPageExample)
+
+	filter := ec2.NewFilter()
+	filter.Add("instance-state-name", "running")
+
+	resp, err := s.ec2.DescribeInstancesPage(nil, filter, &ec2.PageOptions{MaxResults: 5, NextToken: "abc"})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeInstances"})
+	c.Assert(req.Form["MaxResults"], check.DeepEquals, []string{"5"})
+	c.Assert(req.Form["NextToken"], check.DeepEquals, []string{"abc"})
+	c.Assert(req.Form["Filter.1.Name"], check.DeepEquals, []string{"instance-state-name"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.NextToken, check.Equals, "eyJ2IjoiMiIsImMiOiJ0b2tlbiJ9")
+	c.Assert(resp.Reservations, check.HasLen, 1)
+	c.Assert(resp.Reservations[0].Instances[0].OwnerId, check.Equals, "123456789012")
+}
+
+func (s *S) TestDescribeInstancesPages(c *check.C) {
+	testServer.Response(200, nil, DescribeInstancesFirstPageExample)
+	testServer.Response(200, nil, DescribeInstancesExample1)
+
+	var ids []string
+	err := s.ec2.DescribeInstancesPages(nil, nil, &ec2.PageOptions{MaxResults: 5}, func(page *ec2.DescribeInstancesResp) bool {
+		for _, r := range page.Reservations {
+			for _, inst := range r.Instances {
+				ids = append(ids, inst.InstanceId)
+			}
+		}
+		return true
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["MaxResults"], check.DeepEquals, []string{"5"})
+	c.Assert(req.Form["NextToken"], check.IsNil)
+	req = testServer.WaitRequest()
+	c.Assert(req.Form["MaxResults"], check.DeepEquals, []string{"5"})
+	c.Assert(req.Form["NextToken"], check.DeepEquals, []string{"eyJ2IjoiMiIsImMiOiJ0b2tlbiJ9"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(ids, check.DeepEquals, []string{"i-1234567890abcdef0", "i-c5cd56af", "i-d9cd56b3"})
+}
+
+func (s *S) TestDescribeInstancesPagesStop(c *check.C) {
+	testServer.Response(200, nil, DescribeInstancesFirstPageExample)
+
+	pages := 0
+	err := s.ec2.DescribeInstancesPages(nil, nil, nil, func(page *ec2.DescribeInstancesResp) bool {
+		pages++
+		return false
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["MaxResults"], check.IsNil)
+
+	c.Assert(err, check.IsNil)
+	c.Assert(pages, check.Equals, 1)
+}
+
+func (s *S) TestImagesPages(c *check.C) {
+	testServer.Response(200, nil, DescribeImagesExample)
+
+	pages := 0
+	err := s.ec2.ImagesPages(nil, nil, &ec2.PageOptions{MaxResults: 10}, func(page *ec2.ImagesResp) bool {
+		pages++
+		c.Assert(page.Images, check.HasLen, 1)
+		return true
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeImages"})
+	c.Assert(req.Form["MaxResults"], check.DeepEquals, []string{"10"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(pages, check.Equals, 1)
+}
+
+func (s *S) TestSnapshotsPage(c *check.C) {
+	testServer.Response(200, nil, DescribeSnapshotsExample)
+
+	resp, err := s.ec2.SnapshotsPage(nil, nil, &ec2.PageOptions{MaxResults: 20, NextToken: "abc"})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeSnapshots"})
+	c.Assert(req.Form["MaxResults"], check.DeepEquals, []string{"20"})
+	c.Assert(req.Form["NextToken"], check.DeepEquals, []string{"abc"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.NextToken, check.Equals, "")
+	c.Assert(resp.Snapshots, check.HasLen, 1)
+}
+
+func (s *S) TestDescribeTagsPages(c *check.C) {
+	testServer.Response(200, nil, DescribeTagsFirstPageExample)
+	testServer.Response(200, nil, DescribeTagsExample)
+
+	var ids []string
+	err := s.ec2.DescribeTagsPages(nil, nil, func(page *ec2.DescribeTagsResp) bool {
+		for _, t := range page.Tags {
+			ids = append(ids, t.ResourceId)
+		}
+		return true
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeTags"})
+	c.Assert(req.Form["NextToken"], check.IsNil)
+	req = testServer.WaitRequest()
+	c.Assert(req.Form["NextToken"], check.DeepEquals, []string{"dGFnLXRva2Vu"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(ids, check.HasLen, 7)
+	c.Assert(ids[0], check.Equals, "i-1234567890abcdef0")
+}
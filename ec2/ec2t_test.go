@@ -1,6 +1,7 @@
 package ec2_test
 
 import (
+	"context"
 	"fmt"
 	"github.com/crowdmob/goamz/aws"
 	"github.com/crowdmob/goamz/ec2"
@@ -9,6 +10,7 @@ import (
 	"gopkg.in/check.v1"
 	"regexp"
 	"sort"
+	"time"
 )
 
 // LocalServer represents a local ec2test fake server.
@@ -79,6 +81,399 @@ func (s *LocalServerSuite) TestUserData(c *check.C) {
 	c.Assert(tinst.UserData, check.DeepEquals, data)
 }
 
+func (s *LocalServerSuite) TestDescribeInstancesPages(c *check.C) {
+	inst, err := s.ec2.RunInstances(&ec2.RunInstancesOptions{
+		MinCount:     7,
+		ImageId:      imageId,
+		InstanceType: "t1.micro",
+	})
+	c.Assert(err, check.IsNil)
+	var ids []string
+	for _, inst := range inst.Instances {
+		ids = append(ids, inst.InstanceId)
+	}
+	defer s.ec2.TerminateInstances(ids)
+
+	// Other tests share the server, so only look at our own instances.
+	filter := ec2.NewFilter()
+	filter.Add("instance-id", ids...)
+	var pages []int
+	var got []string
+	err = s.ec2.DescribeInstancesPages(nil, filter, &ec2.PageOptions{MaxResults: 5}, func(page *ec2.DescribeInstancesResp) bool {
+		n := 0
+		for _, r := range page.Reservations {
+			for _, inst := range r.Instances {
+				got = append(got, inst.InstanceId)
+				n++
+			}
+		}
+		pages = append(pages, n)
+		return true
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(pages, check.DeepEquals, []int{5, 2})
+	sort.Strings(ids)
+	sort.Strings(got)
+	c.Assert(got, check.DeepEquals, ids)
+
+	_, err = s.ec2.DescribeInstancesPage(ids[:1], nil, &ec2.PageOptions{MaxResults: 5})
+	c.Assert(err, check.ErrorMatches, `.*\(InvalidParameterCombination\)`)
+
+	_, err = s.ec2.DescribeInstancesPage(nil, nil, &ec2.PageOptions{MaxResults: 2})
+	c.Assert(err, check.ErrorMatches, `MaxResults must be between 5 and 1000, got 2 \(InvalidParameterValue\)`)
+}
+
+func (s *LocalServerSuite) runInstance(c *check.C, options *ec2.RunInstancesOptions) string {
+	if options == nil {
+		options = &ec2.RunInstancesOptions{}
+	}
+	options.ImageId = imageId
+	options.InstanceType = "t1.micro"
+	inst, err := s.ec2.RunInstances(options)
+	c.Assert(err, check.IsNil)
+	return inst.Instances[0].InstanceId
+}
+
+func (s *LocalServerSuite) TestStartStopRebootInstances(c *check.C) {
+	id := s.runInstance(c, nil)
+	defer s.ec2.TerminateInstances([]string{id})
+
+	_, err := s.ec2.RebootInstances(id)
+	c.Assert(err, check.IsNil)
+	stop, err := s.ec2.StopInstances(id)
+	c.Assert(err, check.IsNil)
+	c.Assert(stop.StateChanges, check.DeepEquals, []ec2.InstanceStateChange{{
+		InstanceId:    id,
+		PreviousState: ec2test.Pending,
+		CurrentState:  ec2test.Stopping,
+	}})
+	_, err = s.ec2.StartInstances(id)
+	c.Assert(err, check.ErrorMatches, `.*\(IncorrectInstanceState\)`)
+
+	s.srv.srv.Advance(time.Minute)
+	_, err = s.ec2.RebootInstances(id)
+	c.Assert(err, check.ErrorMatches, `.*\(IncorrectState\)`)
+
+	start, err := s.ec2.StartInstances(id)
+	c.Assert(err, check.IsNil)
+	c.Assert(start.StateChanges, check.DeepEquals, []ec2.InstanceStateChange{{
+		InstanceId:    id,
+		PreviousState: ec2test.Stopped,
+		CurrentState:  ec2test.Pending,
+	}})
+
+	s.srv.srv.Advance(time.Minute)
+	filter := ec2.NewFilter()
+	filter.Add("instance-state-name", "running")
+	resp, err := s.ec2.DescribeInstances([]string{id}, filter)
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.Reservations, check.HasLen, 1)
+	c.Assert(resp.Reservations[0].Instances[0].State, check.Equals, ec2test.Running)
+
+	_, err = s.ec2.TerminateInstances([]string{id})
+	c.Assert(err, check.IsNil)
+	_, err = s.ec2.StartInstances(id)
+	c.Assert(err, check.ErrorMatches, `.*\(IncorrectInstanceState\)`)
+	_, err = s.ec2.StopInstances("i-deadbeef")
+	c.Assert(err, check.ErrorMatches, `.*\(InvalidInstanceID\.NotFound\)`)
+}
+
+func (s *LocalServerSuite) TestVirtualClock(c *check.C) {
+	srv := s.srv.srv
+	srv.SetStateDelay(ec2test.Pending, 45*time.Second)
+	defer srv.SetStateDelay(ec2test.Pending, 30*time.Second)
+
+	// Other tests share the server, so move past their actions.
+	srv.Advance(time.Second)
+	t0 := srv.Now()
+	id := s.runInstance(c, nil)
+	defer s.ec2.TerminateInstances([]string{id})
+
+	state := func() ec2.InstanceState {
+		resp, err := s.ec2.DescribeInstances([]string{id}, nil)
+		c.Assert(err, check.IsNil)
+		return resp.Reservations[0].Instances[0].State
+	}
+	srv.Advance(44 * time.Second)
+	c.Assert(state(), check.Equals, ec2test.Pending)
+	srv.Advance(time.Second)
+	c.Assert(state(), check.Equals, ec2test.Running)
+	c.Assert(srv.Now(), check.Equals, t0.Add(45*time.Second))
+
+	actions := srv.ActionsSince(t0)
+	c.Assert(actions, check.HasLen, 3)
+	c.Assert(actions[0].Request.Get("Action"), check.Equals, "RunInstances")
+	c.Assert(actions[0].Time, check.Equals, t0)
+	c.Assert(actions[2].Time, check.Equals, t0.Add(45*time.Second))
+	c.Assert(srv.ActionsSince(t0.Add(time.Second)), check.HasLen, 2)
+	c.Assert(srv.ActionsSince(srv.Now().Add(time.Second)), check.HasLen, 0)
+
+	_, err := s.ec2.StopInstances(id)
+	c.Assert(err, check.IsNil)
+	c.Assert(state(), check.Equals, ec2test.Stopping)
+	srv.Advance(30 * time.Second)
+	c.Assert(state(), check.Equals, ec2test.Stopped)
+
+	_, err = s.ec2.TerminateInstances([]string{id})
+	c.Assert(err, check.IsNil)
+	c.Assert(state(), check.Equals, ec2test.ShuttingDown)
+	srv.Advance(30 * time.Second)
+	c.Assert(state(), check.Equals, ec2test.Terminated)
+}
+
+func (s *LocalServerSuite) TestWaitersWithVirtualClock(c *check.C) {
+	srv := s.srv.srv
+	srv.SetAutoAdvance(10 * time.Second)
+	defer srv.SetAutoAdvance(0)
+
+	id := s.runInstance(c, nil)
+	defer s.ec2.TerminateInstances([]string{id})
+
+	w := &aws.Waiter{Delay: time.Millisecond, MaxAttempts: 5}
+	t0 := srv.Now()
+	err := s.ec2.WaitUntilInstanceRunning(context.Background(), []string{id}, w)
+	c.Assert(err, check.IsNil)
+	c.Assert(srv.Now().Sub(t0) <= 40*time.Second, check.Equals, true)
+
+	_, err = s.ec2.StopInstances(id)
+	c.Assert(err, check.IsNil)
+	err = s.ec2.WaitUntilInstanceStopped(context.Background(), []string{id}, w)
+	c.Assert(err, check.IsNil)
+
+	// An instance that never finishes booting makes the waiter time out.
+	srv.SetStateDelay(ec2test.Pending, time.Hour)
+	defer srv.SetStateDelay(ec2test.Pending, 30*time.Second)
+	_, err = s.ec2.StartInstances(id)
+	c.Assert(err, check.IsNil)
+	err = s.ec2.WaitUntilInstanceRunning(context.Background(), []string{id}, w)
+	c.Assert(err, check.ErrorMatches, `waiter InstanceRunning: gave up after 5 attempts \(last state "pending"\)`)
+}
+
+func (s *LocalServerSuite) TestWaitUntilInstanceTerminatedUnknownId(c *check.C) {
+	srv := s.srv.srv
+	srv.SetAutoAdvance(10 * time.Second)
+	defer srv.SetAutoAdvance(0)
+
+	id := s.runInstance(c, nil)
+	defer s.ec2.TerminateInstances([]string{id})
+
+	// An unknown id doesn't make a running instance count as terminated.
+	w := &aws.Waiter{Delay: time.Millisecond, MaxAttempts: 10}
+	ids := []string{id, "i-00000000"}
+	err := s.ec2.WaitUntilInstanceRunning(context.Background(), ids[:1], w)
+	c.Assert(err, check.IsNil)
+	err = s.ec2.WaitUntilInstanceTerminated(context.Background(), ids, w)
+	c.Assert(err, check.ErrorMatches, `waiter InstanceTerminated: gave up after 10 attempts \(last state "running"\)`)
+
+	_, err = s.ec2.TerminateInstances([]string{id})
+	c.Assert(err, check.IsNil)
+	err = s.ec2.WaitUntilInstanceTerminated(context.Background(), ids, w)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *LocalServerSuite) TestTags(c *check.C) {
+	id := s.runInstance(c, nil)
+	defer s.ec2.TerminateInstances([]string{id})
+	vol, err := s.ec2.CreateVolume(&ec2.CreateVolumeOptions{AvailabilityZone: "us-east-1a", Size: 8})
+	c.Assert(err, check.IsNil)
+	defer s.ec2.DeleteVolume(vol.Id)
+
+	_, err = s.ec2.CreateTags([]string{id, vol.Id}, []ec2.Tag{{Key: "env", Value: "test"}})
+	c.Assert(err, check.IsNil)
+	_, err = s.ec2.CreateTags([]string{id}, []ec2.Tag{{Key: "Name", Value: "web"}})
+	c.Assert(err, check.IsNil)
+	_, err = s.ec2.CreateTags([]string{id, "vol-deadbeef"}, []ec2.Tag{{Key: "Name", Value: "db"}})
+	c.Assert(err, check.ErrorMatches, `.*\(InvalidVolume\.NotFound\)`)
+
+	filter := ec2.NewFilter()
+	filter.Add("tag:Name", "web")
+	resp, err := s.ec2.DescribeInstances(nil, filter)
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.Reservations, check.HasLen, 1)
+	c.Assert(resp.Reservations[0].Instances[0].InstanceId, check.Equals, id)
+	c.Assert(resp.Reservations[0].Instances[0].Tags, check.DeepEquals, []ec2.Tag{
+		{Key: "Name", Value: "web"},
+		{Key: "env", Value: "test"},
+	})
+
+	filter = ec2.NewFilter()
+	filter.Add("key", "env")
+	tags, err := s.ec2.DescribeTags(filter)
+	c.Assert(err, check.IsNil)
+	c.Assert(tags.Tags, check.DeepEquals, []ec2.DescribedTag{
+		{ResourceId: id, ResourceType: "instance", Key: "env", Value: "test"},
+		{ResourceId: vol.Id, ResourceType: "volume", Key: "env", Value: "test"},
+	})
+
+	filter.Add("resource-type", "volume")
+	tags, err = s.ec2.DescribeTags(filter)
+	c.Assert(err, check.IsNil)
+	c.Assert(tags.Tags, check.HasLen, 1)
+	c.Assert(tags.Tags[0].ResourceId, check.Equals, vol.Id)
+}
+
+func (s *LocalServerSuite) TestVolumesSnapshotsAndImages(c *check.C) {
+	id := s.runInstance(c, &ec2.RunInstancesOptions{AvailabilityZone: "us-east-1a"})
+	defer s.ec2.TerminateInstances([]string{id})
+
+	vol, err := s.ec2.CreateVolume(&ec2.CreateVolumeOptions{AvailabilityZone: "us-east-1b", Size: 8})
+	c.Assert(err, check.IsNil)
+	c.Assert(vol.Status, check.Equals, "available")
+	_, err = s.ec2.AttachVolume(vol.Id, id, "/dev/sdf")
+	c.Assert(err, check.ErrorMatches, `.*\(InvalidVolume\.ZoneMismatch\)`)
+	_, err = s.ec2.DeleteVolume(vol.Id)
+	c.Assert(err, check.IsNil)
+
+	vol, err = s.ec2.CreateVolume(&ec2.CreateVolumeOptions{AvailabilityZone: "us-east-1a", Size: 8})
+	c.Assert(err, check.IsNil)
+	attach, err := s.ec2.AttachVolume(vol.Id, id, "/dev/sdf")
+	c.Assert(err, check.IsNil)
+	c.Assert(attach.Status, check.Equals, "attached")
+	_, err = s.ec2.DeleteVolume(vol.Id)
+	c.Assert(err, check.ErrorMatches, `.*\(VolumeInUse\)`)
+
+	filter := ec2.NewFilter()
+	filter.Add("attachment.instance-id", id)
+	vols, err := s.ec2.DescribeVolumes(nil, filter)
+	c.Assert(err, check.IsNil)
+	c.Assert(vols.Volumes, check.HasLen, 1)
+	c.Assert(vols.Volumes[0].Status, check.Equals, "in-use")
+	c.Assert(vols.Volumes[0].Attachments[0].Device, check.Equals, "/dev/sdf")
+
+	snap, err := s.ec2.CreateSnapshot(vol.Id, "backup")
+	c.Assert(err, check.IsNil)
+	c.Assert(snap.Status, check.Equals, "completed")
+	c.Assert(snap.VolumeSize, check.Equals, "8")
+
+	img, err := s.ec2.CreateImage(id, "web-image", "web server", false)
+	c.Assert(err, check.IsNil)
+	_, err = s.ec2.CreateImage(id, "web-image", "", false)
+	c.Assert(err, check.ErrorMatches, `.*\(InvalidAMIName\.Duplicate\)`)
+
+	imgs, err := s.ec2.Images([]string{img.ImageId}, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(imgs.Images, check.HasLen, 1)
+	c.Assert(imgs.Images[0].Name, check.Equals, "web-image")
+	c.Assert(imgs.Images[0].State, check.Equals, "available")
+	c.Assert(imgs.Images[0].BlockDevices, check.HasLen, 1)
+	imageSnap := imgs.Images[0].BlockDevices[0].SnapshotId
+
+	filter = ec2.NewFilter()
+	filter.Add("volume-id", vol.Id)
+	snaps, err := s.ec2.Snapshots(nil, filter)
+	c.Assert(err, check.IsNil)
+	c.Assert(snaps.Snapshots, check.HasLen, 2)
+	c.Assert(snaps.Snapshots[0].Id, check.Equals, snap.Id)
+	c.Assert(snaps.Snapshots[1].Id, check.Equals, imageSnap)
+
+	_, err = s.ec2.DeleteSnapshots(imageSnap)
+	c.Assert(err, check.ErrorMatches, `.*\(InvalidSnapshot\.InUse\)`)
+	_, err = s.ec2.DeregisterImage(img.ImageId)
+	c.Assert(err, check.IsNil)
+	_, err = s.ec2.Images([]string{img.ImageId}, nil)
+	c.Assert(err, check.ErrorMatches, `.*\(InvalidAMIID\.NotFound\)`)
+	for _, id := range []string{snap.Id, imageSnap} {
+		_, err = s.ec2.DeleteSnapshots(id)
+		c.Assert(err, check.IsNil)
+	}
+
+	_, err = s.ec2.DetachVolume(vol.Id, "", "", false)
+	c.Assert(err, check.IsNil)
+	_, err = s.ec2.DeleteVolume(vol.Id)
+	c.Assert(err, check.IsNil)
+	_, err = s.ec2.DescribeVolumes([]string{vol.Id}, nil)
+	c.Assert(err, check.ErrorMatches, `.*\(InvalidVolume\.NotFound\)`)
+}
+
+func (s *LocalServerSuite) TestAddresses(c *check.C) {
+	id := s.runInstance(c, nil)
+	defer s.ec2.TerminateInstances([]string{id})
+
+	alloc, err := s.ec2.AllocateAddress("vpc")
+	c.Assert(err, check.IsNil)
+	c.Assert(alloc.Domain, check.Equals, "vpc")
+	c.Assert(alloc.AllocationId, check.Not(check.Equals), "")
+
+	assoc, err := s.ec2.AssociateAddress(&ec2.AssociateAddressOptions{
+		InstanceId:   id,
+		AllocationId: alloc.AllocationId,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(assoc.AssociationId, check.Not(check.Equals), "")
+
+	resp, err := s.ec2.DescribeInstances([]string{id}, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.Reservations[0].Instances[0].IPAddress, check.Equals, alloc.PublicIp)
+
+	filter := ec2.NewFilter()
+	filter.Add("instance-id", id)
+	addrs, err := s.ec2.DescribeAddresses(nil, nil, filter)
+	c.Assert(err, check.IsNil)
+	c.Assert(addrs.Addresses, check.DeepEquals, []ec2.Address{{
+		PublicIp:      alloc.PublicIp,
+		AllocationId:  alloc.AllocationId,
+		Domain:        "vpc",
+		InstanceId:    id,
+		AssociationId: assoc.AssociationId,
+	}})
+
+	_, err = s.ec2.ReleaseAddress("", alloc.AllocationId)
+	c.Assert(err, check.ErrorMatches, `.*\(InvalidIPAddress\.InUse\)`)
+
+	// Associating another address with the instance replaces the first one.
+	std, err := s.ec2.AllocateAddress("")
+	c.Assert(err, check.IsNil)
+	c.Assert(std.Domain, check.Equals, "standard")
+	_, err = s.ec2.AssociateAddress(&ec2.AssociateAddressOptions{InstanceId: id, PublicIp: std.PublicIp})
+	c.Assert(err, check.IsNil)
+	_, err = s.ec2.ReleaseAddress("", alloc.AllocationId)
+	c.Assert(err, check.IsNil)
+	_, err = s.ec2.ReleaseAddress(std.PublicIp, "")
+	c.Assert(err, check.IsNil)
+
+	addrs, err = s.ec2.DescribeAddresses(nil, nil, filter)
+	c.Assert(err, check.IsNil)
+	c.Assert(addrs.Addresses, check.HasLen, 0)
+	_, err = s.ec2.DescribeAddresses([]string{std.PublicIp}, nil, nil)
+	c.Assert(err, check.ErrorMatches, `.*\(InvalidAddress\.NotFound\)`)
+}
+
+func (s *LocalServerSuite) TestSubnets(c *check.C) {
+	sn, err := s.ec2.CreateSubnet("vpc-1a2b3c4d", "10.0.1.0/24", "us-east-1b")
+	c.Assert(err, check.IsNil)
+	c.Assert(sn.Subnet.AvailableIpAddressCount, check.Equals, 251)
+	c.Assert(sn.Subnet.AvailabilityZone, check.Equals, "us-east-1b")
+
+	_, err = s.ec2.CreateSubnet("vpc-1a2b3c4d", "10.0.1.128/25", "")
+	c.Assert(err, check.ErrorMatches, `.*\(InvalidSubnet\.Conflict\)`)
+	_, err = s.ec2.CreateSubnet("vpc-1a2b3c4d", "10.0.0.0/8", "")
+	c.Assert(err, check.ErrorMatches, `.*\(InvalidSubnet\.Range\)`)
+
+	id := s.runInstance(c, &ec2.RunInstancesOptions{SubnetId: sn.Subnet.Id})
+	resp, err := s.ec2.DescribeInstances([]string{id}, nil)
+	c.Assert(err, check.IsNil)
+	inst := resp.Reservations[0].Instances[0]
+	c.Assert(inst.SubnetId, check.Equals, sn.Subnet.Id)
+	c.Assert(inst.VpcId, check.Equals, "vpc-1a2b3c4d")
+	c.Assert(inst.AvailabilityZone, check.Equals, "us-east-1b")
+
+	filter := ec2.NewFilter()
+	filter.Add("vpc-id", "vpc-1a2b3c4d")
+	subnets, err := s.ec2.Subnets(nil, filter)
+	c.Assert(err, check.IsNil)
+	c.Assert(subnets.Subnets, check.HasLen, 1)
+	c.Assert(subnets.Subnets[0].AvailableIpAddressCount, check.Equals, 250)
+
+	_, err = s.ec2.DeleteSubnet(sn.Subnet.Id)
+	c.Assert(err, check.ErrorMatches, `.*\(DependencyViolation\)`)
+	_, err = s.ec2.TerminateInstances([]string{id})
+	c.Assert(err, check.IsNil)
+	_, err = s.ec2.DeleteSubnet(sn.Subnet.Id)
+	c.Assert(err, check.IsNil)
+	_, err = s.ec2.Subnets([]string{sn.Subnet.Id}, nil)
+	c.Assert(err, check.ErrorMatches, `.*\(InvalidSubnetID\.NotFound\)`)
+}
+
 // AmazonServerSuite runs the ec2test server tests against a live EC2 server.
 // It will only be activated if the -all flag is specified.
 type AmazonServerSuite struct {
@@ -122,7 +517,7 @@ func (s *ServerTests) makeTestGroup(c *check.C, name, descr string) ec2.Security
 		c.Fatalf("delete security group: %v", err)
 	}
 
-	resp, err := s.ec2.CreateSecurityGroup(name, descr)
+	resp, err := s.ec2.CreateSecurityGroup(name, descr, "")
 	c.Assert(err, check.IsNil)
 	c.Assert(resp.Name, check.Equals, name)
 	return resp.SecurityGroup
@@ -250,7 +645,7 @@ func (s *ServerTests) TestDuplicateIPPerm(c *check.C) {
 	s.ec2.DeleteSecurityGroup(ec2.SecurityGroup{Name: name})
 	defer s.ec2.DeleteSecurityGroup(ec2.SecurityGroup{Name: name})
 
-	resp1, err := s.ec2.CreateSecurityGroup(name, descr)
+	resp1, err := s.ec2.CreateSecurityGroup(name, descr, "")
 	c.Assert(err, check.IsNil)
 	c.Assert(resp1.Name, check.Equals, name)
 
@@ -279,12 +674,12 @@ type filterSpec struct {
 }
 
 func (s *ServerTests) TestInstanceFiltering(c *check.C) {
-	groupResp, err := s.ec2.CreateSecurityGroup(sessionName("testgroup1"), "testgroup one description")
+	groupResp, err := s.ec2.CreateSecurityGroup(sessionName("testgroup1"), "testgroup one description", "")
 	c.Assert(err, check.IsNil)
 	group1 := groupResp.SecurityGroup
 	defer s.ec2.DeleteSecurityGroup(group1)
 
-	groupResp, err = s.ec2.CreateSecurityGroup(sessionName("testgroup2"), "testgroup two description")
+	groupResp, err = s.ec2.CreateSecurityGroup(sessionName("testgroup2"), "testgroup two description", "")
 	c.Assert(err, check.IsNil)
 	group2 := groupResp.SecurityGroup
 	defer s.ec2.DeleteSecurityGroup(group2)
@@ -437,7 +832,7 @@ func namesOnly(gs []ec2.SecurityGroup) []ec2.SecurityGroup {
 func (s *ServerTests) TestGroupFiltering(c *check.C) {
 	g := make([]ec2.SecurityGroup, 4)
 	for i := range g {
-		resp, err := s.ec2.CreateSecurityGroup(sessionName(fmt.Sprintf("testgroup%d", i)), fmt.Sprintf("testdescription%d", i))
+		resp, err := s.ec2.CreateSecurityGroup(sessionName(fmt.Sprintf("testgroup%d", i)), fmt.Sprintf("testdescription%d", i), "")
 		c.Assert(err, check.IsNil)
 		g[i] = resp.SecurityGroup
 		c.Logf("group %d: %v", i, g[i])
@@ -0,0 +1,208 @@
+package ec2test
+
+import (
+	"fmt"
+	"github.com/crowdmob/goamz/ec2"
+	"net/http"
+	"sort"
+)
+
+// address holds a simulated ec2 elastic IP address. Only VPC
+// addresses have an allocation id and association ids.
+type address struct {
+	seq           int
+	publicIp      string
+	allocationId  string
+	domain        string
+	inst          *Instance
+	associationId string
+}
+
+func (a *address) ec2address() ec2.Address {
+	ea := ec2.Address{
+		PublicIp:      a.publicIp,
+		AllocationId:  a.allocationId,
+		Domain:        a.domain,
+		AssociationId: a.associationId,
+	}
+	if a.inst != nil {
+		ea.InstanceId = a.inst.id
+	}
+	return ea
+}
+
+func (a *address) matchAttr(attr, value string) (ok bool, err error) {
+	switch attr {
+	case "public-ip":
+		return a.publicIp == value, nil
+	case "allocation-id":
+		return a.allocationId == value, nil
+	case "association-id":
+		return a.associationId != "" && a.associationId == value, nil
+	case "domain":
+		return a.domain == value, nil
+	case "instance-id":
+		return a.inst != nil && a.inst.id == value, nil
+	}
+	return false, fmt.Errorf("unknown attribute %q", attr)
+}
+
+// disassociate removes any association of a.
+func (a *address) disassociate() {
+	if a.inst != nil {
+		a.inst.publicIp = ""
+	}
+	a.inst = nil
+	a.associationId = ""
+}
+
+// address returns the address with the given public ip or allocation
+// id, whichever is set. It calls fatalf if there is no such address.
+func (srv *Server) address(publicIp, allocationId string) *address {
+	if publicIp != "" {
+		if a := srv.addresses[publicIp]; a != nil {
+			return a
+		}
+		fatalf(400, "InvalidAddress.NotFound", "address %q not found", publicIp)
+	}
+	if allocationId == "" {
+		fatalf(400, "MissingParameter", "either public ip or allocation id must be specified")
+	}
+	for _, a := range srv.addresses {
+		if a.allocationId == allocationId {
+			return a
+		}
+	}
+	fatalf(400, "InvalidAllocationID.NotFound", "allocation id %q not found", allocationId)
+	panic("not reached")
+}
+
+// allocateAddress implements the EC2 AllocateAddress entry point.
+// Addresses are allocated from the 203.0.113.0/24 documentation range.
+func (srv *Server) allocateAddress(w http.ResponseWriter, req *http.Request, reqId string) interface{} {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	domain := req.Form.Get("Domain")
+	switch domain {
+	case "":
+		domain = "standard"
+	case "standard", "vpc":
+	default:
+		fatalf(400, "InvalidParameterValue", "invalid domain %q", domain)
+	}
+	n := srv.addressId.next()
+	if n >= 254 {
+		fatalf(400, "AddressLimitExceeded", "too many addresses allocated")
+	}
+	a := &address{
+		seq:      n,
+		publicIp: fmt.Sprintf("203.0.113.%d", n+1),
+		domain:   domain,
+	}
+	if domain == "vpc" {
+		a.allocationId = fmt.Sprintf("eipalloc-%d", n)
+	}
+	srv.addresses[a.publicIp] = a
+	return &ec2.AllocateAddressResp{
+		RequestId:    reqId,
+		PublicIp:     a.publicIp,
+		Domain:       a.domain,
+		AllocationId: a.allocationId,
+	}
+}
+
+// associateAddress implements the EC2 AssociateAddress entry point.
+// Network interfaces are not simulated, so an instance id is required.
+// Any address already associated with the instance is disassociated.
+func (srv *Server) associateAddress(w http.ResponseWriter, req *http.Request, reqId string) interface{} {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	a := srv.address(req.Form.Get("PublicIp"), req.Form.Get("AllocationId"))
+	id := req.Form.Get("InstanceId")
+	if id == "" {
+		fatalf(400, "MissingParameter", "the request must contain the parameter InstanceId")
+	}
+	inst := srv.instances[id]
+	if inst == nil {
+		fatalf(400, "InvalidInstanceID.NotFound", "no such instance id %q", id)
+	}
+	if !inst.alive() {
+		fatalf(400, "IncorrectInstanceState", "instance %q is not in a valid state for this operation", id)
+	}
+	if a.domain == "vpc" && a.inst != nil && a.inst != inst && req.Form.Get("AllowReassociation") != "true" {
+		fatalf(400, "Resource.AlreadyAssociated", "address %s is already associated with %s", a.publicIp, a.inst.id)
+	}
+	for _, other := range srv.addresses {
+		if other.inst == inst {
+			other.disassociate()
+		}
+	}
+	a.disassociate()
+	a.inst = inst
+	inst.publicIp = a.publicIp
+	if a.domain == "vpc" {
+		a.associationId = fmt.Sprintf("eipassoc-%d", a.seq)
+	}
+	return &ec2.AssociateAddressResp{
+		RequestId:     reqId,
+		Return:        true,
+		AssociationId: a.associationId,
+	}
+}
+
+// describeAddresses implements the EC2 DescribeAddresses entry point.
+func (srv *Server) describeAddresses(w http.ResponseWriter, req *http.Request, reqId string) interface{} {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	addrs := make(map[*address]bool)
+	for _, ip := range formList(req.Form, "PublicIp") {
+		addrs[srv.address(ip, "")] = true
+	}
+	for _, id := range formList(req.Form, "AllocationId") {
+		addrs[srv.address("", id)] = true
+	}
+
+	f := newFilter(req.Form)
+	var matches []*address
+	for _, a := range srv.addresses {
+		if len(addrs) > 0 && !addrs[a] {
+			continue
+		}
+		ok, err := f.ok(a)
+		if ok {
+			matches = append(matches, a)
+		} else if err != nil {
+			fatalf(400, "InvalidParameterValue", "describe addresses: %v", err)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].seq < matches[j].seq })
+
+	var resp ec2.DescribeAddressesResp
+	resp.RequestId = reqId
+	for _, a := range matches {
+		resp.Addresses = append(resp.Addresses, a.ec2address())
+	}
+	return &resp
+}
+
+// releaseAddress implements the EC2 ReleaseAddress entry point.
+// As in EC2, standard addresses are disassociated automatically
+// but VPC addresses must be disassociated first.
+func (srv *Server) releaseAddress(w http.ResponseWriter, req *http.Request, reqId string) interface{} {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	a := srv.address(req.Form.Get("PublicIp"), req.Form.Get("AllocationId"))
+	if a.domain == "vpc" && a.inst != nil {
+		fatalf(400, "InvalidIPAddress.InUse", "address %s is in use by %s", a.publicIp, a.inst.id)
+	}
+	a.disassociate()
+	delete(srv.addresses, a.publicIp)
+	return &ec2.ReleaseAddressResp{
+		RequestId: reqId,
+		Return:    true,
+	}
+}
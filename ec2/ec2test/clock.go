@@ -0,0 +1,99 @@
+package ec2test
+
+import (
+	"github.com/crowdmob/goamz/ec2"
+	"sort"
+	"time"
+)
+
+// epoch is the virtual time at which every server starts.
+var epoch = time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// nextState maps each transitional instance state to the
+// state the instance moves to when the transition is over.
+var nextState = map[ec2.InstanceState]ec2.InstanceState{
+	Pending:      Running,
+	Stopping:     Stopped,
+	ShuttingDown: Terminated,
+}
+
+// defaultDelays holds how long instances stay in each
+// transitional state unless changed with SetStateDelay.
+var defaultDelays = map[ec2.InstanceState]time.Duration{
+	Pending:      30 * time.Second,
+	Stopping:     30 * time.Second,
+	ShuttingDown: 30 * time.Second,
+}
+
+// Now returns the current virtual time of the server. The virtual
+// clock only moves when Advance is called, or after every request
+// when SetAutoAdvance has been used.
+func (srv *Server) Now() time.Time {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return srv.clock
+}
+
+// Advance moves the virtual clock forward by d, making any
+// instance state transitions that fall due in that time.
+func (srv *Server) Advance(d time.Duration) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if d < 0 {
+		panic("ec2test: cannot move the clock backwards")
+	}
+	srv.clock = srv.clock.Add(d)
+	srv.tick()
+}
+
+// SetAutoAdvance makes the virtual clock move forward by d after
+// every request, so that clients polling the server, such as the
+// ec2 waiters, see time pass without the test driving the clock.
+// A zero duration turns automatic advancing off.
+func (srv *Server) SetAutoAdvance(d time.Duration) {
+	srv.mu.Lock()
+	srv.autoAdvance = d
+	srv.mu.Unlock()
+}
+
+func (srv *Server) autoAdvanceDuration() time.Duration {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return srv.autoAdvance
+}
+
+// SetStateDelay sets how long, in virtual time, instances stay in the
+// given transitional state (Pending, Stopping or ShuttingDown) before
+// moving on to Running, Stopped or Terminated respectively. The new
+// delay applies to instances entering the state from now on. A zero
+// delay completes the transition before the next request.
+func (srv *Server) SetStateDelay(state ec2.InstanceState, d time.Duration) {
+	if _, ok := nextState[state]; !ok {
+		panic("ec2test: " + state.Name + " is not a transitional state")
+	}
+	srv.mu.Lock()
+	srv.delays[state] = d
+	srv.mu.Unlock()
+}
+
+// ActionsSince returns the actions received at or after
+// the virtual time t, in the order they were received.
+func (srv *Server) ActionsSince(t time.Time) []*Action {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	i := sort.Search(len(srv.reqs), func(i int) bool {
+		return !srv.reqs[i].Time.Before(t)
+	})
+	return append([]*Action(nil), srv.reqs[i:]...)
+}
+
+// tick makes every instance state transition that is due
+// at the current virtual time. It must be called with
+// srv.mu held.
+func (srv *Server) tick() {
+	for _, inst := range srv.instances {
+		if next, ok := nextState[inst.state]; ok && !inst.due.After(srv.clock) {
+			srv.setState(inst, next)
+		}
+	}
+}
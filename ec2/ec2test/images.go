@@ -0,0 +1,179 @@
+package ec2test
+
+import (
+	"fmt"
+	"github.com/crowdmob/goamz/ec2"
+	"net/http"
+	"sort"
+)
+
+// image holds a simulated ec2 machine image.
+type image struct {
+	id           string
+	name         string
+	description  string
+	state        string
+	instanceId   string // the instance the image was created from
+	blockDevices []ec2.BlockDeviceMapping
+	tags         tags
+}
+
+func (img *image) ec2image() ec2.Image {
+	return ec2.Image{
+		Id:                 img.id,
+		Name:               img.name,
+		Description:        img.description,
+		Type:               "machine",
+		State:              img.state,
+		Location:           ownerId + "/" + img.name,
+		Architecture:       "i386",
+		OwnerId:            ownerId,
+		RootDeviceType:     "ebs",
+		RootDeviceName:     "/dev/sda1",
+		VirtualizationType: "hvm",
+		Hypervisor:         "xen",
+		BlockDevices:       img.blockDevices,
+		Tags:               img.tags.ec2Tags(),
+	}
+}
+
+func (img *image) matchAttr(attr, value string) (ok bool, err error) {
+	switch attr {
+	case "image-id":
+		return img.id == value, nil
+	case "name":
+		return img.name == value, nil
+	case "description":
+		return img.description == value, nil
+	case "state":
+		return img.state == value, nil
+	case "owner-id":
+		return value == ownerId, nil
+	case "architecture":
+		return value == "i386", nil
+	case "image-type":
+		return value == "machine", nil
+	case "is-public":
+		return value == "false", nil
+	case "root-device-type":
+		return value == "ebs", nil
+	case "block-device-mapping.snapshot-id":
+		for _, bd := range img.blockDevices {
+			if bd.SnapshotId == value {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return img.tags.matchAttr(attr, value)
+}
+
+// createImage implements the EC2 CreateImage entry point. A snapshot
+// is taken of every volume attached to the instance, and the new image
+// is available immediately.
+func (srv *Server) createImage(w http.ResponseWriter, req *http.Request, reqId string) interface{} {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	id := req.Form.Get("InstanceId")
+	inst := srv.instances[id]
+	if inst == nil {
+		fatalf(400, "InvalidInstanceID.NotFound", "no such instance id %q", id)
+	}
+	if !inst.alive() {
+		fatalf(400, "IncorrectInstanceState", "instance %q is not in a state from which an image can be created", id)
+	}
+	name := req.Form.Get("Name")
+	if name == "" {
+		fatalf(400, "MissingParameter", "image name is required")
+	}
+	for _, img := range srv.images {
+		if img.name == name {
+			fatalf(400, "InvalidAMIName.Duplicate", "AMI name %q is already in use by %s", name, img.id)
+		}
+	}
+
+	img := &image{
+		id:          fmt.Sprintf("ami-%d", srv.imageId.next()),
+		name:        name,
+		description: req.Form.Get("Description"),
+		state:       "available",
+		instanceId:  inst.id,
+		tags:        make(tags),
+	}
+	for _, v := range srv.instanceVolumes(inst) {
+		snap := srv.newSnapshot(v, fmt.Sprintf("Created by CreateImage(%s) for %s from %s", inst.id, img.id, v.id))
+		img.blockDevices = append(img.blockDevices, ec2.BlockDeviceMapping{
+			DeviceName: v.attachment.device,
+			SnapshotId: snap.id,
+			VolumeType: v.volType,
+			VolumeSize: v.size,
+		})
+	}
+	srv.images[img.id] = img
+	return &ec2.CreateImageResp{
+		RequestId: reqId,
+		ImageId:   img.id,
+	}
+}
+
+// describeImages implements the EC2 DescribeImages entry point.
+// Only images created through the server are known to it.
+func (srv *Server) describeImages(w http.ResponseWriter, req *http.Request, reqId string) interface{} {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	ids := formList(req.Form, "ImageId")
+	if len(ids) > 0 && req.Form.Get("MaxResults") != "" {
+		fatalf(400, "InvalidParameterCombination", "the parameter imagesSet cannot be used with the parameter maxResults")
+	}
+	imgs := make(map[string]*image)
+	for _, id := range ids {
+		img := srv.images[id]
+		if img == nil {
+			fatalf(400, "InvalidAMIID.NotFound", "the image id %q does not exist", id)
+		}
+		imgs[id] = img
+	}
+	if len(ids) == 0 {
+		imgs = srv.images
+	}
+
+	f := newFilter(req.Form)
+	var matches []*image
+	for _, img := range imgs {
+		ok, err := f.ok(img)
+		if ok {
+			matches = append(matches, img)
+		} else if err != nil {
+			fatalf(400, "InvalidParameterValue", "describe images: %v", err)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].id < matches[j].id })
+
+	var resp ec2.ImagesResp
+	resp.RequestId = reqId
+	start, end, nextToken := paginate(req, len(matches))
+	resp.NextToken = nextToken
+	for _, img := range matches[start:end] {
+		resp.Images = append(resp.Images, img.ec2image())
+	}
+	return &resp
+}
+
+// deregisterImage implements the EC2 DeregisterImage entry point.
+// As in EC2, the snapshots of the image are left behind.
+func (srv *Server) deregisterImage(w http.ResponseWriter, req *http.Request, reqId string) interface{} {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	id := req.Form.Get("ImageId")
+	if srv.images[id] == nil {
+		fatalf(400, "InvalidAMIID.NotFound", "the image id %q does not exist", id)
+	}
+	delete(srv.images, id)
+	return &ec2.DeregisterImageResponse{
+		RequestId: reqId,
+		Response:  true,
+	}
+}
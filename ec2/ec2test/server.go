@@ -14,9 +14,11 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 var b64 = base64.StdEncoding
@@ -25,6 +27,9 @@ var b64 = base64.StdEncoding
 type Action struct {
 	RequestId string
 
+	// Time holds the virtual time at which the request was received.
+	Time time.Time
+
 	// Request holds the requested action as a url.Values instance
 	Request url.Values
 
@@ -36,10 +41,6 @@ type Action struct {
 	Err *ec2.Error
 }
 
-// TODO possible other things:
-// - some virtual time stamp interface, so a client
-// can ask for all actions after a certain virtual time.
-
 // Server implements an EC2 simulator for use in testing.
 type Server struct {
 	url      string
@@ -50,11 +51,25 @@ type Server struct {
 	instances            map[string]*Instance      // id -> instance
 	reservations         map[string]*reservation   // id -> reservation
 	groups               map[string]*securityGroup // id -> group
+	images               map[string]*image         // id -> image
+	volumes              map[string]*volume        // id -> volume
+	snapshots            map[string]*snapshot      // id -> snapshot
+	addresses            map[string]*address       // public ip -> address
+	subnets              map[string]*subnet        // id -> subnet
 	maxId                counter
 	reqId                counter
 	reservationId        counter
 	groupId              counter
+	imageId              counter
+	volumeId             counter
+	snapshotId           counter
+	addressId            counter
+	subnetId             counter
 	initialInstanceState ec2.InstanceState
+
+	clock       time.Time                           // the current virtual time
+	autoAdvance time.Duration                       // added to clock after every request
+	delays      map[ec2.InstanceState]time.Duration // transitional state -> duration
 }
 
 // reservation holds a simulated ec2 reservation.
@@ -74,6 +89,12 @@ type Instance struct {
 	reservation *reservation
 	instType    string
 	state       ec2.InstanceState
+	due         time.Time // when a transitional state ends
+	launchTime  time.Time
+	zone        string
+	subnet      *subnet
+	publicIp    string
+	tags        tags
 }
 
 // permKey represents permission for a given security
@@ -199,17 +220,42 @@ var actions = map[string]func(*Server, http.ResponseWriter, *http.Request, strin
 	"DeleteSecurityGroup":           (*Server).deleteSecurityGroup,
 	"AuthorizeSecurityGroupIngress": (*Server).authorizeSecurityGroupIngress,
 	"RevokeSecurityGroupIngress":    (*Server).revokeSecurityGroupIngress,
+	"StartInstances":                (*Server).startInstances,
+	"StopInstances":                 (*Server).stopInstances,
+	"RebootInstances":               (*Server).rebootInstances,
+	"CreateTags":                    (*Server).createTags,
+	"DescribeTags":                  (*Server).describeTags,
+	"CreateImage":                   (*Server).createImage,
+	"DescribeImages":                (*Server).describeImages,
+	"DeregisterImage":               (*Server).deregisterImage,
+	"CreateVolume":                  (*Server).createVolume,
+	"DescribeVolumes":               (*Server).describeVolumes,
+	"AttachVolume":                  (*Server).attachVolume,
+	"DetachVolume":                  (*Server).detachVolume,
+	"DeleteVolume":                  (*Server).deleteVolume,
+	"CreateSnapshot":                (*Server).createSnapshot,
+	"DescribeSnapshots":             (*Server).describeSnapshots,
+	"DeleteSnapshot":                (*Server).deleteSnapshot,
+	"AllocateAddress":               (*Server).allocateAddress,
+	"AssociateAddress":              (*Server).associateAddress,
+	"DescribeAddresses":             (*Server).describeAddresses,
+	"ReleaseAddress":                (*Server).releaseAddress,
+	"CreateSubnet":                  (*Server).createSubnet,
+	"DescribeSubnets":               (*Server).describeSubnets,
+	"DeleteSubnet":                  (*Server).deleteSubnet,
 }
 
 const ownerId = "9876"
 
 // newAction allocates a new action and adds it to the
-// recorded list of server actions.
+// recorded list of server actions. Any state transitions
+// that are due are made first.
 func (srv *Server) newAction() *Action {
 	srv.mu.Lock()
 	defer srv.mu.Unlock()
 
-	a := new(Action)
+	srv.tick()
+	a := &Action{Time: srv.clock}
 	srv.reqs = append(srv.reqs, a)
 	return a
 }
@@ -220,7 +266,17 @@ func NewServer() (*Server, error) {
 		instances:            make(map[string]*Instance),
 		groups:               make(map[string]*securityGroup),
 		reservations:         make(map[string]*reservation),
+		images:               make(map[string]*image),
+		volumes:              make(map[string]*volume),
+		snapshots:            make(map[string]*snapshot),
+		addresses:            make(map[string]*address),
+		subnets:              make(map[string]*subnet),
 		initialInstanceState: Pending,
+		clock:                epoch,
+		delays:               make(map[ec2.InstanceState]time.Duration),
+	}
+	for state, d := range defaultDelays {
+		srv.delays[state] = d
 	}
 
 	// Add default security group.
@@ -272,7 +328,9 @@ func (srv *Server) Quit() {
 	srv.listener.Close()
 }
 
-// SetInitialInstanceState sets the state that any new instances will be started in.
+// SetInitialInstanceState sets the state that any new instances will be
+// started in. Instances started in a transitional state move on once
+// the delay for that state has passed.
 func (srv *Server) SetInitialInstanceState(state ec2.InstanceState) {
 	srv.mu.Lock()
 	srv.initialInstanceState = state
@@ -302,6 +360,7 @@ func (srv *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
 		case *ec2.Error:
 			a.Err = err
 			err.RequestId = a.RequestId
+			srv.Advance(srv.autoAdvanceDuration())
 			writeError(w, err)
 		case nil:
 		default:
@@ -317,6 +376,10 @@ func (srv *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
 	response := f(srv, w, req, a.RequestId)
 	a.Response = response
 
+	// Advance the clock before replying so that the
+	// client's next request sees the new time.
+	srv.Advance(srv.autoAdvanceDuration())
+
 	w.Header().Set("Content-Type", `xml version="1.0" encoding="UTF-8"`)
 	xmlMarshal(w, response)
 }
@@ -422,10 +485,8 @@ func (srv *Server) runInstances(w http.ResponseWriter, req *http.Request, reqId
 	//    InstanceType              ?
 	//    KernelId                  ?
 	//    RamdiskId                 ?
-	//    AvailabilityZone          ?
 	//    GroupName                 tag
 	//    Monitoring                ignore?
-	//    SubnetId                  ?
 	//    DisableAPITermination     bool
 	//    ShutdownBehavior          string
 	//    PrivateIPAddress          string
@@ -436,6 +497,21 @@ func (srv *Server) runInstances(w http.ResponseWriter, req *http.Request, reqId
 	// make sure that form fields are correct before creating the reservation.
 	instType := req.Form.Get("InstanceType")
 	imageId := req.Form.Get("ImageId")
+	zone := req.Form.Get("Placement.AvailabilityZone")
+	var sn *subnet
+	if id := req.Form.Get("SubnetId"); id != "" {
+		sn = srv.subnets[id]
+		if sn == nil {
+			fatalf(400, "InvalidSubnetID.NotFound", "subnet %q not found", id)
+		}
+		if zone != "" && zone != sn.zone {
+			fatalf(400, "InvalidParameterCombination", "subnet %q is not in availability zone %q", id, zone)
+		}
+		if sn.availableIps() < max {
+			fatalf(400, "InsufficientFreeAddressesInSubnet", "subnet %q has too few free addresses", id)
+		}
+		zone = sn.zone
+	}
 
 	r := srv.newReservation(srv.formToGroups(req.Form))
 
@@ -447,6 +523,8 @@ func (srv *Server) runInstances(w http.ResponseWriter, req *http.Request, reqId
 	for i := 0; i < max; i++ {
 		inst := srv.newInstance(r, instType, imageId, srv.initialInstanceState)
 		inst.UserData = userData
+		inst.zone = zone
+		inst.subnet = sn
 		resp.Instances = append(resp.Instances, inst.ec2instance())
 	}
 	return &resp
@@ -494,9 +572,11 @@ func (srv *Server) newInstance(r *reservation, instType string, imageId string,
 		id:          fmt.Sprintf("i-%d", srv.maxId.next()),
 		instType:    instType,
 		imageId:     imageId,
-		state:       state,
 		reservation: r,
+		launchTime:  srv.clock,
+		tags:        make(tags),
 	}
+	srv.setState(inst, state)
 	srv.instances[inst.id] = inst
 	r.instances[inst.id] = inst
 	return inst
@@ -530,27 +610,124 @@ func (srv *Server) terminateInstances(w http.ResponseWriter, req *http.Request,
 		}
 	}
 	for _, inst := range insts {
-		resp.StateChanges = append(resp.StateChanges, inst.terminate())
+		resp.StateChanges = append(resp.StateChanges, srv.setState(inst, ShuttingDown))
 	}
 	return &resp
 }
 
-func (inst *Instance) terminate() (d ec2.InstanceStateChange) {
+// instancesFromForm returns the instances listed in the InstanceId.N
+// parameters of form. It calls fatalf if an instance is not found.
+func (srv *Server) instancesFromForm(form url.Values) []*Instance {
+	var insts []*Instance
+	for _, id := range formList(form, "InstanceId") {
+		inst := srv.instances[id]
+		if inst == nil {
+			fatalf(400, "InvalidInstanceID.NotFound", "no such instance id %q", id)
+		}
+		insts = append(insts, inst)
+	}
+	return insts
+}
+
+// startInstances implements the EC2 StartInstances entry point.
+// Stopped instances become pending; others are left alone.
+func (srv *Server) startInstances(w http.ResponseWriter, req *http.Request, reqId string) interface{} {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	insts := srv.instancesFromForm(req.Form)
+	for _, inst := range insts {
+		if !inst.alive() || inst.state == Stopping {
+			fatalf(400, "IncorrectInstanceState", "instance %q is not in a state from which it can be started", inst.id)
+		}
+	}
+	var resp ec2.StartInstanceResp
+	resp.RequestId = reqId
+	for _, inst := range insts {
+		state := inst.state
+		if state == Stopped {
+			state = Pending
+		}
+		resp.StateChanges = append(resp.StateChanges, srv.setState(inst, state))
+	}
+	return &resp
+}
+
+// stopInstances implements the EC2 StopInstances entry point.
+// Pending and running instances start stopping.
+func (srv *Server) stopInstances(w http.ResponseWriter, req *http.Request, reqId string) interface{} {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	insts := srv.instancesFromForm(req.Form)
+	for _, inst := range insts {
+		if !inst.alive() {
+			fatalf(400, "IncorrectInstanceState", "instance %q is not in a state from which it can be stopped", inst.id)
+		}
+	}
+	var resp ec2.StopInstanceResp
+	resp.RequestId = reqId
+	for _, inst := range insts {
+		state := inst.state
+		if state == Pending || state == Running {
+			state = Stopping
+		}
+		resp.StateChanges = append(resp.StateChanges, srv.setState(inst, state))
+	}
+	return &resp
+}
+
+// rebootInstances implements the EC2 RebootInstances entry point.
+// Rebooting does not change the state of an instance.
+func (srv *Server) rebootInstances(w http.ResponseWriter, req *http.Request, reqId string) interface{} {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	for _, inst := range srv.instancesFromForm(req.Form) {
+		if inst.state != Pending && inst.state != Running {
+			fatalf(400, "IncorrectState", "instance %q is not running", inst.id)
+		}
+	}
+	return &ec2.SimpleResp{
+		XMLName:   xml.Name{Local: "RebootInstancesResponse"},
+		RequestId: reqId,
+	}
+}
+
+// setState moves inst to the given state and returns the
+// resulting state change. If the state is transitional, the
+// instance is scheduled to move on once its delay has passed.
+func (srv *Server) setState(inst *Instance, state ec2.InstanceState) (d ec2.InstanceStateChange) {
 	d.PreviousState = inst.state
-	inst.state = ShuttingDown
+	if state != inst.state {
+		inst.state = state
+		inst.due = srv.clock.Add(srv.delays[state])
+	}
 	d.CurrentState = inst.state
 	d.InstanceId = inst.id
 	return d
 }
 
+// alive reports whether inst has not been terminated.
+func (inst *Instance) alive() bool {
+	return inst.state != ShuttingDown && inst.state != Terminated
+}
+
 func (inst *Instance) ec2instance() ec2.Instance {
-	return ec2.Instance{
-		InstanceId:   inst.id,
-		InstanceType: inst.instType,
-		ImageId:      inst.imageId,
-		DNSName:      fmt.Sprintf("%s.example.com", inst.id),
+	i := ec2.Instance{
+		InstanceId:       inst.id,
+		InstanceType:     inst.instType,
+		ImageId:          inst.imageId,
+		State:            inst.state,
+		LaunchTime:       inst.launchTime.Format(time.RFC3339),
+		AvailabilityZone: inst.zone,
+		DNSName:          fmt.Sprintf("%s.example.com", inst.id),
+		IPAddress:        inst.publicIp,
+		Tags:             inst.tags.ec2Tags(),
 		// TODO the rest
 	}
+	if inst.subnet != nil {
+		i.SubnetId = inst.subnet.id
+		i.VpcId = inst.subnet.vpcId
+	}
+	return i
 }
 
 func (inst *Instance) matchAttr(attr, value string) (ok bool, err error) {
@@ -583,16 +760,27 @@ func (inst *Instance) matchAttr(attr, value string) (ok bool, err error) {
 		return code&0xff == inst.state.Code, nil
 	case "instance-state-name":
 		return value == inst.state.Name, nil
+	case "instance-type":
+		return value == inst.instType, nil
+	case "availability-zone":
+		return value == inst.zone, nil
+	case "ip-address":
+		return value == inst.publicIp, nil
+	case "subnet-id":
+		return inst.subnet != nil && value == inst.subnet.id, nil
+	case "vpc-id":
+		return inst.subnet != nil && value == inst.subnet.vpcId, nil
 	}
-	return false, fmt.Errorf("unknown attribute %q", attr)
+	return inst.tags.matchAttr(attr, value)
 }
 
 var (
-	Pending      = ec2.InstanceState{0, "pending"}
-	Running      = ec2.InstanceState{16, "running"}
-	ShuttingDown = ec2.InstanceState{32, "shutting-down"}
-	Terminated   = ec2.InstanceState{16, "terminated"}
-	Stopped      = ec2.InstanceState{16, "stopped"}
+	Pending      = ec2.InstanceState{Code: 0, Name: "pending"}
+	Running      = ec2.InstanceState{Code: 16, Name: "running"}
+	ShuttingDown = ec2.InstanceState{Code: 32, Name: "shutting-down"}
+	Terminated   = ec2.InstanceState{Code: 48, Name: "terminated"}
+	Stopping     = ec2.InstanceState{Code: 64, Name: "stopping"}
+	Stopped      = ec2.InstanceState{Code: 80, Name: "stopped"}
 )
 
 func (srv *Server) createSecurityGroup(w http.ResponseWriter, req *http.Request, reqId string) interface{} {
@@ -648,35 +836,55 @@ func (srv *Server) describeInstances(w http.ResponseWriter, req *http.Request, r
 		insts[inst] = true
 	}
 
+	if len(insts) > 0 && req.Form.Get("MaxResults") != "" {
+		fatalf(400, "InvalidParameterCombination", "the parameter instancesSet cannot be used with the parameter maxResults")
+	}
+
 	f := newFilter(req.Form)
 
-	var resp ec2.DescribeInstancesResp
-	resp.RequestId = reqId
+	var matches []*Instance
 	for _, r := range srv.reservations {
-		var instances []ec2.Instance
 		for _, inst := range r.instances {
 			if len(insts) > 0 && !insts[inst] {
 				continue
 			}
 			ok, err := f.ok(inst)
 			if ok {
-				instances = append(instances, inst.ec2instance())
+				matches = append(matches, inst)
 			} else if err != nil {
 				fatalf(400, "InvalidParameterValue", "describe instances: %v", err)
 			}
 		}
-		if len(instances) > 0 {
-			var groups []ec2.SecurityGroup
-			for _, g := range r.groups {
-				groups = append(groups, g.ec2SecurityGroup())
-			}
-			resp.Reservations = append(resp.Reservations, ec2.Reservation{
-				ReservationId:  r.id,
-				OwnerId:        ownerId,
-				Instances:      instances,
-				SecurityGroups: groups,
-			})
+	}
+	// Order the results so that pages are stable across requests.
+	sort.Slice(matches, func(i, j int) bool {
+		ri, rj := matches[i].reservation.id, matches[j].reservation.id
+		if ri != rj {
+			return ri < rj
 		}
+		return matches[i].id < matches[j].id
+	})
+
+	var resp ec2.DescribeInstancesResp
+	resp.RequestId = reqId
+	start, end, nextToken := paginate(req, len(matches))
+	resp.NextToken = nextToken
+	for _, inst := range matches[start:end] {
+		r := inst.reservation
+		if n := len(resp.Reservations); n > 0 && resp.Reservations[n-1].ReservationId == r.id {
+			resp.Reservations[n-1].Instances = append(resp.Reservations[n-1].Instances, inst.ec2instance())
+			continue
+		}
+		var groups []ec2.SecurityGroup
+		for _, g := range r.groups {
+			groups = append(groups, g.ec2SecurityGroup())
+		}
+		resp.Reservations = append(resp.Reservations, ec2.Reservation{
+			ReservationId:  r.id,
+			OwnerId:        ownerId,
+			Instances:      []ec2.Instance{inst.ec2instance()},
+			SecurityGroups: groups,
+		})
 	}
 	return &resp
 }
@@ -974,6 +1182,19 @@ func (c *counter) next() (i int) {
 	return
 }
 
+// formList returns the values of the list parameter name
+// (name.1, name.2, ...) in form, in index order.
+func formList(form url.Values, name string) []string {
+	var vals []string
+	for i := 1; ; i++ {
+		v, ok := form[name+"."+strconv.Itoa(i)]
+		if !ok {
+			return vals
+		}
+		vals = append(vals, v[0])
+	}
+}
+
 // atoi is like strconv.Atoi but is fatal if the
 // string is not well formed.
 func atoi(s string) int {
@@ -984,6 +1205,31 @@ func atoi(s string) int {
 	return i
 }
 
+// paginate returns the range of the n results selected by the MaxResults
+// and NextToken parameters of req, and the NextToken of the following
+// page, if any. Tokens hold the offset of the first result of a page.
+func paginate(req *http.Request, n int) (start, end int, nextToken string) {
+	if token := req.Form.Get("NextToken"); token != "" {
+		var err error
+		start, err = strconv.Atoi(token)
+		if err != nil || start < 0 || start > n {
+			fatalf(400, "InvalidParameterValue", "invalid NextToken %q", token)
+		}
+	}
+	end = n
+	if max := req.Form.Get("MaxResults"); max != "" {
+		size := atoi(max)
+		if size < 5 || size > 1000 {
+			fatalf(400, "InvalidParameterValue", "MaxResults must be between 5 and 1000, got %d", size)
+		}
+		if start+size < n {
+			end = start + size
+			nextToken = strconv.Itoa(end)
+		}
+	}
+	return
+}
+
 func fatalf(statusCode int, code string, f string, a ...interface{}) {
 	panic(&ec2.Error{
 		StatusCode: statusCode,
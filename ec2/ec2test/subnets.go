@@ -0,0 +1,183 @@
+package ec2test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"github.com/crowdmob/goamz/ec2"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// defaultZone is the availability zone of subnets
+// created without one.
+const defaultZone = "us-east-1a"
+
+// subnet holds a simulated ec2 VPC subnet. VPCs themselves are not
+// simulated, so any VPC id is accepted.
+type subnet struct {
+	id    string
+	vpcId string
+	cidr  *net.IPNet
+	zone  string
+	tags  tags
+	srv   *Server
+}
+
+// availableIps returns the number of unused addresses in sn.
+// As in EC2, five addresses of every subnet are reserved.
+func (sn *subnet) availableIps() int {
+	ones, bits := sn.cidr.Mask.Size()
+	n := 1<<uint(bits-ones) - 5
+	for _, inst := range sn.srv.instances {
+		if inst.subnet == sn && inst.alive() {
+			n--
+		}
+	}
+	return n
+}
+
+func (sn *subnet) ec2subnet() ec2.Subnet {
+	return ec2.Subnet{
+		Id:                      sn.id,
+		State:                   "available",
+		VpcId:                   sn.vpcId,
+		CidrBlock:               sn.cidr.String(),
+		AvailableIpAddressCount: sn.availableIps(),
+		AvailabilityZone:        sn.zone,
+		Tags:                    sn.tags.ec2Tags(),
+	}
+}
+
+func (sn *subnet) matchAttr(attr, value string) (ok bool, err error) {
+	switch attr {
+	case "subnet-id":
+		return sn.id == value, nil
+	case "vpc-id":
+		return sn.vpcId == value, nil
+	case "cidr", "cidr-block", "cidrBlock":
+		return sn.cidr.String() == value, nil
+	case "availability-zone", "availabilityZone":
+		return sn.zone == value, nil
+	case "state":
+		return value == "available", nil
+	case "available-ip-address-count":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return false, err
+		}
+		return sn.availableIps() == n, nil
+	case "default-for-az", "defaultForAz":
+		return value == "false", nil
+	}
+	return sn.tags.matchAttr(attr, value)
+}
+
+// overlaps reports whether the address ranges of a and b overlap.
+func overlaps(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
+
+// createSubnet implements the EC2 CreateSubnet entry point.
+func (srv *Server) createSubnet(w http.ResponseWriter, req *http.Request, reqId string) interface{} {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	vpcId := req.Form.Get("VpcId")
+	if vpcId == "" {
+		fatalf(400, "MissingParameter", "the request must contain the parameter VpcId")
+	}
+	block := req.Form.Get("CidrBlock")
+	ip, cidr, err := net.ParseCIDR(block)
+	if err != nil || ip.To4() == nil {
+		fatalf(400, "InvalidParameterValue", "value (%s) for parameter cidrBlock is invalid", block)
+	}
+	if !bytes.Equal(ip.To4(), cidr.IP.To4()) {
+		fatalf(400, "InvalidParameterValue", "value (%s) for parameter cidrBlock is invalid: it is not a network address", block)
+	}
+	if ones, _ := cidr.Mask.Size(); ones < 16 || ones > 28 {
+		fatalf(400, "InvalidSubnet.Range", "the CIDR %q is invalid", block)
+	}
+	for _, other := range srv.subnets {
+		if other.vpcId == vpcId && overlaps(other.cidr, cidr) {
+			fatalf(400, "InvalidSubnet.Conflict", "the CIDR %q conflicts with another subnet", block)
+		}
+	}
+	sn := &subnet{
+		id:    fmt.Sprintf("subnet-%d", srv.subnetId.next()),
+		vpcId: vpcId,
+		cidr:  cidr,
+		zone:  req.Form.Get("AvailabilityZone"),
+		tags:  make(tags),
+		srv:   srv,
+	}
+	if sn.zone == "" {
+		sn.zone = defaultZone
+	}
+	srv.subnets[sn.id] = sn
+	return &ec2.CreateSubnetResp{
+		RequestId: reqId,
+		Subnet:    sn.ec2subnet(),
+	}
+}
+
+// describeSubnets implements the EC2 DescribeSubnets entry point.
+func (srv *Server) describeSubnets(w http.ResponseWriter, req *http.Request, reqId string) interface{} {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	subnets := make(map[string]*subnet)
+	for _, id := range formList(req.Form, "SubnetId") {
+		sn := srv.subnets[id]
+		if sn == nil {
+			fatalf(400, "InvalidSubnetID.NotFound", "the subnet ID %q does not exist", id)
+		}
+		subnets[id] = sn
+	}
+	if len(subnets) == 0 {
+		subnets = srv.subnets
+	}
+
+	f := newFilter(req.Form)
+	var matches []*subnet
+	for _, sn := range subnets {
+		ok, err := f.ok(sn)
+		if ok {
+			matches = append(matches, sn)
+		} else if err != nil {
+			fatalf(400, "InvalidParameterValue", "describe subnets: %v", err)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].id < matches[j].id })
+
+	var resp ec2.SubnetsResp
+	resp.RequestId = reqId
+	for _, sn := range matches {
+		resp.Subnets = append(resp.Subnets, sn.ec2subnet())
+	}
+	return &resp
+}
+
+// deleteSubnet implements the EC2 DeleteSubnet entry point.
+func (srv *Server) deleteSubnet(w http.ResponseWriter, req *http.Request, reqId string) interface{} {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	id := req.Form.Get("SubnetId")
+	sn := srv.subnets[id]
+	if sn == nil {
+		fatalf(400, "InvalidSubnetID.NotFound", "the subnet ID %q does not exist", id)
+	}
+	for _, inst := range srv.instances {
+		if inst.subnet == sn && inst.alive() {
+			fatalf(400, "DependencyViolation", "the subnet %q has dependencies and cannot be deleted", id)
+		}
+	}
+	delete(srv.subnets, id)
+	return &ec2.SimpleResp{
+		XMLName:   xml.Name{Local: "DeleteSubnetResponse"},
+		RequestId: reqId,
+	}
+}
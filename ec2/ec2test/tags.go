@@ -0,0 +1,196 @@
+package ec2test
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/crowdmob/goamz/ec2"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// tags holds the tags of a simulated resource, mapping
+// each key to its value.
+type tags map[string]string
+
+// ec2Tags returns the tags sorted by key.
+func (t tags) ec2Tags() []ec2.Tag {
+	var result []ec2.Tag
+	for k, v := range t {
+		result = append(result, ec2.Tag{Key: k, Value: v})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
+
+// matchAttr implements the tag filters shared by all taggable
+// resources: tag:<key>, tag-key and tag-value. Resources call it
+// for any attribute they don't recognise themselves.
+func (t tags) matchAttr(attr, value string) (ok bool, err error) {
+	switch {
+	case strings.HasPrefix(attr, "tag:"):
+		v, ok := t[attr[len("tag:"):]]
+		return ok && v == value, nil
+	case attr == "tag-key":
+		_, ok := t[value]
+		return ok, nil
+	case attr == "tag-value":
+		for _, v := range t {
+			if v == value {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("unknown attribute %q", attr)
+}
+
+// resourceTags returns the tags and the resource type of the
+// resource with the given id. It calls fatalf if there is no such
+// resource.
+func (srv *Server) resourceTags(id string) (resourceType string, t tags) {
+	if inst := srv.instances[id]; inst != nil {
+		return "instance", inst.tags
+	}
+	if img := srv.images[id]; img != nil {
+		return "image", img.tags
+	}
+	if v := srv.volumes[id]; v != nil {
+		return "volume", v.tags
+	}
+	if snap := srv.snapshots[id]; snap != nil {
+		return "snapshot", snap.tags
+	}
+	if sn := srv.subnets[id]; sn != nil {
+		return "subnet", sn.tags
+	}
+	switch {
+	case strings.HasPrefix(id, "i-"):
+		fatalf(400, "InvalidInstanceID.NotFound", "no such instance id %q", id)
+	case strings.HasPrefix(id, "ami-"):
+		fatalf(400, "InvalidAMIID.NotFound", "no such image id %q", id)
+	case strings.HasPrefix(id, "vol-"):
+		fatalf(400, "InvalidVolume.NotFound", "no such volume id %q", id)
+	case strings.HasPrefix(id, "snap-"):
+		fatalf(400, "InvalidSnapshot.NotFound", "no such snapshot id %q", id)
+	case strings.HasPrefix(id, "subnet-"):
+		fatalf(400, "InvalidSubnetID.NotFound", "no such subnet id %q", id)
+	}
+	fatalf(400, "InvalidID", "the ID %q is not valid", id)
+	panic("not reached")
+}
+
+// createTags implements the EC2 CreateTags entry point.
+func (srv *Server) createTags(w http.ResponseWriter, req *http.Request, reqId string) interface{} {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	ids := formList(req.Form, "ResourceId")
+	if len(ids) == 0 {
+		fatalf(400, "MissingParameter", "no resource ids given")
+	}
+	newTags := make(tags)
+	for i := 1; ; i++ {
+		prefix := "Tag." + strconv.Itoa(i) + "."
+		key, ok := req.Form[prefix+"Key"]
+		if !ok {
+			break
+		}
+		if key[0] == "" || strings.HasPrefix(key[0], "aws:") {
+			fatalf(400, "InvalidParameterValue", "invalid tag key %q", key[0])
+		}
+		newTags[key[0]] = req.Form.Get(prefix + "Value")
+	}
+	// Check every resource before tagging any of them.
+	var all []tags
+	for _, id := range ids {
+		_, t := srv.resourceTags(id)
+		all = append(all, t)
+	}
+	for _, t := range all {
+		for k, v := range newTags {
+			t[k] = v
+		}
+	}
+	return &ec2.SimpleResp{
+		XMLName:   xml.Name{Local: "CreateTagsResponse"},
+		RequestId: reqId,
+	}
+}
+
+// describedTag is a single resource tag as seen by DescribeTags.
+type describedTag ec2.DescribedTag
+
+func (t *describedTag) matchAttr(attr, value string) (ok bool, err error) {
+	switch attr {
+	case "key":
+		return t.Key == value, nil
+	case "value":
+		return t.Value == value, nil
+	case "resource-id":
+		return t.ResourceId == value, nil
+	case "resource-type":
+		return t.ResourceType == value, nil
+	}
+	return false, fmt.Errorf("unknown attribute %q", attr)
+}
+
+// describeTags implements the EC2 DescribeTags entry point.
+func (srv *Server) describeTags(w http.ResponseWriter, req *http.Request, reqId string) interface{} {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	var all []*describedTag
+	add := func(resourceType, id string, t tags) {
+		for _, tag := range t.ec2Tags() {
+			all = append(all, &describedTag{
+				ResourceId:   id,
+				ResourceType: resourceType,
+				Key:          tag.Key,
+				Value:        tag.Value,
+			})
+		}
+	}
+	for id, inst := range srv.instances {
+		add("instance", id, inst.tags)
+	}
+	for id, img := range srv.images {
+		add("image", id, img.tags)
+	}
+	for id, v := range srv.volumes {
+		add("volume", id, v.tags)
+	}
+	for id, snap := range srv.snapshots {
+		add("snapshot", id, snap.tags)
+	}
+	for id, sn := range srv.subnets {
+		add("subnet", id, sn.tags)
+	}
+
+	f := newFilter(req.Form)
+	var matches []*describedTag
+	for _, t := range all {
+		ok, err := f.ok(t)
+		if ok {
+			matches = append(matches, t)
+		} else if err != nil {
+			fatalf(400, "InvalidParameterValue", "describe tags: %v", err)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].ResourceId != matches[j].ResourceId {
+			return matches[i].ResourceId < matches[j].ResourceId
+		}
+		return matches[i].Key < matches[j].Key
+	})
+
+	var resp ec2.DescribeTagsResp
+	resp.RequestId = reqId
+	start, end, nextToken := paginate(req, len(matches))
+	resp.NextToken = nextToken
+	for _, t := range matches[start:end] {
+		resp.Tags = append(resp.Tags, ec2.DescribedTag(*t))
+	}
+	return &resp
+}
@@ -0,0 +1,432 @@
+package ec2test
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/crowdmob/goamz/ec2"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// volume holds a simulated ec2 EBS volume.
+type volume struct {
+	id         string
+	size       int64
+	snapshotId string
+	zone       string
+	volType    string
+	iops       int64
+	encrypted  bool
+	status     string
+	createTime time.Time
+	attachment *volumeAttachment
+	tags       tags
+}
+
+// volumeAttachment holds the attachment of a simulated
+// volume to an instance.
+type volumeAttachment struct {
+	inst       *Instance
+	device     string
+	attachTime time.Time
+}
+
+func (v *volume) ec2volume() ec2.Volume {
+	ev := ec2.Volume{
+		Id:               v.id,
+		Size:             v.size,
+		SnapshotId:       v.snapshotId,
+		AvailabilityZone: v.zone,
+		Status:           v.status,
+		CreateTime:       v.createTime.Format(time.RFC3339),
+		VolumeType:       v.volType,
+		IOPS:             v.iops,
+		Encrypted:        v.encrypted,
+		Tags:             v.tags.ec2Tags(),
+	}
+	if v.attachment != nil {
+		ev.Attachments = []ec2.VolumeAttachment{v.ec2attachment("attached")}
+	}
+	return ev
+}
+
+func (v *volume) ec2attachment(status string) ec2.VolumeAttachment {
+	return ec2.VolumeAttachment{
+		VolumeId:   v.id,
+		InstanceId: v.attachment.inst.id,
+		Device:     v.attachment.device,
+		Status:     status,
+		AttachTime: v.attachment.attachTime.Format(time.RFC3339),
+	}
+}
+
+func (v *volume) matchAttr(attr, value string) (ok bool, err error) {
+	switch attr {
+	case "volume-id":
+		return v.id == value, nil
+	case "status":
+		return v.status == value, nil
+	case "size":
+		size, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false, err
+		}
+		return v.size == size, nil
+	case "snapshot-id":
+		return v.snapshotId == value, nil
+	case "availability-zone":
+		return v.zone == value, nil
+	case "volume-type":
+		return v.volType == value, nil
+	case "encrypted":
+		return strconv.FormatBool(v.encrypted) == value, nil
+	case "attachment.instance-id":
+		return v.attachment != nil && v.attachment.inst.id == value, nil
+	case "attachment.device":
+		return v.attachment != nil && v.attachment.device == value, nil
+	case "attachment.status":
+		return v.attachment != nil && value == "attached", nil
+	}
+	return v.tags.matchAttr(attr, value)
+}
+
+// volume returns the volume with the given id.
+// It calls fatalf if there is no such volume.
+func (srv *Server) volume(id string) *volume {
+	v := srv.volumes[id]
+	if v == nil {
+		fatalf(400, "InvalidVolume.NotFound", "the volume %q does not exist", id)
+	}
+	return v
+}
+
+// instanceVolumes returns the volumes attached to inst,
+// ordered by device name.
+func (srv *Server) instanceVolumes(inst *Instance) []*volume {
+	var vols []*volume
+	for _, v := range srv.volumes {
+		if v.attachment != nil && v.attachment.inst == inst {
+			vols = append(vols, v)
+		}
+	}
+	sort.Slice(vols, func(i, j int) bool { return vols[i].attachment.device < vols[j].attachment.device })
+	return vols
+}
+
+// createVolume implements the EC2 CreateVolume entry point.
+func (srv *Server) createVolume(w http.ResponseWriter, req *http.Request, reqId string) interface{} {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	v := &volume{
+		zone:       req.Form.Get("AvailabilityZone"),
+		snapshotId: req.Form.Get("SnapshotId"),
+		volType:    req.Form.Get("VolumeType"),
+		encrypted:  req.Form.Get("Encrypted") == "true",
+		status:     "available",
+		createTime: srv.clock,
+		tags:       make(tags),
+	}
+	if v.zone == "" {
+		fatalf(400, "MissingParameter", "the request must contain the parameter AvailabilityZone")
+	}
+	if s := req.Form.Get("Size"); s != "" {
+		v.size = int64(atoi(s))
+	}
+	if v.snapshotId != "" {
+		snap := srv.snapshots[v.snapshotId]
+		if snap == nil {
+			fatalf(400, "InvalidSnapshot.NotFound", "the snapshot %q does not exist", v.snapshotId)
+		}
+		if v.size == 0 {
+			v.size = snap.volumeSize
+		} else if v.size < snap.volumeSize {
+			fatalf(400, "InvalidParameterValue", "volume of %d GiB is smaller than snapshot %s (%d GiB)", v.size, snap.id, snap.volumeSize)
+		}
+	}
+	if v.size <= 0 {
+		fatalf(400, "MissingParameter", "the request must contain the parameter size or snapshotId")
+	}
+	if v.volType == "" {
+		v.volType = "standard"
+	}
+	if v.volType == "io1" {
+		if s := req.Form.Get("Iops"); s != "" {
+			v.iops = int64(atoi(s))
+		}
+		if v.iops <= 0 {
+			fatalf(400, "InvalidParameterCombination", "the parameter iops must be specified for io1 volumes")
+		}
+	}
+	v.id = fmt.Sprintf("vol-%d", srv.volumeId.next())
+	srv.volumes[v.id] = v
+	return &ec2.CreateVolumeResp{
+		RequestId: reqId,
+		Volume:    v.ec2volume(),
+	}
+}
+
+// describeVolumes implements the EC2 DescribeVolumes entry point.
+func (srv *Server) describeVolumes(w http.ResponseWriter, req *http.Request, reqId string) interface{} {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	vols := make(map[string]*volume)
+	for _, id := range formList(req.Form, "VolumeId") {
+		vols[id] = srv.volume(id)
+	}
+	if len(vols) == 0 {
+		vols = srv.volumes
+	}
+
+	f := newFilter(req.Form)
+	var matches []*volume
+	for _, v := range vols {
+		ok, err := f.ok(v)
+		if ok {
+			matches = append(matches, v)
+		} else if err != nil {
+			fatalf(400, "InvalidParameterValue", "describe volumes: %v", err)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].id < matches[j].id })
+
+	var resp ec2.VolumesResp
+	resp.RequestId = reqId
+	for _, v := range matches {
+		resp.Volumes = append(resp.Volumes, v.ec2volume())
+	}
+	return &resp
+}
+
+// attachVolume implements the EC2 AttachVolume entry point.
+func (srv *Server) attachVolume(w http.ResponseWriter, req *http.Request, reqId string) interface{} {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	v := srv.volume(req.Form.Get("VolumeId"))
+	id := req.Form.Get("InstanceId")
+	inst := srv.instances[id]
+	if inst == nil {
+		fatalf(400, "InvalidInstanceID.NotFound", "no such instance id %q", id)
+	}
+	device := req.Form.Get("Device")
+	if device == "" {
+		fatalf(400, "MissingParameter", "the request must contain the parameter device")
+	}
+	if v.attachment != nil {
+		fatalf(400, "VolumeInUse", "%s is already attached to an instance", v.id)
+	}
+	if !inst.alive() {
+		fatalf(400, "IncorrectState", "instance %q is not running", inst.id)
+	}
+	if inst.zone != "" && inst.zone != v.zone {
+		fatalf(400, "InvalidVolume.ZoneMismatch", "volume %s is not in the same availability zone as instance %s", v.id, inst.id)
+	}
+	for _, other := range srv.instanceVolumes(inst) {
+		if other.attachment.device == device {
+			fatalf(400, "InvalidParameterValue", "device %s is already in use on instance %s", device, inst.id)
+		}
+	}
+	v.attachment = &volumeAttachment{
+		inst:       inst,
+		device:     device,
+		attachTime: srv.clock,
+	}
+	v.status = "in-use"
+	return &ec2.VolumeAttachmentResp{
+		RequestId:        reqId,
+		VolumeAttachment: v.ec2attachment("attached"),
+	}
+}
+
+// detachVolume implements the EC2 DetachVolume entry point.
+func (srv *Server) detachVolume(w http.ResponseWriter, req *http.Request, reqId string) interface{} {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	v := srv.volume(req.Form.Get("VolumeId"))
+	if v.attachment == nil {
+		fatalf(400, "IncorrectState", "volume %q is in the 'available' state", v.id)
+	}
+	if id := req.Form.Get("InstanceId"); id != "" && id != v.attachment.inst.id {
+		fatalf(400, "InvalidAttachment.NotFound", "volume %s is not attached to instance %s", v.id, id)
+	}
+	if device := req.Form.Get("Device"); device != "" && device != v.attachment.device {
+		fatalf(400, "InvalidAttachment.NotFound", "volume %s is not attached at %s", v.id, device)
+	}
+	resp := &ec2.VolumeAttachmentResp{
+		RequestId:        reqId,
+		VolumeAttachment: v.ec2attachment("detached"),
+	}
+	v.attachment = nil
+	v.status = "available"
+	return resp
+}
+
+// deleteVolume implements the EC2 DeleteVolume entry point.
+func (srv *Server) deleteVolume(w http.ResponseWriter, req *http.Request, reqId string) interface{} {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	v := srv.volume(req.Form.Get("VolumeId"))
+	if v.attachment != nil {
+		fatalf(400, "VolumeInUse", "volume %s is currently attached to %s", v.id, v.attachment.inst.id)
+	}
+	delete(srv.volumes, v.id)
+	return &ec2.SimpleResp{
+		XMLName:   xml.Name{Local: "DeleteVolumeResponse"},
+		RequestId: reqId,
+	}
+}
+
+// snapshot holds a simulated ec2 EBS snapshot.
+type snapshot struct {
+	id          string
+	volumeId    string
+	volumeSize  int64
+	description string
+	status      string
+	startTime   time.Time
+	tags        tags
+}
+
+func (snap *snapshot) ec2snapshot() ec2.Snapshot {
+	return ec2.Snapshot{
+		Id:          snap.id,
+		VolumeId:    snap.volumeId,
+		VolumeSize:  strconv.FormatInt(snap.volumeSize, 10),
+		Status:      snap.status,
+		StartTime:   snap.startTime.Format(time.RFC3339),
+		Description: snap.description,
+		Progress:    "100%",
+		OwnerId:     ownerId,
+		Tags:        snap.tags.ec2Tags(),
+	}
+}
+
+func (snap *snapshot) matchAttr(attr, value string) (ok bool, err error) {
+	switch attr {
+	case "snapshot-id":
+		return snap.id == value, nil
+	case "volume-id":
+		return snap.volumeId == value, nil
+	case "volume-size":
+		size, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false, err
+		}
+		return snap.volumeSize == size, nil
+	case "status":
+		return snap.status == value, nil
+	case "description":
+		return snap.description == value, nil
+	case "owner-id":
+		return value == ownerId, nil
+	case "progress":
+		return value == "100%", nil
+	}
+	return snap.tags.matchAttr(attr, value)
+}
+
+// newSnapshot takes a snapshot of v. The snapshot
+// is completed immediately.
+func (srv *Server) newSnapshot(v *volume, description string) *snapshot {
+	snap := &snapshot{
+		id:          fmt.Sprintf("snap-%d", srv.snapshotId.next()),
+		volumeId:    v.id,
+		volumeSize:  v.size,
+		description: description,
+		status:      "completed",
+		startTime:   srv.clock,
+		tags:        make(tags),
+	}
+	srv.snapshots[snap.id] = snap
+	return snap
+}
+
+// createSnapshot implements the EC2 CreateSnapshot entry point.
+func (srv *Server) createSnapshot(w http.ResponseWriter, req *http.Request, reqId string) interface{} {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	v := srv.volume(req.Form.Get("VolumeId"))
+	snap := srv.newSnapshot(v, req.Form.Get("Description"))
+	return &ec2.CreateSnapshotResp{
+		RequestId: reqId,
+		Snapshot:  snap.ec2snapshot(),
+	}
+}
+
+// describeSnapshots implements the EC2 DescribeSnapshots entry point.
+func (srv *Server) describeSnapshots(w http.ResponseWriter, req *http.Request, reqId string) interface{} {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	ids := formList(req.Form, "SnapshotId")
+	if len(ids) > 0 && req.Form.Get("MaxResults") != "" {
+		fatalf(400, "InvalidParameterCombination", "the parameter snapshotSet cannot be used with the parameter maxResults")
+	}
+	snaps := make(map[string]*snapshot)
+	for _, id := range ids {
+		snap := srv.snapshots[id]
+		if snap == nil {
+			fatalf(400, "InvalidSnapshot.NotFound", "the snapshot %q does not exist", id)
+		}
+		snaps[id] = snap
+	}
+	if len(ids) == 0 {
+		snaps = srv.snapshots
+	}
+
+	f := newFilter(req.Form)
+	var matches []*snapshot
+	for _, snap := range snaps {
+		ok, err := f.ok(snap)
+		if ok {
+			matches = append(matches, snap)
+		} else if err != nil {
+			fatalf(400, "InvalidParameterValue", "describe snapshots: %v", err)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].id < matches[j].id })
+
+	var resp ec2.SnapshotsResp
+	resp.RequestId = reqId
+	start, end, nextToken := paginate(req, len(matches))
+	resp.NextToken = nextToken
+	for _, snap := range matches[start:end] {
+		resp.Snapshots = append(resp.Snapshots, snap.ec2snapshot())
+	}
+	return &resp
+}
+
+// deleteSnapshot implements the EC2 DeleteSnapshot entry point.
+// Snapshots backing a registered image cannot be deleted.
+func (srv *Server) deleteSnapshot(w http.ResponseWriter, req *http.Request, reqId string) interface{} {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	id := req.Form.Get("SnapshotId")
+	if id == "" {
+		// ec2.DeleteSnapshots sends the id as a list.
+		id = req.Form.Get("SnapshotId.1")
+	}
+	if srv.snapshots[id] == nil {
+		fatalf(400, "InvalidSnapshot.NotFound", "the snapshot %q does not exist", id)
+	}
+	for _, img := range srv.images {
+		for _, bd := range img.blockDevices {
+			if bd.SnapshotId == id {
+				fatalf(400, "InvalidSnapshot.InUse", "the snapshot %s is currently in use by %s", id, img.id)
+			}
+		}
+	}
+	delete(srv.snapshots, id)
+	return &ec2.SimpleResp{
+		XMLName:   xml.Name{Local: "DeleteSnapshotResponse"},
+		RequestId: reqId,
+	}
+}
@@ -0,0 +1,139 @@
+package ec2
+
+import (
+	"strconv"
+)
+
+// ----------------------------------------------------------------------------
+// Instance attribute and console output functions and types.
+
+// Response to a DescribeInstanceAttribute request. Only the field for
+// the requested attribute is set.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeInstanceAttribute.html for more details.
+type InstanceAttributeResp struct {
+	RequestId             string          `xml:"requestId"`
+	InstanceId            string          `xml:"instanceId"`
+	InstanceType          string          `xml:"instanceType>value"`
+	KernelId              string          `xml:"kernel>value"`
+	RamdiskId             string          `xml:"ramdisk>value"`
+	UserData              string          `xml:"userData>value"` // Decoded from base64
+	DisableAPITermination bool            `xml:"disableApiTermination>value"`
+	ShutdownBehavior      string          `xml:"instanceInitiatedShutdownBehavior>value"`
+	RootDeviceName        string          `xml:"rootDeviceName>value"`
+	BlockDevices          []BlockDevice   `xml:"blockDeviceMapping>item"`
+	SourceDestCheck       bool            `xml:"sourceDestCheck>value"`
+	SecurityGroups        []SecurityGroup `xml:"groupSet>item"`
+	EbsOptimized          bool            `xml:"ebsOptimized>value"`
+}
+
+// DescribeInstanceAttribute returns the value of a single attribute of an
+// instance. Valid attributes are instanceType, kernel, ramdisk, userData,
+// disableApiTermination, instanceInitiatedShutdownBehavior,
+// rootDeviceName, blockDeviceMapping, sourceDestCheck, groupSet and
+// ebsOptimized.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeInstanceAttribute.html for more details.
+func (ec2 *EC2) DescribeInstanceAttribute(instId, attribute string) (resp *InstanceAttributeResp, err error) {
+	params := makeParams("DescribeInstanceAttribute")
+	params["InstanceId"] = instId
+	params["Attribute"] = attribute
+
+	resp = &InstanceAttributeResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.UserData != "" {
+		userData, err := b64.DecodeString(resp.UserData)
+		if err != nil {
+			return nil, err
+		}
+		resp.UserData = string(userData)
+	}
+	return
+}
+
+// The ModifyInstanceAttributeOptions type holds the attribute to change
+// in a ModifyInstanceAttribute request. EC2 accepts a single attribute
+// per request, so set only one of the fields. Changing InstanceType or
+// UserData requires the instance to be stopped. Only the Id of
+// SecurityGroups is used.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_ModifyInstanceAttribute.html for more details.
+type ModifyInstanceAttributeOptions struct {
+	InstanceType          string
+	UserData              []byte
+	DisableAPITermination *bool
+	ShutdownBehavior      string
+	SourceDestCheck       *bool
+	SecurityGroups        []SecurityGroup
+	EbsOptimized          *bool
+}
+
+// ModifyInstanceAttribute changes an attribute of an instance.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_ModifyInstanceAttribute.html for more details.
+func (ec2 *EC2) ModifyInstanceAttribute(instId string, options *ModifyInstanceAttributeOptions) (resp *SimpleResp, err error) {
+	params := makeParams("ModifyInstanceAttribute")
+	params["InstanceId"] = instId
+	if options.InstanceType != "" {
+		params["InstanceType.Value"] = options.InstanceType
+	}
+	if options.UserData != nil {
+		params["UserData.Value"] = b64.EncodeToString(options.UserData)
+	}
+	if options.DisableAPITermination != nil {
+		params["DisableApiTermination.Value"] = strconv.FormatBool(*options.DisableAPITermination)
+	}
+	if options.ShutdownBehavior != "" {
+		params["InstanceInitiatedShutdownBehavior.Value"] = options.ShutdownBehavior
+	}
+	if options.SourceDestCheck != nil {
+		params["SourceDestCheck.Value"] = strconv.FormatBool(*options.SourceDestCheck)
+	}
+	for i, g := range options.SecurityGroups {
+		params["GroupId."+strconv.Itoa(i+1)] = g.Id
+	}
+	if options.EbsOptimized != nil {
+		params["EbsOptimized.Value"] = strconv.FormatBool(*options.EbsOptimized)
+	}
+
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Response to a GetConsoleOutput request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_GetConsoleOutput.html for more details.
+type ConsoleOutputResp struct {
+	RequestId  string `xml:"requestId"`
+	InstanceId string `xml:"instanceId"`
+	Timestamp  string `xml:"timestamp"` // The time the output was last updated
+	Output     string `xml:"output"`    // Decoded from base64
+}
+
+// GetConsoleOutput returns the console output of an instance. EC2 only
+// keeps the most recent 64 KB of output.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_GetConsoleOutput.html for more details.
+func (ec2 *EC2) GetConsoleOutput(instId string) (resp *ConsoleOutputResp, err error) {
+	params := makeParams("GetConsoleOutput")
+	params["InstanceId"] = instId
+
+	resp = &ConsoleOutputResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	output, err := b64.DecodeString(resp.Output)
+	if err != nil {
+		return nil, err
+	}
+	resp.Output = string(output)
+	return
+}
@@ -0,0 +1,95 @@
+package ec2_test
+
+import (
+	"github.com/crowdmob/goamz/ec2"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestDescribeInstanceAttributeExample(c *check.C) {
+	testServer.Response(200, nil, DescribeInstanceAttributeExample)
+
+	resp, err := s.ec2.DescribeInstanceAttribute("i-1234567890abcdef0", "groupSet")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeInstanceAttribute"})
+	c.Assert(req.Form["InstanceId"], check.DeepEquals, []string{"i-1234567890abcdef0"})
+	c.Assert(req.Form["Attribute"], check.DeepEquals, []string{"groupSet"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.InstanceId, check.Equals, "i-1234567890abcdef0")
+	c.Assert(resp.SecurityGroups, check.DeepEquals, ec2.SecurityGroupIds("sg-1a2b3c4d", "sg-2a2b3c4d"))
+	c.Assert(resp.UserData, check.Equals, "")
+}
+
+func (s *S) TestDescribeInstanceAttributeUserData(c *check.C) {
+	testServer.Response(200, nil, DescribeInstanceAttributeUserDataExample)
+
+	resp, err := s.ec2.DescribeInstanceAttribute("i-1234567890abcdef0", "userData")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Attribute"], check.DeepEquals, []string{"userData"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.UserData, check.Equals, "#!/bin/bash\necho hello")
+}
+
+func (s *S) TestModifyInstanceAttribute(c *check.C) {
+	testServer.Response(200, nil, ModifyInstanceAttributeExample)
+
+	_, err := s.ec2.ModifyInstanceAttribute("i-1234567890abcdef0", &ec2.ModifyInstanceAttributeOptions{
+		InstanceType: "m1.large",
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"ModifyInstanceAttribute"})
+	c.Assert(req.Form["InstanceId"], check.DeepEquals, []string{"i-1234567890abcdef0"})
+	c.Assert(req.Form["InstanceType.Value"], check.DeepEquals, []string{"m1.large"})
+	c.Assert(req.Form["UserData.Value"], check.IsNil)
+	c.Assert(req.Form["DisableApiTermination.Value"], check.IsNil)
+	c.Assert(req.Form["SourceDestCheck.Value"], check.IsNil)
+	c.Assert(req.Form["GroupId.1"], check.IsNil)
+
+	c.Assert(err, check.IsNil)
+
+	testServer.Response(200, nil, ModifyInstanceAttributeExample)
+
+	protect := true
+	_, err = s.ec2.ModifyInstanceAttribute("i-1234567890abcdef0", &ec2.ModifyInstanceAttributeOptions{
+		DisableAPITermination: &protect,
+	})
+
+	req = testServer.WaitRequest()
+	c.Assert(req.Form["DisableApiTermination.Value"], check.DeepEquals, []string{"true"})
+	c.Assert(req.Form["InstanceType.Value"], check.IsNil)
+
+	c.Assert(err, check.IsNil)
+
+	testServer.Response(200, nil, ModifyInstanceAttributeExample)
+
+	_, err = s.ec2.ModifyInstanceAttribute("i-1234567890abcdef0", &ec2.ModifyInstanceAttributeOptions{
+		UserData:       []byte("#!/bin/bash\necho hello"),
+		SecurityGroups: ec2.SecurityGroupIds("sg-1a2b3c4d", "sg-2a2b3c4d"),
+	})
+
+	req = testServer.WaitRequest()
+	c.Assert(req.Form["UserData.Value"], check.DeepEquals, []string{"IyEvYmluL2Jhc2gKZWNobyBoZWxsbw=="})
+	c.Assert(req.Form["GroupId.1"], check.DeepEquals, []string{"sg-1a2b3c4d"})
+	c.Assert(req.Form["GroupId.2"], check.DeepEquals, []string{"sg-2a2b3c4d"})
+
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestGetConsoleOutputExample(c *check.C) {
+	testServer.Response(200, nil, GetConsoleOutputExample)
+
+	resp, err := s.ec2.GetConsoleOutput("i-1234567890abcdef0")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"GetConsoleOutput"})
+	c.Assert(req.Form["InstanceId"], check.DeepEquals, []string{"i-1234567890abcdef0"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.InstanceId, check.Equals, "i-1234567890abcdef0")
+	c.Assert(resp.Timestamp, check.Equals, "2010-10-14T01:12:41.000Z")
+	c.Assert(resp.Output, check.Equals, "Linux version 2.6.16-xenU (builder@patchbat.amazonsa) (gcc version 4.0.1 20050727 (Red Hat 4.0.1-5)) #1 SMP Thu Oct 26 08:41:26 SAST 2006")
+}
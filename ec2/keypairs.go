@@ -0,0 +1,169 @@
+package ec2
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// Key pair management functions and types.
+
+// KeyPair represents details about a key pair.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_KeyPairInfo.html for more details.
+type KeyPair struct {
+	Name        string `xml:"keyName"`
+	Fingerprint string `xml:"keyFingerprint"`
+}
+
+// Response to a CreateKeyPair request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateKeyPair.html for more details.
+type CreateKeyPairResp struct {
+	RequestId   string `xml:"requestId"`
+	Name        string `xml:"keyName"`
+	Fingerprint string `xml:"keyFingerprint"`
+	Material    string `xml:"keyMaterial"` // The unencrypted PEM encoded RSA private key
+}
+
+// CreateKeyPair creates a 2048-bit RSA key pair with the given name.
+// EC2 stores the public key; the private key is only available in the
+// response and must be saved by the caller.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateKeyPair.html for more details.
+func (ec2 *EC2) CreateKeyPair(keyName string) (resp *CreateKeyPairResp, err error) {
+	params := makeParams("CreateKeyPair")
+	params["KeyName"] = keyName
+
+	resp = &CreateKeyPairResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Response to an ImportKeyPair request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_ImportKeyPair.html for more details.
+type ImportKeyPairResp struct {
+	RequestId   string `xml:"requestId"`
+	Name        string `xml:"keyName"`
+	Fingerprint string `xml:"keyFingerprint"`
+}
+
+// ImportKeyPair imports the public key from a key pair created with a
+// third-party tool. The public key must be in OpenSSH, base64 encoded
+// DER or SSH public key file (RFC4716) format.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_ImportKeyPair.html for more details.
+func (ec2 *EC2) ImportKeyPair(keyName string, publicKey []byte) (resp *ImportKeyPairResp, err error) {
+	params := makeParams("ImportKeyPair")
+	params["KeyName"] = keyName
+	params["PublicKeyMaterial"] = b64.EncodeToString(publicKey)
+
+	resp = &ImportKeyPairResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Response to a DescribeKeyPairs request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeKeyPairs.html for more details.
+type KeyPairsResp struct {
+	RequestId string    `xml:"requestId"`
+	KeyPairs  []KeyPair `xml:"keySet>item"`
+}
+
+// DescribeKeyPairs returns details about key pairs. The names and filter
+// parameters, if provided, limit the key pairs returned.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeKeyPairs.html for more details.
+func (ec2 *EC2) DescribeKeyPairs(keyNames []string, filter *Filter) (resp *KeyPairsResp, err error) {
+	params := makeParams("DescribeKeyPairs")
+	addParamsList(params, "KeyName", keyNames)
+	filter.addParams(params)
+
+	resp = &KeyPairsResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DeleteKeyPair deletes a key pair.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DeleteKeyPair.html for more details.
+func (ec2 *EC2) DeleteKeyPair(keyName string) (resp *SimpleResp, err error) {
+	params := makeParams("DeleteKeyPair")
+	params["KeyName"] = keyName
+
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Response to a GetPasswordData request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_GetPasswordData.html for more details.
+type PasswordDataResp struct {
+	RequestId    string `xml:"requestId"`
+	InstanceId   string `xml:"instanceId"`
+	Timestamp    string `xml:"timestamp"`
+	PasswordData string `xml:"passwordData"` // Encrypted and base64 encoded; empty until available
+}
+
+// GetPasswordData returns the encrypted administrator password of a
+// Windows instance. The password is usually available a few minutes
+// after launch; use DecryptPasswordData to decrypt it.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_GetPasswordData.html for more details.
+func (ec2 *EC2) GetPasswordData(instId string) (resp *PasswordDataResp, err error) {
+	params := makeParams("GetPasswordData")
+	params["InstanceId"] = instId
+
+	resp = &PasswordDataResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DecryptPasswordData decrypts the PasswordData of a GetPasswordData
+// response with the PEM encoded RSA private key of the key pair the
+// instance was launched with.
+func DecryptPasswordData(passwordData string, privateKey []byte) (string, error) {
+	passwordData = strings.TrimSpace(passwordData)
+	if passwordData == "" {
+		return "", errors.New("password data is not available yet")
+	}
+	block, _ := pem.Decode(privateKey)
+	if block == nil {
+		return "", errors.New("no PEM data found in private key")
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return "", err
+	}
+	encrypted, err := b64.DecodeString(passwordData)
+	if err != nil {
+		return "", err
+	}
+	password, err := rsa.DecryptPKCS1v15(rand.Reader, key, encrypted)
+	if err != nil {
+		return "", err
+	}
+	return string(password), nil
+}
@@ -0,0 +1,434 @@
+package ec2
+
+import (
+	"strconv"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// Launch template and EC2 Fleet functions and types.
+
+// LaunchTemplateData holds the instance configuration stored in a launch
+// template version. All fields are optional; fields left empty can be
+// given when launching instances from the template.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_RequestLaunchTemplateData.html for more details.
+type LaunchTemplateData struct {
+	ImageId               string
+	InstanceType          string
+	KeyName               string
+	SecurityGroups        []SecurityGroup
+	KernelId              string
+	RamdiskId             string
+	UserData              []byte
+	AvailabilityZone      string
+	PlacementGroupName    string
+	Tenancy               string
+	Monitoring            bool
+	DisableAPITermination bool
+	ShutdownBehavior      string
+	IamInstanceProfile    IamInstanceProfile
+	BlockDeviceMappings   []BlockDeviceMapping
+	EbsOptimized          bool
+	NetworkInterfaces     []NetworkInterface
+}
+
+// addParams adds the parameters of the template data, each name prefixed
+// with prefix. The fields shared with RunInstancesOptions are added by
+// addLaunchParams.
+func (data *LaunchTemplateData) addParams(params map[string]string, prefix string) {
+	options := RunInstancesOptions{
+		ImageId:             data.ImageId,
+		InstanceType:        data.InstanceType,
+		KeyName:             data.KeyName,
+		SecurityGroups:      data.SecurityGroups,
+		KernelId:            data.KernelId,
+		UserData:            data.UserData,
+		AvailabilityZone:    data.AvailabilityZone,
+		PlacementGroupName:  data.PlacementGroupName,
+		Tenancy:             data.Tenancy,
+		Monitoring:          data.Monitoring,
+		IamInstanceProfile:  data.IamInstanceProfile,
+		BlockDeviceMappings: data.BlockDeviceMappings,
+		EbsOptimized:        data.EbsOptimized,
+		NetworkInterfaces:   data.NetworkInterfaces,
+	}
+	options.addLaunchParams(params, prefix)
+
+	// Unlike RunInstances, the template data spells it RamDiskId.
+	if data.RamdiskId != "" {
+		params[prefix+"RamDiskId"] = data.RamdiskId
+	}
+	if data.DisableAPITermination {
+		params[prefix+"DisableApiTermination"] = "true"
+	}
+	if data.ShutdownBehavior != "" {
+		params[prefix+"InstanceInitiatedShutdownBehavior"] = data.ShutdownBehavior
+	}
+}
+
+// LaunchTemplateSpecification identifies a launch template and one of
+// its versions. Either Id or Name must be set; Id takes precedence.
+// Version is a version number, "$Latest" or "$Default", and defaults to
+// the latter.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_LaunchTemplateSpecification.html for more details.
+type LaunchTemplateSpecification struct {
+	Id      string
+	Name    string
+	Version string
+}
+
+func (spec *LaunchTemplateSpecification) addParams(params map[string]string, prefix string) {
+	if spec.Id != "" {
+		params[prefix+"LaunchTemplateId"] = spec.Id
+	} else {
+		params[prefix+"LaunchTemplateName"] = spec.Name
+	}
+	if spec.Version != "" {
+		params[prefix+"Version"] = spec.Version
+	}
+}
+
+// LaunchTemplate represents details about a launch template.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_LaunchTemplate.html for more details.
+type LaunchTemplate struct {
+	Id                   string `xml:"launchTemplateId"`
+	Name                 string `xml:"launchTemplateName"`
+	CreateTime           string `xml:"createTime"`
+	CreatedBy            string `xml:"createdBy"`
+	DefaultVersionNumber int64  `xml:"defaultVersionNumber"`
+	LatestVersionNumber  int64  `xml:"latestVersionNumber"`
+	Tags                 []Tag  `xml:"tagSet>item"`
+}
+
+// The CreateLaunchTemplateOptions type encapsulates options for the
+// respective request in EC2.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateLaunchTemplate.html for more details.
+type CreateLaunchTemplateOptions struct {
+	Name               string
+	VersionDescription string
+	Data               LaunchTemplateData
+}
+
+// Response to a CreateLaunchTemplate or DeleteLaunchTemplate request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateLaunchTemplate.html for more details.
+type LaunchTemplateResp struct {
+	RequestId      string         `xml:"requestId"`
+	LaunchTemplate LaunchTemplate `xml:"launchTemplate"`
+}
+
+// CreateLaunchTemplate creates a launch template with a first version
+// holding the given configuration.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateLaunchTemplate.html for more details.
+func (ec2 *EC2) CreateLaunchTemplate(options *CreateLaunchTemplateOptions) (resp *LaunchTemplateResp, err error) {
+	params := makeParams("CreateLaunchTemplate")
+	params["LaunchTemplateName"] = options.Name
+	if options.VersionDescription != "" {
+		params["VersionDescription"] = options.VersionDescription
+	}
+	options.Data.addParams(params, "LaunchTemplateData.")
+	token, err := clientToken()
+	if err != nil {
+		return nil, err
+	}
+	params["ClientToken"] = token
+
+	resp = &LaunchTemplateResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// LaunchTemplateVersion represents details about a version of a launch
+// template.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_LaunchTemplateVersion.html for more details.
+type LaunchTemplateVersion struct {
+	LaunchTemplateId   string `xml:"launchTemplateId"`
+	LaunchTemplateName string `xml:"launchTemplateName"`
+	VersionNumber      int64  `xml:"versionNumber"`
+	VersionDescription string `xml:"versionDescription"`
+	CreateTime         string `xml:"createTime"`
+	CreatedBy          string `xml:"createdBy"`
+	DefaultVersion     bool   `xml:"defaultVersion"`
+}
+
+// The CreateLaunchTemplateVersionOptions type encapsulates options for
+// the respective request in EC2. Template identifies the launch template;
+// its Version, if set, is used as the source version whose configuration
+// Data overrides.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateLaunchTemplateVersion.html for more details.
+type CreateLaunchTemplateVersionOptions struct {
+	Template           LaunchTemplateSpecification
+	VersionDescription string
+	Data               LaunchTemplateData
+}
+
+// Response to a CreateLaunchTemplateVersion request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateLaunchTemplateVersion.html for more details.
+type CreateLaunchTemplateVersionResp struct {
+	RequestId             string                `xml:"requestId"`
+	LaunchTemplateVersion LaunchTemplateVersion `xml:"launchTemplateVersion"`
+}
+
+// CreateLaunchTemplateVersion creates a new version of a launch template.
+// The new version doesn't become the default version.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateLaunchTemplateVersion.html for more details.
+func (ec2 *EC2) CreateLaunchTemplateVersion(options *CreateLaunchTemplateVersionOptions) (resp *CreateLaunchTemplateVersionResp, err error) {
+	params := makeParams("CreateLaunchTemplateVersion")
+	template := options.Template
+	template.Version = ""
+	template.addParams(params, "")
+	if options.Template.Version != "" {
+		params["SourceVersion"] = options.Template.Version
+	}
+	if options.VersionDescription != "" {
+		params["VersionDescription"] = options.VersionDescription
+	}
+	options.Data.addParams(params, "LaunchTemplateData.")
+	token, err := clientToken()
+	if err != nil {
+		return nil, err
+	}
+	params["ClientToken"] = token
+
+	resp = &CreateLaunchTemplateVersionResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Response to a DescribeLaunchTemplates request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeLaunchTemplates.html for more details.
+type LaunchTemplatesResp struct {
+	RequestId       string           `xml:"requestId"`
+	LaunchTemplates []LaunchTemplate `xml:"launchTemplates>item"`
+	NextToken       string           `xml:"nextToken"` // Set when more results are available
+}
+
+// DescribeLaunchTemplates returns details about launch templates. The
+// ids, names and filter parameters, if provided, limit the templates
+// returned.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeLaunchTemplates.html for more details.
+func (ec2 *EC2) DescribeLaunchTemplates(ids, names []string, filter *Filter) (resp *LaunchTemplatesResp, err error) {
+	return ec2.DescribeLaunchTemplatesPage(ids, names, filter, nil)
+}
+
+// DescribeLaunchTemplatesPage is like DescribeLaunchTemplates, but
+// returns only the page of results selected by page.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeLaunchTemplates.html for more details.
+func (ec2 *EC2) DescribeLaunchTemplatesPage(ids, names []string, filter *Filter, page *PageOptions) (resp *LaunchTemplatesResp, err error) {
+	params := makeParams("DescribeLaunchTemplates")
+	addParamsList(params, "LaunchTemplateId", ids)
+	addParamsList(params, "LaunchTemplateName", names)
+	filter.addParams(params)
+	page.addParams(params)
+
+	resp = &LaunchTemplatesResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DescribeLaunchTemplatesPages calls fn with every page of the launch
+// templates selected by ids, names and filter, until fn returns false or
+// there are no more pages. The page parameter selects the first page and
+// the page size.
+func (ec2 *EC2) DescribeLaunchTemplatesPages(ids, names []string, filter *Filter, page *PageOptions, fn func(page *LaunchTemplatesResp) bool) error {
+	for {
+		resp, err := ec2.DescribeLaunchTemplatesPage(ids, names, filter, page)
+		if err != nil {
+			return err
+		}
+		if !fn(resp) || resp.NextToken == "" {
+			return nil
+		}
+		page = page.next(resp.NextToken)
+	}
+}
+
+// DeleteLaunchTemplate deletes a launch template and all its versions.
+// The Version of template is ignored.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DeleteLaunchTemplate.html for more details.
+func (ec2 *EC2) DeleteLaunchTemplate(template LaunchTemplateSpecification) (resp *LaunchTemplateResp, err error) {
+	params := makeParams("DeleteLaunchTemplate")
+	template.Version = ""
+	template.addParams(params, "")
+
+	resp = &LaunchTemplateResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// FleetOverride overrides the configuration of a launch template for a
+// fleet. Unset fields use the launch template values.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_FleetLaunchTemplateOverridesRequest.html for more details.
+type FleetOverride struct {
+	InstanceType     string
+	SubnetId         string
+	AvailabilityZone string
+	MaxPrice         string
+	WeightedCapacity float64
+	Priority         float64
+}
+
+// FleetLaunchTemplateConfig specifies a launch template and the
+// overrides to use with it in a fleet.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_FleetLaunchTemplateConfigRequest.html for more details.
+type FleetLaunchTemplateConfig struct {
+	Template  LaunchTemplateSpecification
+	Overrides []FleetOverride
+}
+
+// The CreateFleetOptions type encapsulates options for the respective
+// request in EC2.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateFleet.html for more details.
+type CreateFleetOptions struct {
+	LaunchTemplateConfigs            []FleetLaunchTemplateConfig
+	TotalTargetCapacity              int
+	OnDemandTargetCapacity           int
+	SpotTargetCapacity               int
+	DefaultTargetCapacityType        string // Valid values: spot | on-demand
+	Type                             string // Valid values: request | maintain | instant
+	SpotAllocationStrategy           string // Valid values: lowest-price | diversified | capacity-optimized
+	OnDemandAllocationStrategy       string // Valid values: lowest-price | prioritized
+	ExcessCapacityTerminationPolicy  string // Valid values: termination | no-termination
+	TerminateInstancesWithExpiration bool
+	ReplaceUnhealthyInstances        bool
+	ValidFrom                        time.Time
+	ValidUntil                       time.Time
+}
+
+// FleetError describes an error launching instances of a fleet.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateFleetError.html for more details.
+type FleetError struct {
+	Code         string `xml:"errorCode"`
+	Message      string `xml:"errorMessage"`
+	Lifecycle    string `xml:"lifecycle"` // Valid values: spot | on-demand
+	InstanceType string `xml:"launchTemplateAndOverrides>overrides>instanceType"`
+	SubnetId     string `xml:"launchTemplateAndOverrides>overrides>subnetId"`
+}
+
+// FleetInstances describes instances launched by an instant fleet.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateFleetInstance.html for more details.
+type FleetInstances struct {
+	InstanceIds  []string `xml:"instanceIds>item"`
+	InstanceType string   `xml:"instanceType"`
+	Lifecycle    string   `xml:"lifecycle"` // Valid values: spot | on-demand
+	Platform     string   `xml:"platform"`
+}
+
+// Response to a CreateFleet request. Errors and Instances are only set
+// for fleets of type instant.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateFleet.html for more details.
+type CreateFleetResp struct {
+	RequestId string           `xml:"requestId"`
+	FleetId   string           `xml:"fleetId"`
+	Errors    []FleetError     `xml:"errorSet>item"`
+	Instances []FleetInstances `xml:"fleetInstanceSet>item"`
+}
+
+// CreateFleet launches an EC2 Fleet from one or more launch templates.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateFleet.html for more details.
+func (ec2 *EC2) CreateFleet(options *CreateFleetOptions) (resp *CreateFleetResp, err error) {
+	params := makeParams("CreateFleet")
+	for i, config := range options.LaunchTemplateConfigs {
+		prefix := "LaunchTemplateConfigs." + strconv.Itoa(i+1) + "."
+		config.Template.addParams(params, prefix+"LaunchTemplateSpecification.")
+		for j, o := range config.Overrides {
+			oprefix := prefix + "Overrides." + strconv.Itoa(j+1) + "."
+			if o.InstanceType != "" {
+				params[oprefix+"InstanceType"] = o.InstanceType
+			}
+			if o.SubnetId != "" {
+				params[oprefix+"SubnetId"] = o.SubnetId
+			}
+			if o.AvailabilityZone != "" {
+				params[oprefix+"AvailabilityZone"] = o.AvailabilityZone
+			}
+			if o.MaxPrice != "" {
+				params[oprefix+"MaxPrice"] = o.MaxPrice
+			}
+			if o.WeightedCapacity != 0 {
+				params[oprefix+"WeightedCapacity"] = strconv.FormatFloat(o.WeightedCapacity, 'f', -1, 64)
+			}
+			if o.Priority != 0 {
+				params[oprefix+"Priority"] = strconv.FormatFloat(o.Priority, 'f', -1, 64)
+			}
+		}
+	}
+	params["TargetCapacitySpecification.TotalTargetCapacity"] = strconv.Itoa(options.TotalTargetCapacity)
+	if options.OnDemandTargetCapacity != 0 {
+		params["TargetCapacitySpecification.OnDemandTargetCapacity"] = strconv.Itoa(options.OnDemandTargetCapacity)
+	}
+	if options.SpotTargetCapacity != 0 {
+		params["TargetCapacitySpecification.SpotTargetCapacity"] = strconv.Itoa(options.SpotTargetCapacity)
+	}
+	if options.DefaultTargetCapacityType != "" {
+		params["TargetCapacitySpecification.DefaultTargetCapacityType"] = options.DefaultTargetCapacityType
+	}
+	if options.Type != "" {
+		params["Type"] = options.Type
+	}
+	if options.SpotAllocationStrategy != "" {
+		params["SpotOptions.AllocationStrategy"] = options.SpotAllocationStrategy
+	}
+	if options.OnDemandAllocationStrategy != "" {
+		params["OnDemandOptions.AllocationStrategy"] = options.OnDemandAllocationStrategy
+	}
+	if options.ExcessCapacityTerminationPolicy != "" {
+		params["ExcessCapacityTerminationPolicy"] = options.ExcessCapacityTerminationPolicy
+	}
+	if options.TerminateInstancesWithExpiration {
+		params["TerminateInstancesWithExpiration"] = "true"
+	}
+	if options.ReplaceUnhealthyInstances {
+		params["ReplaceUnhealthyInstances"] = "true"
+	}
+	if !options.ValidFrom.IsZero() {
+		params["ValidFrom"] = options.ValidFrom.In(time.UTC).Format(time.RFC3339)
+	}
+	if !options.ValidUntil.IsZero() {
+		params["ValidUntil"] = options.ValidUntil.In(time.UTC).Format(time.RFC3339)
+	}
+	token, err := clientToken()
+	if err != nil {
+		return nil, err
+	}
+	params["ClientToken"] = token
+
+	resp = &CreateFleetResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
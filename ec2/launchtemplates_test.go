@@ -0,0 +1,230 @@
+package ec2_test
+
+import (
+	"github.com/crowdmob/goamz/ec2"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestCreateLaunchTemplateExample(c *check.C) {
+	testServer.Response(200, nil, CreateLaunchTemplateExample)
+
+	resp, err := s.ec2.CreateLaunchTemplate(&ec2.CreateLaunchTemplateOptions{
+		Name:               "MyLaunchTemplate",
+		VersionDescription: "WebVersion1",
+		Data: ec2.LaunchTemplateData{
+			ImageId:          "ami-8c1be5f6",
+			InstanceType:     "t2.small",
+			RamdiskId:        "ari-1a2b3c4d",
+			ShutdownBehavior: "terminate",
+			UserData:         []byte("#!/bin/bash\necho hello"),
+			SecurityGroups:   []ec2.SecurityGroup{{Id: "sg-7c227019"}, {Name: "web"}},
+			BlockDeviceMappings: []ec2.BlockDeviceMapping{
+				{DeviceName: "/dev/sdb", VolumeSize: 100, VolumeType: "gp2"},
+			},
+			NetworkInterfaces: []ec2.NetworkInterface{
+				{SubnetId: "subnet-7b16de0c", AssociatePublicIpAddress: true, DeleteOnTermination: true},
+			},
+		},
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"CreateLaunchTemplate"})
+	c.Assert(req.Form["LaunchTemplateName"], check.DeepEquals, []string{"MyLaunchTemplate"})
+	c.Assert(req.Form["VersionDescription"], check.DeepEquals, []string{"WebVersion1"})
+	c.Assert(req.Form["LaunchTemplateData.ImageId"], check.DeepEquals, []string{"ami-8c1be5f6"})
+	c.Assert(req.Form["LaunchTemplateData.InstanceType"], check.DeepEquals, []string{"t2.small"})
+	c.Assert(req.Form["LaunchTemplateData.RamDiskId"], check.DeepEquals, []string{"ari-1a2b3c4d"})
+	c.Assert(req.Form["LaunchTemplateData.RamdiskId"], check.IsNil)
+	c.Assert(req.Form["LaunchTemplateData.InstanceInitiatedShutdownBehavior"], check.DeepEquals, []string{"terminate"})
+	c.Assert(req.Form["LaunchTemplateData.UserData"], check.DeepEquals, []string{"IyEvYmluL2Jhc2gKZWNobyBoZWxsbw=="})
+	c.Assert(req.Form["LaunchTemplateData.SecurityGroupId.1"], check.DeepEquals, []string{"sg-7c227019"})
+	c.Assert(req.Form["LaunchTemplateData.SecurityGroup.1"], check.DeepEquals, []string{"web"})
+	c.Assert(req.Form["LaunchTemplateData.BlockDeviceMapping.1.DeviceName"], check.DeepEquals, []string{"/dev/sdb"})
+	c.Assert(req.Form["LaunchTemplateData.BlockDeviceMapping.1.Ebs.VolumeSize"], check.DeepEquals, []string{"100"})
+	c.Assert(req.Form["LaunchTemplateData.BlockDeviceMapping.1.Ebs.VolumeType"], check.DeepEquals, []string{"gp2"})
+	c.Assert(req.Form["LaunchTemplateData.NetworkInterface.1.DeviceIndex"], check.DeepEquals, []string{"0"})
+	c.Assert(req.Form["LaunchTemplateData.NetworkInterface.1.SubnetId"], check.DeepEquals, []string{"subnet-7b16de0c"})
+	c.Assert(req.Form["LaunchTemplateData.NetworkInterface.1.AssociatePublicIpAddress"], check.DeepEquals, []string{"true"})
+	c.Assert(req.Form["LaunchTemplateData.NetworkInterface.1.DeleteOnTermination"], check.DeepEquals, []string{"true"})
+	c.Assert(req.Form["LaunchTemplateData.KeyName"], check.IsNil)
+	c.Assert(req.Form["ClientToken"], check.HasLen, 1)
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.RequestId, check.Equals, "39f36a23-1d62-4bd1-8aaa-1a5aeEXAMPLE")
+	c.Assert(resp.LaunchTemplate, check.DeepEquals, ec2.LaunchTemplate{
+		Id:                   "lt-0a20c965061f64abc",
+		Name:                 "MyLaunchTemplate",
+		CreateTime:           "2017-10-31T11:38:52.000Z",
+		CreatedBy:            "arn:aws:iam::123456789012:root",
+		DefaultVersionNumber: 1,
+		LatestVersionNumber:  1,
+	})
+}
+
+func (s *S) TestCreateLaunchTemplateVersionExample(c *check.C) {
+	testServer.Response(200, nil, CreateLaunchTemplateVersionExample)
+
+	resp, err := s.ec2.CreateLaunchTemplateVersion(&ec2.CreateLaunchTemplateVersionOptions{
+		Template:           ec2.LaunchTemplateSpecification{Id: "lt-0a20c965061f64abc", Version: "1"},
+		VersionDescription: "WebVersion2",
+		Data:               ec2.LaunchTemplateData{ImageId: "ami-aabbccdd"},
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"CreateLaunchTemplateVersion"})
+	c.Assert(req.Form["LaunchTemplateId"], check.DeepEquals, []string{"lt-0a20c965061f64abc"})
+	c.Assert(req.Form["LaunchTemplateName"], check.IsNil)
+	c.Assert(req.Form["SourceVersion"], check.DeepEquals, []string{"1"})
+	c.Assert(req.Form["Version"], check.DeepEquals, []string{"2016-11-15"}) // API version, not template version
+	c.Assert(req.Form["VersionDescription"], check.DeepEquals, []string{"WebVersion2"})
+	c.Assert(req.Form["LaunchTemplateData.ImageId"], check.DeepEquals, []string{"ami-aabbccdd"})
+	c.Assert(req.Form["LaunchTemplateData.InstanceType"], check.IsNil)
+
+	c.Assert(err, check.IsNil)
+	v := resp.LaunchTemplateVersion
+	c.Assert(v.LaunchTemplateId, check.Equals, "lt-0a20c965061f64abc")
+	c.Assert(v.VersionNumber, check.Equals, int64(2))
+	c.Assert(v.VersionDescription, check.Equals, "WebVersion2")
+	c.Assert(v.DefaultVersion, check.Equals, false)
+}
+
+func (s *S) TestDescribeLaunchTemplatesExample(c *check.C) {
+	testServer.Response(200, nil, DescribeLaunchTemplatesExample)
+
+	resp, err := s.ec2.DescribeLaunchTemplates([]string{"lt-0a20c965061f64abc"}, []string{"MyLaunchTemplate"}, nil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeLaunchTemplates"})
+	c.Assert(req.Form["LaunchTemplateId.1"], check.DeepEquals, []string{"lt-0a20c965061f64abc"})
+	c.Assert(req.Form["LaunchTemplateName.1"], check.DeepEquals, []string{"MyLaunchTemplate"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.LaunchTemplates, check.HasLen, 1)
+	lt := resp.LaunchTemplates[0]
+	c.Assert(lt.Id, check.Equals, "lt-0a20c965061f64abc")
+	c.Assert(lt.LatestVersionNumber, check.Equals, int64(2))
+	c.Assert(lt.Tags, check.DeepEquals, []ec2.Tag{{Key: "purpose", Value: "webservers"}})
+}
+
+func (s *S) TestDescribeLaunchTemplatesPage(c *check.C) {
+	testServer.Response(200, nil, DescribeLaunchTemplatesExample)
+
+	resp, err := s.ec2.DescribeLaunchTemplatesPage(nil, nil, nil, &ec2.PageOptions{MaxResults: 5, NextToken: "abc"})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeLaunchTemplates"})
+	c.Assert(req.Form["MaxResults"], check.DeepEquals, []string{"5"})
+	c.Assert(req.Form["NextToken"], check.DeepEquals, []string{"abc"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.NextToken, check.Equals, "")
+	c.Assert(resp.LaunchTemplates, check.HasLen, 1)
+}
+
+func (s *S) TestDescribeLaunchTemplatesPages(c *check.C) {
+	testServer.Response(200, nil, DescribeLaunchTemplatesFirstPageExample)
+	testServer.Response(200, nil, DescribeLaunchTemplatesExample)
+
+	var ids []string
+	err := s.ec2.DescribeLaunchTemplatesPages(nil, nil, nil, &ec2.PageOptions{MaxResults: 1}, func(page *ec2.LaunchTemplatesResp) bool {
+		for _, lt := range page.LaunchTemplates {
+			ids = append(ids, lt.Id)
+		}
+		return true
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["MaxResults"], check.DeepEquals, []string{"1"})
+	c.Assert(req.Form["NextToken"], check.IsNil)
+	req = testServer.WaitRequest()
+	c.Assert(req.Form["MaxResults"], check.DeepEquals, []string{"1"})
+	c.Assert(req.Form["NextToken"], check.DeepEquals, []string{"bHQtdG9rZW4"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(ids, check.DeepEquals, []string{"lt-01238c059e3466abc", "lt-0a20c965061f64abc"})
+}
+
+func (s *S) TestDeleteLaunchTemplateExample(c *check.C) {
+	testServer.Response(200, nil, DeleteLaunchTemplateExample)
+
+	resp, err := s.ec2.DeleteLaunchTemplate(ec2.LaunchTemplateSpecification{Name: "MyLaunchTemplate", Version: "2"})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DeleteLaunchTemplate"})
+	c.Assert(req.Form["LaunchTemplateName"], check.DeepEquals, []string{"MyLaunchTemplate"})
+	c.Assert(req.Form["LaunchTemplateId"], check.IsNil)
+	c.Assert(req.Form["Version"], check.DeepEquals, []string{"2016-11-15"}) // API version, not template version
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.LaunchTemplate.Id, check.Equals, "lt-0a20c965061f64abc")
+}
+
+func (s *S) TestRunInstancesWithLaunchTemplate(c *check.C) {
+	testServer.Response(200, nil, RunInstancesExample)
+
+	_, err := s.ec2.RunInstances(&ec2.RunInstancesOptions{
+		LaunchTemplate: &ec2.LaunchTemplateSpecification{Id: "lt-0a20c965061f64abc", Version: "$Latest"},
+		InstanceType:   "m5.large",
+		MaxCount:       2,
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"RunInstances"})
+	c.Assert(req.Form["LaunchTemplate.LaunchTemplateId"], check.DeepEquals, []string{"lt-0a20c965061f64abc"})
+	c.Assert(req.Form["LaunchTemplate.Version"], check.DeepEquals, []string{"$Latest"})
+	c.Assert(req.Form["InstanceType"], check.DeepEquals, []string{"m5.large"})
+	c.Assert(req.Form["ImageId"], check.IsNil)
+	c.Assert(req.Form["MaxCount"], check.DeepEquals, []string{"2"})
+
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestCreateFleetExample(c *check.C) {
+	testServer.Response(200, nil, CreateFleetExample)
+
+	resp, err := s.ec2.CreateFleet(&ec2.CreateFleetOptions{
+		LaunchTemplateConfigs: []ec2.FleetLaunchTemplateConfig{{
+			Template: ec2.LaunchTemplateSpecification{Id: "lt-0a20c965061f64abc", Version: "1"},
+			Overrides: []ec2.FleetOverride{
+				{InstanceType: "c5.large", SubnetId: "subnet-1a2b3c4d", WeightedCapacity: 2},
+				{InstanceType: "m5.large", MaxPrice: "0.05"},
+			},
+		}},
+		TotalTargetCapacity:       4,
+		DefaultTargetCapacityType: "spot",
+		Type:                      "instant",
+		SpotAllocationStrategy:    "lowest-price",
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"CreateFleet"})
+	c.Assert(req.Form["LaunchTemplateConfigs.1.LaunchTemplateSpecification.LaunchTemplateId"], check.DeepEquals, []string{"lt-0a20c965061f64abc"})
+	c.Assert(req.Form["LaunchTemplateConfigs.1.LaunchTemplateSpecification.Version"], check.DeepEquals, []string{"1"})
+	c.Assert(req.Form["LaunchTemplateConfigs.1.Overrides.1.InstanceType"], check.DeepEquals, []string{"c5.large"})
+	c.Assert(req.Form["LaunchTemplateConfigs.1.Overrides.1.SubnetId"], check.DeepEquals, []string{"subnet-1a2b3c4d"})
+	c.Assert(req.Form["LaunchTemplateConfigs.1.Overrides.1.WeightedCapacity"], check.DeepEquals, []string{"2"})
+	c.Assert(req.Form["LaunchTemplateConfigs.1.Overrides.2.InstanceType"], check.DeepEquals, []string{"m5.large"})
+	c.Assert(req.Form["LaunchTemplateConfigs.1.Overrides.2.MaxPrice"], check.DeepEquals, []string{"0.05"})
+	c.Assert(req.Form["LaunchTemplateConfigs.1.Overrides.2.WeightedCapacity"], check.IsNil)
+	c.Assert(req.Form["TargetCapacitySpecification.TotalTargetCapacity"], check.DeepEquals, []string{"4"})
+	c.Assert(req.Form["TargetCapacitySpecification.DefaultTargetCapacityType"], check.DeepEquals, []string{"spot"})
+	c.Assert(req.Form["TargetCapacitySpecification.OnDemandTargetCapacity"], check.IsNil)
+	c.Assert(req.Form["Type"], check.DeepEquals, []string{"instant"})
+	c.Assert(req.Form["SpotOptions.AllocationStrategy"], check.DeepEquals, []string{"lowest-price"})
+	c.Assert(req.Form["ClientToken"], check.HasLen, 1)
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.FleetId, check.Equals, "fleet-73fbd2ce-aa30-494c-8788-1cee4EXAMPLE")
+	c.Assert(resp.Errors, check.DeepEquals, []ec2.FleetError{{
+		Code:         "InsufficientInstanceCapacity",
+		Message:      "There is no Spot capacity available that matches your request.",
+		Lifecycle:    "spot",
+		InstanceType: "c5.large",
+		SubnetId:     "subnet-1a2b3c4d",
+	}})
+	c.Assert(resp.Instances, check.DeepEquals, []ec2.FleetInstances{{
+		InstanceIds:  []string{"i-1234567890abcdef0", "i-1234567890abcdef1"},
+		InstanceType: "m5.large",
+		Lifecycle:    "spot",
+	}})
+}
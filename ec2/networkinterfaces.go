@@ -0,0 +1,249 @@
+package ec2
+
+import (
+	"strconv"
+)
+
+// ----------------------------------------------------------------------------
+// Elastic network interface management functions and types.
+
+// NetworkInterfaceInfo represents details about an elastic network
+// interface, attached or not.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_NetworkInterface.html for more details.
+type NetworkInterfaceInfo struct {
+	InstanceNetworkInterface
+	AvailabilityZone string `xml:"availabilityZone"`
+	RequesterId      string `xml:"requesterId"`
+	RequesterManaged bool   `xml:"requesterManaged"`
+	InterfaceType    string `xml:"interfaceType"` // Valid values: interface | natGateway
+	Tags             []Tag  `xml:"tagSet>item"`
+}
+
+// The CreateNetworkInterfaceOptions type encapsulates options for the
+// respective request in EC2. Only the Id of SecurityGroups is used.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateNetworkInterface.html for more details.
+type CreateNetworkInterfaceOptions struct {
+	SubnetId                       string
+	Description                    string
+	PrivateIpAddress               string // primary private ip
+	PrivateIpAddresses             []InstancePrivateIpAddress
+	SecondaryPrivateIpAddressCount int
+	SecurityGroups                 []SecurityGroup
+}
+
+// Response to a CreateNetworkInterface request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateNetworkInterface.html for more details.
+type CreateNetworkInterfaceResp struct {
+	RequestId        string               `xml:"requestId"`
+	NetworkInterface NetworkInterfaceInfo `xml:"networkInterface"`
+}
+
+// CreateNetworkInterface creates a network interface in a subnet.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateNetworkInterface.html for more details.
+func (ec2 *EC2) CreateNetworkInterface(options *CreateNetworkInterfaceOptions) (resp *CreateNetworkInterfaceResp, err error) {
+	params := makeParams("CreateNetworkInterface")
+	params["SubnetId"] = options.SubnetId
+	if options.Description != "" {
+		params["Description"] = options.Description
+	}
+	if options.PrivateIpAddress != "" {
+		params["PrivateIpAddress"] = options.PrivateIpAddress
+	}
+	for i, addy := range options.PrivateIpAddresses {
+		prefix := "PrivateIpAddresses." + strconv.Itoa(i+1)
+		params[prefix+".PrivateIpAddress"] = addy.PrivateIPAddress
+		if addy.Primary {
+			params[prefix+".Primary"] = "true"
+		}
+	}
+	if options.SecondaryPrivateIpAddressCount != 0 {
+		params["SecondaryPrivateIpAddressCount"] = strconv.Itoa(options.SecondaryPrivateIpAddressCount)
+	}
+	for i, g := range options.SecurityGroups {
+		params["SecurityGroupId."+strconv.Itoa(i+1)] = g.Id
+	}
+
+	resp = &CreateNetworkInterfaceResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Response to a DescribeNetworkInterfaces request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeNetworkInterfaces.html for more details.
+type NetworkInterfacesResp struct {
+	RequestId         string                 `xml:"requestId"`
+	NetworkInterfaces []NetworkInterfaceInfo `xml:"networkInterfaceSet>item"`
+}
+
+// DescribeNetworkInterfaces returns details about network interfaces.
+// The ids and filter parameters, if provided, limit the interfaces
+// returned.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeNetworkInterfaces.html for more details.
+func (ec2 *EC2) DescribeNetworkInterfaces(ids []string, filter *Filter) (resp *NetworkInterfacesResp, err error) {
+	params := makeParams("DescribeNetworkInterfaces")
+	addParamsList(params, "NetworkInterfaceId", ids)
+	filter.addParams(params)
+
+	resp = &NetworkInterfacesResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Response to an AttachNetworkInterface request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_AttachNetworkInterface.html for more details.
+type AttachNetworkInterfaceResp struct {
+	RequestId    string `xml:"requestId"`
+	AttachmentId string `xml:"attachmentId"`
+}
+
+// AttachNetworkInterface attaches a network interface to an instance
+// at the given device index.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_AttachNetworkInterface.html for more details.
+func (ec2 *EC2) AttachNetworkInterface(networkInterfaceId, instanceId string, deviceIndex int) (resp *AttachNetworkInterfaceResp, err error) {
+	params := makeParams("AttachNetworkInterface")
+	params["NetworkInterfaceId"] = networkInterfaceId
+	params["InstanceId"] = instanceId
+	params["DeviceIndex"] = strconv.Itoa(deviceIndex)
+
+	resp = &AttachNetworkInterfaceResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DetachNetworkInterface detaches a network interface from an instance,
+// given the id of the attachment. If force is true, the interface is
+// detached even if the instance does not release it.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DetachNetworkInterface.html for more details.
+func (ec2 *EC2) DetachNetworkInterface(attachmentId string, force bool) (resp *SimpleResp, err error) {
+	params := makeParams("DetachNetworkInterface")
+	params["AttachmentId"] = attachmentId
+	if force {
+		params["Force"] = "true"
+	}
+
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// AssignPrivateIpAddresses assigns secondary private IP addresses to a
+// network interface. Either specific addresses or a number of addresses
+// to be picked from the subnet range must be given. If allowReassignment
+// is true, addresses already assigned to another interface are moved.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_AssignPrivateIpAddresses.html for more details.
+func (ec2 *EC2) AssignPrivateIpAddresses(networkInterfaceId string, ips []string, secondaryCount int, allowReassignment bool) (resp *SimpleResp, err error) {
+	params := makeParams("AssignPrivateIpAddresses")
+	params["NetworkInterfaceId"] = networkInterfaceId
+	addParamsList(params, "PrivateIpAddress", ips)
+	if secondaryCount != 0 {
+		params["SecondaryPrivateIpAddressCount"] = strconv.Itoa(secondaryCount)
+	}
+	if allowReassignment {
+		params["AllowReassignment"] = "true"
+	}
+
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// UnassignPrivateIpAddresses removes secondary private IP addresses from
+// a network interface.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_UnassignPrivateIpAddresses.html for more details.
+func (ec2 *EC2) UnassignPrivateIpAddresses(networkInterfaceId string, ips []string) (resp *SimpleResp, err error) {
+	params := makeParams("UnassignPrivateIpAddresses")
+	params["NetworkInterfaceId"] = networkInterfaceId
+	addParamsList(params, "PrivateIpAddress", ips)
+
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// The ModifyNetworkInterfaceAttributeOptions type holds the attribute to
+// change in a ModifyNetworkInterfaceAttribute request. EC2 accepts a
+// single attribute per request: set only one of Description,
+// SecurityGroups, SourceDestCheck or AttachmentId. DeleteOnTermination
+// applies to the attachment identified by AttachmentId.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_ModifyNetworkInterfaceAttribute.html for more details.
+type ModifyNetworkInterfaceAttributeOptions struct {
+	Description         string
+	SecurityGroups      []SecurityGroup
+	SourceDestCheck     *bool
+	AttachmentId        string
+	DeleteOnTermination bool
+}
+
+// ModifyNetworkInterfaceAttribute changes an attribute of a network
+// interface.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_ModifyNetworkInterfaceAttribute.html for more details.
+func (ec2 *EC2) ModifyNetworkInterfaceAttribute(networkInterfaceId string, options *ModifyNetworkInterfaceAttributeOptions) (resp *SimpleResp, err error) {
+	params := makeParams("ModifyNetworkInterfaceAttribute")
+	params["NetworkInterfaceId"] = networkInterfaceId
+	if options.Description != "" {
+		params["Description.Value"] = options.Description
+	}
+	for i, g := range options.SecurityGroups {
+		params["SecurityGroupId."+strconv.Itoa(i+1)] = g.Id
+	}
+	if options.SourceDestCheck != nil {
+		params["SourceDestCheck.Value"] = strconv.FormatBool(*options.SourceDestCheck)
+	}
+	if options.AttachmentId != "" {
+		params["Attachment.AttachmentId"] = options.AttachmentId
+		params["Attachment.DeleteOnTermination"] = strconv.FormatBool(options.DeleteOnTermination)
+	}
+
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DeleteNetworkInterface deletes a detached network interface.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DeleteNetworkInterface.html for more details.
+func (ec2 *EC2) DeleteNetworkInterface(networkInterfaceId string) (resp *SimpleResp, err error) {
+	params := makeParams("DeleteNetworkInterface")
+	params["NetworkInterfaceId"] = networkInterfaceId
+
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
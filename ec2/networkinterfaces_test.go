@@ -0,0 +1,187 @@
+package ec2_test
+
+import (
+	"github.com/crowdmob/goamz/ec2"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestCreateNetworkInterfaceExample(c *check.C) {
+	testServer.Response(200, nil, CreateNetworkInterfaceExample)
+
+	resp, err := s.ec2.CreateNetworkInterface(&ec2.CreateNetworkInterfaceOptions{
+		SubnetId:    "subnet-b2a249da",
+		Description: "web",
+		PrivateIpAddresses: []ec2.InstancePrivateIpAddress{
+			{PrivateIPAddress: "10.0.2.157", Primary: true},
+			{PrivateIPAddress: "10.0.2.158"},
+		},
+		SecurityGroups: ec2.SecurityGroupIds("sg-1a2b3c4d", "sg-2a2b3c4d"),
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"CreateNetworkInterface"})
+	c.Assert(req.Form["SubnetId"], check.DeepEquals, []string{"subnet-b2a249da"})
+	c.Assert(req.Form["Description"], check.DeepEquals, []string{"web"})
+	c.Assert(req.Form["PrivateIpAddress"], check.IsNil)
+	c.Assert(req.Form["PrivateIpAddresses.1.PrivateIpAddress"], check.DeepEquals, []string{"10.0.2.157"})
+	c.Assert(req.Form["PrivateIpAddresses.1.Primary"], check.DeepEquals, []string{"true"})
+	c.Assert(req.Form["PrivateIpAddresses.2.PrivateIpAddress"], check.DeepEquals, []string{"10.0.2.158"})
+	c.Assert(req.Form["PrivateIpAddresses.2.Primary"], check.IsNil)
+	c.Assert(req.Form["SecondaryPrivateIpAddressCount"], check.IsNil)
+	c.Assert(req.Form["SecurityGroupId.1"], check.DeepEquals, []string{"sg-1a2b3c4d"})
+	c.Assert(req.Form["SecurityGroupId.2"], check.DeepEquals, []string{"sg-2a2b3c4d"})
+
+	c.Assert(err, check.IsNil)
+	eni := resp.NetworkInterface
+	c.Assert(eni.Id, check.Equals, "eni-cfca76a6")
+	c.Assert(eni.SubnetId, check.Equals, "subnet-b2a249da")
+	c.Assert(eni.AvailabilityZone, check.Equals, "ap-southeast-1b")
+	c.Assert(eni.Status, check.Equals, "available")
+	c.Assert(eni.MacAddress, check.Equals, "02:74:b0:72:79:61")
+	c.Assert(eni.SourceDestCheck, check.Equals, true)
+	c.Assert(eni.SecurityGroups, check.DeepEquals, []ec2.SecurityGroup{{Id: "sg-1a2b3c4d", Name: "default"}})
+	c.Assert(eni.PrivateIPAddresses, check.HasLen, 1)
+	c.Assert(eni.PrivateIPAddresses[0].PrivateIPAddress, check.Equals, "10.0.2.157")
+}
+
+func (s *S) TestDescribeNetworkInterfacesExample(c *check.C) {
+	testServer.Response(200, nil, DescribeNetworkInterfacesExample)
+
+	filter := ec2.NewFilter()
+	filter.Add("attachment.instance-id", "i-1234567890abcdef0")
+
+	resp, err := s.ec2.DescribeNetworkInterfaces([]string{"eni-0f62d866"}, filter)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeNetworkInterfaces"})
+	c.Assert(req.Form["NetworkInterfaceId.1"], check.DeepEquals, []string{"eni-0f62d866"})
+	c.Assert(req.Form["Filter.1.Name"], check.DeepEquals, []string{"attachment.instance-id"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.NetworkInterfaces, check.HasLen, 1)
+	eni := resp.NetworkInterfaces[0]
+	c.Assert(eni.Id, check.Equals, "eni-0f62d866")
+	c.Assert(eni.Status, check.Equals, "in-use")
+	c.Assert(eni.Attachment.AttachmentID, check.Equals, "eni-attach-6537fc0c")
+	c.Assert(eni.Attachment.InstanceId, check.Equals, "i-1234567890abcdef0")
+	c.Assert(eni.Attachment.InstanceOwnerId, check.Equals, "053230519467")
+	c.Assert(eni.Attachment.Status, check.Equals, "attached")
+	c.Assert(eni.Attachment.DeleteOnTermination, check.Equals, true)
+	c.Assert(eni.Tags, check.DeepEquals, []ec2.Tag{{Key: "Name", Value: "web"}})
+	c.Assert(eni.PrivateIPAddresses, check.HasLen, 2)
+	c.Assert(eni.PrivateIPAddresses[1].PrivateIPAddress, check.Equals, "10.0.0.148")
+	c.Assert(eni.PrivateIPAddresses[1].Primary, check.Equals, false)
+}
+
+func (s *S) TestAttachNetworkInterfaceExample(c *check.C) {
+	testServer.Response(200, nil, AttachNetworkInterfaceExample)
+
+	resp, err := s.ec2.AttachNetworkInterface("eni-ffda3197", "i-1234567890abcdef0", 1)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"AttachNetworkInterface"})
+	c.Assert(req.Form["NetworkInterfaceId"], check.DeepEquals, []string{"eni-ffda3197"})
+	c.Assert(req.Form["InstanceId"], check.DeepEquals, []string{"i-1234567890abcdef0"})
+	c.Assert(req.Form["DeviceIndex"], check.DeepEquals, []string{"1"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.AttachmentId, check.Equals, "eni-attach-d94b09b0")
+}
+
+func (s *S) TestDetachNetworkInterfaceExample(c *check.C) {
+	testServer.Response(200, nil, DetachNetworkInterfaceExample)
+
+	resp, err := s.ec2.DetachNetworkInterface("eni-attach-d94b09b0", false)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DetachNetworkInterface"})
+	c.Assert(req.Form["AttachmentId"], check.DeepEquals, []string{"eni-attach-d94b09b0"})
+	c.Assert(req.Form["Force"], check.IsNil)
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.RequestId, check.Equals, "ce540707-0635-46bc-97da-33a8a362a0e8")
+}
+
+func (s *S) TestAssignPrivateIpAddresses(c *check.C) {
+	testServer.Response(200, nil, DetachNetworkInterfaceExample)
+
+	_, err := s.ec2.AssignPrivateIpAddresses("eni-d83388b1", []string{"10.0.2.1", "10.0.2.11"}, 0, true)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"AssignPrivateIpAddresses"})
+	c.Assert(req.Form["NetworkInterfaceId"], check.DeepEquals, []string{"eni-d83388b1"})
+	c.Assert(req.Form["PrivateIpAddress.1"], check.DeepEquals, []string{"10.0.2.1"})
+	c.Assert(req.Form["PrivateIpAddress.2"], check.DeepEquals, []string{"10.0.2.11"})
+	c.Assert(req.Form["SecondaryPrivateIpAddressCount"], check.IsNil)
+	c.Assert(req.Form["AllowReassignment"], check.DeepEquals, []string{"true"})
+
+	c.Assert(err, check.IsNil)
+
+	testServer.Response(200, nil, DetachNetworkInterfaceExample)
+
+	_, err = s.ec2.AssignPrivateIpAddresses("eni-d83388b1", nil, 2, false)
+
+	req = testServer.WaitRequest()
+	c.Assert(req.Form["PrivateIpAddress.1"], check.IsNil)
+	c.Assert(req.Form["SecondaryPrivateIpAddressCount"], check.DeepEquals, []string{"2"})
+	c.Assert(req.Form["AllowReassignment"], check.IsNil)
+
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestUnassignPrivateIpAddresses(c *check.C) {
+	testServer.Response(200, nil, DetachNetworkInterfaceExample)
+
+	_, err := s.ec2.UnassignPrivateIpAddresses("eni-197d9972", []string{"10.0.2.60"})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"UnassignPrivateIpAddresses"})
+	c.Assert(req.Form["NetworkInterfaceId"], check.DeepEquals, []string{"eni-197d9972"})
+	c.Assert(req.Form["PrivateIpAddress.1"], check.DeepEquals, []string{"10.0.2.60"})
+
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestModifyNetworkInterfaceAttribute(c *check.C) {
+	testServer.Response(200, nil, DetachNetworkInterfaceExample)
+
+	sourceDestCheck := false
+	_, err := s.ec2.ModifyNetworkInterfaceAttribute("eni-ffda3197", &ec2.ModifyNetworkInterfaceAttributeOptions{
+		SourceDestCheck: &sourceDestCheck,
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"ModifyNetworkInterfaceAttribute"})
+	c.Assert(req.Form["NetworkInterfaceId"], check.DeepEquals, []string{"eni-ffda3197"})
+	c.Assert(req.Form["SourceDestCheck.Value"], check.DeepEquals, []string{"false"})
+	c.Assert(req.Form["Description.Value"], check.IsNil)
+	c.Assert(req.Form["Attachment.AttachmentId"], check.IsNil)
+
+	c.Assert(err, check.IsNil)
+
+	testServer.Response(200, nil, DetachNetworkInterfaceExample)
+
+	_, err = s.ec2.ModifyNetworkInterfaceAttribute("eni-ffda3197", &ec2.ModifyNetworkInterfaceAttributeOptions{
+		AttachmentId:        "eni-attach-d94b09b0",
+		DeleteOnTermination: true,
+	})
+
+	req = testServer.WaitRequest()
+	c.Assert(req.Form["Attachment.AttachmentId"], check.DeepEquals, []string{"eni-attach-d94b09b0"})
+	c.Assert(req.Form["Attachment.DeleteOnTermination"], check.DeepEquals, []string{"true"})
+	c.Assert(req.Form["SourceDestCheck.Value"], check.IsNil)
+
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestDeleteNetworkInterface(c *check.C) {
+	testServer.Response(200, nil, DetachNetworkInterfaceExample)
+
+	_, err := s.ec2.DeleteNetworkInterface("eni-ffda3197")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DeleteNetworkInterface"})
+	c.Assert(req.Form["NetworkInterfaceId"], check.DeepEquals, []string{"eni-ffda3197"})
+
+	c.Assert(err, check.IsNil)
+}
@@ -0,0 +1,285 @@
+package ec2
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ----------------------------------------------------------------------------
+// Spot instance management functions and types.
+
+// The RequestSpotInstancesOptions type encapsulates options for the
+// respective request in EC2.
+//
+// The LaunchSpecification is encoded as for RunInstances, except that
+// MinCount, MaxCount, DisableAPITermination, ShutdownBehavior,
+// PrivateIPAddress and LaunchTemplate are not supported by spot requests
+// and are ignored.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_RequestSpotInstances.html for more details.
+type RequestSpotInstancesOptions struct {
+	SpotPrice             string // The maximum hourly price; defaults to the On-Demand price
+	InstanceCount         int
+	Type                  string // Valid values: one-time | persistent
+	ValidFrom             time.Time
+	ValidUntil            time.Time
+	LaunchGroup           string
+	AvailabilityZoneGroup string
+	BlockDurationMinutes  int
+	LaunchSpecification   RunInstancesOptions
+}
+
+// SpotInstanceRequest represents details about a spot instance request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_SpotInstanceRequest.html for more details.
+type SpotInstanceRequest struct {
+	Id                       string                  `xml:"spotInstanceRequestId"`
+	SpotPrice                string                  `xml:"spotPrice"`
+	Type                     string                  `xml:"type"`
+	State                    string                  `xml:"state"` // Valid values: open | active | closed | cancelled | failed
+	FaultCode                string                  `xml:"fault>code"`
+	FaultMessage             string                  `xml:"fault>message"`
+	StatusCode               string                  `xml:"status>code"`
+	StatusMessage            string                  `xml:"status>message"`
+	StatusUpdateTime         string                  `xml:"status>updateTime"`
+	ValidFrom                string                  `xml:"validFrom"`
+	ValidUntil               string                  `xml:"validUntil"`
+	LaunchGroup              string                  `xml:"launchGroup"`
+	AvailabilityZoneGroup    string                  `xml:"availabilityZoneGroup"`
+	LaunchSpecification      SpotLaunchSpecification `xml:"launchSpecification"`
+	InstanceId               string                  `xml:"instanceId"` // The instance fulfilling the request, if any
+	CreateTime               string                  `xml:"createTime"`
+	ProductDescription       string                  `xml:"productDescription"`
+	LaunchedAvailabilityZone string                  `xml:"launchedAvailabilityZone"`
+	Tags                     []Tag                   `xml:"tagSet>item"`
+}
+
+// SpotLaunchSpecification describes the instances launched to fulfill a
+// spot instance request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_LaunchSpecification.html for more details.
+type SpotLaunchSpecification struct {
+	ImageId            string               `xml:"imageId"`
+	KeyName            string               `xml:"keyName"`
+	SecurityGroups     []SecurityGroup      `xml:"groupSet>item"`
+	InstanceType       string               `xml:"instanceType"`
+	AvailabilityZone   string               `xml:"placement>availabilityZone"`
+	KernelId           string               `xml:"kernelId"`
+	RamdiskId          string               `xml:"ramdiskId"`
+	SubnetId           string               `xml:"subnetId"`
+	BlockDevices       []BlockDeviceMapping `xml:"blockDeviceMapping>item"`
+	Monitoring         bool                 `xml:"monitoring>enabled"`
+	IamInstanceProfile IamInstanceProfile   `xml:"iamInstanceProfile"`
+	EbsOptimized       bool                 `xml:"ebsOptimized"`
+}
+
+// Response to a RequestSpotInstances or DescribeSpotInstanceRequests
+// request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeSpotInstanceRequests.html for more details.
+type SpotInstanceRequestsResp struct {
+	RequestId    string                `xml:"requestId"`
+	SpotRequests []SpotInstanceRequest `xml:"spotInstanceRequestSet>item"`
+}
+
+// RequestSpotInstances requests spot instances. The returned requests
+// are usually open; see WaitForSpotInstances to wait until they are
+// fulfilled.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_RequestSpotInstances.html for more details.
+func (ec2 *EC2) RequestSpotInstances(options *RequestSpotInstancesOptions) (resp *SpotInstanceRequestsResp, err error) {
+	params := makeParams("RequestSpotInstances")
+	options.LaunchSpecification.addLaunchParams(params, "LaunchSpecification.")
+	if options.SpotPrice != "" {
+		params["SpotPrice"] = options.SpotPrice
+	}
+	if options.InstanceCount != 0 {
+		params["InstanceCount"] = strconv.Itoa(options.InstanceCount)
+	}
+	if options.Type != "" {
+		params["Type"] = options.Type
+	}
+	if !options.ValidFrom.IsZero() {
+		params["ValidFrom"] = options.ValidFrom.In(time.UTC).Format(time.RFC3339)
+	}
+	if !options.ValidUntil.IsZero() {
+		params["ValidUntil"] = options.ValidUntil.In(time.UTC).Format(time.RFC3339)
+	}
+	if options.LaunchGroup != "" {
+		params["LaunchGroup"] = options.LaunchGroup
+	}
+	if options.AvailabilityZoneGroup != "" {
+		params["AvailabilityZoneGroup"] = options.AvailabilityZoneGroup
+	}
+	if options.BlockDurationMinutes != 0 {
+		params["BlockDurationMinutes"] = strconv.Itoa(options.BlockDurationMinutes)
+	}
+	token, err := clientToken()
+	if err != nil {
+		return nil, err
+	}
+	params["ClientToken"] = token
+
+	resp = &SpotInstanceRequestsResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DescribeSpotInstanceRequests returns details about spot instance
+// requests. The ids and filter parameters, if provided, limit the
+// requests returned.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeSpotInstanceRequests.html for more details.
+func (ec2 *EC2) DescribeSpotInstanceRequests(ids []string, filter *Filter) (resp *SpotInstanceRequestsResp, err error) {
+	params := makeParams("DescribeSpotInstanceRequests")
+	addParamsList(params, "SpotInstanceRequestId", ids)
+	filter.addParams(params)
+
+	resp = &SpotInstanceRequestsResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// CancelledSpotInstanceRequest describes a cancelled spot instance request.
+type CancelledSpotInstanceRequest struct {
+	Id    string `xml:"spotInstanceRequestId"`
+	State string `xml:"state"`
+}
+
+// Response to a CancelSpotInstanceRequests request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CancelSpotInstanceRequests.html for more details.
+type CancelSpotInstanceRequestsResp struct {
+	RequestId             string                         `xml:"requestId"`
+	CancelledSpotRequests []CancelledSpotInstanceRequest `xml:"spotInstanceRequestSet>item"`
+}
+
+// CancelSpotInstanceRequests cancels spot instance requests. Instances
+// already launched for them are not terminated.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CancelSpotInstanceRequests.html for more details.
+func (ec2 *EC2) CancelSpotInstanceRequests(ids []string) (resp *CancelSpotInstanceRequestsResp, err error) {
+	params := makeParams("CancelSpotInstanceRequests")
+	addParamsList(params, "SpotInstanceRequestId", ids)
+
+	resp = &CancelSpotInstanceRequestsResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// The SpotPriceHistoryOptions type encapsulates options for the
+// respective request in EC2. All fields are optional.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeSpotPriceHistory.html for more details.
+type SpotPriceHistoryOptions struct {
+	StartTime           time.Time
+	EndTime             time.Time
+	InstanceTypes       []string
+	ProductDescriptions []string // e.g. "Linux/UNIX"
+	AvailabilityZone    string
+	Filter              *Filter
+	MaxResults          int
+	NextToken           string
+}
+
+// SpotPrice represents the spot price of an instance type at a point in
+// time.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_SpotPrice.html for more details.
+type SpotPrice struct {
+	InstanceType       string `xml:"instanceType"`
+	ProductDescription string `xml:"productDescription"`
+	SpotPrice          string `xml:"spotPrice"`
+	Timestamp          string `xml:"timestamp"`
+	AvailabilityZone   string `xml:"availabilityZone"`
+}
+
+// Response to a DescribeSpotPriceHistory request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeSpotPriceHistory.html for more details.
+type SpotPriceHistoryResp struct {
+	RequestId string      `xml:"requestId"`
+	History   []SpotPrice `xml:"spotPriceHistorySet>item"`
+	NextToken string      `xml:"nextToken"` // Set when more results are available
+}
+
+// DescribeSpotPriceHistory returns a page of the spot price history.
+// Pass the NextToken of the response in options to get the next page.
+// The options may be nil.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeSpotPriceHistory.html for more details.
+func (ec2 *EC2) DescribeSpotPriceHistory(options *SpotPriceHistoryOptions) (resp *SpotPriceHistoryResp, err error) {
+	if options == nil {
+		options = &SpotPriceHistoryOptions{}
+	}
+	params := makeParams("DescribeSpotPriceHistory")
+	if !options.StartTime.IsZero() {
+		params["StartTime"] = options.StartTime.In(time.UTC).Format(time.RFC3339)
+	}
+	if !options.EndTime.IsZero() {
+		params["EndTime"] = options.EndTime.In(time.UTC).Format(time.RFC3339)
+	}
+	addParamsList(params, "InstanceType", options.InstanceTypes)
+	addParamsList(params, "ProductDescription", options.ProductDescriptions)
+	if options.AvailabilityZone != "" {
+		params["AvailabilityZone"] = options.AvailabilityZone
+	}
+	options.Filter.addParams(params)
+	if options.MaxResults != 0 {
+		params["MaxResults"] = strconv.Itoa(options.MaxResults)
+	}
+	if options.NextToken != "" {
+		params["NextToken"] = options.NextToken
+	}
+
+	resp = &SpotPriceHistoryResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DescribeSpotPriceHistoryPages calls fn with every page of the spot
+// price history matching options, until fn returns false or there are
+// no more pages. The NextToken of options, which may be nil, is used as
+// the starting point.
+func (ec2 *EC2) DescribeSpotPriceHistoryPages(options *SpotPriceHistoryOptions, fn func(page *SpotPriceHistoryResp) bool) error {
+	var opts SpotPriceHistoryOptions
+	if options != nil {
+		opts = *options
+	}
+	for {
+		resp, err := ec2.DescribeSpotPriceHistory(&opts)
+		if err != nil {
+			return err
+		}
+		if !fn(resp) || resp.NextToken == "" {
+			return nil
+		}
+		opts.NextToken = resp.NextToken
+	}
+}
+
+// SpotRequestError is returned by WaitForSpotInstances when a spot
+// instance request is closed, cancelled or failed before being fulfilled.
+type SpotRequestError struct {
+	Id            string
+	State         string
+	StatusCode    string
+	StatusMessage string
+}
+
+func (err *SpotRequestError) Error() string {
+	return fmt.Sprintf("spot instance request %s is %s: %s (%s)", err.Id, err.State, err.StatusMessage, err.StatusCode)
+}
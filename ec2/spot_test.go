@@ -0,0 +1,183 @@
+package ec2_test
+
+import (
+	"time"
+
+	"github.com/crowdmob/goamz/ec2"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestRequestSpotInstancesExample(c *check.C) {
+	testServer.Response(200, nil, RequestSpotInstancesExample)
+
+	options := ec2.RequestSpotInstancesOptions{
+		SpotPrice:             "0.5",
+		InstanceCount:         2,
+		Type:                  "one-time",
+		ValidUntil:            time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC),
+		AvailabilityZoneGroup: "MyAzGroup",
+		LaunchSpecification: ec2.RunInstancesOptions{
+			ImageId:          "ami-1a2b3c4d",
+			KeyName:          "my-key-pair",
+			InstanceType:     "m3.medium",
+			SecurityGroups:   []ec2.SecurityGroup{{Id: "sg-1a2b3c4d"}},
+			AvailabilityZone: "us-west-2a",
+			BlockDeviceMappings: []ec2.BlockDeviceMapping{
+				{DeviceName: "/dev/sdb", VolumeSize: 20, VolumeType: "gp2", DeleteOnTermination: true},
+			},
+			MinCount: 3, // ignored
+		},
+	}
+	resp, err := s.ec2.RequestSpotInstances(&options)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"RequestSpotInstances"})
+	c.Assert(req.Form["SpotPrice"], check.DeepEquals, []string{"0.5"})
+	c.Assert(req.Form["InstanceCount"], check.DeepEquals, []string{"2"})
+	c.Assert(req.Form["Type"], check.DeepEquals, []string{"one-time"})
+	c.Assert(req.Form["ValidUntil"], check.DeepEquals, []string{"2015-01-01T00:00:00Z"})
+	c.Assert(req.Form["ValidFrom"], check.IsNil)
+	c.Assert(req.Form["AvailabilityZoneGroup"], check.DeepEquals, []string{"MyAzGroup"})
+	c.Assert(req.Form["LaunchSpecification.ImageId"], check.DeepEquals, []string{"ami-1a2b3c4d"})
+	c.Assert(req.Form["LaunchSpecification.KeyName"], check.DeepEquals, []string{"my-key-pair"})
+	c.Assert(req.Form["LaunchSpecification.InstanceType"], check.DeepEquals, []string{"m3.medium"})
+	c.Assert(req.Form["LaunchSpecification.SecurityGroupId.1"], check.DeepEquals, []string{"sg-1a2b3c4d"})
+	c.Assert(req.Form["LaunchSpecification.Placement.AvailabilityZone"], check.DeepEquals, []string{"us-west-2a"})
+	c.Assert(req.Form["LaunchSpecification.BlockDeviceMapping.1.DeviceName"], check.DeepEquals, []string{"/dev/sdb"})
+	c.Assert(req.Form["LaunchSpecification.BlockDeviceMapping.1.Ebs.VolumeSize"], check.DeepEquals, []string{"20"})
+	c.Assert(req.Form["LaunchSpecification.BlockDeviceMapping.1.Ebs.VolumeType"], check.DeepEquals, []string{"gp2"})
+	c.Assert(req.Form["LaunchSpecification.BlockDeviceMapping.1.Ebs.DeleteOnTermination"], check.DeepEquals, []string{"true"})
+	c.Assert(req.Form["LaunchSpecification.MinCount"], check.IsNil)
+	c.Assert(req.Form["MinCount"], check.IsNil)
+	c.Assert(req.Form["ImageId"], check.IsNil)
+	c.Assert(req.Form["ClientToken"], check.HasLen, 1)
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.RequestId, check.Equals, "59dbff89-35bd-4eac-99ed-be587EXAMPLE")
+	c.Assert(resp.SpotRequests, check.HasLen, 1)
+	r := resp.SpotRequests[0]
+	c.Assert(r.Id, check.Equals, "sir-1a2b3c4d")
+	c.Assert(r.SpotPrice, check.Equals, "0.5")
+	c.Assert(r.State, check.Equals, "open")
+	c.Assert(r.StatusCode, check.Equals, "pending-evaluation")
+	c.Assert(r.InstanceId, check.Equals, "")
+	c.Assert(r.LaunchSpecification.ImageId, check.Equals, "ami-1a2b3c4d")
+	c.Assert(r.LaunchSpecification.AvailabilityZone, check.Equals, "us-west-2a")
+	c.Assert(r.LaunchSpecification.SecurityGroups, check.DeepEquals, []ec2.SecurityGroup{{Id: "sg-1a2b3c4d", Name: "websrv"}})
+}
+
+func (s *S) TestDescribeSpotInstanceRequestsExample(c *check.C) {
+	testServer.Response(200, nil, DescribeSpotInstanceRequestsExample)
+
+	filter := ec2.NewFilter()
+	filter.Add("state", "active")
+
+	resp, err := s.ec2.DescribeSpotInstanceRequests([]string{"sir-1a2b3c4d"}, filter)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeSpotInstanceRequests"})
+	c.Assert(req.Form["SpotInstanceRequestId.1"], check.DeepEquals, []string{"sir-1a2b3c4d"})
+	c.Assert(req.Form["Filter.1.Name"], check.DeepEquals, []string{"state"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.SpotRequests, check.HasLen, 1)
+	r := resp.SpotRequests[0]
+	c.Assert(r.State, check.Equals, "active")
+	c.Assert(r.StatusCode, check.Equals, "fulfilled")
+	c.Assert(r.InstanceId, check.Equals, "i-1234567890abcdef0")
+	c.Assert(r.LaunchedAvailabilityZone, check.Equals, "us-west-1b")
+}
+
+func (s *S) TestCancelSpotInstanceRequestsExample(c *check.C) {
+	testServer.Response(200, nil, CancelSpotInstanceRequestsExample)
+
+	resp, err := s.ec2.CancelSpotInstanceRequests([]string{"sir-1a2b3c4d"})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"CancelSpotInstanceRequests"})
+	c.Assert(req.Form["SpotInstanceRequestId.1"], check.DeepEquals, []string{"sir-1a2b3c4d"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.CancelledSpotRequests, check.DeepEquals, []ec2.CancelledSpotInstanceRequest{{Id: "sir-1a2b3c4d", State: "cancelled"}})
+}
+
+func (s *S) TestDescribeSpotPriceHistoryExample(c *check.C) {
+	testServer.Response(200, nil, DescribeSpotPriceHistoryExample)
+
+	resp, err := s.ec2.DescribeSpotPriceHistory(&ec2.SpotPriceHistoryOptions{
+		StartTime:           time.Date(2014, 1, 6, 7, 8, 9, 0, time.UTC),
+		InstanceTypes:       []string{"m3.medium"},
+		ProductDescriptions: []string{"Linux/UNIX"},
+		MaxResults:          2,
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeSpotPriceHistory"})
+	c.Assert(req.Form["StartTime"], check.DeepEquals, []string{"2014-01-06T07:08:09Z"})
+	c.Assert(req.Form["EndTime"], check.IsNil)
+	c.Assert(req.Form["InstanceType.1"], check.DeepEquals, []string{"m3.medium"})
+	c.Assert(req.Form["ProductDescription.1"], check.DeepEquals, []string{"Linux/UNIX"})
+	c.Assert(req.Form["MaxResults"], check.DeepEquals, []string{"2"})
+	c.Assert(req.Form["NextToken"], check.IsNil)
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.NextToken, check.Equals, "aYmDAwcmVzcG9uc2U")
+	c.Assert(resp.History, check.HasLen, 2)
+	c.Assert(resp.History[0], check.DeepEquals, ec2.SpotPrice{
+		InstanceType:       "m3.medium",
+		ProductDescription: "Linux/UNIX",
+		SpotPrice:          "0.0287",
+		Timestamp:          "2014-01-06T04:32:53.000Z",
+		AvailabilityZone:   "us-west-2a",
+	})
+}
+
+func (s *S) TestDescribeSpotPriceHistoryPages(c *check.C) {
+	testServer.Response(200, nil, DescribeSpotPriceHistoryExample)
+	testServer.Response(200, nil, DescribeSpotPriceHistoryLastPageExample)
+
+	var zones []string
+	err := s.ec2.DescribeSpotPriceHistoryPages(&ec2.SpotPriceHistoryOptions{MaxResults: 2}, func(page *ec2.SpotPriceHistoryResp) bool {
+		for _, p := range page.History {
+			zones = append(zones, p.AvailabilityZone)
+		}
+		return true
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["NextToken"], check.IsNil)
+	req = testServer.WaitRequest()
+	c.Assert(req.Form["NextToken"], check.DeepEquals, []string{"aYmDAwcmVzcG9uc2U"})
+	c.Assert(req.Form["MaxResults"], check.DeepEquals, []string{"2"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(zones, check.DeepEquals, []string{"us-west-2a", "us-west-2b", "us-west-2c"})
+}
+
+func (s *S) TestDescribeSpotPriceHistoryPagesStop(c *check.C) {
+	testServer.Response(200, nil, DescribeSpotPriceHistoryExample)
+
+	pages := 0
+	err := s.ec2.DescribeSpotPriceHistoryPages(nil, func(page *ec2.SpotPriceHistoryResp) bool {
+		pages++
+		return false
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["NextToken"], check.IsNil)
+	c.Assert(err, check.IsNil)
+	c.Assert(pages, check.Equals, 1)
+}
+
+func (s *S) TestDescribeSpotPriceHistoryNilOptions(c *check.C) {
+	testServer.Response(200, nil, DescribeSpotPriceHistoryExample)
+
+	resp, err := s.ec2.DescribeSpotPriceHistory(nil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeSpotPriceHistory"})
+	c.Assert(req.Form["StartTime"], check.IsNil)
+	c.Assert(req.Form["MaxResults"], check.IsNil)
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.History, check.Not(check.HasLen), 0)
+}
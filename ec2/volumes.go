@@ -0,0 +1,452 @@
+package ec2
+
+import (
+	"github.com/crowdmob/goamz/aws"
+	"strconv"
+)
+
+// ----------------------------------------------------------------------------
+// Volume management functions and types.
+
+// Volume represents details about an EBS volume.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_Volume.html for more details.
+type Volume struct {
+	Id               string             `xml:"volumeId"`
+	Size             int64              `xml:"size"` // The size of the volume, in GiBs
+	SnapshotId       string             `xml:"snapshotId"`
+	AvailabilityZone string             `xml:"availabilityZone"`
+	Status           string             `xml:"status"` // Valid values: creating | available | in-use | deleting | deleted | error
+	CreateTime       string             `xml:"createTime"`
+	Attachments      []VolumeAttachment `xml:"attachmentSet>item"`
+	VolumeType       string             `xml:"volumeType"` // Valid values: standard | io1 | gp2 | sc1 | st1
+	IOPS             int64              `xml:"iops"`
+	Encrypted        bool               `xml:"encrypted"`
+	KmsKeyId         string             `xml:"kmsKeyId"`
+	Tags             []Tag              `xml:"tagSet>item"`
+}
+
+// VolumeAttachment describes the attachment of an EBS volume to an instance.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_VolumeAttachment.html for more details.
+type VolumeAttachment struct {
+	VolumeId            string `xml:"volumeId"`
+	InstanceId          string `xml:"instanceId"`
+	Device              string `xml:"device"`
+	Status              string `xml:"status"` // Valid values: attaching | attached | detaching | detached
+	AttachTime          string `xml:"attachTime"`
+	DeleteOnTermination bool   `xml:"deleteOnTermination"`
+}
+
+// The CreateVolumeOptions type encapsulates options for the respective
+// request in EC2. Either Size or SnapshotId must be set.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateVolume.html for more details.
+type CreateVolumeOptions struct {
+	AvailabilityZone string
+	Size             int64 // In GiBs; defaults to the snapshot size
+	SnapshotId       string
+	VolumeType       string
+	IOPS             int64 // Required for io1 volumes
+	Encrypted        bool
+	KmsKeyId         string // Requires Encrypted; defaults to the account's EBS key
+}
+
+// Response to a CreateVolume request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateVolume.html for more details.
+type CreateVolumeResp struct {
+	RequestId string `xml:"requestId"`
+	Volume
+}
+
+// CreateVolume creates an EBS volume, optionally restoring it from a
+// snapshot, that can be attached to any instance in the same Availability
+// Zone.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateVolume.html for more details.
+func (ec2 *EC2) CreateVolume(options *CreateVolumeOptions) (resp *CreateVolumeResp, err error) {
+	params := makeParams("CreateVolume")
+	params["AvailabilityZone"] = options.AvailabilityZone
+	if options.Size != 0 {
+		params["Size"] = strconv.FormatInt(options.Size, 10)
+	}
+	if options.SnapshotId != "" {
+		params["SnapshotId"] = options.SnapshotId
+	}
+	if options.VolumeType != "" {
+		params["VolumeType"] = options.VolumeType
+	}
+	if options.IOPS != 0 {
+		params["Iops"] = strconv.FormatInt(options.IOPS, 10)
+	}
+	if options.Encrypted {
+		params["Encrypted"] = "true"
+	}
+	if options.KmsKeyId != "" {
+		params["KmsKeyId"] = options.KmsKeyId
+	}
+
+	resp = &CreateVolumeResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Response to an AttachVolume or DetachVolume request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_AttachVolume.html for more details.
+type VolumeAttachmentResp struct {
+	RequestId string `xml:"requestId"`
+	VolumeAttachment
+}
+
+// AttachVolume attaches an EBS volume to a running or stopped instance
+// and exposes it to the instance with the given device name.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_AttachVolume.html for more details.
+func (ec2 *EC2) AttachVolume(volumeId, instanceId, device string) (resp *VolumeAttachmentResp, err error) {
+	params := makeParams("AttachVolume")
+	params["VolumeId"] = volumeId
+	params["InstanceId"] = instanceId
+	params["Device"] = device
+
+	resp = &VolumeAttachmentResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DetachVolume detaches an EBS volume from an instance. The instanceId
+// and device parameters are optional. If force is true, the volume is
+// detached even if the instance fails to release it, which may lose data.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DetachVolume.html for more details.
+func (ec2 *EC2) DetachVolume(volumeId, instanceId, device string, force bool) (resp *VolumeAttachmentResp, err error) {
+	params := makeParams("DetachVolume")
+	params["VolumeId"] = volumeId
+	if instanceId != "" {
+		params["InstanceId"] = instanceId
+	}
+	if device != "" {
+		params["Device"] = device
+	}
+	if force {
+		params["Force"] = "true"
+	}
+
+	resp = &VolumeAttachmentResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Response to a DescribeVolumes request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeVolumes.html for more details.
+type VolumesResp struct {
+	RequestId string   `xml:"requestId"`
+	Volumes   []Volume `xml:"volumeSet>item"`
+}
+
+// DescribeVolumes returns details about EBS volumes. The ids and filter
+// parameters, if provided, limit the volumes returned.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeVolumes.html for more details.
+func (ec2 *EC2) DescribeVolumes(ids []string, filter *Filter) (resp *VolumesResp, err error) {
+	params := makeParams("DescribeVolumes")
+	addParamsList(params, "VolumeId", ids)
+	filter.addParams(params)
+
+	resp = &VolumesResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// VolumeStatus describes the status checks, scheduled events and
+// pending actions of a volume.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_VolumeStatusItem.html for more details.
+type VolumeStatus struct {
+	VolumeId         string               `xml:"volumeId"`
+	AvailabilityZone string               `xml:"availabilityZone"`
+	Status           string               `xml:"volumeStatus>status"` // Valid values: ok | impaired | warning | insufficient-data
+	Details          []VolumeStatusDetail `xml:"volumeStatus>details>item"`
+	Events           []VolumeStatusEvent  `xml:"eventsSet>item"`
+	Actions          []VolumeStatusAction `xml:"actionsSet>item"`
+}
+
+// VolumeStatusDetail reports the result of a single volume status check.
+type VolumeStatusDetail struct {
+	Name   string `xml:"name"` // Valid values: io-enabled | io-performance
+	Status string `xml:"status"`
+}
+
+// VolumeStatusEvent describes an event affecting a volume.
+type VolumeStatusEvent struct {
+	EventId     string `xml:"eventId"`
+	EventType   string `xml:"eventType"`
+	Description string `xml:"description"`
+	NotBefore   string `xml:"notBefore"`
+	NotAfter    string `xml:"notAfter"`
+}
+
+// VolumeStatusAction describes an action required to resolve an
+// impaired volume.
+type VolumeStatusAction struct {
+	Code        string `xml:"code"`
+	EventId     string `xml:"eventId"`
+	EventType   string `xml:"eventType"`
+	Description string `xml:"description"`
+}
+
+// Response to a DescribeVolumeStatus request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeVolumeStatus.html for more details.
+type VolumeStatusResp struct {
+	RequestId string         `xml:"requestId"`
+	Volumes   []VolumeStatus `xml:"volumeStatusSet>item"`
+}
+
+// DescribeVolumeStatus returns the status of EBS volumes. The ids and
+// filter parameters, if provided, limit the volumes returned.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeVolumeStatus.html for more details.
+func (ec2 *EC2) DescribeVolumeStatus(ids []string, filter *Filter) (resp *VolumeStatusResp, err error) {
+	params := makeParams("DescribeVolumeStatus")
+	addParamsList(params, "VolumeId", ids)
+	filter.addParams(params)
+
+	resp = &VolumeStatusResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// The ModifyVolumeOptions type encapsulates options for the respective
+// request in EC2. Zero fields are left unchanged.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_ModifyVolume.html for more details.
+type ModifyVolumeOptions struct {
+	Size       int64
+	VolumeType string
+	IOPS       int64
+}
+
+// VolumeModification describes the progress of a volume modification.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_VolumeModification.html for more details.
+type VolumeModification struct {
+	VolumeId           string `xml:"volumeId"`
+	ModificationState  string `xml:"modificationState"` // Valid values: modifying | optimizing | completed | failed
+	StatusMessage      string `xml:"statusMessage"`
+	TargetSize         int64  `xml:"targetSize"`
+	TargetIOPS         int64  `xml:"targetIops"`
+	TargetVolumeType   string `xml:"targetVolumeType"`
+	OriginalSize       int64  `xml:"originalSize"`
+	OriginalIOPS       int64  `xml:"originalIops"`
+	OriginalVolumeType string `xml:"originalVolumeType"`
+	Progress           int    `xml:"progress"`
+	StartTime          string `xml:"startTime"`
+	EndTime            string `xml:"endTime"`
+}
+
+// Response to a ModifyVolume request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_ModifyVolume.html for more details.
+type ModifyVolumeResp struct {
+	RequestId    string             `xml:"requestId"`
+	Modification VolumeModification `xml:"volumeModification"`
+}
+
+// ModifyVolume changes the size, type or provisioned IOPS of an EBS
+// volume while it remains in use.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_ModifyVolume.html for more details.
+func (ec2 *EC2) ModifyVolume(volumeId string, options *ModifyVolumeOptions) (resp *ModifyVolumeResp, err error) {
+	params := makeParams("ModifyVolume")
+	params["VolumeId"] = volumeId
+	if options.Size != 0 {
+		params["Size"] = strconv.FormatInt(options.Size, 10)
+	}
+	if options.VolumeType != "" {
+		params["VolumeType"] = options.VolumeType
+	}
+	if options.IOPS != 0 {
+		params["Iops"] = strconv.FormatInt(options.IOPS, 10)
+	}
+
+	resp = &ModifyVolumeResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DeleteVolume deletes an EBS volume. The volume must be in the
+// available state, i.e. not attached to an instance.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DeleteVolume.html for more details.
+func (ec2 *EC2) DeleteVolume(volumeId string) (resp *SimpleResp, err error) {
+	params := makeParams("DeleteVolume")
+	params["VolumeId"] = volumeId
+
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// ----------------------------------------------------------------------------
+// Snapshot copying and sharing.
+
+// The CopySnapshotOptions type encapsulates options for the respective
+// request in EC2.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CopySnapshot.html for more details.
+type CopySnapshotOptions struct {
+	SourceRegion     aws.Region
+	SourceSnapshotId string
+	Description      string
+	Encrypted        bool
+	KmsKeyId         string
+
+	// PresignedUrl is required when copying an encrypted snapshot
+	// between regions.
+	PresignedUrl string
+}
+
+// Response to a CopySnapshot request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CopySnapshot.html for more details.
+type CopySnapshotResp struct {
+	RequestId  string `xml:"requestId"`
+	SnapshotId string `xml:"snapshotId"`
+}
+
+// CopySnapshot copies a snapshot from the given source region into the
+// current region.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CopySnapshot.html for more details.
+func (ec2 *EC2) CopySnapshot(options *CopySnapshotOptions) (resp *CopySnapshotResp, err error) {
+	params := makeParams("CopySnapshot")
+	params["SourceRegion"] = options.SourceRegion.Name
+	params["SourceSnapshotId"] = options.SourceSnapshotId
+	if options.Description != "" {
+		params["Description"] = options.Description
+	}
+	if options.Encrypted {
+		params["Encrypted"] = "true"
+	}
+	if options.KmsKeyId != "" {
+		params["KmsKeyId"] = options.KmsKeyId
+	}
+	if options.PresignedUrl != "" {
+		params["PresignedUrl"] = options.PresignedUrl
+	}
+
+	resp = &CopySnapshotResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// CreateVolumePermission grants an account, or everyone when Group is
+// "all", permission to create volumes from a snapshot.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateVolumePermission.html for more details.
+type CreateVolumePermission struct {
+	UserId string `xml:"userId"`
+	Group  string `xml:"group"`
+}
+
+// Response to a DescribeSnapshotAttribute request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeSnapshotAttribute.html for more details.
+type SnapshotAttributeResp struct {
+	RequestId               string                   `xml:"requestId"`
+	SnapshotId              string                   `xml:"snapshotId"`
+	CreateVolumePermissions []CreateVolumePermission `xml:"createVolumePermission>item"`
+	ProductCodes            []ProductCode            `xml:"productCodes>item"`
+}
+
+// DescribeSnapshotAttribute returns the given attribute of a snapshot.
+// Valid attributes are "createVolumePermission" and "productCodes".
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeSnapshotAttribute.html for more details.
+func (ec2 *EC2) DescribeSnapshotAttribute(snapshotId, attribute string) (resp *SnapshotAttributeResp, err error) {
+	params := makeParams("DescribeSnapshotAttribute")
+	params["SnapshotId"] = snapshotId
+	params["Attribute"] = attribute
+
+	resp = &SnapshotAttributeResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// ModifySnapshotAttribute shares a snapshot by adding and removing
+// permissions to create volumes from it.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_ModifySnapshotAttribute.html for more details.
+func (ec2 *EC2) ModifySnapshotAttribute(snapshotId string, add, remove []CreateVolumePermission) (resp *SimpleResp, err error) {
+	params := makeParams("ModifySnapshotAttribute")
+	params["SnapshotId"] = snapshotId
+	addVolumePermissions(params, "CreateVolumePermission.Add", add)
+	addVolumePermissions(params, "CreateVolumePermission.Remove", remove)
+
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func addVolumePermissions(params map[string]string, label string, perms []CreateVolumePermission) {
+	for i, perm := range perms {
+		prefix := label + "." + strconv.Itoa(i+1)
+		if perm.UserId != "" {
+			params[prefix+".UserId"] = perm.UserId
+		}
+		if perm.Group != "" {
+			params[prefix+".Group"] = perm.Group
+		}
+	}
+}
+
+// ResetSnapshotAttribute restores the given attribute of a snapshot to
+// its default value. Only "createVolumePermission" can be reset, which
+// makes the snapshot private to its owner again.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_ResetSnapshotAttribute.html for more details.
+func (ec2 *EC2) ResetSnapshotAttribute(snapshotId, attribute string) (resp *SimpleResp, err error) {
+	params := makeParams("ResetSnapshotAttribute")
+	params["SnapshotId"] = snapshotId
+	params["Attribute"] = attribute
+
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
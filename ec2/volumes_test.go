@@ -0,0 +1,253 @@
+package ec2_test
+
+import (
+	"github.com/crowdmob/goamz/aws"
+	"github.com/crowdmob/goamz/ec2"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestCreateVolumeExample(c *check.C) {
+	testServer.Response(200, nil, CreateVolumeExample)
+
+	options := &ec2.CreateVolumeOptions{
+		AvailabilityZone: "us-east-1a",
+		Size:             80,
+		SnapshotId:       "snap-1a2b3c4d",
+		VolumeType:       "io1",
+		IOPS:             3000,
+		Encrypted:        true,
+		KmsKeyId:         "arn:aws:kms:us-east-1:012345678910:key/abcd1234-a123-456a-a12b-a123b4cd56ef",
+	}
+	resp, err := s.ec2.CreateVolume(options)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"CreateVolume"})
+	c.Assert(req.Form["AvailabilityZone"], check.DeepEquals, []string{"us-east-1a"})
+	c.Assert(req.Form["Size"], check.DeepEquals, []string{"80"})
+	c.Assert(req.Form["SnapshotId"], check.DeepEquals, []string{"snap-1a2b3c4d"})
+	c.Assert(req.Form["VolumeType"], check.DeepEquals, []string{"io1"})
+	c.Assert(req.Form["Iops"], check.DeepEquals, []string{"3000"})
+	c.Assert(req.Form["Encrypted"], check.DeepEquals, []string{"true"})
+	c.Assert(req.Form["KmsKeyId"], check.DeepEquals, []string{options.KmsKeyId})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.RequestId, check.Equals, "59dbff89-35bd-4eac-99ed-be587EXAMPLE")
+	c.Assert(resp.Volume.Id, check.Equals, "vol-1234567890abcdef0")
+	c.Assert(resp.Volume.Size, check.Equals, int64(80))
+	c.Assert(resp.Volume.SnapshotId, check.Equals, "snap-1a2b3c4d")
+	c.Assert(resp.Volume.Status, check.Equals, "creating")
+	c.Assert(resp.Volume.VolumeType, check.Equals, "io1")
+	c.Assert(resp.Volume.IOPS, check.Equals, int64(3000))
+	c.Assert(resp.Volume.Encrypted, check.Equals, true)
+	c.Assert(resp.Volume.KmsKeyId, check.Equals, options.KmsKeyId)
+}
+
+func (s *S) TestCreateVolumeDefaults(c *check.C) {
+	testServer.Response(200, nil, CreateVolumeExample)
+
+	_, err := s.ec2.CreateVolume(&ec2.CreateVolumeOptions{AvailabilityZone: "us-east-1a", Size: 10})
+	c.Assert(err, check.IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Size"], check.DeepEquals, []string{"10"})
+	c.Assert(req.Form["SnapshotId"], check.IsNil)
+	c.Assert(req.Form["VolumeType"], check.IsNil)
+	c.Assert(req.Form["Iops"], check.IsNil)
+	c.Assert(req.Form["Encrypted"], check.IsNil)
+	c.Assert(req.Form["KmsKeyId"], check.IsNil)
+}
+
+func (s *S) TestAttachVolumeExample(c *check.C) {
+	testServer.Response(200, nil, AttachVolumeExample)
+
+	resp, err := s.ec2.AttachVolume("vol-1234567890abcdef0", "i-1234567890abcdef0", "/dev/sdh")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"AttachVolume"})
+	c.Assert(req.Form["VolumeId"], check.DeepEquals, []string{"vol-1234567890abcdef0"})
+	c.Assert(req.Form["InstanceId"], check.DeepEquals, []string{"i-1234567890abcdef0"})
+	c.Assert(req.Form["Device"], check.DeepEquals, []string{"/dev/sdh"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.RequestId, check.Equals, "59dbff89-35bd-4eac-99ed-be587EXAMPLE")
+	c.Assert(resp.VolumeId, check.Equals, "vol-1234567890abcdef0")
+	c.Assert(resp.InstanceId, check.Equals, "i-1234567890abcdef0")
+	c.Assert(resp.Device, check.Equals, "/dev/sdh")
+	c.Assert(resp.Status, check.Equals, "attaching")
+	c.Assert(resp.AttachTime, check.Equals, "2014-02-22T18:00:03.000Z")
+}
+
+func (s *S) TestDetachVolumeExample(c *check.C) {
+	testServer.Response(200, nil, DetachVolumeExample)
+
+	resp, err := s.ec2.DetachVolume("vol-1234567890abcdef0", "", "", true)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DetachVolume"})
+	c.Assert(req.Form["VolumeId"], check.DeepEquals, []string{"vol-1234567890abcdef0"})
+	c.Assert(req.Form["InstanceId"], check.IsNil)
+	c.Assert(req.Form["Device"], check.IsNil)
+	c.Assert(req.Form["Force"], check.DeepEquals, []string{"true"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.Status, check.Equals, "detaching")
+}
+
+func (s *S) TestDescribeVolumesExample(c *check.C) {
+	testServer.Response(200, nil, DescribeVolumesExample)
+
+	filter := ec2.NewFilter()
+	filter.Add("attachment.instance-id", "i-1234567890abcdef0")
+
+	resp, err := s.ec2.DescribeVolumes([]string{"vol-1", "vol-2"}, filter)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeVolumes"})
+	c.Assert(req.Form["VolumeId.1"], check.DeepEquals, []string{"vol-1"})
+	c.Assert(req.Form["VolumeId.2"], check.DeepEquals, []string{"vol-2"})
+	c.Assert(req.Form["Filter.1.Name"], check.DeepEquals, []string{"attachment.instance-id"})
+	c.Assert(req.Form["Filter.1.Value.1"], check.DeepEquals, []string{"i-1234567890abcdef0"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.Volumes, check.HasLen, 1)
+
+	v0 := resp.Volumes[0]
+	c.Assert(v0.Id, check.Equals, "vol-1234567890abcdef0")
+	c.Assert(v0.Size, check.Equals, int64(80))
+	c.Assert(v0.Status, check.Equals, "in-use")
+	c.Assert(v0.VolumeType, check.Equals, "gp2")
+	c.Assert(v0.Tags, check.DeepEquals, []ec2.Tag{{Key: "Name", Value: "data"}})
+	c.Assert(v0.Attachments, check.HasLen, 1)
+	c.Assert(v0.Attachments[0].InstanceId, check.Equals, "i-1234567890abcdef0")
+	c.Assert(v0.Attachments[0].Device, check.Equals, "/dev/sdh")
+	c.Assert(v0.Attachments[0].Status, check.Equals, "attached")
+}
+
+func (s *S) TestDescribeVolumeStatusExample(c *check.C) {
+	testServer.Response(200, nil, DescribeVolumeStatusExample)
+
+	resp, err := s.ec2.DescribeVolumeStatus([]string{"vol-1234567890abcdef0"}, nil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeVolumeStatus"})
+	c.Assert(req.Form["VolumeId.1"], check.DeepEquals, []string{"vol-1234567890abcdef0"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.Volumes, check.HasLen, 1)
+
+	v0 := resp.Volumes[0]
+	c.Assert(v0.VolumeId, check.Equals, "vol-1234567890abcdef0")
+	c.Assert(v0.Status, check.Equals, "impaired")
+	c.Assert(v0.Details, check.DeepEquals, []ec2.VolumeStatusDetail{{Name: "io-enabled", Status: "failed"}})
+	c.Assert(v0.Events, check.HasLen, 1)
+	c.Assert(v0.Events[0].EventType, check.Equals, "potential-data-inconsistency")
+	c.Assert(v0.Actions, check.HasLen, 1)
+	c.Assert(v0.Actions[0].Code, check.Equals, "enable-volume-io")
+}
+
+func (s *S) TestModifyVolumeExample(c *check.C) {
+	testServer.Response(200, nil, ModifyVolumeExample)
+
+	options := &ec2.ModifyVolumeOptions{Size: 200, VolumeType: "io1", IOPS: 10000}
+	resp, err := s.ec2.ModifyVolume("vol-1234567890abcdef0", options)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"ModifyVolume"})
+	c.Assert(req.Form["VolumeId"], check.DeepEquals, []string{"vol-1234567890abcdef0"})
+	c.Assert(req.Form["Size"], check.DeepEquals, []string{"200"})
+	c.Assert(req.Form["VolumeType"], check.DeepEquals, []string{"io1"})
+	c.Assert(req.Form["Iops"], check.DeepEquals, []string{"10000"})
+
+	c.Assert(err, check.IsNil)
+	m := resp.Modification
+	c.Assert(m.VolumeId, check.Equals, "vol-1234567890abcdef0")
+	c.Assert(m.ModificationState, check.Equals, "modifying")
+	c.Assert(m.TargetSize, check.Equals, int64(200))
+	c.Assert(m.TargetIOPS, check.Equals, int64(10000))
+	c.Assert(m.OriginalSize, check.Equals, int64(100))
+	c.Assert(m.OriginalVolumeType, check.Equals, "gp2")
+}
+
+func (s *S) TestDeleteVolumeExample(c *check.C) {
+	testServer.Response(200, nil, DeleteVolumeExample)
+
+	resp, err := s.ec2.DeleteVolume("vol-1234567890abcdef0")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DeleteVolume"})
+	c.Assert(req.Form["VolumeId"], check.DeepEquals, []string{"vol-1234567890abcdef0"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.RequestId, check.Equals, "59dbff89-35bd-4eac-99ed-be587EXAMPLE")
+}
+
+func (s *S) TestCopySnapshotExample(c *check.C) {
+	testServer.Response(200, nil, CopySnapshotExample)
+
+	resp, err := s.ec2.CopySnapshot(&ec2.CopySnapshotOptions{
+		SourceRegion:     aws.USWest,
+		SourceSnapshotId: "snap-1a2b3c4d",
+		Description:      "My snapshot",
+		Encrypted:        true,
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"CopySnapshot"})
+	c.Assert(req.Form["SourceRegion"], check.DeepEquals, []string{"us-west-1"})
+	c.Assert(req.Form["SourceSnapshotId"], check.DeepEquals, []string{"snap-1a2b3c4d"})
+	c.Assert(req.Form["Description"], check.DeepEquals, []string{"My snapshot"})
+	c.Assert(req.Form["Encrypted"], check.DeepEquals, []string{"true"})
+	c.Assert(req.Form["KmsKeyId"], check.IsNil)
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.SnapshotId, check.Equals, "snap-1234567890abcdef0")
+}
+
+func (s *S) TestDescribeSnapshotAttributeExample(c *check.C) {
+	testServer.Response(200, nil, DescribeSnapshotAttributeExample)
+
+	resp, err := s.ec2.DescribeSnapshotAttribute("snap-1234567890abcdef0", "createVolumePermission")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeSnapshotAttribute"})
+	c.Assert(req.Form["SnapshotId"], check.DeepEquals, []string{"snap-1234567890abcdef0"})
+	c.Assert(req.Form["Attribute"], check.DeepEquals, []string{"createVolumePermission"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.SnapshotId, check.Equals, "snap-1234567890abcdef0")
+	c.Assert(resp.CreateVolumePermissions, check.DeepEquals, []ec2.CreateVolumePermission{
+		{Group: "all"},
+		{UserId: "111122223333"},
+	})
+}
+
+func (s *S) TestModifySnapshotAttributeExample(c *check.C) {
+	testServer.Response(200, nil, ModifySnapshotAttributeExample)
+
+	add := []ec2.CreateVolumePermission{{UserId: "111122223333"}, {UserId: "444455556666"}}
+	remove := []ec2.CreateVolumePermission{{Group: "all"}}
+	_, err := s.ec2.ModifySnapshotAttribute("snap-1234567890abcdef0", add, remove)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"ModifySnapshotAttribute"})
+	c.Assert(req.Form["SnapshotId"], check.DeepEquals, []string{"snap-1234567890abcdef0"})
+	c.Assert(req.Form["CreateVolumePermission.Add.1.UserId"], check.DeepEquals, []string{"111122223333"})
+	c.Assert(req.Form["CreateVolumePermission.Add.2.UserId"], check.DeepEquals, []string{"444455556666"})
+	c.Assert(req.Form["CreateVolumePermission.Add.1.Group"], check.IsNil)
+	c.Assert(req.Form["CreateVolumePermission.Remove.1.Group"], check.DeepEquals, []string{"all"})
+
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestResetSnapshotAttribute(c *check.C) {
+	testServer.Response(200, nil, ModifySnapshotAttributeExample)
+
+	_, err := s.ec2.ResetSnapshotAttribute("snap-1234567890abcdef0", "createVolumePermission")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"ResetSnapshotAttribute"})
+	c.Assert(req.Form["SnapshotId"], check.DeepEquals, []string{"snap-1234567890abcdef0"})
+	c.Assert(req.Form["Attribute"], check.DeepEquals, []string{"createVolumePermission"})
+
+	c.Assert(err, check.IsNil)
+}
@@ -0,0 +1,739 @@
+package ec2
+
+import (
+	"strconv"
+)
+
+// ----------------------------------------------------------------------------
+// VPC management functions and types.
+
+// Vpc represents details about a VPC.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_Vpc.html for more details.
+type Vpc struct {
+	Id              string `xml:"vpcId"`
+	State           string `xml:"state"` // Valid values: pending | available
+	CidrBlock       string `xml:"cidrBlock"`
+	DhcpOptionsId   string `xml:"dhcpOptionsId"`
+	InstanceTenancy string `xml:"instanceTenancy"` // Valid values: default | dedicated
+	IsDefault       bool   `xml:"isDefault"`
+	Tags            []Tag  `xml:"tagSet>item"`
+}
+
+// Response to a CreateVpc request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateVpc.html for more details.
+type CreateVpcResp struct {
+	RequestId string `xml:"requestId"`
+	Vpc       Vpc    `xml:"vpc"`
+}
+
+// CreateVpc creates a VPC with the given CIDR block. The instanceTenancy
+// parameter is optional.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateVpc.html for more details.
+func (ec2 *EC2) CreateVpc(cidrBlock, instanceTenancy string) (resp *CreateVpcResp, err error) {
+	params := makeParams("CreateVpc")
+	params["CidrBlock"] = cidrBlock
+	if instanceTenancy != "" {
+		params["InstanceTenancy"] = instanceTenancy
+	}
+
+	resp = &CreateVpcResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Response to a DescribeVpcs request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeVpcs.html for more details.
+type VpcsResp struct {
+	RequestId string `xml:"requestId"`
+	Vpcs      []Vpc  `xml:"vpcSet>item"`
+}
+
+// DescribeVpcs returns details about VPCs. The ids and filter parameters,
+// if provided, limit the VPCs returned.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeVpcs.html for more details.
+func (ec2 *EC2) DescribeVpcs(ids []string, filter *Filter) (resp *VpcsResp, err error) {
+	params := makeParams("DescribeVpcs")
+	addParamsList(params, "VpcId", ids)
+	filter.addParams(params)
+
+	resp = &VpcsResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DeleteVpc deletes a VPC. Everything created within it, except for the
+// main route table, default security group and network ACL, must be
+// deleted first.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DeleteVpc.html for more details.
+func (ec2 *EC2) DeleteVpc(vpcId string) (resp *SimpleResp, err error) {
+	params := makeParams("DeleteVpc")
+	params["VpcId"] = vpcId
+
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// ModifyVpcAttribute enables or disables the given attribute of a VPC.
+// Valid attributes are "EnableDnsSupport" and "EnableDnsHostnames".
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_ModifyVpcAttribute.html for more details.
+func (ec2 *EC2) ModifyVpcAttribute(vpcId, attribute string, value bool) (resp *SimpleResp, err error) {
+	params := makeParams("ModifyVpcAttribute")
+	params["VpcId"] = vpcId
+	params[attribute+".Value"] = strconv.FormatBool(value)
+
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// ----------------------------------------------------------------------------
+// Subnet management functions and types.
+
+// Response to a CreateSubnet request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateSubnet.html for more details.
+type CreateSubnetResp struct {
+	RequestId string `xml:"requestId"`
+	Subnet    Subnet `xml:"subnet"`
+}
+
+// CreateSubnet creates a subnet with the given CIDR block in a VPC. The
+// availabilityZone parameter is optional.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateSubnet.html for more details.
+func (ec2 *EC2) CreateSubnet(vpcId, cidrBlock, availabilityZone string) (resp *CreateSubnetResp, err error) {
+	params := makeParams("CreateSubnet")
+	params["VpcId"] = vpcId
+	params["CidrBlock"] = cidrBlock
+	if availabilityZone != "" {
+		params["AvailabilityZone"] = availabilityZone
+	}
+
+	resp = &CreateSubnetResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DeleteSubnet deletes a subnet. All instances in it must be terminated
+// first.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DeleteSubnet.html for more details.
+func (ec2 *EC2) DeleteSubnet(subnetId string) (resp *SimpleResp, err error) {
+	params := makeParams("DeleteSubnet")
+	params["SubnetId"] = subnetId
+
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// ----------------------------------------------------------------------------
+// Route table management functions and types.
+
+// RouteTable represents details about a route table of a VPC.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_RouteTable.html for more details.
+type RouteTable struct {
+	Id           string                  `xml:"routeTableId"`
+	VpcId        string                  `xml:"vpcId"`
+	Routes       []Route                 `xml:"routeSet>item"`
+	Associations []RouteTableAssociation `xml:"associationSet>item"`
+	Tags         []Tag                   `xml:"tagSet>item"`
+}
+
+// Route describes a route in a route table. Exactly one target,
+// be it a gateway, instance, network interface, NAT gateway or
+// peering connection, is set.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_Route.html for more details.
+type Route struct {
+	DestinationCidrBlock   string `xml:"destinationCidrBlock"`
+	GatewayId              string `xml:"gatewayId"`
+	InstanceId             string `xml:"instanceId"`
+	InstanceOwnerId        string `xml:"instanceOwnerId"`
+	NetworkInterfaceId     string `xml:"networkInterfaceId"`
+	NatGatewayId           string `xml:"natGatewayId"`
+	VpcPeeringConnectionId string `xml:"vpcPeeringConnectionId"`
+	State                  string `xml:"state"`  // Valid values: active | blackhole
+	Origin                 string `xml:"origin"` // Valid values: CreateRouteTable | CreateRoute | EnableVgwRoutePropagation
+}
+
+// RouteTableAssociation describes the association between a route table
+// and a subnet. The main route table of a VPC has no subnet.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_RouteTableAssociation.html for more details.
+type RouteTableAssociation struct {
+	Id           string `xml:"routeTableAssociationId"`
+	RouteTableId string `xml:"routeTableId"`
+	SubnetId     string `xml:"subnetId"`
+	Main         bool   `xml:"main"`
+}
+
+// Response to a CreateRouteTable request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateRouteTable.html for more details.
+type CreateRouteTableResp struct {
+	RequestId  string     `xml:"requestId"`
+	RouteTable RouteTable `xml:"routeTable"`
+}
+
+// CreateRouteTable creates a route table for a VPC.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateRouteTable.html for more details.
+func (ec2 *EC2) CreateRouteTable(vpcId string) (resp *CreateRouteTableResp, err error) {
+	params := makeParams("CreateRouteTable")
+	params["VpcId"] = vpcId
+
+	resp = &CreateRouteTableResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Response to a DescribeRouteTables request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeRouteTables.html for more details.
+type RouteTablesResp struct {
+	RequestId   string       `xml:"requestId"`
+	RouteTables []RouteTable `xml:"routeTableSet>item"`
+}
+
+// DescribeRouteTables returns details about route tables. The ids and
+// filter parameters, if provided, limit the route tables returned.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeRouteTables.html for more details.
+func (ec2 *EC2) DescribeRouteTables(ids []string, filter *Filter) (resp *RouteTablesResp, err error) {
+	params := makeParams("DescribeRouteTables")
+	addParamsList(params, "RouteTableId", ids)
+	filter.addParams(params)
+
+	resp = &RouteTablesResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DeleteRouteTable deletes a route table. It must not be associated
+// with any subnet, nor be the main route table of its VPC.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DeleteRouteTable.html for more details.
+func (ec2 *EC2) DeleteRouteTable(routeTableId string) (resp *SimpleResp, err error) {
+	params := makeParams("DeleteRouteTable")
+	params["RouteTableId"] = routeTableId
+
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Response to an AssociateRouteTable request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_AssociateRouteTable.html for more details.
+type AssociateRouteTableResp struct {
+	RequestId     string `xml:"requestId"`
+	AssociationId string `xml:"associationId"`
+}
+
+// AssociateRouteTable associates a route table with a subnet.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_AssociateRouteTable.html for more details.
+func (ec2 *EC2) AssociateRouteTable(routeTableId, subnetId string) (resp *AssociateRouteTableResp, err error) {
+	params := makeParams("AssociateRouteTable")
+	params["RouteTableId"] = routeTableId
+	params["SubnetId"] = subnetId
+
+	resp = &AssociateRouteTableResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DisassociateRouteTable removes the association between a route table
+// and a subnet, which then uses the main route table of its VPC.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DisassociateRouteTable.html for more details.
+func (ec2 *EC2) DisassociateRouteTable(associationId string) (resp *SimpleResp, err error) {
+	params := makeParams("DisassociateRouteTable")
+	params["AssociationId"] = associationId
+
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// The RouteOptions type encapsulates the destination and target of a
+// route for the CreateRoute and ReplaceRoute requests. Exactly one
+// target must be set.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateRoute.html for more details.
+type RouteOptions struct {
+	DestinationCidrBlock   string
+	GatewayId              string
+	InstanceId             string
+	NetworkInterfaceId     string
+	NatGatewayId           string
+	VpcPeeringConnectionId string
+}
+
+func (options *RouteOptions) addParams(params map[string]string) {
+	params["DestinationCidrBlock"] = options.DestinationCidrBlock
+	if options.GatewayId != "" {
+		params["GatewayId"] = options.GatewayId
+	}
+	if options.InstanceId != "" {
+		params["InstanceId"] = options.InstanceId
+	}
+	if options.NetworkInterfaceId != "" {
+		params["NetworkInterfaceId"] = options.NetworkInterfaceId
+	}
+	if options.NatGatewayId != "" {
+		params["NatGatewayId"] = options.NatGatewayId
+	}
+	if options.VpcPeeringConnectionId != "" {
+		params["VpcPeeringConnectionId"] = options.VpcPeeringConnectionId
+	}
+}
+
+// CreateRoute adds a route to a route table.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateRoute.html for more details.
+func (ec2 *EC2) CreateRoute(routeTableId string, options *RouteOptions) (resp *SimpleResp, err error) {
+	params := makeParams("CreateRoute")
+	params["RouteTableId"] = routeTableId
+	options.addParams(params)
+
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// ReplaceRoute changes the target of an existing route in a route table.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_ReplaceRoute.html for more details.
+func (ec2 *EC2) ReplaceRoute(routeTableId string, options *RouteOptions) (resp *SimpleResp, err error) {
+	params := makeParams("ReplaceRoute")
+	params["RouteTableId"] = routeTableId
+	options.addParams(params)
+
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DeleteRoute removes the route for the given destination from a route
+// table.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DeleteRoute.html for more details.
+func (ec2 *EC2) DeleteRoute(routeTableId, destinationCidrBlock string) (resp *SimpleResp, err error) {
+	params := makeParams("DeleteRoute")
+	params["RouteTableId"] = routeTableId
+	params["DestinationCidrBlock"] = destinationCidrBlock
+
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// ----------------------------------------------------------------------------
+// Internet gateway management functions and types.
+
+// InternetGateway represents details about an internet gateway.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_InternetGateway.html for more details.
+type InternetGateway struct {
+	Id          string                      `xml:"internetGatewayId"`
+	Attachments []InternetGatewayAttachment `xml:"attachmentSet>item"`
+	Tags        []Tag                       `xml:"tagSet>item"`
+}
+
+// InternetGatewayAttachment describes the attachment of an internet
+// gateway to a VPC.
+type InternetGatewayAttachment struct {
+	VpcId string `xml:"vpcId"`
+	State string `xml:"state"` // Valid values: attaching | attached | detaching | detached | available
+}
+
+// Response to a CreateInternetGateway request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateInternetGateway.html for more details.
+type CreateInternetGatewayResp struct {
+	RequestId       string          `xml:"requestId"`
+	InternetGateway InternetGateway `xml:"internetGateway"`
+}
+
+// CreateInternetGateway creates an internet gateway, which can then be
+// attached to a VPC with AttachInternetGateway.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateInternetGateway.html for more details.
+func (ec2 *EC2) CreateInternetGateway() (resp *CreateInternetGatewayResp, err error) {
+	params := makeParams("CreateInternetGateway")
+
+	resp = &CreateInternetGatewayResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Response to a DescribeInternetGateways request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeInternetGateways.html for more details.
+type InternetGatewaysResp struct {
+	RequestId        string            `xml:"requestId"`
+	InternetGateways []InternetGateway `xml:"internetGatewaySet>item"`
+}
+
+// DescribeInternetGateways returns details about internet gateways. The
+// ids and filter parameters, if provided, limit the gateways returned.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeInternetGateways.html for more details.
+func (ec2 *EC2) DescribeInternetGateways(ids []string, filter *Filter) (resp *InternetGatewaysResp, err error) {
+	params := makeParams("DescribeInternetGateways")
+	addParamsList(params, "InternetGatewayId", ids)
+	filter.addParams(params)
+
+	resp = &InternetGatewaysResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// AttachInternetGateway attaches an internet gateway to a VPC.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_AttachInternetGateway.html for more details.
+func (ec2 *EC2) AttachInternetGateway(internetGatewayId, vpcId string) (resp *SimpleResp, err error) {
+	return ec2.attachOrDetachGateway("AttachInternetGateway", internetGatewayId, vpcId)
+}
+
+// DetachInternetGateway detaches an internet gateway from a VPC.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DetachInternetGateway.html for more details.
+func (ec2 *EC2) DetachInternetGateway(internetGatewayId, vpcId string) (resp *SimpleResp, err error) {
+	return ec2.attachOrDetachGateway("DetachInternetGateway", internetGatewayId, vpcId)
+}
+
+func (ec2 *EC2) attachOrDetachGateway(op, internetGatewayId, vpcId string) (resp *SimpleResp, err error) {
+	params := makeParams(op)
+	params["InternetGatewayId"] = internetGatewayId
+	params["VpcId"] = vpcId
+
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DeleteInternetGateway deletes a detached internet gateway.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DeleteInternetGateway.html for more details.
+func (ec2 *EC2) DeleteInternetGateway(internetGatewayId string) (resp *SimpleResp, err error) {
+	params := makeParams("DeleteInternetGateway")
+	params["InternetGatewayId"] = internetGatewayId
+
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// ----------------------------------------------------------------------------
+// NAT gateway management functions and types.
+
+// NatGateway represents details about a NAT gateway.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_NatGateway.html for more details.
+type NatGateway struct {
+	Id             string              `xml:"natGatewayId"`
+	SubnetId       string              `xml:"subnetId"`
+	VpcId          string              `xml:"vpcId"`
+	State          string              `xml:"state"` // Valid values: pending | failed | available | deleting | deleted
+	FailureCode    string              `xml:"failureCode"`
+	FailureMessage string              `xml:"failureMessage"`
+	CreateTime     string              `xml:"createTime"`
+	DeleteTime     string              `xml:"deleteTime"`
+	Addresses      []NatGatewayAddress `xml:"natGatewayAddressSet>item"`
+}
+
+// NatGatewayAddress describes the addresses associated with a NAT gateway.
+type NatGatewayAddress struct {
+	AllocationId       string `xml:"allocationId"`
+	NetworkInterfaceId string `xml:"networkInterfaceId"`
+	PrivateIp          string `xml:"privateIp"`
+	PublicIp           string `xml:"publicIp"`
+}
+
+// Response to a CreateNatGateway request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateNatGateway.html for more details.
+type CreateNatGatewayResp struct {
+	RequestId  string     `xml:"requestId"`
+	NatGateway NatGateway `xml:"natGateway"`
+}
+
+// CreateNatGateway creates a NAT gateway in a public subnet, using the
+// Elastic IP address with the given allocation id.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateNatGateway.html for more details.
+func (ec2 *EC2) CreateNatGateway(subnetId, allocationId string) (resp *CreateNatGatewayResp, err error) {
+	params := makeParams("CreateNatGateway")
+	params["SubnetId"] = subnetId
+	params["AllocationId"] = allocationId
+	token, err := clientToken()
+	if err != nil {
+		return nil, err
+	}
+	params["ClientToken"] = token
+
+	resp = &CreateNatGatewayResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Response to a DescribeNatGateways request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeNatGateways.html for more details.
+type NatGatewaysResp struct {
+	RequestId   string       `xml:"requestId"`
+	NatGateways []NatGateway `xml:"natGatewaySet>item"`
+}
+
+// DescribeNatGateways returns details about NAT gateways. The ids and
+// filter parameters, if provided, limit the gateways returned.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeNatGateways.html for more details.
+func (ec2 *EC2) DescribeNatGateways(ids []string, filter *Filter) (resp *NatGatewaysResp, err error) {
+	params := makeParams("DescribeNatGateways")
+	addParamsList(params, "NatGatewayId", ids)
+	filter.addParams(params)
+
+	resp = &NatGatewaysResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Response to a DeleteNatGateway request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DeleteNatGateway.html for more details.
+type DeleteNatGatewayResp struct {
+	RequestId    string `xml:"requestId"`
+	NatGatewayId string `xml:"natGatewayId"`
+}
+
+// DeleteNatGateway deletes a NAT gateway. Its Elastic IP address is
+// disassociated but not released.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DeleteNatGateway.html for more details.
+func (ec2 *EC2) DeleteNatGateway(natGatewayId string) (resp *DeleteNatGatewayResp, err error) {
+	params := makeParams("DeleteNatGateway")
+	params["NatGatewayId"] = natGatewayId
+
+	resp = &DeleteNatGatewayResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// ----------------------------------------------------------------------------
+// VPC peering management functions and types.
+
+// VpcPeeringConnection represents details about a VPC peering connection.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_VpcPeeringConnection.html for more details.
+type VpcPeeringConnection struct {
+	Id             string                      `xml:"vpcPeeringConnectionId"`
+	RequesterVpc   VpcPeeringConnectionVpcInfo `xml:"requesterVpcInfo"`
+	AccepterVpc    VpcPeeringConnectionVpcInfo `xml:"accepterVpcInfo"`
+	Status         string                      `xml:"status>code"` // Valid values: initiating-request | pending-acceptance | active | deleted | rejected | failed | expired | provisioning | deleting
+	StatusMessage  string                      `xml:"status>message"`
+	ExpirationTime string                      `xml:"expirationTime"`
+	Tags           []Tag                       `xml:"tagSet>item"`
+}
+
+// VpcPeeringConnectionVpcInfo describes one side of a VPC peering connection.
+type VpcPeeringConnectionVpcInfo struct {
+	VpcId     string `xml:"vpcId"`
+	OwnerId   string `xml:"ownerId"`
+	CidrBlock string `xml:"cidrBlock"`
+	Region    string `xml:"region"`
+}
+
+// The CreateVpcPeeringConnectionOptions type encapsulates options for the
+// respective request in EC2. PeerOwnerId defaults to the current account
+// and PeerRegion to the current region.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateVpcPeeringConnection.html for more details.
+type CreateVpcPeeringConnectionOptions struct {
+	VpcId       string
+	PeerVpcId   string
+	PeerOwnerId string
+	PeerRegion  string
+}
+
+// Response to a CreateVpcPeeringConnection or AcceptVpcPeeringConnection
+// request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateVpcPeeringConnection.html for more details.
+type VpcPeeringConnectionResp struct {
+	RequestId            string               `xml:"requestId"`
+	VpcPeeringConnection VpcPeeringConnection `xml:"vpcPeeringConnection"`
+}
+
+// CreateVpcPeeringConnection requests a peering connection between two
+// VPCs. The owner of the peer VPC must accept the request with
+// AcceptVpcPeeringConnection before it becomes active.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateVpcPeeringConnection.html for more details.
+func (ec2 *EC2) CreateVpcPeeringConnection(options *CreateVpcPeeringConnectionOptions) (resp *VpcPeeringConnectionResp, err error) {
+	params := makeParams("CreateVpcPeeringConnection")
+	params["VpcId"] = options.VpcId
+	params["PeerVpcId"] = options.PeerVpcId
+	if options.PeerOwnerId != "" {
+		params["PeerOwnerId"] = options.PeerOwnerId
+	}
+	if options.PeerRegion != "" {
+		params["PeerRegion"] = options.PeerRegion
+	}
+
+	resp = &VpcPeeringConnectionResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// AcceptVpcPeeringConnection accepts a pending VPC peering connection
+// request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_AcceptVpcPeeringConnection.html for more details.
+func (ec2 *EC2) AcceptVpcPeeringConnection(vpcPeeringConnectionId string) (resp *VpcPeeringConnectionResp, err error) {
+	params := makeParams("AcceptVpcPeeringConnection")
+	params["VpcPeeringConnectionId"] = vpcPeeringConnectionId
+
+	resp = &VpcPeeringConnectionResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// RejectVpcPeeringConnection rejects a pending VPC peering connection
+// request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_RejectVpcPeeringConnection.html for more details.
+func (ec2 *EC2) RejectVpcPeeringConnection(vpcPeeringConnectionId string) (resp *SimpleResp, err error) {
+	params := makeParams("RejectVpcPeeringConnection")
+	params["VpcPeeringConnectionId"] = vpcPeeringConnectionId
+
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Response to a DescribeVpcPeeringConnections request.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeVpcPeeringConnections.html for more details.
+type VpcPeeringConnectionsResp struct {
+	RequestId             string                 `xml:"requestId"`
+	VpcPeeringConnections []VpcPeeringConnection `xml:"vpcPeeringConnectionSet>item"`
+}
+
+// DescribeVpcPeeringConnections returns details about VPC peering
+// connections. The ids and filter parameters, if provided, limit the
+// connections returned.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeVpcPeeringConnections.html for more details.
+func (ec2 *EC2) DescribeVpcPeeringConnections(ids []string, filter *Filter) (resp *VpcPeeringConnectionsResp, err error) {
+	params := makeParams("DescribeVpcPeeringConnections")
+	addParamsList(params, "VpcPeeringConnectionId", ids)
+	filter.addParams(params)
+
+	resp = &VpcPeeringConnectionsResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DeleteVpcPeeringConnection deletes an active VPC peering connection,
+// or cancels a pending request made by the current account.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DeleteVpcPeeringConnection.html for more details.
+func (ec2 *EC2) DeleteVpcPeeringConnection(vpcPeeringConnectionId string) (resp *SimpleResp, err error) {
+	params := makeParams("DeleteVpcPeeringConnection")
+	params["VpcPeeringConnectionId"] = vpcPeeringConnectionId
+
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
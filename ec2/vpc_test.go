@@ -0,0 +1,361 @@
+package ec2_test
+
+import (
+	"github.com/crowdmob/goamz/ec2"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestCreateVpcExample(c *check.C) {
+	testServer.Response(200, nil, CreateVpcExample)
+
+	resp, err := s.ec2.CreateVpc("10.0.0.0/16", "")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"CreateVpc"})
+	c.Assert(req.Form["CidrBlock"], check.DeepEquals, []string{"10.0.0.0/16"})
+	c.Assert(req.Form["InstanceTenancy"], check.IsNil)
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.RequestId, check.Equals, "7a62c49f-347e-4fc4-9331-6e8eEXAMPLE")
+	c.Assert(resp.Vpc.Id, check.Equals, "vpc-1a2b3c4d")
+	c.Assert(resp.Vpc.State, check.Equals, "pending")
+	c.Assert(resp.Vpc.CidrBlock, check.Equals, "10.0.0.0/16")
+	c.Assert(resp.Vpc.DhcpOptionsId, check.Equals, "dopt-1a2b3c4d2")
+	c.Assert(resp.Vpc.InstanceTenancy, check.Equals, "default")
+}
+
+func (s *S) TestDescribeVpcsExample(c *check.C) {
+	testServer.Response(200, nil, DescribeVpcsExample)
+
+	filter := ec2.NewFilter()
+	filter.Add("state", "available")
+
+	resp, err := s.ec2.DescribeVpcs([]string{"vpc-1a2b3c4d"}, filter)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeVpcs"})
+	c.Assert(req.Form["VpcId.1"], check.DeepEquals, []string{"vpc-1a2b3c4d"})
+	c.Assert(req.Form["Filter.1.Name"], check.DeepEquals, []string{"state"})
+	c.Assert(req.Form["Filter.1.Value.1"], check.DeepEquals, []string{"available"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.Vpcs, check.HasLen, 1)
+	v0 := resp.Vpcs[0]
+	c.Assert(v0.Id, check.Equals, "vpc-1a2b3c4d")
+	c.Assert(v0.State, check.Equals, "available")
+	c.Assert(v0.IsDefault, check.Equals, false)
+	c.Assert(v0.Tags, check.DeepEquals, []ec2.Tag{{Key: "Name", Value: "staging"}})
+}
+
+func (s *S) TestDeleteVpcExample(c *check.C) {
+	testServer.Response(200, nil, DeleteVpcExample)
+
+	resp, err := s.ec2.DeleteVpc("vpc-1a2b3c4d")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DeleteVpc"})
+	c.Assert(req.Form["VpcId"], check.DeepEquals, []string{"vpc-1a2b3c4d"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.RequestId, check.Equals, "7a62c49f-347e-4fc4-9331-6e8eEXAMPLE")
+}
+
+func (s *S) TestModifyVpcAttribute(c *check.C) {
+	testServer.Response(200, nil, DeleteVpcExample)
+
+	_, err := s.ec2.ModifyVpcAttribute("vpc-1a2b3c4d", "EnableDnsHostnames", false)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"ModifyVpcAttribute"})
+	c.Assert(req.Form["VpcId"], check.DeepEquals, []string{"vpc-1a2b3c4d"})
+	c.Assert(req.Form["EnableDnsHostnames.Value"], check.DeepEquals, []string{"false"})
+
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestCreateSubnetExample(c *check.C) {
+	testServer.Response(200, nil, CreateSubnetExample)
+
+	resp, err := s.ec2.CreateSubnet("vpc-1a2b3c4d", "10.0.1.0/24", "us-east-1a")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"CreateSubnet"})
+	c.Assert(req.Form["VpcId"], check.DeepEquals, []string{"vpc-1a2b3c4d"})
+	c.Assert(req.Form["CidrBlock"], check.DeepEquals, []string{"10.0.1.0/24"})
+	c.Assert(req.Form["AvailabilityZone"], check.DeepEquals, []string{"us-east-1a"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.Subnet.Id, check.Equals, "subnet-9d4a7b6c")
+	c.Assert(resp.Subnet.State, check.Equals, "pending")
+	c.Assert(resp.Subnet.VpcId, check.Equals, "vpc-1a2b3c4d")
+	c.Assert(resp.Subnet.AvailableIpAddressCount, check.Equals, 251)
+}
+
+func (s *S) TestDeleteSubnet(c *check.C) {
+	testServer.Response(200, nil, DeleteVpcExample)
+
+	_, err := s.ec2.DeleteSubnet("subnet-9d4a7b6c")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DeleteSubnet"})
+	c.Assert(req.Form["SubnetId"], check.DeepEquals, []string{"subnet-9d4a7b6c"})
+
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestCreateRouteTableExample(c *check.C) {
+	testServer.Response(200, nil, CreateRouteTableExample)
+
+	resp, err := s.ec2.CreateRouteTable("vpc-11ad4878")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"CreateRouteTable"})
+	c.Assert(req.Form["VpcId"], check.DeepEquals, []string{"vpc-11ad4878"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.RouteTable.Id, check.Equals, "rtb-f9ad4890")
+	c.Assert(resp.RouteTable.Routes, check.DeepEquals, []ec2.Route{{
+		DestinationCidrBlock: "10.0.0.0/22",
+		GatewayId:            "local",
+		State:                "active",
+	}})
+	c.Assert(resp.RouteTable.Associations, check.HasLen, 0)
+}
+
+func (s *S) TestDescribeRouteTablesExample(c *check.C) {
+	testServer.Response(200, nil, DescribeRouteTablesExample)
+
+	filter := ec2.NewFilter()
+	filter.Add("vpc-id", "vpc-11ad4878")
+
+	resp, err := s.ec2.DescribeRouteTables(nil, filter)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeRouteTables"})
+	c.Assert(req.Form["RouteTableId.1"], check.IsNil)
+	c.Assert(req.Form["Filter.1.Name"], check.DeepEquals, []string{"vpc-id"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.RouteTables, check.HasLen, 1)
+	rt := resp.RouteTables[0]
+	c.Assert(rt.Id, check.Equals, "rtb-13ad487a")
+	c.Assert(rt.Routes, check.HasLen, 2)
+	c.Assert(rt.Routes[1].NatGatewayId, check.Equals, "nat-04b7a2e3")
+	c.Assert(rt.Routes[1].State, check.Equals, "blackhole")
+	c.Assert(rt.Routes[1].Origin, check.Equals, "CreateRoute")
+	c.Assert(rt.Associations, check.DeepEquals, []ec2.RouteTableAssociation{
+		{Id: "rtbassoc-12ad487b", RouteTableId: "rtb-13ad487a", Main: true},
+		{Id: "rtbassoc-faad4893", RouteTableId: "rtb-13ad487a", SubnetId: "subnet-15ad487c"},
+	})
+}
+
+func (s *S) TestAssociateRouteTableExample(c *check.C) {
+	testServer.Response(200, nil, AssociateRouteTableExample)
+
+	resp, err := s.ec2.AssociateRouteTable("rtb-e4ad488d", "subnet-15ad487c")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"AssociateRouteTable"})
+	c.Assert(req.Form["RouteTableId"], check.DeepEquals, []string{"rtb-e4ad488d"})
+	c.Assert(req.Form["SubnetId"], check.DeepEquals, []string{"subnet-15ad487c"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.AssociationId, check.Equals, "rtbassoc-f8ad4891")
+}
+
+func (s *S) TestCreateRouteExample(c *check.C) {
+	testServer.Response(200, nil, CreateRouteExample)
+
+	options := &ec2.RouteOptions{
+		DestinationCidrBlock: "0.0.0.0/0",
+		GatewayId:            "igw-eaad4883",
+	}
+	_, err := s.ec2.CreateRoute("rtb-e4ad488d", options)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"CreateRoute"})
+	c.Assert(req.Form["RouteTableId"], check.DeepEquals, []string{"rtb-e4ad488d"})
+	c.Assert(req.Form["DestinationCidrBlock"], check.DeepEquals, []string{"0.0.0.0/0"})
+	c.Assert(req.Form["GatewayId"], check.DeepEquals, []string{"igw-eaad4883"})
+	c.Assert(req.Form["InstanceId"], check.IsNil)
+	c.Assert(req.Form["NatGatewayId"], check.IsNil)
+
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestReplaceRoute(c *check.C) {
+	testServer.Response(200, nil, CreateRouteExample)
+
+	options := &ec2.RouteOptions{
+		DestinationCidrBlock:   "10.1.0.0/16",
+		VpcPeeringConnectionId: "pcx-73a5401a",
+	}
+	_, err := s.ec2.ReplaceRoute("rtb-e4ad488d", options)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"ReplaceRoute"})
+	c.Assert(req.Form["DestinationCidrBlock"], check.DeepEquals, []string{"10.1.0.0/16"})
+	c.Assert(req.Form["VpcPeeringConnectionId"], check.DeepEquals, []string{"pcx-73a5401a"})
+	c.Assert(req.Form["GatewayId"], check.IsNil)
+
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestDeleteRoute(c *check.C) {
+	testServer.Response(200, nil, CreateRouteExample)
+
+	_, err := s.ec2.DeleteRoute("rtb-e4ad488d", "0.0.0.0/0")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DeleteRoute"})
+	c.Assert(req.Form["RouteTableId"], check.DeepEquals, []string{"rtb-e4ad488d"})
+	c.Assert(req.Form["DestinationCidrBlock"], check.DeepEquals, []string{"0.0.0.0/0"})
+
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestCreateInternetGatewayExample(c *check.C) {
+	testServer.Response(200, nil, CreateInternetGatewayExample)
+
+	resp, err := s.ec2.CreateInternetGateway()
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"CreateInternetGateway"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.InternetGateway.Id, check.Equals, "igw-eaad4883")
+	c.Assert(resp.InternetGateway.Attachments, check.HasLen, 0)
+}
+
+func (s *S) TestAttachInternetGateway(c *check.C) {
+	testServer.Response(200, nil, CreateRouteExample)
+
+	_, err := s.ec2.AttachInternetGateway("igw-eaad4883", "vpc-11ad4878")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"AttachInternetGateway"})
+	c.Assert(req.Form["InternetGatewayId"], check.DeepEquals, []string{"igw-eaad4883"})
+	c.Assert(req.Form["VpcId"], check.DeepEquals, []string{"vpc-11ad4878"})
+
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestDescribeInternetGatewaysExample(c *check.C) {
+	testServer.Response(200, nil, DescribeInternetGatewaysExample)
+
+	resp, err := s.ec2.DescribeInternetGateways([]string{"igw-eaad4883EXAMPLE"}, nil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeInternetGateways"})
+	c.Assert(req.Form["InternetGatewayId.1"], check.DeepEquals, []string{"igw-eaad4883EXAMPLE"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.InternetGateways, check.HasLen, 1)
+	c.Assert(resp.InternetGateways[0].Attachments, check.DeepEquals, []ec2.InternetGatewayAttachment{
+		{VpcId: "vpc-11ad4878", State: "available"},
+	})
+}
+
+func (s *S) TestCreateNatGatewayExample(c *check.C) {
+	testServer.Response(200, nil, CreateNatGatewayExample)
+
+	resp, err := s.ec2.CreateNatGateway("subnet-1a2b3c4d", "eipalloc-37fc1a52")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"CreateNatGateway"})
+	c.Assert(req.Form["SubnetId"], check.DeepEquals, []string{"subnet-1a2b3c4d"})
+	c.Assert(req.Form["AllocationId"], check.DeepEquals, []string{"eipalloc-37fc1a52"})
+	c.Assert(req.Form["ClientToken"], check.HasLen, 1)
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.NatGateway.Id, check.Equals, "nat-04e77a5e9c34432f9")
+	c.Assert(resp.NatGateway.State, check.Equals, "pending")
+	c.Assert(resp.NatGateway.Addresses, check.DeepEquals, []ec2.NatGatewayAddress{
+		{AllocationId: "eipalloc-37fc1a52"},
+	})
+}
+
+func (s *S) TestDescribeNatGatewaysExample(c *check.C) {
+	testServer.Response(200, nil, DescribeNatGatewaysExample)
+
+	filter := ec2.NewFilter()
+	filter.Add("vpc-id", "vpc-4e20d42b")
+
+	resp, err := s.ec2.DescribeNatGateways(nil, filter)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeNatGateways"})
+	c.Assert(req.Form["Filter.1.Name"], check.DeepEquals, []string{"vpc-id"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.NatGateways, check.HasLen, 1)
+	n0 := resp.NatGateways[0]
+	c.Assert(n0.State, check.Equals, "available")
+	c.Assert(n0.Addresses[0].PublicIp, check.Equals, "198.18.125.129")
+	c.Assert(n0.Addresses[0].PrivateIp, check.Equals, "10.0.2.147")
+}
+
+func (s *S) TestDeleteNatGatewayExample(c *check.C) {
+	testServer.Response(200, nil, DeleteNatGatewayExample)
+
+	resp, err := s.ec2.DeleteNatGateway("nat-04ae55e711cec5680")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DeleteNatGateway"})
+	c.Assert(req.Form["NatGatewayId"], check.DeepEquals, []string{"nat-04ae55e711cec5680"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.NatGatewayId, check.Equals, "nat-04ae55e711cec5680")
+}
+
+func (s *S) TestCreateVpcPeeringConnectionExample(c *check.C) {
+	testServer.Response(200, nil, CreateVpcPeeringConnectionExample)
+
+	resp, err := s.ec2.CreateVpcPeeringConnection(&ec2.CreateVpcPeeringConnectionOptions{
+		VpcId:       "vpc-1a2b3c4d",
+		PeerVpcId:   "vpc-a1b2c3d4",
+		PeerOwnerId: "123456789012",
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"CreateVpcPeeringConnection"})
+	c.Assert(req.Form["VpcId"], check.DeepEquals, []string{"vpc-1a2b3c4d"})
+	c.Assert(req.Form["PeerVpcId"], check.DeepEquals, []string{"vpc-a1b2c3d4"})
+	c.Assert(req.Form["PeerOwnerId"], check.DeepEquals, []string{"123456789012"})
+	c.Assert(req.Form["PeerRegion"], check.IsNil)
+
+	c.Assert(err, check.IsNil)
+	pcx := resp.VpcPeeringConnection
+	c.Assert(pcx.Id, check.Equals, "pcx-73a5401a")
+	c.Assert(pcx.RequesterVpc.OwnerId, check.Equals, "777788889999")
+	c.Assert(pcx.RequesterVpc.CidrBlock, check.Equals, "10.0.0.0/28")
+	c.Assert(pcx.AccepterVpc.VpcId, check.Equals, "vpc-a1b2c3d4")
+	c.Assert(pcx.Status, check.Equals, "initiating-request")
+	c.Assert(pcx.StatusMessage, check.Equals, "Initiating Request to 123456789012")
+}
+
+func (s *S) TestAcceptVpcPeeringConnectionExample(c *check.C) {
+	testServer.Response(200, nil, AcceptVpcPeeringConnectionExample)
+
+	resp, err := s.ec2.AcceptVpcPeeringConnection("pcx-1a2b3c4d")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"AcceptVpcPeeringConnection"})
+	c.Assert(req.Form["VpcPeeringConnectionId"], check.DeepEquals, []string{"pcx-1a2b3c4d"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.VpcPeeringConnection.Status, check.Equals, "active")
+	c.Assert(resp.VpcPeeringConnection.AccepterVpc.CidrBlock, check.Equals, "10.0.1.0/28")
+}
+
+func (s *S) TestDeleteVpcPeeringConnection(c *check.C) {
+	testServer.Response(200, nil, DeleteVpcExample)
+
+	_, err := s.ec2.DeleteVpcPeeringConnection("pcx-1a2b3c4d")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DeleteVpcPeeringConnection"})
+	c.Assert(req.Form["VpcPeeringConnectionId"], check.DeepEquals, []string{"pcx-1a2b3c4d"})
+
+	c.Assert(err, check.IsNil)
+}
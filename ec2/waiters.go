@@ -0,0 +1,246 @@
+package ec2
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/crowdmob/goamz/aws"
+)
+
+// ----------------------------------------------------------------------------
+// Waiters for resource state transitions.
+//
+// Each WaitUntil* function polls the respective Describe* call until all
+// the given resources reach the desired state, one of them reaches a
+// state from which the desired one can't be reached, or the waiter runs
+// out of attempts. In the latter two cases an *aws.WaiterError is
+// returned. A nil waiter polls every 15 seconds up to 40 times.
+
+var defaultWaiter = aws.Waiter{Delay: 15 * time.Second, MaxAttempts: 40}
+
+func (ec2 *EC2) wait(ctx context.Context, name string, w *aws.Waiter, fn aws.WaitFunc) error {
+	if w == nil {
+		w = &defaultWaiter
+	}
+	return w.Wait(ctx, name, fn)
+}
+
+// matchStates succeeds when every state is the success state and fails
+// as soon as one of them is a failure state. The first state that isn't
+// the success state is returned alongside the result.
+func matchStates(states []string, success string, failure ...string) (aws.WaitResult, string) {
+	if len(states) == 0 {
+		return aws.WaitRetry, ""
+	}
+	result, current := aws.WaitSuccess, success
+	for _, state := range states {
+		if state == success {
+			continue
+		}
+		for _, f := range failure {
+			if state == f {
+				return aws.WaitFailure, state
+			}
+		}
+		if result == aws.WaitSuccess {
+			result, current = aws.WaitRetry, state
+		}
+	}
+	return result, current
+}
+
+// notFound reports whether err is an EC2 error stating that a resource
+// doesn't exist. Resources aren't always visible right after creation,
+// so waiters keep polling in that case.
+func notFound(err error) bool {
+	ec2err, ok := err.(*Error)
+	return ok && strings.HasSuffix(ec2err.Code, ".NotFound")
+}
+
+// waitForInstances waits for the given instances to reach the success
+// state.
+func (ec2 *EC2) waitForInstances(ctx context.Context, name string, ids []string, w *aws.Waiter, success string, failure ...string) error {
+	return ec2.wait(ctx, name, w, func() (aws.WaitResult, string, error) {
+		resp, err := ec2.DescribeInstances(ids, nil)
+		if notFound(err) {
+			return aws.WaitRetry, "", nil
+		}
+		if err != nil {
+			return aws.WaitRetry, "", err
+		}
+		var states []string
+		for _, r := range resp.Reservations {
+			for _, inst := range r.Instances {
+				states = append(states, inst.State.Name)
+			}
+		}
+		result, state := matchStates(states, success, failure...)
+		return result, state, nil
+	})
+}
+
+// WaitUntilInstanceRunning waits until the given instances are running.
+func (ec2 *EC2) WaitUntilInstanceRunning(ctx context.Context, ids []string, w *aws.Waiter) error {
+	return ec2.waitForInstances(ctx, "InstanceRunning", ids, w, "running", "shutting-down", "terminated", "stopping")
+}
+
+// WaitUntilInstanceStopped waits until the given instances are stopped.
+func (ec2 *EC2) WaitUntilInstanceStopped(ctx context.Context, ids []string, w *aws.Waiter) error {
+	return ec2.waitForInstances(ctx, "InstanceStopped", ids, w, "stopped", "pending", "terminated")
+}
+
+// WaitUntilInstanceTerminated waits until the given instances are
+// terminated. Instances that are no longer found, as happens some time
+// after they terminate, count as terminated.
+func (ec2 *EC2) WaitUntilInstanceTerminated(ctx context.Context, ids []string, w *aws.Waiter) error {
+	// DescribeInstances fails as a whole when one of the given ids is
+	// unknown, so look the instances up with a filter instead.
+	filter := NewFilter()
+	filter.Add("instance-id", ids...)
+	return ec2.wait(ctx, "InstanceTerminated", w, func() (aws.WaitResult, string, error) {
+		resp, err := ec2.DescribeInstances(nil, filter)
+		if err != nil {
+			return aws.WaitRetry, "", err
+		}
+		found := make(map[string]string)
+		for _, r := range resp.Reservations {
+			for _, inst := range r.Instances {
+				found[inst.InstanceId] = inst.State.Name
+			}
+		}
+		states := make([]string, len(ids))
+		for i, id := range ids {
+			state, ok := found[id]
+			if !ok {
+				state = "terminated"
+			}
+			states[i] = state
+		}
+		result, state := matchStates(states, "terminated", "pending", "stopping")
+		return result, state, nil
+	})
+}
+
+// WaitUntilImageAvailable waits until the given images are available.
+func (ec2 *EC2) WaitUntilImageAvailable(ctx context.Context, ids []string, w *aws.Waiter) error {
+	return ec2.wait(ctx, "ImageAvailable", w, func() (aws.WaitResult, string, error) {
+		resp, err := ec2.Images(ids, nil)
+		if notFound(err) {
+			return aws.WaitRetry, "", nil
+		}
+		if err != nil {
+			return aws.WaitRetry, "", err
+		}
+		states := make([]string, len(resp.Images))
+		for i, image := range resp.Images {
+			states[i] = image.State
+		}
+		result, state := matchStates(states, "available", "failed", "deregistered")
+		return result, state, nil
+	})
+}
+
+// WaitUntilSnapshotCompleted waits until the given snapshots are
+// completed.
+func (ec2 *EC2) WaitUntilSnapshotCompleted(ctx context.Context, ids []string, w *aws.Waiter) error {
+	return ec2.wait(ctx, "SnapshotCompleted", w, func() (aws.WaitResult, string, error) {
+		resp, err := ec2.Snapshots(ids, nil)
+		if notFound(err) {
+			return aws.WaitRetry, "", nil
+		}
+		if err != nil {
+			return aws.WaitRetry, "", err
+		}
+		states := make([]string, len(resp.Snapshots))
+		for i, snap := range resp.Snapshots {
+			states[i] = snap.Status
+		}
+		result, state := matchStates(states, "completed", "error")
+		return result, state, nil
+	})
+}
+
+func (ec2 *EC2) waitForVolumes(ctx context.Context, name string, ids []string, w *aws.Waiter, state func(v *Volume) string, success string) error {
+	return ec2.wait(ctx, name, w, func() (aws.WaitResult, string, error) {
+		resp, err := ec2.DescribeVolumes(ids, nil)
+		if notFound(err) {
+			return aws.WaitRetry, "", nil
+		}
+		if err != nil {
+			return aws.WaitRetry, "", err
+		}
+		states := make([]string, len(resp.Volumes))
+		for i := range resp.Volumes {
+			states[i] = state(&resp.Volumes[i])
+		}
+		result, current := matchStates(states, success, "deleting", "deleted", "error")
+		return result, current, nil
+	})
+}
+
+// WaitUntilVolumeAttached waits until the given volumes are attached to
+// an instance.
+func (ec2 *EC2) WaitUntilVolumeAttached(ctx context.Context, ids []string, w *aws.Waiter) error {
+	return ec2.waitForVolumes(ctx, "VolumeAttached", ids, w, func(v *Volume) string {
+		if v.Status != "in-use" || len(v.Attachments) == 0 {
+			return v.Status
+		}
+		return v.Attachments[0].Status
+	}, "attached")
+}
+
+// WaitUntilVolumeDetached waits until the given volumes are detached and
+// available for use.
+func (ec2 *EC2) WaitUntilVolumeDetached(ctx context.Context, ids []string, w *aws.Waiter) error {
+	return ec2.waitForVolumes(ctx, "VolumeDetached", ids, w, func(v *Volume) string {
+		return v.Status
+	}, "available")
+}
+
+// WaitForSpotInstances waits until the given spot instance requests are
+// fulfilled, and returns the instances launched for them. A
+// *SpotRequestError is returned as soon as one of the requests is
+// closed, cancelled or failed before being fulfilled.
+func (ec2 *EC2) WaitForSpotInstances(ctx context.Context, ids []string, w *aws.Waiter) ([]Instance, error) {
+	var instIds []string
+	err := ec2.wait(ctx, "SpotInstanceRequestFulfilled", w, func() (aws.WaitResult, string, error) {
+		resp, err := ec2.DescribeSpotInstanceRequests(ids, nil)
+		if notFound(err) {
+			return aws.WaitRetry, "", nil
+		}
+		if err != nil {
+			return aws.WaitRetry, "", err
+		}
+		instIds = instIds[:0]
+		states := make([]string, len(resp.SpotRequests))
+		for i, r := range resp.SpotRequests {
+			states[i] = r.State
+			if r.InstanceId != "" {
+				states[i] = "fulfilled"
+				instIds = append(instIds, r.InstanceId)
+			}
+		}
+		result, state := matchStates(states, "fulfilled", "closed", "cancelled", "failed")
+		if result == aws.WaitFailure {
+			for _, r := range resp.SpotRequests {
+				if r.InstanceId == "" && r.State == state {
+					return result, state, &SpotRequestError{r.Id, r.State, r.StatusCode, r.StatusMessage}
+				}
+			}
+		}
+		return result, state, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := ec2.DescribeInstances(instIds, nil)
+	if err != nil {
+		return nil, err
+	}
+	var instances []Instance
+	for _, rsv := range resp.Reservations {
+		instances = append(instances, rsv.Instances...)
+	}
+	return instances, nil
+}
@@ -0,0 +1,197 @@
+package ec2_test
+
+import (
+	"context"
+
+	"github.com/crowdmob/goamz/aws"
+	"github.com/crowdmob/goamz/ec2"
+	"gopkg.in/check.v1"
+)
+
+var testWaiter = &aws.Waiter{Delay: 1e6, MaxAttempts: 3}
+
+func (s *S) TestWaitUntilInstanceRunning(c *check.C) {
+	testServer.Response(400, nil, InstanceNotFoundErrorDump)
+	testServer.Response(200, nil, DescribeInstancesPendingExample)
+	testServer.Response(200, nil, DescribeInstancesExample1)
+
+	err := s.ec2.WaitUntilInstanceRunning(context.Background(), []string{"i-1234567890abcdef0"}, testWaiter)
+
+	for i := 0; i < 3; i++ {
+		req := testServer.WaitRequest()
+		c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeInstances"})
+		c.Assert(req.Form["InstanceId.1"], check.DeepEquals, []string{"i-1234567890abcdef0"})
+	}
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestWaitUntilInstanceRunningFailure(c *check.C) {
+	testServer.Response(200, nil, DescribeInstancesTerminatedExample)
+
+	err := s.ec2.WaitUntilInstanceRunning(context.Background(), []string{"i-1234567890abcdef0"}, testWaiter)
+
+	testServer.WaitRequest()
+	c.Assert(err, check.DeepEquals, &aws.WaiterError{Name: "InstanceRunning", State: "terminated", Attempts: 1})
+}
+
+func (s *S) TestWaitUntilInstanceStoppedTimeout(c *check.C) {
+	testServer.Response(200, nil, DescribeInstancesExample1)
+	testServer.Response(200, nil, DescribeInstancesExample1)
+	testServer.Response(200, nil, DescribeInstancesExample1)
+
+	err := s.ec2.WaitUntilInstanceStopped(context.Background(), []string{"i-c5cd56af"}, testWaiter)
+
+	for i := 0; i < 3; i++ {
+		testServer.WaitRequest()
+	}
+	c.Assert(err, check.DeepEquals, &aws.WaiterError{Name: "InstanceStopped", State: "running", Attempts: 3, Timeout: true})
+}
+
+func (s *S) TestWaitUntilInstanceTerminated(c *check.C) {
+	testServer.Response(200, nil, DescribeInstancesTerminatedExample)
+
+	err := s.ec2.WaitUntilInstanceTerminated(context.Background(), []string{"i-1234567890abcdef0"}, testWaiter)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeInstances"})
+	c.Assert(req.Form["InstanceId.1"], check.IsNil)
+	c.Assert(req.Form["Filter.1.Name"], check.DeepEquals, []string{"instance-id"})
+	c.Assert(req.Form["Filter.1.Value.1"], check.DeepEquals, []string{"i-1234567890abcdef0"})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestWaitUntilInstanceTerminatedNotFound(c *check.C) {
+	testServer.Response(200, nil, DescribeInstancesEmptyExample)
+
+	err := s.ec2.WaitUntilInstanceTerminated(context.Background(), []string{"i-1234567890abcdef0"}, testWaiter)
+
+	testServer.WaitRequest()
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestWaitUntilInstanceTerminatedPartlyNotFound(c *check.C) {
+	// i-c5cd56af is still running, i-unknown isn't found.
+	testServer.Response(200, nil, DescribeInstancesExample1)
+	testServer.Response(200, nil, DescribeInstancesExample1)
+	testServer.Response(200, nil, DescribeInstancesExample1)
+
+	err := s.ec2.WaitUntilInstanceTerminated(context.Background(), []string{"i-c5cd56af", "i-unknown"}, testWaiter)
+
+	for i := 0; i < 3; i++ {
+		req := testServer.WaitRequest()
+		c.Assert(req.Form["Filter.1.Value.1"], check.DeepEquals, []string{"i-c5cd56af"})
+		c.Assert(req.Form["Filter.1.Value.2"], check.DeepEquals, []string{"i-unknown"})
+	}
+	c.Assert(err, check.DeepEquals, &aws.WaiterError{Name: "InstanceTerminated", State: "running", Attempts: 3, Timeout: true})
+}
+
+func (s *S) TestWaitUntilInstanceRunningError(c *check.C) {
+	testServer.Response(400, nil, ErrorDump)
+
+	err := s.ec2.WaitUntilInstanceRunning(context.Background(), []string{"i-1234567890abcdef0"}, testWaiter)
+
+	testServer.WaitRequest()
+	c.Assert(err, check.ErrorMatches, `.*\(UnsupportedOperation\)`)
+}
+
+func (s *S) TestWaitUntilInstanceRunningCancelled(c *check.C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := s.ec2.WaitUntilInstanceRunning(ctx, []string{"i-1234567890abcdef0"}, nil)
+	c.Assert(err, check.Equals, context.Canceled)
+}
+
+func (s *S) TestWaitUntilImageAvailable(c *check.C) {
+	testServer.Response(200, nil, DescribeImagesExample)
+
+	err := s.ec2.WaitUntilImageAvailable(context.Background(), []string{"ami-a2469acf"}, testWaiter)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeImages"})
+	c.Assert(req.Form["ImageId.1"], check.DeepEquals, []string{"ami-a2469acf"})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestWaitUntilSnapshotCompleted(c *check.C) {
+	testServer.Response(200, nil, DescribeSnapshotsExample)
+	testServer.Response(200, nil, DescribeSnapshotsCompletedExample)
+
+	err := s.ec2.WaitUntilSnapshotCompleted(context.Background(), []string{"snap-1a2b3c4d"}, testWaiter)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeSnapshots"})
+	c.Assert(req.Form["SnapshotId.1"], check.DeepEquals, []string{"snap-1a2b3c4d"})
+	testServer.WaitRequest()
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestWaitUntilVolumeAttached(c *check.C) {
+	testServer.Response(200, nil, DescribeVolumesAvailableExample)
+	testServer.Response(200, nil, DescribeVolumesExample)
+
+	err := s.ec2.WaitUntilVolumeAttached(context.Background(), []string{"vol-1234567890abcdef0"}, testWaiter)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeVolumes"})
+	c.Assert(req.Form["VolumeId.1"], check.DeepEquals, []string{"vol-1234567890abcdef0"})
+	testServer.WaitRequest()
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestWaitUntilVolumeDetached(c *check.C) {
+	testServer.Response(200, nil, DescribeVolumesExample)
+	testServer.Response(200, nil, DescribeVolumesAvailableExample)
+
+	err := s.ec2.WaitUntilVolumeDetached(context.Background(), []string{"vol-1234567890abcdef0"}, testWaiter)
+
+	testServer.WaitRequest()
+	testServer.WaitRequest()
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestWaitForSpotInstances(c *check.C) {
+	testServer.Response(200, nil, RequestSpotInstancesExample)
+	testServer.Response(200, nil, DescribeSpotInstanceRequestsExample)
+	testServer.Response(200, nil, DescribeInstancesExample1)
+
+	instances, err := s.ec2.WaitForSpotInstances(context.Background(), []string{"sir-1a2b3c4d"}, testWaiter)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeSpotInstanceRequests"})
+	req = testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeSpotInstanceRequests"})
+	req = testServer.WaitRequest()
+	c.Assert(req.Form["Action"], check.DeepEquals, []string{"DescribeInstances"})
+	c.Assert(req.Form["InstanceId.1"], check.DeepEquals, []string{"i-1234567890abcdef0"})
+
+	c.Assert(err, check.IsNil)
+	c.Assert(instances, check.Not(check.HasLen), 0)
+	c.Assert(instances[0].InstanceId, check.Equals, "i-c5cd56af")
+}
+
+func (s *S) TestWaitForSpotInstancesCancelled(c *check.C) {
+	testServer.Response(200, nil, DescribeSpotInstanceRequestsCancelledExample)
+
+	_, err := s.ec2.WaitForSpotInstances(context.Background(), []string{"sir-1a2b3c4d"}, testWaiter)
+
+	testServer.WaitRequest()
+	c.Assert(err, check.FitsTypeOf, &ec2.SpotRequestError{})
+	serr := err.(*ec2.SpotRequestError)
+	c.Assert(serr.Id, check.Equals, "sir-1a2b3c4d")
+	c.Assert(serr.State, check.Equals, "cancelled")
+	c.Assert(serr.StatusCode, check.Equals, "canceled-before-fulfillment")
+}
+
+func (s *S) TestWaitForSpotInstancesTimeout(c *check.C) {
+	for i := 0; i < 3; i++ {
+		testServer.Response(200, nil, RequestSpotInstancesExample)
+	}
+
+	_, err := s.ec2.WaitForSpotInstances(context.Background(), []string{"sir-1a2b3c4d"}, testWaiter)
+
+	for i := 0; i < 3; i++ {
+		testServer.WaitRequest()
+	}
+	c.Assert(err, check.DeepEquals, &aws.WaiterError{Name: "SpotInstanceRequestFulfilled", State: "open", Attempts: 3, Timeout: true})
+}
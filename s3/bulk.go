@@ -0,0 +1,164 @@
+package s3
+
+import (
+	"sync"
+)
+
+// The number of multi-object delete requests DeletePrefix runs
+// concurrently.
+const deletePrefixConcurrency = 4
+
+// The most keys accepted by a single multi-object delete request.
+// That's the default. Here just for testing.
+var deleteBatchMax = 1000
+
+// DeletePrefixResult reports the outcome of a DeletePrefix call.
+type DeletePrefixResult struct {
+	// Deleted is the number of objects, versions and delete
+	// markers removed.
+	Deleted int
+	// Errors holds every key that could not be removed. Keys in
+	// a batch whose request failed as a whole are reported with
+	// the code and message of the request error.
+	Errors []DeleteError
+}
+
+// DeletePrefix removes every object whose key starts with prefix.
+// If the bucket is versioned, all versions and delete markers of
+// those keys are removed as well, leaving nothing behind.
+//
+// Keys are streamed from the bucket listing in batches of up to
+// 1000 and deleted with concurrent DelMultiResult requests. When
+// some keys could not be removed, the result lists them all and
+// the first one is also returned as a *DeleteError.
+func (b *Bucket) DeletePrefix(prefix string) (*DeletePrefixResult, error) {
+	versioning, err := b.Versioning()
+	if err != nil {
+		return nil, err
+	}
+
+	batches := make(chan []Object)
+	result := &DeletePrefixResult{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < deletePrefixConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				deleted, errors := b.deleteBatch(batch)
+				mu.Lock()
+				result.Deleted += deleted
+				result.Errors = append(result.Errors, errors...)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	if versioning.Status != "" {
+		err = b.walkVersions(prefix, batches)
+	} else {
+		err = b.walkKeys(prefix, batches)
+	}
+	close(batches)
+	wg.Wait()
+
+	if err != nil {
+		return result, err
+	}
+	if len(result.Errors) > 0 {
+		return result, &result.Errors[0]
+	}
+	return result, nil
+}
+
+// deleteBatch removes objects with a single quiet DelMultiResult
+// request, returning the number deleted and the per-key failures.
+func (b *Bucket) deleteBatch(objects []Object) (int, []DeleteError) {
+	var resp *DeleteResult
+	var err error
+	for attempt := attempts.Start(); attempt.Next(); {
+		resp, err = b.DelMultiResult(Delete{Quiet: true, Objects: objects})
+		if !shouldRetry(err) {
+			break
+		}
+	}
+	if err != nil {
+		code := ""
+		if s3err, ok := err.(*Error); ok {
+			code = s3err.Code
+		}
+		errors := make([]DeleteError, len(objects))
+		for i, obj := range objects {
+			errors[i] = DeleteError{
+				Key:       obj.Key,
+				VersionId: obj.VersionId,
+				Code:      code,
+				Message:   err.Error(),
+			}
+		}
+		return 0, errors
+	}
+	return len(objects) - len(resp.Errors), resp.Errors
+}
+
+// walkKeys sends the keys under prefix to batches.
+func (b *Bucket) walkKeys(prefix string, batches chan<- []Object) error {
+	marker := ""
+	var batch []Object
+	for {
+		resp, err := b.List(prefix, "", marker, deleteBatchMax)
+		if err != nil {
+			return err
+		}
+		for _, key := range resp.Contents {
+			batch = append(batch, Object{Key: key.Key})
+			if len(batch) == deleteBatchMax {
+				batches <- batch
+				batch = nil
+			}
+		}
+		if !resp.IsTruncated || len(resp.Contents) == 0 {
+			break
+		}
+		marker = resp.Contents[len(resp.Contents)-1].Key
+	}
+	if len(batch) > 0 {
+		batches <- batch
+	}
+	return nil
+}
+
+// walkVersions sends every version and delete marker of the keys
+// under prefix to batches.
+func (b *Bucket) walkVersions(prefix string, batches chan<- []Object) error {
+	keyMarker, versionIdMarker := "", ""
+	var batch []Object
+	add := func(key, versionId string) {
+		batch = append(batch, Object{Key: key, VersionId: versionId})
+		if len(batch) == deleteBatchMax {
+			batches <- batch
+			batch = nil
+		}
+	}
+	for {
+		resp, err := b.Versions(prefix, "", keyMarker, versionIdMarker, deleteBatchMax)
+		if err != nil {
+			return err
+		}
+		for _, v := range resp.Versions {
+			add(v.Key, v.VersionId)
+		}
+		for _, m := range resp.DeleteMarkers {
+			add(m.Key, m.VersionId)
+		}
+		if !resp.IsTruncated {
+			break
+		}
+		keyMarker, versionIdMarker = resp.NextKeyMarker, resp.NextVersionIdMarker
+	}
+	if len(batch) > 0 {
+		batches <- batch
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package s3_test
+
+import (
+	"encoding/xml"
+	"github.com/crowdmob/goamz/s3"
+	"gopkg.in/check.v1"
+	"io/ioutil"
+)
+
+func readDelete(c *check.C, body []byte) s3.Delete {
+	var del s3.Delete
+	c.Assert(xml.Unmarshal(body, &del), check.IsNil)
+	return del
+}
+
+func (s *S) TestDelMultiReportsErrors(c *check.C) {
+	testServer.Response(200, nil, DeleteResultDump)
+
+	b := s.s3.Bucket("bucket")
+	result, err := b.DelMultiResult(s3.Delete{Objects: []s3.Object{{Key: "a"}, {Key: "b"}}})
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Deleted, check.DeepEquals, []s3.DeletedObject{{Key: "a"}})
+	c.Assert(result.Errors, check.DeepEquals, []s3.DeleteError{
+		{Key: "b", Code: "AccessDenied", Message: "Access Denied"},
+	})
+	testServer.WaitRequest()
+
+	testServer.Response(200, nil, DeleteResultDump)
+	err = b.DelMulti(s3.Delete{Objects: []s3.Object{{Key: "a"}, {Key: "b"}}})
+	c.Assert(err, check.FitsTypeOf, &s3.DeleteError{})
+	c.Assert(err.(*s3.DeleteError).Key, check.Equals, "b")
+}
+
+func (s *S) TestDeletePrefix(c *check.C) {
+	testServer.Response(200, nil, GetVersioningDisabledDump)
+	testServer.Response(200, nil, GetListResultDump1)
+	testServer.Response(200, nil, DeleteResultQuietDump)
+
+	b := s.s3.Bucket("bucket")
+	result, err := b.DeletePrefix("N")
+	c.Assert(err, check.FitsTypeOf, &s3.DeleteError{})
+	c.Assert(result.Deleted, check.Equals, 1)
+	c.Assert(result.Errors, check.DeepEquals, []s3.DeleteError{
+		{Key: "Neo", Code: "AccessDenied", Message: "Access Denied"},
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.URL.RawQuery, check.Equals, "versioning=")
+
+	req = testServer.WaitRequest()
+	c.Assert(req.Method, check.Equals, "GET")
+	c.Assert(req.Form["prefix"], check.DeepEquals, []string{"N"})
+
+	req = testServer.WaitRequest()
+	c.Assert(req.Method, check.Equals, "POST")
+	c.Assert(req.URL.RawQuery, check.Equals, "delete=")
+	body, err := ioutil.ReadAll(req.Body)
+	c.Assert(err, check.IsNil)
+	c.Assert(readDelete(c, body), check.DeepEquals, s3.Delete{
+		Quiet:   true,
+		Objects: []s3.Object{{Key: "Nelson"}, {Key: "Neo"}},
+	})
+}
+
+func (s *S) TestDeletePrefixVersioned(c *check.C) {
+	testServer.Response(200, nil, GetVersioningEnabledDump)
+	testServer.Response(200, nil, GetVersionsResultDump)
+	testServer.Response(200, nil, "")
+
+	b := s.s3.Bucket("bucket")
+	result, err := b.DeletePrefix("my")
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Deleted, check.Equals, 3)
+	c.Assert(result.Errors, check.HasLen, 0)
+
+	testServer.WaitRequest()
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["versions"], check.DeepEquals, []string{""})
+
+	req = testServer.WaitRequest()
+	body, err := ioutil.ReadAll(req.Body)
+	c.Assert(err, check.IsNil)
+	c.Assert(readDelete(c, body).Objects, check.DeepEquals, []s3.Object{
+		{Key: "my-image.jpg", VersionId: "3/L4kqtJl40Nr8X8gdRQBpUMLUo"},
+		{Key: "my-image.jpg", VersionId: "QUpfdndhfd8438MNFDN93jdnJFkdmqnh893"},
+		{Key: "my-second-image.jpg", VersionId: "03jpff543dhffds434rfdsFDN943fdsFkdmqnh892"},
+	})
+}
@@ -0,0 +1,168 @@
+package s3
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Checksum algorithms accepted in Options.ChecksumAlgorithm.
+const (
+	ChecksumCRC32C = "CRC32C"
+	ChecksumSHA256 = "SHA256"
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ChecksumError is returned when data sent to or received from S3 does
+// not match its checksum.
+type ChecksumError struct {
+	Algorithm string // "MD5", "CRC32C" or "SHA256"
+	Expected  string
+	Actual    string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("s3: %s checksum mismatch: expected %s, got %s", e.Algorithm, e.Expected, e.Actual)
+}
+
+// checksumHeader returns the header carrying the checksum for algorithm
+// and a new hash computing it.
+func checksumHeader(algorithm string) (string, hash.Hash, error) {
+	switch strings.ToUpper(algorithm) {
+	case ChecksumCRC32C:
+		return "x-amz-checksum-crc32c", crc32.New(crc32cTable), nil
+	case ChecksumSHA256:
+		return "x-amz-checksum-sha256", sha256.New(), nil
+	}
+	return "", nil, fmt.Errorf("s3: unsupported checksum algorithm %q", algorithm)
+}
+
+// addChecksums computes the Content-MD5 and, if requested, the
+// x-amz-checksum-* header of the next length bytes of r, restoring
+// the position of r afterwards.
+func (o Options) addChecksums(headers map[string][]string, r io.ReadSeeker, length int64) error {
+	var hashes []io.Writer
+	md5sum := md5.New()
+	if len(o.ContentMD5) == 0 && !o.DisableContentMD5 {
+		hashes = append(hashes, md5sum)
+	}
+	var header string
+	var sum hash.Hash
+	if len(o.ChecksumAlgorithm) != 0 {
+		var err error
+		header, sum, err = checksumHeader(o.ChecksumAlgorithm)
+		if err != nil {
+			return err
+		}
+		hashes = append(hashes, sum)
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	pos, err := r.Seek(0, 1)
+	if err != nil {
+		return err
+	}
+	if _, err := io.CopyN(io.MultiWriter(hashes...), r, length); err != nil {
+		return err
+	}
+	if _, err := r.Seek(pos, 0); err != nil {
+		return err
+	}
+	if len(o.ContentMD5) == 0 && !o.DisableContentMD5 {
+		headers["Content-MD5"] = []string{base64.StdEncoding.EncodeToString(md5sum.Sum(nil))}
+	}
+	if sum != nil {
+		headers[header] = []string{base64.StdEncoding.EncodeToString(sum.Sum(nil))}
+	}
+	return nil
+}
+
+// withChecksumMode returns a copy of headers asking S3 to include the
+// x-amz-checksum-* headers of the object in its response, which it
+// otherwise leaves out.
+func withChecksumMode(headers map[string][]string) map[string][]string {
+	h := make(map[string][]string, len(headers)+1)
+	for k, v := range headers {
+		h[k] = v
+	}
+	h["x-amz-checksum-mode"] = []string{"ENABLED"}
+	return h
+}
+
+// etagMD5 returns the hex MD5 sum held in the ETag of header, or
+// an empty string if the ETag is not known to be the MD5 of the data,
+// as is the case for multipart uploads and KMS or customer encryption.
+func etagMD5(header http.Header) string {
+	if header.Get("x-amz-server-side-encryption") == "aws:kms" ||
+		header.Get("x-amz-server-side-encryption-customer-algorithm") != "" {
+		return ""
+	}
+	etag := strings.ToLower(strings.Trim(header.Get("ETag"), `"`))
+	if len(etag) != 2*md5.Size {
+		return ""
+	}
+	if _, err := hex.DecodeString(etag); err != nil {
+		return ""
+	}
+	return etag
+}
+
+// validatingReader checks the data read from an object against the
+// checksum advertised by S3 once the end of the body is reached.
+type validatingReader struct {
+	io.ReadCloser
+	hash      hash.Hash
+	algorithm string
+	expected  []byte
+	encode    func([]byte) string
+}
+
+// newValidatingReader wraps the body of resp so that reading it fails
+// with a *ChecksumError if the data does not match the x-amz-checksum-*
+// headers or the ETag of the object. The body is returned unchanged if
+// none of them can be checked.
+func newValidatingReader(resp *http.Response) io.ReadCloser {
+	if resp.StatusCode != 200 || resp.Uncompressed {
+		return resp.Body
+	}
+	for _, algorithm := range []string{ChecksumSHA256, ChecksumCRC32C} {
+		header, sum, _ := checksumHeader(algorithm)
+		value := resp.Header.Get(header)
+		if value == "" || strings.Contains(value, "-") {
+			// Composite checksums of multipart uploads cannot be verified.
+			continue
+		}
+		expected, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			continue
+		}
+		return &validatingReader{resp.Body, sum, algorithm, expected, base64.StdEncoding.EncodeToString}
+	}
+	if etag := etagMD5(resp.Header); etag != "" {
+		expected, _ := hex.DecodeString(etag)
+		return &validatingReader{resp.Body, md5.New(), "MD5", expected, hex.EncodeToString}
+	}
+	return resp.Body
+}
+
+func (r *validatingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF {
+		if actual := r.hash.Sum(nil); !bytes.Equal(actual, r.expected) {
+			return n, &ChecksumError{r.algorithm, r.encode(r.expected), r.encode(actual)}
+		}
+	}
+	return n, err
+}
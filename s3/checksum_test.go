@@ -0,0 +1,145 @@
+package s3_test
+
+import (
+	"github.com/crowdmob/goamz/s3"
+	"gopkg.in/check.v1"
+	"io/ioutil"
+	"strings"
+)
+
+// onlyReader hides every method but Read, as a network stream would.
+type onlyReader struct {
+	r *strings.Reader
+}
+
+func (r onlyReader) Read(p []byte) (int, error) {
+	return r.r.Read(p)
+}
+
+func (s *S) TestPutComputesChecksums(c *check.C) {
+	b := s.s3.Bucket("bucket")
+	tests := []struct {
+		options s3.Options
+		header  string
+		value   string
+	}{
+		{s3.Options{}, "Content-Md5", "mgNkuembtIDdJeHwKEyFVQ=="},
+		{s3.Options{ContentMD5: "given"}, "Content-Md5", "given"},
+		{s3.Options{DisableContentMD5: true}, "Content-Md5", ""},
+		{s3.Options{ChecksumAlgorithm: s3.ChecksumCRC32C}, "X-Amz-Checksum-Crc32c", "Ya91Mw=="},
+		{s3.Options{ChecksumAlgorithm: s3.ChecksumSHA256}, "X-Amz-Checksum-Sha256", "7XACtDnprIRfIjV9giusFERzD722AW0+yUMil7nsn3M="},
+	}
+	for _, t := range tests {
+		testServer.Response(200, nil, "")
+		err := b.Put("name", []byte("content"), "text/plain", s3.Private, t.options)
+		c.Assert(err, check.IsNil)
+
+		req := testServer.WaitRequest()
+		c.Check(req.Header.Get(t.header), check.Equals, t.value)
+		c.Check(readAll(req.Body), check.Equals, "content")
+	}
+}
+
+func (s *S) TestPutReaderStreamsWithoutChecksum(c *check.C) {
+	testServer.Response(200, nil, "")
+
+	b := s.s3.Bucket("bucket")
+	r := onlyReader{strings.NewReader("content")}
+	err := b.PutReader("name", r, 7, "text/plain", s3.Private, s3.Options{})
+	c.Assert(err, check.IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Header["Content-Md5"], check.IsNil)
+
+	r = onlyReader{strings.NewReader("content")}
+	err = b.PutReader("name", r, 7, "text/plain", s3.Private, s3.Options{ChecksumAlgorithm: s3.ChecksumSHA256})
+	c.Assert(err, check.ErrorMatches, "s3: SHA256 checksum requires an io.ReadSeeker")
+}
+
+func (s *S) TestGetValidatesETag(c *check.C) {
+	b := s.s3.Bucket("bucket")
+
+	testServer.Response(200, map[string]string{"ETag": `"9a0364b9e99bb480dd25e1f0284c8555"`}, "content")
+	data, err := b.Get("name")
+	c.Assert(err, check.IsNil)
+	c.Assert(string(data), check.Equals, "content")
+
+	testServer.Response(200, map[string]string{"ETag": `"9a0364b9e99bb480dd25e1f0284c8555"`}, "corrupt")
+	_, err = b.Get("name")
+	c.Assert(err, check.FitsTypeOf, &s3.ChecksumError{})
+	c.Assert(err.(*s3.ChecksumError).Algorithm, check.Equals, "MD5")
+
+	// ETags of multipart uploads are not MD5 sums of the data.
+	testServer.Response(200, map[string]string{"ETag": `"9a0364b9e99bb480dd25e1f0284c8555-2"`}, "corrupt")
+	_, err = b.Get("name")
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestGetValidatesChecksumHeader(c *check.C) {
+	b := s.s3.Bucket("bucket")
+	headers := map[string]string{
+		"ETag":                  `"9a0364b9e99bb480dd25e1f0284c8555"`,
+		"x-amz-checksum-sha256": "7XACtDnprIRfIjV9giusFERzD722AW0+yUMil7nsn3M=",
+	}
+
+	testServer.Response(200, headers, "content")
+	rc, err := b.GetReader("name")
+	c.Assert(err, check.IsNil)
+	_, err = ioutil.ReadAll(rc)
+	rc.Close()
+	c.Assert(err, check.IsNil)
+	req := testServer.WaitRequest()
+	c.Assert(req.Header["X-Amz-Checksum-Mode"], check.DeepEquals, []string{"ENABLED"})
+
+	headers["ETag"] = `"multipart-1"`
+	testServer.Response(200, headers, "CONTENT")
+	_, err = b.Get("name")
+	c.Assert(err, check.FitsTypeOf, &s3.ChecksumError{})
+	c.Assert(err.(*s3.ChecksumError).Algorithm, check.Equals, "SHA256")
+}
+
+func (s *S) TestGetChecksumValidationDisabled(c *check.C) {
+	s.s3.DisableChecksumValidation = true
+	defer func() {
+		s.s3.DisableChecksumValidation = false
+	}()
+
+	testServer.Response(200, map[string]string{"ETag": `"9a0364b9e99bb480dd25e1f0284c8555"`}, "corrupt")
+	data, err := s.s3.Bucket("bucket").Get("name")
+	c.Assert(err, check.IsNil)
+	c.Assert(string(data), check.Equals, "corrupt")
+	req := testServer.WaitRequest()
+	c.Assert(req.Header["X-Amz-Checksum-Mode"], check.IsNil)
+}
+
+func (s *S) TestHeadRequestsChecksums(c *check.C) {
+	testServer.Response(200, nil, "")
+	headers := map[string][]string{"Range": {"bytes=0-1"}}
+	_, err := s.s3.Bucket("bucket").Head("name", headers)
+	c.Assert(err, check.IsNil)
+	c.Assert(headers, check.HasLen, 1)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, check.Equals, "HEAD")
+	c.Assert(req.Header["X-Amz-Checksum-Mode"], check.DeepEquals, []string{"ENABLED"})
+	c.Assert(req.Header["Range"], check.DeepEquals, []string{"bytes=0-1"})
+}
+
+func (s *S) TestPutPartVerifiesETag(c *check.C) {
+	headers := map[string]string{
+		"ETag": `"9a0364b9e99bb480dd25e1f0284c8555"`,
+	}
+	testServer.Response(200, nil, InitMultiResultDump)
+	testServer.Response(200, headers, "")
+
+	b := s.s3.Bucket("sample")
+	multi, err := b.InitMulti("multi", "text/plain", s3.Private, s3.Options{})
+	c.Assert(err, check.IsNil)
+
+	_, err = multi.PutPart(1, strings.NewReader("<part 1>"))
+	c.Assert(err, check.DeepEquals, &s3.ChecksumError{
+		Algorithm: "MD5",
+		Expected:  "26f90efd10d614f100252ff56d88dad8",
+		Actual:    "9a0364b9e99bb480dd25e1f0284c8555",
+	})
+}
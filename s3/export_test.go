@@ -25,3 +25,7 @@ func SetListPartsMax(n int) {
 func SetListMultiMax(n int) {
 	listMultiMax = n
 }
+
+func SetDeleteBatchMax(n int) {
+	deleteBatchMax = n
+}
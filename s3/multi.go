@@ -187,6 +187,12 @@ func (m *Multi) putPart(n int, r io.ReadSeeker, partSize int64, md5b64 string) (
 		if etag == "" {
 			return Part{}, errors.New("part upload succeeded with no ETag")
 		}
+		if actual := etagMD5(resp.Header); actual != "" {
+			sum, _ := base64.StdEncoding.DecodeString(md5b64)
+			if expected := hex.EncodeToString(sum); actual != expected {
+				return Part{}, &ChecksumError{"MD5", expected, actual}
+			}
+		}
 		return Part{n, etag, partSize}, nil
 	}
 	panic("unreachable")
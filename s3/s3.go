@@ -39,9 +39,51 @@ type S3 struct {
 	aws.Region
 	ConnectTimeout time.Duration
 	ReadTimeout    time.Duration
-	private        byte // Reserve the right of using private data.
+
+	// AddressingStyle selects how buckets are addressed in request URLs.
+	AddressingStyle AddressingStyle
+
+	// Dualstack sends requests to the IPv4/IPv6 dual-stack endpoints
+	// of the region instead of Region.S3Endpoint.
+	Dualstack bool
+
+	// Accelerate sends requests to the transfer acceleration endpoint.
+	// It requires virtual-hosted style addressing and bucket names
+	// without dots.
+	Accelerate bool
+
+	// DisableChecksumValidation stops downloaded objects from being
+	// checked against their ETag or x-amz-checksum-* headers, for
+	// callers that consume partial bodies. Unless it is set, GET and
+	// HEAD requests send x-amz-checksum-mode: ENABLED so that S3
+	// returns the x-amz-checksum-* headers.
+	DisableChecksumValidation bool
+
+	private byte // Reserve the right of using private data.
 }
 
+// AddressingStyle determines whether a bucket is addressed through the
+// request path (http://endpoint/bucket/key) or through the host name
+// (http://bucket.endpoint/key).
+type AddressingStyle int
+
+const (
+	// AutoAddressing uses Region.S3BucketEndpoint when it is set and the
+	// path otherwise. Bucket names with dots fall back to the path over
+	// HTTPS, as they do not match the endpoint's wildcard certificate.
+	AutoAddressing AddressingStyle = iota
+
+	// PathStyle always addresses buckets through the path, ignoring
+	// Region.S3BucketEndpoint. This is what most S3-compatible stores
+	// such as MinIO or Ceph expect.
+	PathStyle
+
+	// VirtualHostedStyle always addresses buckets through the host name.
+	// When Region.S3BucketEndpoint is empty, the bucket is prepended to
+	// the host of Region.S3Endpoint.
+	VirtualHostedStyle
+)
+
 // The Bucket type encapsulates operations with an S3 bucket.
 type Bucket struct {
 	*S3
@@ -66,6 +108,15 @@ type Options struct {
 	CacheControl         string
 	RedirectLocation     string
 	ContentMD5           string
+	// Tags are sent as the x-amz-tagging header on uploads. On copies
+	// they replace the source object's tags.
+	Tags map[string]string
+	// ChecksumAlgorithm, if set to ChecksumCRC32C or ChecksumSHA256,
+	// makes single-part uploads send the matching x-amz-checksum-* header.
+	ChecksumAlgorithm string
+	// DisableContentMD5 stops single-part uploads from computing the
+	// Content-MD5 header when ContentMD5 is empty.
+	DisableContentMD5 bool
 	// What else?
 	// Content-Disposition string
 	//// The following become headers so they are []strings rather than strings... I think
@@ -75,6 +126,7 @@ type Options struct {
 type CopyOptions struct {
 	Options
 	MetadataDirective string
+	TaggingDirective  string
 	ContentType       string
 }
 
@@ -92,12 +144,13 @@ var attempts = aws.AttemptStrategy{
 
 // New creates a new S3.
 func New(auth aws.Auth, region aws.Region) *S3 {
-	return &S3{auth, region, 0, 0, 0}
+	return &S3{Auth: auth, Region: region}
 }
 
 // Bucket returns a Bucket with the given name.
 func (s3 *S3) Bucket(name string) *Bucket {
-	if s3.Region.S3BucketEndpoint != "" || s3.Region.S3LowercaseBucket {
+	if s3.Region.S3BucketEndpoint != "" || s3.Region.S3LowercaseBucket ||
+		s3.AddressingStyle == VirtualHostedStyle || s3.Accelerate {
 		name = strings.ToLower(name)
 	}
 	return &Bucket{s3, name}
@@ -235,6 +288,9 @@ func (b *Bucket) GetResponse(path string) (resp *http.Response, err error) {
 // It is the caller's responsibility to call Close on rc when
 // finished reading
 func (b *Bucket) GetResponseWithHeaders(path string, headers map[string][]string) (resp *http.Response, err error) {
+	if !b.S3.DisableChecksumValidation {
+		headers = withChecksumMode(headers)
+	}
 	req := &request{
 		bucket:  b.Name,
 		path:    path,
@@ -252,6 +308,9 @@ func (b *Bucket) GetResponseWithHeaders(path string, headers map[string][]string
 		if err != nil {
 			return nil, err
 		}
+		if !b.S3.DisableChecksumValidation && req.method == "GET" {
+			resp.Body = newValidatingReader(resp)
+		}
 		return resp, nil
 	}
 	panic("unreachable")
@@ -297,6 +356,9 @@ func (b *Bucket) Exists(path string) (exists bool, err error) {
 // Head HEADs an object in the S3 bucket, returns the response with
 // no body see http://bit.ly/17K1ylI
 func (b *Bucket) Head(path string, headers map[string][]string) (*http.Response, error) {
+	if !b.S3.DisableChecksumValidation {
+		headers = withChecksumMode(headers)
+	}
 	req := &request{
 		method:  "HEAD",
 		bucket:  b.Name,
@@ -325,7 +387,7 @@ func (b *Bucket) Head(path string, headers map[string][]string) (*http.Response,
 //
 // See http://goo.gl/FEBPD for details.
 func (b *Bucket) Put(path string, data []byte, contType string, perm ACL, options Options) error {
-	body := bytes.NewBuffer(data)
+	body := bytes.NewReader(data)
 	return b.PutReader(path, body, int64(len(data)), contType, perm, options)
 }
 
@@ -352,12 +414,24 @@ func (b *Bucket) PutCopy(path string, perm ACL, options CopyOptions, source stri
 
 // PutReader inserts an object into the S3 bucket by consuming data
 // from r until EOF.
+//
+// If r is an io.ReadSeeker, the data is read once beforehand to compute
+// the Content-MD5 and any checksum requested in options. Other readers
+// are streamed without checksums, and fail if options.ChecksumAlgorithm
+// is set.
 func (b *Bucket) PutReader(path string, r io.Reader, length int64, contType string, perm ACL, options Options) error {
 	headers := map[string][]string{
 		"Content-Length": {strconv.FormatInt(length, 10)},
 		"Content-Type":   {contType},
 		"x-amz-acl":      {string(perm)},
 	}
+	if rs, ok := r.(io.ReadSeeker); ok {
+		if err := options.addChecksums(headers, rs, length); err != nil {
+			return err
+		}
+	} else if len(options.ChecksumAlgorithm) != 0 {
+		return fmt.Errorf("s3: %s checksum requires an io.ReadSeeker", options.ChecksumAlgorithm)
+	}
 	options.addHeaders(headers)
 	req := &request{
 		method:  "PUT",
@@ -391,6 +465,9 @@ func (o Options) addHeaders(headers map[string][]string) {
 	if len(o.RedirectLocation) != 0 {
 		headers["x-amz-website-redirect-location"] = []string{o.RedirectLocation}
 	}
+	if len(o.Tags) != 0 {
+		headers["x-amz-tagging"] = []string{encodeTags(o.Tags)}
+	}
 	for k, v := range o.Meta {
 		headers["x-amz-meta-"+k] = v
 	}
@@ -402,6 +479,11 @@ func (o CopyOptions) addHeaders(headers map[string][]string) {
 	if len(o.MetadataDirective) != 0 {
 		headers["x-amz-metadata-directive"] = []string{o.MetadataDirective}
 	}
+	if len(o.TaggingDirective) != 0 {
+		headers["x-amz-tagging-directive"] = []string{o.TaggingDirective}
+	} else if len(o.Tags) != 0 {
+		headers["x-amz-tagging-directive"] = []string{"REPLACE"}
+	}
 	if len(o.ContentType) != 0 {
 		headers["Content-Type"] = []string{o.ContentType}
 	}
@@ -477,20 +559,65 @@ type Object struct {
 	VersionId string `xml:"VersionId,omitempty"`
 }
 
+// DeleteResult holds the outcome of a multi-object delete request.
+// In quiet mode only the objects that failed to be deleted are reported.
+type DeleteResult struct {
+	Deleted []DeletedObject `xml:"Deleted"`
+	Errors  []DeleteError   `xml:"Error"`
+}
+
+// DeletedObject is an object successfully removed by DelMulti.
+type DeletedObject struct {
+	Key                   string
+	VersionId             string
+	DeleteMarker          bool
+	DeleteMarkerVersionId string
+}
+
+// DeleteError reports an object that DelMulti failed to remove.
+type DeleteError struct {
+	Key       string
+	VersionId string
+	Code      string
+	Message   string
+}
+
+func (e *DeleteError) Error() string {
+	return fmt.Sprintf("cannot delete %q: %s", e.Key, e.Message)
+}
+
 // DelMulti removes up to 1000 objects from the S3 bucket.
+// If some of the objects could not be removed, the first failure
+// is returned as a *DeleteError. Use DelMultiResult to inspect
+// all of them.
 //
 // See http://goo.gl/jx6cWK for details.
 func (b *Bucket) DelMulti(objects Delete) error {
-	doc, err := xml.Marshal(objects)
+	result, err := b.DelMultiResult(objects)
 	if err != nil {
 		return err
 	}
+	if len(result.Errors) > 0 {
+		return &result.Errors[0]
+	}
+	return nil
+}
+
+// DelMultiResult removes up to 1000 objects from the S3 bucket and
+// returns the per-object outcome reported by S3.
+//
+// See http://goo.gl/jx6cWK for details.
+func (b *Bucket) DelMultiResult(objects Delete) (*DeleteResult, error) {
+	doc, err := xml.Marshal(objects)
+	if err != nil {
+		return nil, err
+	}
 
 	buf := makeXmlBuffer(doc)
 	digest := md5.New()
 	size, err := digest.Write(buf.Bytes())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	headers := map[string][]string{
@@ -507,7 +634,16 @@ func (b *Bucket) DelMulti(objects Delete) error {
 		payload: buf,
 	}
 
-	return b.S3.query(req, nil)
+	result := &DeleteResult{}
+	err = b.S3.query(req, result)
+	if err == io.EOF {
+		// Some S3 implementations reply to quiet requests with no body.
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // The ListResp type holds the results of a List bucket operation.
@@ -629,8 +765,14 @@ type VersionsResp struct {
 	MaxKeys         int
 	Delimiter       string
 	IsTruncated     bool
-	Versions        []Version
-	CommonPrefixes  []string `xml:">Prefix"`
+	Versions        []Version      `xml:"Version"`
+	DeleteMarkers   []DeleteMarker `xml:"DeleteMarker"`
+	CommonPrefixes  []string       `xml:">Prefix"`
+
+	// NextKeyMarker and NextVersionIdMarker are set when IsTruncated
+	// is true and should be passed to the next Versions call.
+	NextKeyMarker       string
+	NextVersionIdMarker string
 }
 
 // The Version type represents an object version stored in an S3 bucket.
@@ -647,6 +789,16 @@ type Version struct {
 	StorageClass string
 }
 
+// The DeleteMarker type represents a delete marker placed on a key
+// in a versioned S3 bucket.
+type DeleteMarker struct {
+	Key          string
+	VersionId    string
+	IsLatest     bool
+	LastModified string
+	Owner        Owner
+}
+
 func (b *Bucket) Versions(prefix, delim, keyMarker string, versionIdMarker string, max int) (result *VersionsResp, err error) {
 	params := map[string][]string{
 		"versions":  {""},
@@ -681,6 +833,38 @@ func (b *Bucket) Versions(prefix, delim, keyMarker string, versionIdMarker strin
 	return result, nil
 }
 
+// VersioningConfiguration holds the versioning state of a bucket.
+// Status is empty for buckets that never had versioning enabled.
+type VersioningConfiguration struct {
+	XMLName   xml.Name `xml:"VersioningConfiguration"`
+	Status    string   `xml:"Status,omitempty"`
+	MfaDelete string   `xml:"MfaDelete,omitempty"`
+}
+
+// Versioning returns the versioning state of the bucket.
+//
+// See http://docs.aws.amazon.com/AmazonS3/latest/API/RESTBucketGETversioningStatus.html
+// for details.
+func (b *Bucket) Versioning() (*VersioningConfiguration, error) {
+	req := &request{
+		bucket: b.Name,
+		path:   "/",
+		params: url.Values{"versioning": {""}},
+	}
+	result := &VersioningConfiguration{}
+	var err error
+	for attempt := attempts.Start(); attempt.Next(); {
+		err = b.S3.query(req, result)
+		if !shouldRetry(err) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 type GetLocationResp struct {
 	Location string `xml:",innerxml"`
 }
@@ -706,27 +890,52 @@ func (b *Bucket) Location() (string, error) {
 
 // URL returns a non-signed URL that allows retriving the
 // object at path. It only works if the object is publicly
-// readable (see SignedURL).
+// readable (see SignedURL). URL panics if the bucket can't be
+// addressed with the options of its S3; see ObjectURL.
 func (b *Bucket) URL(path string) string {
+	u, err := b.ObjectURL(path)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+// ObjectURL is like URL, but returns an error instead of panicking
+// when the bucket can't be addressed with the options of its S3: when
+// Accelerate is set with PathStyle addressing or for a bucket name with
+// dots, or when Dualstack is set without a region name.
+func (b *Bucket) ObjectURL(path string) (string, error) {
 	req := &request{
 		bucket: b.Name,
 		path:   path,
 	}
 	err := b.S3.prepare(req)
 	if err != nil {
-		panic(err)
+		return "", err
 	}
 	u, err := req.url()
 	if err != nil {
-		panic(err)
+		return "", err
 	}
 	u.RawQuery = ""
-	return u.String()
+	return u.String(), nil
 }
 
 // SignedURL returns a signed URL that allows anyone holding the URL
 // to retrieve the object at path. The signature is valid until expires.
+// SignedURL panics if the bucket can't be addressed with the options
+// of its S3; see SignedObjectURL.
 func (b *Bucket) SignedURL(path string, expires time.Time) string {
+	u, err := b.SignedObjectURL(path, expires)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+// SignedObjectURL is like SignedURL, but returns an error instead of
+// panicking when the bucket can't be addressed, as ObjectURL does.
+func (b *Bucket) SignedObjectURL(path string, expires time.Time) (string, error) {
 	req := &request{
 		bucket: b.Name,
 		path:   path,
@@ -734,30 +943,42 @@ func (b *Bucket) SignedURL(path string, expires time.Time) string {
 	}
 	err := b.S3.prepare(req)
 	if err != nil {
-		panic(err)
+		return "", err
 	}
 	u, err := req.url()
 	if err != nil {
-		panic(err)
+		return "", err
 	}
 	if b.S3.Auth.Token() != "" {
-		return u.String() + "&x-amz-security-token=" + url.QueryEscape(req.headers["X-Amz-Security-Token"][0])
+		return u.String() + "&x-amz-security-token=" + url.QueryEscape(req.headers["X-Amz-Security-Token"][0]), nil
 	} else {
-		return u.String()
+		return u.String(), nil
 	}
 }
 
 // UploadSignedURL returns a signed URL that allows anyone holding the URL
 // to upload the object at path. The signature is valid until expires.
+// The bucket is addressed as for other requests; if it can't be, the
+// error is logged and the URL is empty. See UploadSignedObjectURL.
 // contenttype is a string like image/png
 // path is the resource name in s3 terminalogy like images/ali.png [obviously exclusing the bucket name itself]
 func (b *Bucket) UploadSignedURL(path, method, content_type string, expires time.Time) string {
+	u, err := b.UploadSignedObjectURL(path, method, content_type, expires)
+	if err != nil {
+		log.Println("ERROR sining url for S3 upload", err)
+		return ""
+	}
+	return u
+}
+
+// UploadSignedObjectURL is like UploadSignedURL, but returns an error
+// when the bucket can't be addressed, as SignedObjectURL does.
+func (b *Bucket) UploadSignedObjectURL(path, method, content_type string, expires time.Time) (string, error) {
 	expire_date := expires.Unix()
 	if method != "POST" {
 		method = "PUT"
 	}
 	stringToSign := method + "\n\n" + content_type + "\n" + strconv.FormatInt(expire_date, 10) + "\n/" + b.Name + "/" + path
-	fmt.Println("String to sign:\n", stringToSign)
 	a := b.S3.Auth
 	secretKey := a.SecretKey
 	accessId := a.AccessKey
@@ -767,12 +988,18 @@ func (b *Bucket) UploadSignedURL(path, method, content_type string, expires time
 	signature := base64.StdEncoding.EncodeToString([]byte(macsum))
 	signature = strings.TrimSpace(signature)
 
-	signedurl, err := url.Parse("https://" + b.Name + ".s3.amazonaws.com/")
+	req := &request{
+		bucket: b.Name,
+		path:   "/" + path,
+	}
+	err := b.S3.setBaseURL(req)
 	if err != nil {
-		log.Println("ERROR sining url for S3 upload", err)
-		return ""
+		return "", err
+	}
+	signedurl, err := req.url()
+	if err != nil {
+		return "", err
 	}
-	signedurl.Path += path
 	params := url.Values{}
 	params.Add("AWSAccessKeyId", accessId)
 	params.Add("Expires", strconv.FormatInt(expire_date, 10))
@@ -782,11 +1009,12 @@ func (b *Bucket) UploadSignedURL(path, method, content_type string, expires time
 	}
 
 	signedurl.RawQuery = params.Encode()
-	return signedurl.String()
+	return signedurl.String(), nil
 }
 
 // PostFormArgs returns the action and input fields needed to allow anonymous
-// uploads to a bucket within the expiration limit
+// uploads to a bucket within the expiration limit. The action addresses the
+// bucket as for other requests; if it can't, the action is empty.
 func (b *Bucket) PostFormArgs(path string, expires time.Time, redirect string) (action string, fields map[string]string) {
 	conditions := make([]string, 0)
 	fields = map[string]string{
@@ -811,7 +1039,15 @@ func (b *Bucket) PostFormArgs(path string, expires time.Time, redirect string) (
 	signer.Write([]byte(policy64))
 	fields["signature"] = base64.StdEncoding.EncodeToString(signer.Sum(nil))
 
-	action = fmt.Sprintf("%s/%s/", b.S3.Region.S3Endpoint, b.Name)
+	req := &request{
+		bucket: b.Name,
+		path:   "/",
+	}
+	if err := b.S3.setBaseURL(req); err != nil {
+		log.Println("ERROR building the form action for S3 upload", err)
+		return "", fields
+	}
+	action = req.baseurl + req.path
 	return
 }
 
@@ -859,7 +1095,9 @@ func (s3 *S3) queryV4Sign(req *request, resp interface{}) error {
 		req.headers = map[string][]string{}
 	}
 
-	s3.setBaseURL(req)
+	if err := s3.setBaseURL(req); err != nil {
+		return err
+	}
 
 	hreq, err := s3.setupHttpRequest(req)
 	if err != nil {
@@ -877,23 +1115,75 @@ func (s3 *S3) queryV4Sign(req *request, resp interface{}) error {
 	return err
 }
 
+// endpoints returns the service endpoint and the bucket endpoint
+// template, with ${bucket} standing for the bucket name, to be used
+// for requests. The bucket endpoint is empty if buckets must be
+// addressed through the path.
+func (s3 *S3) endpoints() (endpoint, bucketEndpoint string, err error) {
+	switch {
+	case s3.Accelerate:
+		if s3.AddressingStyle == PathStyle {
+			return "", "", fmt.Errorf("S3 transfer acceleration does not support path-style addressing")
+		}
+		host := "s3-accelerate.amazonaws.com"
+		if s3.Dualstack {
+			host = "s3-accelerate.dualstack.amazonaws.com"
+		}
+		endpoint = "https://" + host
+		bucketEndpoint = "https://${bucket}." + host
+	case s3.Dualstack:
+		if s3.Region.Name == "" {
+			return "", "", fmt.Errorf("S3 dual-stack endpoints require a region name")
+		}
+		host := "s3.dualstack." + s3.Region.Name + ".amazonaws.com"
+		endpoint = "https://" + host
+		bucketEndpoint = "https://${bucket}." + host
+	default:
+		endpoint = s3.Region.S3Endpoint
+		bucketEndpoint = s3.Region.S3BucketEndpoint
+		if bucketEndpoint == "" && s3.AddressingStyle == VirtualHostedStyle {
+			u, err := url.Parse(endpoint)
+			if err != nil {
+				return "", "", fmt.Errorf("bad S3 endpoint URL %q: %v", endpoint, err)
+			}
+			bucketEndpoint = u.Scheme + "://${bucket}." + u.Host + u.Path
+		}
+	}
+	if s3.AddressingStyle == PathStyle {
+		bucketEndpoint = ""
+	}
+	return endpoint, bucketEndpoint, nil
+}
+
 // Sets baseurl on req from bucket name and the region endpoint
 func (s3 *S3) setBaseURL(req *request) error {
+	endpoint, bucketEndpoint, err := s3.endpoints()
+	if err != nil {
+		return err
+	}
 	if req.bucket == "" {
-		req.baseurl = s3.Region.S3Endpoint
+		req.baseurl = endpoint
+		return nil
+	}
+	if bucketEndpoint != "" && strings.Contains(req.bucket, ".") {
+		if s3.Accelerate {
+			return fmt.Errorf("bad S3 bucket for transfer acceleration: %q", req.bucket)
+		}
+		if s3.AddressingStyle == AutoAddressing && strings.HasPrefix(bucketEndpoint, "https:") {
+			// The wildcard certificate does not cover dotted names.
+			bucketEndpoint = ""
+		}
+	}
+	if bucketEndpoint == "" {
+		// Use the path method to address the bucket.
+		req.baseurl = endpoint
+		req.path = "/" + req.bucket + req.path
 	} else {
-		req.baseurl = s3.Region.S3BucketEndpoint
-		if req.baseurl == "" {
-			// Use the path method to address the bucket.
-			req.baseurl = s3.Region.S3Endpoint
-			req.path = "/" + req.bucket + req.path
-		} else {
-			// Just in case, prevent injection.
-			if strings.IndexAny(req.bucket, "/:@") >= 0 {
-				return fmt.Errorf("bad S3 bucket: %q", req.bucket)
-			}
-			req.baseurl = strings.Replace(req.baseurl, "${bucket}", req.bucket, -1)
+		// Just in case, prevent injection.
+		if strings.IndexAny(req.bucket, "/:@") >= 0 {
+			return fmt.Errorf("bad S3 bucket: %q", req.bucket)
 		}
+		req.baseurl = strings.Replace(bucketEndpoint, "${bucket}", req.bucket, -1)
 	}
 
 	return nil
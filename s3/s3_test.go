@@ -132,6 +132,119 @@ func (s *S) TestURL(c *check.C) {
 	c.Assert(req.URL.Path, check.Equals, "/bucket/name")
 }
 
+func (s *S) TestAddressingStyles(c *check.C) {
+	auth := aws.Auth{AccessKey: "abc", SecretKey: "123"}
+	minio := aws.Region{Name: "us-east-1", S3Endpoint: "http://minio.local:9000"}
+	domain := aws.USEast
+	domain.S3BucketEndpoint = "https://${bucket}.s3.amazonaws.com"
+
+	tests := []struct {
+		region aws.Region
+		style  s3.AddressingStyle
+		dual   bool
+		accel  bool
+		bucket string
+		url    string
+	}{
+		{aws.USEast, s3.AutoAddressing, false, false, "bucket", "https://s3.amazonaws.com/bucket/name"},
+		{domain, s3.AutoAddressing, false, false, "bucket", "https://bucket.s3.amazonaws.com/name"},
+		{domain, s3.AutoAddressing, false, false, "my.bucket", "https://s3.amazonaws.com/my.bucket/name"},
+		{domain, s3.PathStyle, false, false, "bucket", "https://s3.amazonaws.com/bucket/name"},
+		{domain, s3.VirtualHostedStyle, false, false, "my.bucket", "https://my.bucket.s3.amazonaws.com/name"},
+		{aws.USWest2, s3.VirtualHostedStyle, false, false, "bucket", "https://bucket.s3-us-west-2.amazonaws.com/name"},
+		{minio, s3.AutoAddressing, false, false, "bucket", "http://minio.local:9000/bucket/name"},
+		{minio, s3.VirtualHostedStyle, false, false, "bucket", "http://bucket.minio.local:9000/name"},
+		{aws.USWest2, s3.AutoAddressing, true, false, "bucket", "https://bucket.s3.dualstack.us-west-2.amazonaws.com/name"},
+		{aws.USWest2, s3.PathStyle, true, false, "bucket", "https://s3.dualstack.us-west-2.amazonaws.com/bucket/name"},
+		{aws.USEast, s3.AutoAddressing, false, true, "bucket", "https://bucket.s3-accelerate.amazonaws.com/name"},
+		{aws.USEast, s3.AutoAddressing, true, true, "bucket", "https://bucket.s3-accelerate.dualstack.amazonaws.com/name"},
+	}
+	for i, t := range tests {
+		client := s3.New(auth, t.region)
+		client.AddressingStyle = t.style
+		client.Dualstack = t.dual
+		client.Accelerate = t.accel
+		c.Check(client.Bucket(t.bucket).URL("name"), check.Equals, t.url, check.Commentf("test %d", i))
+	}
+}
+
+func (s *S) TestUploadURLAddressing(c *check.C) {
+	auth := aws.Auth{AccessKey: "abc", SecretKey: "123"}
+	expires := time.Now().Add(time.Hour)
+
+	client := s3.New(auth, aws.USWest2)
+	action, _ := client.Bucket("bucket").PostFormArgs("name", expires, "")
+	c.Check(action, check.Equals, "https://s3-us-west-2.amazonaws.com/bucket/")
+	upload := client.Bucket("bucket").UploadSignedURL("dir/name", "PUT", "image/png", expires)
+	c.Check(upload, check.Matches, `https://s3-us-west-2\.amazonaws\.com/bucket/dir/name\?AWSAccessKeyId=abc&.*`)
+
+	client.AddressingStyle = s3.VirtualHostedStyle
+	action, _ = client.Bucket("bucket").PostFormArgs("name", expires, "")
+	c.Check(action, check.Equals, "https://bucket.s3-us-west-2.amazonaws.com/")
+
+	client.Accelerate = true
+	upload = client.Bucket("bucket").UploadSignedURL("dir/name", "PUT", "image/png", expires)
+	c.Check(upload, check.Matches, `https://bucket\.s3-accelerate\.amazonaws\.com/dir/name\?.*`)
+
+	client.AddressingStyle = s3.PathStyle
+	action, fields := client.Bucket("bucket").PostFormArgs("name", expires, "")
+	c.Check(action, check.Equals, "")
+	c.Check(fields["key"], check.Equals, "name")
+	c.Check(client.Bucket("bucket").UploadSignedURL("name", "PUT", "", expires), check.Equals, "")
+	_, err := client.Bucket("bucket").UploadSignedObjectURL("name", "PUT", "", expires)
+	c.Check(err, check.ErrorMatches, "S3 transfer acceleration does not support path-style addressing")
+}
+
+func (s *S) TestAddressingErrors(c *check.C) {
+	auth := aws.Auth{AccessKey: "abc", SecretKey: "123"}
+	client := s3.New(auth, aws.USEast)
+	client.Accelerate = true
+	err := client.Bucket("my.bucket").Del("name")
+	c.Assert(err, check.ErrorMatches, `bad S3 bucket for transfer acceleration: "my.bucket"`)
+
+	_, err = client.Bucket("my.bucket").ObjectURL("name")
+	c.Assert(err, check.ErrorMatches, `bad S3 bucket for transfer acceleration: "my.bucket"`)
+	c.Assert(func() { client.Bucket("my.bucket").URL("name") }, check.PanicMatches, `bad S3 bucket .*`)
+
+	client.AddressingStyle = s3.PathStyle
+	err = client.Bucket("bucket").Del("name")
+	c.Assert(err, check.ErrorMatches, "S3 transfer acceleration does not support path-style addressing")
+	_, err = client.Bucket("bucket").SignedObjectURL("name", time.Now().Add(time.Hour))
+	c.Assert(err, check.ErrorMatches, "S3 transfer acceleration does not support path-style addressing")
+
+	client = s3.New(auth, aws.Region{S3Endpoint: "https://s3.amazonaws.com"})
+	client.Dualstack = true
+	_, err = client.Bucket("bucket").ObjectURL("name")
+	c.Assert(err, check.ErrorMatches, "S3 dual-stack endpoints require a region name")
+	c.Assert(func() { client.Bucket("bucket").SignedURL("name", time.Now()) }, check.PanicMatches, "S3 dual-stack .*")
+}
+
+func (s *S) TestPathStyleOverridesBucketEndpoint(c *check.C) {
+	testServer.Response(200, nil, "content")
+
+	region := aws.Region{
+		Name:             "faux-region-1",
+		S3Endpoint:       testServer.URL,
+		S3BucketEndpoint: "http://${bucket}.invalid",
+	}
+	client := s3.New(aws.Auth{AccessKey: "abc", SecretKey: "123"}, region)
+	client.AddressingStyle = s3.PathStyle
+	data, err := client.Bucket("bucket").Get("name")
+	c.Assert(err, check.IsNil)
+	c.Assert(string(data), check.Equals, "content")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.URL.Path, check.Equals, "/bucket/name")
+
+	// The V2 signature covers the bucket in either style.
+	headers := map[string][]string{
+		"Date":                req.Header["Date"],
+		"x-amz-checksum-mode": req.Header["X-Amz-Checksum-Mode"],
+	}
+	s3.Sign(aws.Auth{AccessKey: "abc", SecretKey: "123"}, "GET", "/bucket/name", nil, headers)
+	c.Assert(req.Header["Authorization"], check.DeepEquals, headers["Authorization"])
+}
+
 func (s *S) TestGetReader(c *check.C) {
 	testServer.Response(200, nil, "content")
 
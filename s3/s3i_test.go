@@ -587,3 +587,87 @@ func (s *ClientTests) TestMultiPutAllZeroLength(c *check.C) {
 	err = multi.Complete(parts)
 	c.Assert(err, check.IsNil)
 }
+
+func (s *ClientTests) TestObjectTagging(c *check.C) {
+	b := testBucket(s.s3)
+	err := b.PutBucket(s3.Private)
+	c.Assert(err, check.IsNil)
+
+	options := s3.Options{Tags: map[string]string{"stage": "test"}}
+	err = b.Put("tagged", []byte("data"), "text/plain", s3.Private, options)
+	c.Assert(err, check.IsNil)
+	defer b.Del("tagged")
+
+	tags, err := b.GetTags("tagged")
+	c.Assert(err, check.IsNil)
+	c.Assert(tags, check.DeepEquals, []s3.Tag{{Key: "stage", Value: "test"}})
+
+	err = b.PutTags("tagged", []s3.Tag{{Key: "project", Value: "goamz"}})
+	c.Assert(err, check.IsNil)
+	tags, err = b.GetTags("tagged")
+	c.Assert(err, check.IsNil)
+	c.Assert(tags, check.DeepEquals, []s3.Tag{{Key: "project", Value: "goamz"}})
+
+	err = b.DeleteTags("tagged")
+	c.Assert(err, check.IsNil)
+	tags, err = b.GetTags("tagged")
+	c.Assert(err, check.IsNil)
+	c.Assert(tags, check.HasLen, 0)
+}
+
+func (s *ClientTests) TestUpdateMetadata(c *check.C) {
+	b := testBucket(s.s3)
+	err := b.PutBucket(s3.Private)
+	c.Assert(err, check.IsNil)
+
+	options := s3.Options{
+		Meta: map[string][]string{"owner": {"nobody"}},
+		Tags: map[string]string{"stage": "test"},
+	}
+	err = b.Put("meta", []byte("data"), "text/plain", s3.Private, options)
+	c.Assert(err, check.IsNil)
+	defer b.Del("meta")
+
+	options.Meta = map[string][]string{"owner": {"goamz"}}
+	options.Tags = map[string]string{"stage": "prod"}
+	_, err = b.UpdateMetadata("meta", "application/json", s3.Private, options)
+	c.Assert(err, check.IsNil)
+
+	resp, err := b.Head("meta", nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.Header.Get("Content-Type"), check.Equals, "application/json")
+	c.Assert(resp.Header.Get("X-Amz-Meta-Owner"), check.Equals, "goamz")
+
+	tags, err := b.GetTags("meta")
+	c.Assert(err, check.IsNil)
+	c.Assert(tags, check.DeepEquals, []s3.Tag{{Key: "stage", Value: "prod"}})
+
+	data, err := b.Get("meta")
+	c.Assert(err, check.IsNil)
+	c.Assert(string(data), check.Equals, "data")
+}
+
+func (s *ClientTests) TestDeletePrefix(c *check.C) {
+	b := testBucket(s.s3)
+	err := b.PutBucket(s3.Private)
+	c.Assert(err, check.IsNil)
+
+	s3.SetDeleteBatchMax(2)
+	defer s3.SetDeleteBatchMax(1000)
+
+	for _, name := range []string{"a/1", "a/2", "a/3", "a/4", "a/5", "b/1"} {
+		err := b.Put(name, []byte(name), "text/plain", s3.Private, s3.Options{})
+		c.Assert(err, check.IsNil)
+	}
+	defer b.Del("b/1")
+
+	result, err := b.DeletePrefix("a/")
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Deleted, check.Equals, 5)
+	c.Assert(result.Errors, check.HasLen, 0)
+
+	resp, err := b.List("", "", "", 0)
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.Contents, check.HasLen, 1)
+	c.Assert(resp.Contents[0].Key, check.Equals, "b/1")
+}
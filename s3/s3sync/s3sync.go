@@ -0,0 +1,447 @@
+// Package s3sync mirrors a local directory or an S3 prefix onto another
+// S3 prefix, transferring only the objects that have changed.
+package s3sync
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"github.com/crowdmob/goamz/s3"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultConcurrency is the number of transfers run in parallel
+// when Options.Concurrency is not set.
+const DefaultConcurrency = 8
+
+// maxDeleteKeys is the largest number of keys accepted by a single
+// multi-object delete request.
+const maxDeleteKeys = 1000
+
+// Options controls the behaviour of a sync.
+type Options struct {
+	// Concurrency bounds the number of transfers in flight.
+	Concurrency int
+
+	// Delete removes keys under the destination prefix that have
+	// no counterpart in the source.
+	Delete bool
+
+	// DryRun reports what would be done without transferring or
+	// deleting anything.
+	DryRun bool
+
+	// Include and Exclude hold glob patterns, as understood by
+	// path.Match, matched against the slash-separated path relative
+	// to the source. Patterns without a slash are also matched against
+	// the base name. When Include is not empty, only matching paths are
+	// synced. Paths matching Exclude are never synced nor deleted.
+	Include []string
+	Exclude []string
+
+	// CheckETag compares the MD5 sum of unchanged-size objects against
+	// the destination ETag rather than relying on modification times.
+	CheckETag bool
+
+	// Perm and Options are used for every uploaded or copied object.
+	Perm    s3.ACL
+	Options s3.Options
+
+	// Output receives a line for every action taken, or planned when
+	// DryRun is set. It may be nil.
+	Output io.Writer
+}
+
+// Result reports the outcome of a sync.
+type Result struct {
+	// Transferred holds the destination keys uploaded or copied.
+	Transferred []string
+	// Deleted holds the destination keys removed.
+	Deleted []string
+	// Unchanged is the number of source entries that were already
+	// up to date.
+	Unchanged int
+}
+
+// Error reports the keys that failed to sync.
+type Error struct {
+	Failures map[string]error
+}
+
+func (e *Error) Error() string {
+	keys := make([]string, 0, len(e.Failures))
+	for k := range e.Failures {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return fmt.Sprintf("s3sync: %d keys failed, first %q: %v", len(keys), keys[0], e.Failures[keys[0]])
+}
+
+// entry describes a single object found in a source or destination.
+type entry struct {
+	name  string // path relative to the synced root, slash-separated.
+	size  int64
+	mtime time.Time
+	etag  string // unquoted, if known.
+	file  string // local file name, for directory sources.
+}
+
+// Dir mirrors the files under the local directory dir onto keys
+// under prefix in dst.
+func Dir(dir string, dst *s3.Bucket, prefix string, options *Options) (*Result, error) {
+	if options == nil {
+		options = &Options{}
+	}
+	src, err := listDir(dir, options)
+	if err != nil {
+		return nil, err
+	}
+	s := newSyncer(dst, prefix, options)
+	return s.run(src, func(e *entry, key string) error {
+		return s.upload(e, key)
+	})
+}
+
+// Bucket mirrors the objects under srcPrefix in src onto keys under
+// dstPrefix in dst, copying them on the server side.
+func Bucket(src *s3.Bucket, srcPrefix string, dst *s3.Bucket, dstPrefix string, options *Options) (*Result, error) {
+	if options == nil {
+		options = &Options{}
+	}
+	entries, err := listBucket(src, srcPrefix, options)
+	if err != nil {
+		return nil, err
+	}
+	s := newSyncer(dst, dstPrefix, options)
+	return s.run(entries, func(e *entry, key string) error {
+		copyOptions := s3.CopyOptions{Options: options.Options}
+		_, err := dst.PutCopy(key, s.perm(), copyOptions, src.Name+"/"+joinKey(srcPrefix, e.name))
+		return err
+	})
+}
+
+type syncer struct {
+	dst     *s3.Bucket
+	prefix  string
+	options *Options
+
+	mu       sync.Mutex
+	result   Result
+	failures map[string]error
+}
+
+func newSyncer(dst *s3.Bucket, prefix string, options *Options) *syncer {
+	return &syncer{
+		dst:      dst,
+		prefix:   prefix,
+		options:  options,
+		failures: make(map[string]error),
+	}
+}
+
+func (s *syncer) perm() s3.ACL {
+	if s.options.Perm == "" {
+		return s3.Private
+	}
+	return s.options.Perm
+}
+
+// run compares src against the destination, transfers changed entries
+// with transfer and removes extraneous keys if requested.
+func (s *syncer) run(src []*entry, transfer func(e *entry, key string) error) (*Result, error) {
+	dst, err := listBucket(s.dst, s.prefix, s.options)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]*entry, len(dst))
+	for _, e := range dst {
+		existing[e.name] = e
+	}
+
+	var pending []*entry
+	for _, e := range src {
+		d := existing[e.name]
+		delete(existing, e.name)
+		if d != nil && !s.changed(e, d) {
+			s.result.Unchanged++
+			continue
+		}
+		pending = append(pending, e)
+	}
+
+	s.parallel(pending, func(e *entry) {
+		key := joinKey(s.prefix, e.name)
+		s.report("transfer", e.name, key)
+		if !s.options.DryRun {
+			if err := transfer(e, key); err != nil {
+				s.fail(key, err)
+				return
+			}
+		}
+		s.mu.Lock()
+		s.result.Transferred = append(s.result.Transferred, key)
+		s.mu.Unlock()
+	})
+
+	if s.options.Delete && len(existing) > 0 {
+		s.deleteExtraneous(existing)
+	}
+
+	sort.Strings(s.result.Transferred)
+	sort.Strings(s.result.Deleted)
+	if len(s.failures) > 0 {
+		return &s.result, &Error{s.failures}
+	}
+	return &s.result, nil
+}
+
+// changed reports whether the source entry src differs from the
+// destination entry dst.
+func (s *syncer) changed(src, dst *entry) bool {
+	if src.size != dst.size {
+		return true
+	}
+	if s.options.CheckETag && !isMultipartETag(dst.etag) {
+		if src.etag == "" && src.file != "" {
+			sum, err := fileMD5(src.file)
+			if err != nil {
+				return true
+			}
+			src.etag = sum
+		}
+		if src.etag != "" && !isMultipartETag(src.etag) {
+			return src.etag != dst.etag
+		}
+	}
+	if src.file == "" && src.etag != "" && dst.etag != "" &&
+		!isMultipartETag(src.etag) && !isMultipartETag(dst.etag) {
+		// Both sides are objects; ETags of copies match when unchanged.
+		// Copies of multipart objects are single part, so their ETags
+		// never match the source's.
+		return src.etag != dst.etag
+	}
+	return src.mtime.After(dst.mtime)
+}
+
+func (s *syncer) deleteExtraneous(existing map[string]*entry) {
+	names := make([]string, 0, len(existing))
+	for name := range existing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var objects []s3.Object
+	for _, name := range names {
+		key := joinKey(s.prefix, name)
+		s.report("delete", "", key)
+		objects = append(objects, s3.Object{Key: key})
+	}
+	for len(objects) > 0 {
+		n := len(objects)
+		if n > maxDeleteKeys {
+			n = maxDeleteKeys
+		}
+		batch := objects[:n]
+		objects = objects[n:]
+		failed := make(map[string]bool)
+		if !s.options.DryRun {
+			resp, err := s.dst.DelMultiResult(s3.Delete{Quiet: true, Objects: batch})
+			if err != nil {
+				for _, obj := range batch {
+					s.fail(obj.Key, err)
+				}
+				continue
+			}
+			for i := range resp.Errors {
+				failed[resp.Errors[i].Key] = true
+				s.fail(resp.Errors[i].Key, &resp.Errors[i])
+			}
+		}
+		for _, obj := range batch {
+			if !failed[obj.Key] {
+				s.result.Deleted = append(s.result.Deleted, obj.Key)
+			}
+		}
+	}
+}
+
+// parallel runs f for every entry with at most
+// options.Concurrency calls in flight.
+func (s *syncer) parallel(entries []*entry, f func(e *entry)) {
+	n := s.options.Concurrency
+	if n <= 0 {
+		n = DefaultConcurrency
+	}
+	work := make(chan *entry)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range work {
+				f(e)
+			}
+		}()
+	}
+	for _, e := range entries {
+		work <- e
+	}
+	close(work)
+	wg.Wait()
+}
+
+func (s *syncer) upload(e *entry, key string) error {
+	f, err := os.Open(e.file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	contType := mime.TypeByExtension(path.Ext(e.name))
+	if contType == "" {
+		contType = "application/octet-stream"
+	}
+	return s.dst.PutReader(key, f, e.size, contType, s.perm(), s.options.Options)
+}
+
+func (s *syncer) fail(key string, err error) {
+	s.mu.Lock()
+	s.failures[key] = err
+	s.mu.Unlock()
+}
+
+func (s *syncer) report(action, name, key string) {
+	if s.options.Output == nil {
+		return
+	}
+	prefix := ""
+	if s.options.DryRun {
+		prefix = "(dryrun) "
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if name == "" {
+		fmt.Fprintf(s.options.Output, "%s%s: s3://%s/%s\n", prefix, action, s.dst.Name, key)
+	} else {
+		fmt.Fprintf(s.options.Output, "%s%s: %s to s3://%s/%s\n", prefix, action, name, s.dst.Name, key)
+	}
+}
+
+// listDir returns the regular files under dir selected by options.
+func listDir(dir string, options *Options) ([]*entry, error) {
+	var entries []*entry
+	err := filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if !selected(name, options) {
+			return nil
+		}
+		entries = append(entries, &entry{
+			name:  name,
+			size:  info.Size(),
+			mtime: info.ModTime(),
+			file:  file,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// listBucket returns the objects under prefix in b selected by options.
+func listBucket(b *s3.Bucket, prefix string, options *Options) ([]*entry, error) {
+	var entries []*entry
+	listPrefix := prefix
+	if listPrefix != "" && !strings.HasSuffix(listPrefix, "/") {
+		listPrefix += "/"
+	}
+	marker := ""
+	for {
+		resp, err := b.List(listPrefix, "", marker, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range resp.Contents {
+			name := key.Key[len(listPrefix):]
+			if name == "" || !selected(name, options) {
+				continue
+			}
+			mtime, _ := time.Parse(time.RFC3339, key.LastModified)
+			entries = append(entries, &entry{
+				name:  name,
+				size:  key.Size,
+				mtime: mtime,
+				etag:  strings.Trim(key.ETag, `"`),
+			})
+		}
+		if !resp.IsTruncated || len(resp.Contents) == 0 {
+			return entries, nil
+		}
+		marker = resp.Contents[len(resp.Contents)-1].Key
+	}
+}
+
+// selected reports whether name passes the include and
+// exclude patterns in options.
+func selected(name string, options *Options) bool {
+	if len(options.Include) > 0 && !matchAny(options.Include, name) {
+		return false
+	}
+	return !matchAny(options.Exclude, name)
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, path.Base(name)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" || strings.HasSuffix(prefix, "/") {
+		return prefix + name
+	}
+	return prefix + "/" + name
+}
+
+func isMultipartETag(etag string) bool {
+	return strings.Contains(etag, "-")
+}
+
+func fileMD5(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,223 @@
+package s3sync_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/crowdmob/goamz/aws"
+	"github.com/crowdmob/goamz/s3"
+	"github.com/crowdmob/goamz/s3/s3sync"
+	"github.com/crowdmob/goamz/s3/s3test"
+	"gopkg.in/check.v1"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test(t *testing.T) {
+	check.TestingT(t)
+}
+
+type S struct {
+	srv *s3test.Server
+	s3  *s3.S3
+	dir string
+}
+
+var _ = check.Suite(&S{})
+
+func (s *S) SetUpTest(c *check.C) {
+	srv, err := s3test.NewServer(nil)
+	c.Assert(err, check.IsNil)
+	s.srv = srv
+	s.s3 = s3.New(aws.Auth{}, aws.Region{
+		Name:                 "faux-region-1",
+		S3Endpoint:           srv.URL(),
+		S3LocationConstraint: true,
+	})
+	s.dir = c.MkDir()
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	s.srv.Quit()
+}
+
+func (s *S) bucket(c *check.C, name string) *s3.Bucket {
+	b := s.s3.Bucket(name)
+	c.Assert(b.PutBucket(s3.Private), check.IsNil)
+	return b
+}
+
+func (s *S) writeFile(c *check.C, name, data string) {
+	file := filepath.Join(s.dir, filepath.FromSlash(name))
+	c.Assert(os.MkdirAll(filepath.Dir(file), 0755), check.IsNil)
+	c.Assert(ioutil.WriteFile(file, []byte(data), 0644), check.IsNil)
+	// Keep local files older than anything uploaded.
+	old := time.Now().Add(-time.Hour)
+	c.Assert(os.Chtimes(file, old, old), check.IsNil)
+}
+
+func keys(c *check.C, b *s3.Bucket, prefix string) []string {
+	resp, err := b.List(prefix, "", "", 0)
+	c.Assert(err, check.IsNil)
+	var names []string
+	for _, key := range resp.Contents {
+		names = append(names, key.Key)
+	}
+	return names
+}
+
+func (s *S) TestDir(c *check.C) {
+	b := s.bucket(c, "dst")
+	s.writeFile(c, "a.txt", "a")
+	s.writeFile(c, "sub/b.txt", "bb")
+
+	result, err := s3sync.Dir(s.dir, b, "backup", nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Transferred, check.DeepEquals, []string{"backup/a.txt", "backup/sub/b.txt"})
+	c.Assert(result.Unchanged, check.Equals, 0)
+
+	data, err := b.Get("backup/sub/b.txt")
+	c.Assert(err, check.IsNil)
+	c.Assert(string(data), check.Equals, "bb")
+
+	// Only the modified file is uploaded again.
+	s.writeFile(c, "a.txt", "changed")
+	result, err = s3sync.Dir(s.dir, b, "backup", nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Transferred, check.DeepEquals, []string{"backup/a.txt"})
+	c.Assert(result.Unchanged, check.Equals, 1)
+}
+
+func (s *S) TestDirCheckETag(c *check.C) {
+	b := s.bucket(c, "dst")
+	s.writeFile(c, "a.txt", "one")
+	_, err := s3sync.Dir(s.dir, b, "", nil)
+	c.Assert(err, check.IsNil)
+
+	// Same size and an old mtime: only the checksum reveals the change.
+	s.writeFile(c, "a.txt", "two")
+	result, err := s3sync.Dir(s.dir, b, "", nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Transferred, check.HasLen, 0)
+
+	result, err = s3sync.Dir(s.dir, b, "", &s3sync.Options{CheckETag: true})
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Transferred, check.DeepEquals, []string{"a.txt"})
+}
+
+func (s *S) TestDirDeleteAndFilters(c *check.C) {
+	b := s.bucket(c, "dst")
+	c.Assert(b.Put("p/stale.txt", []byte("x"), "text/plain", s3.Private, s3.Options{}), check.IsNil)
+	c.Assert(b.Put("p/keep.log", []byte("x"), "text/plain", s3.Private, s3.Options{}), check.IsNil)
+	c.Assert(b.Put("other", []byte("x"), "text/plain", s3.Private, s3.Options{}), check.IsNil)
+	s.writeFile(c, "a.txt", "a")
+	s.writeFile(c, "debug.log", "log")
+
+	options := &s3sync.Options{Delete: true, Exclude: []string{"*.log"}, Concurrency: 1}
+	result, err := s3sync.Dir(s.dir, b, "p/", options)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Transferred, check.DeepEquals, []string{"p/a.txt"})
+	c.Assert(result.Deleted, check.DeepEquals, []string{"p/stale.txt"})
+	c.Assert(keys(c, b, ""), check.DeepEquals, []string{"other", "p/a.txt", "p/keep.log"})
+}
+
+func (s *S) TestDirDryRun(c *check.C) {
+	b := s.bucket(c, "dst")
+	c.Assert(b.Put("stale", []byte("x"), "text/plain", s3.Private, s3.Options{}), check.IsNil)
+	s.writeFile(c, "a.txt", "a")
+
+	var out bytes.Buffer
+	options := &s3sync.Options{Delete: true, DryRun: true, Output: &out}
+	result, err := s3sync.Dir(s.dir, b, "", options)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Transferred, check.DeepEquals, []string{"a.txt"})
+	c.Assert(result.Deleted, check.DeepEquals, []string{"stale"})
+	c.Assert(out.String(), check.Equals,
+		"(dryrun) transfer: a.txt to s3://dst/a.txt\n"+
+			"(dryrun) delete: s3://dst/stale\n")
+	c.Assert(keys(c, b, ""), check.DeepEquals, []string{"stale"})
+}
+
+func (s *S) TestBucket(c *check.C) {
+	src := s.bucket(c, "src")
+	dst := s.bucket(c, "dst")
+	c.Assert(src.Put("in/a", []byte("a"), "text/plain", s3.Private, s3.Options{}), check.IsNil)
+	c.Assert(src.Put("in/b", []byte("b"), "text/plain", s3.Private, s3.Options{}), check.IsNil)
+	c.Assert(src.Put("out/c", []byte("c"), "text/plain", s3.Private, s3.Options{}), check.IsNil)
+
+	result, err := s3sync.Bucket(src, "in", dst, "mirror", &s3sync.Options{Include: []string{"a"}})
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Transferred, check.DeepEquals, []string{"mirror/a"})
+
+	result, err = s3sync.Bucket(src, "in", dst, "mirror", nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Transferred, check.DeepEquals, []string{"mirror/b"})
+	c.Assert(result.Unchanged, check.Equals, 1)
+
+	data, err := dst.Get("mirror/b")
+	c.Assert(err, check.IsNil)
+	c.Assert(string(data), check.Equals, "b")
+}
+
+// multipartServer serves a source bucket holding a multipart object and
+// a destination bucket receiving copies of it, as S3 does: copies get
+// the plain MD5 of their content as ETag.
+type multipartServer struct {
+	mu     sync.Mutex
+	copies int
+	copied bool
+}
+
+const listResultFormat = `<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">%s</ListBucketResult>`
+
+const listContentsFormat = `<Contents><Key>a</Key><LastModified>%s</LastModified><ETag>&quot;%s&quot;</ETag><Size>10</Size></Contents>`
+
+func (srv *multipartServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	switch {
+	case req.Method == "GET" && strings.HasPrefix(req.URL.Path, "/src"):
+		contents := fmt.Sprintf(listContentsFormat, "2017-01-01T00:00:00.000Z", "9b2cf535f27731c974343645a3985328-2")
+		fmt.Fprintf(w, listResultFormat, contents)
+	case req.Method == "GET" && strings.HasPrefix(req.URL.Path, "/dst"):
+		contents := ""
+		if srv.copied {
+			contents = fmt.Sprintf(listContentsFormat, "2017-01-02T00:00:00.000Z", "e807f1fcf82d132f9bb018ca6738a19f")
+		}
+		fmt.Fprintf(w, listResultFormat, contents)
+	case req.Method == "PUT" && req.URL.Path == "/dst/a":
+		srv.copies++
+		srv.copied = true
+		fmt.Fprint(w, `<CopyObjectResult><ETag>&quot;e807f1fcf82d132f9bb018ca6738a19f&quot;</ETag></CopyObjectResult>`)
+	default:
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+	}
+}
+
+func (s *S) TestBucketMultipartSource(c *check.C) {
+	srv := &multipartServer{}
+	httpSrv := httptest.NewServer(srv)
+	defer httpSrv.Close()
+	s3c := s3.New(aws.Auth{}, aws.Region{Name: "faux-region-1", S3Endpoint: httpSrv.URL})
+	src, dst := s3c.Bucket("src"), s3c.Bucket("dst")
+
+	result, err := s3sync.Bucket(src, "", dst, "", nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Transferred, check.DeepEquals, []string{"a"})
+	c.Assert(srv.copies, check.Equals, 1)
+
+	// The copy's ETag differs from the multipart source's, but size and
+	// modification time show it's up to date.
+	result, err = s3sync.Bucket(src, "", dst, "", nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Transferred, check.HasLen, 0)
+	c.Assert(result.Unchanged, check.Equals, 1)
+	c.Assert(srv.copies, check.Equals, 1)
+}
@@ -77,3 +77,15 @@ func (s *LocalServerSuite) TestBucketList(c *check.C) {
 func (s *LocalServerSuite) TestDoublePutBucket(c *check.C) {
 	s.clientTests.TestDoublePutBucket(c)
 }
+
+func (s *LocalServerSuite) TestObjectTagging(c *check.C) {
+	s.clientTests.TestObjectTagging(c)
+}
+
+func (s *LocalServerSuite) TestUpdateMetadata(c *check.C) {
+	s.clientTests.TestUpdateMetadata(c)
+}
+
+func (s *LocalServerSuite) TestDeletePrefix(c *check.C) {
+	s.clientTests.TestDeletePrefix(c)
+}
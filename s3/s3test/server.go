@@ -3,11 +3,14 @@ package s3test
 import (
 	"bytes"
 	"crypto/md5"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/xml"
 	"fmt"
 	"github.com/crowdmob/goamz/s3"
+	"hash"
+	"hash/crc32"
 	"io"
 	"io/ioutil"
 	"log"
@@ -84,6 +87,7 @@ type object struct {
 	meta     http.Header // metadata to return with requests.
 	checksum []byte      // also held as Content-MD5 in meta.
 	data     []byte
+	tags     []s3.Tag
 }
 
 // A resource encapsulates the subject of an HTTP request.
@@ -157,6 +161,8 @@ func (srv *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
 			switch r := r.(type) {
 			case objectResource:
 				err.BucketName = r.bucket.name
+			case objectTaggingResource:
+				err.BucketName = r.bucket.name
 			case bucketResource:
 				err.BucketName = r.name
 			}
@@ -211,7 +217,6 @@ var unimplementedBucketResourceNames = map[string]bool{
 	"notification":   true,
 	"versions":       true,
 	"requestPayment": true,
-	"versioning":     true,
 	"website":        true,
 	"uploads":        true,
 }
@@ -266,6 +271,9 @@ func (srv *Server) resourceForURL(u *url.URL) (r resource) {
 	if obj := objr.bucket.objects[objr.name]; obj != nil {
 		objr.object = obj
 	}
+	if _, ok := q["tagging"]; ok {
+		return objectTaggingResource{objr}
+	}
 	return objr
 }
 
@@ -295,6 +303,10 @@ func (r bucketResource) get(a *action) interface{} {
 	if r.bucket == nil {
 		fatalf(404, "NoSuchBucket", "The specified bucket does not exist")
 	}
+	if _, ok := a.req.Form["versioning"]; ok {
+		// Versioning is not simulated; report a bucket that never had it enabled.
+		return &s3.VersioningConfiguration{}
+	}
 	delimiter := a.req.Form.Get("delimiter")
 	marker := a.req.Form.Get("marker")
 	maxKeys := -1
@@ -432,9 +444,35 @@ func (r bucketResource) put(a *action) interface{} {
 	return nil
 }
 
-func (bucketResource) post(a *action) interface{} {
-	fatalf(400, "Method", "bucket POST method not available")
-	return nil
+// POST on a bucket with the delete parameter removes multiple objects.
+// http://docs.aws.amazon.com/AmazonS3/latest/API/multiobjectdeleteapi.html
+func (r bucketResource) post(a *action) interface{} {
+	if _, ok := a.req.Form["delete"]; !ok {
+		fatalf(400, "Method", "bucket POST method not available")
+	}
+	if r.bucket == nil {
+		fatalf(404, "NoSuchBucket", "The specified bucket does not exist")
+	}
+	var del s3.Delete
+	if err := xml.NewDecoder(a.req.Body).Decode(&del); err != nil {
+		fatalf(400, "MalformedXML", "The XML you provided was not well-formed or did not validate against our published schema")
+	}
+	if len(del.Objects) > 1000 {
+		fatalf(400, "MalformedXML", "The XML you provided was not well-formed or did not validate against our published schema")
+	}
+	resp := &deleteResult{}
+	for _, obj := range del.Objects {
+		delete(r.bucket.objects, obj.Key)
+		if !del.Quiet {
+			resp.Deleted = append(resp.Deleted, obj)
+		}
+	}
+	return resp
+}
+
+type deleteResult struct {
+	XMLName struct{}    `xml:"DeleteResult"`
+	Deleted []s3.Object `xml:"Deleted"`
 }
 
 // validBucketName returns whether name is a valid bucket name.
@@ -525,6 +563,9 @@ func (objr objectResource) get(a *action) interface{} {
 	h.Set("Content-Length", fmt.Sprint(len(obj.data)))
 	h.Set("ETag", hex.EncodeToString(obj.checksum))
 	h.Set("Last-Modified", obj.mtime.Format(time.RFC1123))
+	if len(obj.tags) > 0 {
+		h.Set("x-amz-tagging-count", strconv.Itoa(len(obj.tags)))
+	}
 	if a.req.Method == "HEAD" {
 		return nil
 	}
@@ -552,6 +593,10 @@ func (objr objectResource) put(a *action) interface{} {
 	// TODO x-amz-server-side-encryption
 	// TODO x-amz-storage-class
 
+	if source := a.req.Header.Get("x-amz-copy-source"); source != "" {
+		return objr.copy(a, source)
+	}
+
 	// TODO is this correct, or should we erase all previous metadata?
 	obj := objr.object
 	if obj == nil {
@@ -579,6 +624,7 @@ func (objr objectResource) put(a *action) interface{} {
 	if expectHash != nil && bytes.Compare(gotHash, expectHash) != 0 {
 		fatalf(400, "BadDigest", "The Content-MD5 you specified did not match what we received")
 	}
+	checkChecksums(a.req.Header, data)
 	if a.req.ContentLength >= 0 && int64(len(data)) != a.req.ContentLength {
 		fatalf(400, "IncompleteBody", "You did not provide the number of bytes specified by the Content-Length HTTP header")
 	}
@@ -590,6 +636,9 @@ func (objr objectResource) put(a *action) interface{} {
 			obj.meta[key] = values
 		}
 	}
+	if tagging := a.req.Header.Get("x-amz-tagging"); tagging != "" {
+		obj.tags = parseTagging(tagging)
+	}
 	obj.data = data
 	obj.checksum = gotHash
 	obj.mtime = time.Now()
@@ -597,6 +646,164 @@ func (objr objectResource) put(a *action) interface{} {
 	return nil
 }
 
+// checkChecksums verifies the x-amz-checksum-* headers of a request
+// against the data received.
+func checkChecksums(header http.Header, data []byte) {
+	checksums := []struct {
+		header string
+		hash   hash.Hash
+	}{
+		{"x-amz-checksum-crc32c", crc32.New(crc32.MakeTable(crc32.Castagnoli))},
+		{"x-amz-checksum-sha256", sha256.New()},
+	}
+	for _, c := range checksums {
+		value := header.Get(c.header)
+		if value == "" {
+			continue
+		}
+		c.hash.Write(data)
+		if base64.StdEncoding.EncodeToString(c.hash.Sum(nil)) != value {
+			fatalf(400, "BadDigest", "The %s you specified did not match the calculated checksum.", c.header)
+		}
+	}
+}
+
+// copy handles a PUT with an x-amz-copy-source header by copying
+// the source object, replacing its metadata if requested.
+// http://docs.aws.amazon.com/AmazonS3/latest/API/RESTObjectCOPY.html
+func (objr objectResource) copy(a *action, source string) interface{} {
+	source, err := url.QueryUnescape(source)
+	if err != nil {
+		fatalf(400, "InvalidArgument", "Copy Source must mention the source bucket and key: sourcebucket/sourcekey")
+	}
+	m := pathRegexp.FindStringSubmatch("/" + strings.TrimPrefix(source, "/"))
+	if m == nil || m[2] == "" || m[4] == "" {
+		fatalf(400, "InvalidArgument", "Copy Source must mention the source bucket and key: sourcebucket/sourcekey")
+	}
+	srcBucket := a.srv.buckets[m[2]]
+	if srcBucket == nil {
+		fatalf(404, "NoSuchBucket", "The specified bucket does not exist")
+	}
+	src := srcBucket.objects[m[4]]
+	if src == nil {
+		fatalf(404, "NoSuchKey", "The specified key does not exist.")
+	}
+
+	obj := &object{
+		name:     objr.name,
+		meta:     make(http.Header),
+		checksum: src.checksum,
+		data:     src.data,
+		tags:     src.tags,
+		mtime:    time.Now(),
+	}
+	switch directive := a.req.Header.Get("x-amz-metadata-directive"); directive {
+	case "", "COPY":
+		if src == objr.object {
+			fatalf(400, "InvalidRequest", "This copy request is illegal because it is trying to copy an object to itself without changing the object's metadata, storage class, website redirect location or encryption attributes.")
+		}
+		for key, values := range src.meta {
+			obj.meta[key] = values
+		}
+	case "REPLACE":
+		for key, values := range a.req.Header {
+			key = http.CanonicalHeaderKey(key)
+			if metaHeaders[key] || strings.HasPrefix(key, "X-Amz-Meta-") {
+				obj.meta[key] = values
+			}
+		}
+	default:
+		fatalf(400, "InvalidArgument", "Unknown metadata directive %q", directive)
+	}
+	switch directive := a.req.Header.Get("x-amz-tagging-directive"); directive {
+	case "", "COPY":
+	case "REPLACE":
+		obj.tags = parseTagging(a.req.Header.Get("x-amz-tagging"))
+	default:
+		fatalf(400, "InvalidArgument", "Unknown tagging directive %q", directive)
+	}
+	objr.bucket.objects[objr.name] = obj
+
+	return &s3.CopyObjectResult{
+		ETag:         fmt.Sprintf(`"%x"`, obj.checksum),
+		LastModified: obj.mtime.Format(timeFormat),
+	}
+}
+
+// parseTagging parses the URL-encoded value of an x-amz-tagging header.
+func parseTagging(tagging string) []s3.Tag {
+	q, err := url.ParseQuery(tagging)
+	if err != nil {
+		fatalf(400, "InvalidArgument", "The header 'x-amz-tagging' shall be encoded as UTF-8 then URLEncoded URL query parameters without tag name duplicates.")
+	}
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	tags := make([]s3.Tag, 0, len(keys))
+	for _, k := range keys {
+		tags = append(tags, s3.Tag{Key: k, Value: q.Get(k)})
+	}
+	return tags
+}
+
+// objectTaggingResource is the tagging subresource of an object.
+type objectTaggingResource struct {
+	objectResource
+}
+
+func (r objectTaggingResource) mustObject() *object {
+	if r.object == nil {
+		fatalf(404, "NoSuchKey", "The specified key does not exist.")
+	}
+	return r.object
+}
+
+// GET on an object's tagging subresource returns its tag set.
+// http://docs.aws.amazon.com/AmazonS3/latest/API/RESTObjectGETtagging.html
+func (r objectTaggingResource) get(a *action) interface{} {
+	obj := r.mustObject()
+	if a.req.Method == "HEAD" {
+		return nil
+	}
+	return &s3.Tagging{TagSet: obj.tags}
+}
+
+// PUT on an object's tagging subresource replaces its tag set.
+// http://docs.aws.amazon.com/AmazonS3/latest/API/RESTObjectPUTtagging.html
+func (r objectTaggingResource) put(a *action) interface{} {
+	obj := r.mustObject()
+	var tagging s3.Tagging
+	if err := xml.NewDecoder(a.req.Body).Decode(&tagging); err != nil {
+		fatalf(400, "MalformedXML", "The XML you provided was not well-formed or did not validate against our published schema")
+	}
+	seen := make(map[string]bool)
+	for _, tag := range tagging.TagSet {
+		if seen[tag.Key] {
+			fatalf(400, "InvalidTag", "Cannot provide multiple Tags with the same key")
+		}
+		seen[tag.Key] = true
+	}
+	if len(tagging.TagSet) > 10 {
+		fatalf(400, "BadRequest", "Object tags cannot be greater than 10")
+	}
+	obj.tags = tagging.TagSet
+	return nil
+}
+
+// DELETE on an object's tagging subresource removes all its tags.
+// http://docs.aws.amazon.com/AmazonS3/latest/API/RESTObjectDELETEtagging.html
+func (r objectTaggingResource) delete(a *action) interface{} {
+	obj := r.mustObject()
+	obj.tags = nil
+	return nil
+}
+
+func (r objectTaggingResource) post(a *action) interface{} {
+	return notAllowed()
+}
+
 func (objr objectResource) delete(a *action) interface{} {
 	delete(objr.bucket.objects, objr.name)
 	return nil
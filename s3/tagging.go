@@ -0,0 +1,125 @@
+package s3
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Implements object tagging and in-place metadata updates.
+// See http://docs.aws.amazon.com/AmazonS3/latest/dev/object-tagging.html
+// for details on object tagging.
+
+// The Tag type represents a single key/value tag attached to an object.
+type Tag struct {
+	Key   string `xml:"Key"`
+	Value string `xml:"Value"`
+}
+
+// Tagging is the document used to get and put the tag set of an object.
+type Tagging struct {
+	XMLName xml.Name `xml:"Tagging"`
+	TagSet  []Tag    `xml:"TagSet>Tag"`
+}
+
+// encodeTags encodes tags as expected by the x-amz-tagging header,
+// sorted by key so that requests are deterministic.
+func encodeTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	params := make(url.Values)
+	for _, k := range keys {
+		params.Add(k, tags[k])
+	}
+	return params.Encode()
+}
+
+// GetTags retrieves the tag set of the object at path.
+//
+// See http://docs.aws.amazon.com/AmazonS3/latest/API/RESTObjectGETtagging.html
+// for details.
+func (b *Bucket) GetTags(path string) ([]Tag, error) {
+	req := &request{
+		bucket: b.Name,
+		path:   path,
+		params: url.Values{"tagging": {""}},
+	}
+	var resp Tagging
+	var err error
+	for attempt := attempts.Start(); attempt.Next(); {
+		err = b.S3.query(req, &resp)
+		if !shouldRetry(err) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp.TagSet, nil
+}
+
+// PutTags replaces the tag set of the object at path with tags.
+//
+// See http://docs.aws.amazon.com/AmazonS3/latest/API/RESTObjectPUTtagging.html
+// for details.
+func (b *Bucket) PutTags(path string, tags []Tag) error {
+	doc, err := xml.Marshal(Tagging{TagSet: tags})
+	if err != nil {
+		return err
+	}
+
+	buf := makeXmlBuffer(doc)
+	digest := md5.New()
+	size, err := digest.Write(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	headers := map[string][]string{
+		"Content-Length": {strconv.FormatInt(int64(size), 10)},
+		"Content-MD5":    {base64.StdEncoding.EncodeToString(digest.Sum(nil))},
+		"Content-Type":   {"text/xml"},
+	}
+	req := &request{
+		method:  "PUT",
+		bucket:  b.Name,
+		path:    path,
+		headers: headers,
+		payload: buf,
+		params:  url.Values{"tagging": {""}},
+	}
+	return b.S3.query(req, nil)
+}
+
+// DeleteTags removes all tags from the object at path.
+//
+// See http://docs.aws.amazon.com/AmazonS3/latest/API/RESTObjectDELETEtagging.html
+// for details.
+func (b *Bucket) DeleteTags(path string) error {
+	req := &request{
+		method: "DELETE",
+		bucket: b.Name,
+		path:   path,
+		params: url.Values{"tagging": {""}},
+	}
+	return b.S3.query(req, nil)
+}
+
+// UpdateMetadata replaces the content type and metadata of the object at
+// path without re-uploading its data, by copying the object onto itself.
+// Any metadata not present in options is dropped from the object.
+func (b *Bucket) UpdateMetadata(path string, contType string, perm ACL, options Options) (*CopyObjectResult, error) {
+	copyOptions := CopyOptions{
+		Options:           options,
+		MetadataDirective: "REPLACE",
+		ContentType:       contType,
+	}
+	return b.PutCopy(path, perm, copyOptions, b.Name+"/"+strings.TrimPrefix(path, "/"))
+}
@@ -0,0 +1,113 @@
+package s3_test
+
+import (
+	"github.com/crowdmob/goamz/s3"
+	"gopkg.in/check.v1"
+	"io/ioutil"
+)
+
+func (s *S) TestGetTags(c *check.C) {
+	testServer.Response(200, nil, GetTaggingResultDump)
+
+	b := s.s3.Bucket("bucket")
+	tags, err := b.GetTags("name")
+	c.Assert(err, check.IsNil)
+	c.Assert(tags, check.DeepEquals, []s3.Tag{
+		{Key: "project", Value: "goamz"},
+		{Key: "stage", Value: "test"},
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, check.Equals, "GET")
+	c.Assert(req.URL.Path, check.Equals, "/bucket/name")
+	c.Assert(req.URL.RawQuery, check.Equals, "tagging=")
+}
+
+func (s *S) TestPutTags(c *check.C) {
+	testServer.Response(200, nil, "")
+
+	b := s.s3.Bucket("bucket")
+	err := b.PutTags("name", []s3.Tag{{Key: "project", Value: "goamz"}})
+	c.Assert(err, check.IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, check.Equals, "PUT")
+	c.Assert(req.URL.Path, check.Equals, "/bucket/name")
+	c.Assert(req.URL.RawQuery, check.Equals, "tagging=")
+	c.Assert(req.Header["Content-Md5"], check.HasLen, 1)
+
+	data, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	c.Assert(err, check.IsNil)
+	header := "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n"
+	c.Assert(string(data), check.Equals, header+"<Tagging><TagSet><Tag><Key>project</Key><Value>goamz</Value></Tag></TagSet></Tagging>")
+}
+
+func (s *S) TestDeleteTags(c *check.C) {
+	testServer.Response(204, nil, "")
+
+	b := s.s3.Bucket("bucket")
+	err := b.DeleteTags("name")
+	c.Assert(err, check.IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, check.Equals, "DELETE")
+	c.Assert(req.URL.Path, check.Equals, "/bucket/name")
+	c.Assert(req.URL.RawQuery, check.Equals, "tagging=")
+}
+
+func (s *S) TestPutObjectWithTags(c *check.C) {
+	testServer.Response(200, nil, "")
+
+	b := s.s3.Bucket("bucket")
+	options := s3.Options{Tags: map[string]string{"stage": "test", "project": "go amz"}}
+	err := b.Put("name", []byte("content"), "content-type", s3.Private, options)
+	c.Assert(err, check.IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Header["X-Amz-Tagging"], check.DeepEquals, []string{"project=go+amz&stage=test"})
+}
+
+func (s *S) TestUpdateMetadata(c *check.C) {
+	testServer.Response(200, nil, PutCopyResultDump)
+
+	b := s.s3.Bucket("bucket")
+	options := s3.Options{Meta: map[string][]string{"owner": {"goamz"}}}
+	res, err := b.UpdateMetadata("dir/name", "text/plain", s3.Private, options)
+	c.Assert(err, check.IsNil)
+	c.Assert(res.ETag, check.Equals, `"9b2cf535f27731c974343645a3985328"`)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, check.Equals, "PUT")
+	c.Assert(req.URL.Path, check.Equals, "/bucket/dir/name")
+	c.Assert(req.Header["X-Amz-Copy-Source"], check.DeepEquals, []string{"bucket%2Fdir%2Fname"})
+	c.Assert(req.Header["X-Amz-Metadata-Directive"], check.DeepEquals, []string{"REPLACE"})
+	c.Assert(req.Header["Content-Type"], check.DeepEquals, []string{"text/plain"})
+	c.Assert(req.Header["X-Amz-Meta-Owner"], check.DeepEquals, []string{"goamz"})
+}
+
+func (s *S) TestUpdateMetadataTags(c *check.C) {
+	testServer.Response(200, nil, PutCopyResultDump)
+
+	b := s.s3.Bucket("bucket")
+	options := s3.Options{Tags: map[string]string{"stage": "test"}}
+	_, err := b.UpdateMetadata("name", "text/plain", s3.Private, options)
+	c.Assert(err, check.IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Header["X-Amz-Tagging"], check.DeepEquals, []string{"stage=test"})
+	c.Assert(req.Header["X-Amz-Tagging-Directive"], check.DeepEquals, []string{"REPLACE"})
+}
+
+func (s *S) TestPutCopyTaggingDirective(c *check.C) {
+	testServer.Response(200, nil, PutCopyResultDump)
+
+	b := s.s3.Bucket("bucket")
+	options := s3.CopyOptions{TaggingDirective: "COPY"}
+	_, err := b.PutCopy("name", s3.Private, options, "source-bucket/source-name")
+	c.Assert(err, check.IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Header["X-Amz-Tagging"], check.IsNil)
+	c.Assert(req.Header["X-Amz-Tagging-Directive"], check.DeepEquals, []string{"COPY"})
+}